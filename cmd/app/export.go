@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/admin"
+)
+
+// runExport export referral history as csv or ndjson
+//
+//	referral_service export -format csv -from 2021-08-01 -to 2021-08-31 [-msisdn 62821000000] [-code ABC] [-output file]
+func runExport(cfg *config.AppConfig, args []string) {
+	command := flag.NewFlagSet("export", flag.ExitOnError)
+	format := command.String("format", admin.ExportFormatCSV, "csv or ndjson")
+	msisdn := command.String("msisdn", "", "msisdn of referrer")
+	code := command.String("code", "", "referral code")
+	from := command.String("from", "", "start of referral date (yyyy-MM-dd)")
+	to := command.String("to", "", "end of referral date (yyyy-MM-dd)")
+	output := command.String("output", "", "output file, default to stdout")
+	_ = command.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalln("failed to create output: ", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	buffered := bufio.NewWriter(w)
+
+	exportUseCase := admin.SetupExportUseCase(storage.Setup(cfg).ReferralHistory)
+
+	err := exportUseCase.ExportReferral(context.Background(), admin.ExportReferralRequest{
+		Format: *format,
+		Msisdn: *msisdn,
+		Code:   *code,
+		From:   *from,
+		To:     *to,
+	}, buffered)
+	if err != nil {
+		log.Fatalln("export stopped: ", err)
+	}
+	if err = buffered.Flush(); err != nil {
+		log.Fatalln("failed to write output: ", err)
+	}
+}
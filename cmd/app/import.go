@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/counter"
+	"github.com/candraalim/be_tsel_candra/internal/storage"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/referral"
+)
+
+// runImport import historical referral from csv file
+//
+//	referral_service import -file legacy.csv -report report.csv [-dry-run] [-batch 1000]
+func runImport(cfg *config.AppConfig, args []string) {
+	command := flag.NewFlagSet("import", flag.ExitOnError)
+	file := command.String("file", "", "csv file with column code, msisdn and referral_date")
+	reportFile := command.String("report", "import_report.csv", "csv file of row failed to import")
+	dryRun := command.Bool("dry-run", false, "check every row without inserting them")
+	batchSize := command.Int("batch", 1000, "number of row inserted in single batch")
+	_ = command.Parse(args)
+
+	if *file == "" {
+		command.Usage()
+		os.Exit(2)
+	}
+
+	source, err := os.Open(*file)
+	if err != nil {
+		log.Fatalln("failed to open file: ", err)
+	}
+	defer source.Close()
+
+	report, err := os.Create(*reportFile)
+	if err != nil {
+		log.Fatalln("failed to create report: ", err)
+	}
+	defer report.Close()
+
+	repositories := storage.Setup(cfg)
+	//counter in memory belongs to this process only, it is left to the service to backfill
+	tracker := counter.SetupTracker(counter.SetupReferralCounter(setupCounterConfig(cfg)), repositories.ReferralHistory)
+	importUseCase := referral.SetupImportUseCase(repositories.ReferralCode, repositories.ReferralHistory,
+		repositories.Campaign, cfg.Referral, tracker)
+
+	result, err := importUseCase.ImportReferral(context.Background(), source, report, referral.ImportOption{
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	})
+	fmt.Printf("total: %d, imported: %d, failed: %d, dry run: %v, report: %s\n", result.Total, result.Imported,
+		result.Failed, *dryRun, *reportFile)
+	if err != nil {
+		log.Fatalln("import stopped: ", err)
+	}
+}
@@ -1,26 +1,163 @@
 package main
 
 import (
+	"context"
+	"os"
+	"time"
+
 	"github.com/candraalim/be_tsel_candra/config"
-	"github.com/candraalim/be_tsel_candra/internal/storage/postgresql"
+	"github.com/candraalim/be_tsel_candra/internal/counter"
+	"github.com/candraalim/be_tsel_candra/internal/fraud"
+	"github.com/candraalim/be_tsel_candra/internal/generator"
+	"github.com/candraalim/be_tsel_candra/internal/job"
+	"github.com/candraalim/be_tsel_candra/internal/notification"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage"
 	"github.com/candraalim/be_tsel_candra/internal/transport/http"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/admin"
 	"github.com/candraalim/be_tsel_candra/internal/usecase/inquiry"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/leaderboard"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/link"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/preference"
 	"github.com/candraalim/be_tsel_candra/internal/usecase/referral"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/reward"
+	"github.com/candraalim/be_tsel_candra/internal/webhook"
 )
 
 func main() {
 	cfg := config.LoadFile()
 
-	db := postgresql.NewDatabase(cfg.Database)
-	codeRepo := postgresql.SetupReferralCodeRepository(db)
-	historyRepo := postgresql.SetupReferralHistoryRepository(db)
-	rewardRepo := postgresql.SetupRewardRepository(db)
+	//subcommand, run http service when there is no subcommand
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			runImport(cfg, os.Args[2:])
+			return
+		case "export":
+			runExport(cfg, os.Args[2:])
+			return
+		case "migrate":
+			runMigrate(cfg, os.Args[2:])
+			return
+		}
+	}
+
+	repositories := storage.Setup(cfg)
+	db := repositories.Transactor
+	codeRepo := repositories.ReferralCode
+	historyRepo := repositories.ReferralHistory
+	rewardRepo := repositories.Reward
+	claimRepo := repositories.RewardClaim
+	campaignRepo := repositories.Campaign
+	clickRepo := repositories.ReferralClick
+	subscriptionRepo := repositories.WebhookSubscription
+	deliveryRepo := repositories.WebhookDelivery
+	outboxRepo := repositories.Outbox
+	notificationRepo := repositories.Notification
+	preferenceRepo := repositories.NotificationPreference
+
+	//webhook is optional on config, zero value use default delivery setting
+	webhookConfig := config.WebhookConfig{}
+	if cfg.Webhook != nil {
+		webhookConfig = *cfg.Webhook
+	}
+	dispatcher := webhook.SetupDispatcher(subscriptionRepo, deliveryRepo, &webhookConfig)
+
+	//outbox is optional on config, zero value use default relay setting
+	outboxConfig := config.OutboxConfig{}
+	if cfg.Outbox != nil {
+		outboxConfig = *cfg.Outbox
+	}
+
+	//notification is optional on config, zero value only write notification to log
+	notificationConfig := config.NotificationConfig{}
+	if cfg.Notification != nil {
+		notificationConfig = *cfg.Notification
+	}
+	notificationDispatcher := notification.SetupDispatcher(notificationRepo, preferenceRepo,
+		notification.SetupNotifier(notificationConfig), &notificationConfig)
+
+	relay := outbox.SetupRelay(outboxRepo, outbox.Publishers(dispatcher, notificationDispatcher), &outboxConfig)
 
-	inquiryUseCase := inquiry.SetupInquiryUseCase(codeRepo, historyRepo, rewardRepo)
+	counterConfig := setupCounterConfig(cfg)
+	tracker := counter.SetupTracker(counter.SetupReferralCounter(counterConfig), historyRepo)
+
+	codeGenerator := generator.SetupCodeGenerator(cfg.Referral.CodeConfig())
+
+	inquiryUseCase := inquiry.SetupInquiryUseCase(codeRepo, historyRepo, rewardRepo, campaignRepo, cfg.Referral, codeGenerator, db, outboxRepo, tracker)
 	inquiryHandler := inquiry.SetupInquiringHandler(inquiryUseCase)
 
-	referralUseCase := referral.SetupReferUseCase(codeRepo, historyRepo)
+	ruleEngine := fraud.SetupRuleEngine(historyRepo, codeRepo, cfg.Fraud)
+	referralUseCase := referral.SetupReferUseCase(codeRepo, historyRepo, campaignRepo, rewardRepo, cfg.Referral, ruleEngine, db, outboxRepo, tracker)
 	referralHandler := referral.SetupReferHandler(referralUseCase)
 
-	http.StartHttpService(cfg.Server, cfg.Auth, inquiryHandler, referralHandler)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go job.Schedule(ctx, "expire pending referral", cfg.Referral.PendingCheckInterval(), referralUseCase.ExpirePendingReferral)
+	go job.Schedule(ctx, "relay outbox", outboxConfig.Interval(), relay.Relay)
+	go job.Schedule(ctx, "purge outbox", time.Hour, relay.Purge)
+	go job.Schedule(ctx, "deliver webhook", webhookConfig.Interval(), dispatcher.DeliverPending)
+	go job.Schedule(ctx, "send notification", notificationConfig.Interval(), notificationDispatcher.SendPending)
+	go job.Schedule(ctx, "reconcile referral counter", counterConfig.ReconcileInterval(), tracker.Reconcile)
+
+	rewardUseCase := reward.SetupRewardUseCase(historyRepo, rewardRepo, claimRepo, cfg.Referral, db)
+	rewardHandler := reward.SetupRewardHandler(rewardUseCase)
+
+	leaderboardUseCase := leaderboard.SetupLeaderboardUseCase(historyRepo, cfg.Referral)
+	leaderboardHandler := leaderboard.SetupLeaderboardHandler(leaderboardUseCase)
+
+	linkUseCase := link.SetupLinkUseCase(codeRepo, clickRepo, cfg.Referral)
+	linkHandler := link.SetupLinkHandler(linkUseCase)
+
+	preferenceUseCase := preference.SetupPreferenceUseCase(preferenceRepo, &notificationConfig)
+	preferenceHandler := preference.SetupPreferenceHandler(preferenceUseCase)
+
+	adminRewardUseCase := admin.SetupRewardUseCase(rewardRepo, campaignRepo)
+	adminRewardHandler := admin.SetupRewardHandler(adminRewardUseCase)
+
+	adminCampaignUseCase := admin.SetupCampaignUseCase(campaignRepo)
+	adminCampaignHandler := admin.SetupCampaignHandler(adminCampaignUseCase)
+
+	adminCodeUseCase := admin.SetupCodeUseCase(codeRepo, db)
+	adminCodeHandler := admin.SetupCodeHandler(adminCodeUseCase)
+
+	adminReferralUseCase := admin.SetupReferralUseCase(historyRepo, claimRepo, cfg.Referral, db, outboxRepo, tracker)
+	adminReferralHandler := admin.SetupReferralHandler(adminReferralUseCase)
+
+	adminExportUseCase := admin.SetupExportUseCase(historyRepo)
+	adminExportHandler := admin.SetupExportHandler(adminExportUseCase)
+
+	adminReportUseCase := admin.SetupReportUseCase(historyRepo)
+	adminReportHandler := admin.SetupReportHandler(adminReportUseCase)
+
+	adminClickUseCase := admin.SetupClickUseCase(clickRepo)
+	adminClickHandler := admin.SetupClickHandler(adminClickUseCase)
+
+	adminWebhookUseCase := admin.SetupWebhookUseCase(subscriptionRepo, deliveryRepo)
+	adminWebhookHandler := admin.SetupWebhookHandler(adminWebhookUseCase)
+
+	http.StartHttpService(cfg.Server, cfg.Auth, cfg.Admin, http.Handlers{
+		Inquiring:     inquiryHandler,
+		Referral:      referralHandler,
+		Reward:        rewardHandler,
+		Leaderboard:   leaderboardHandler,
+		Link:          linkHandler,
+		Preference:    preferenceHandler,
+		AdminReward:   adminRewardHandler,
+		AdminCampaign: adminCampaignHandler,
+		AdminCode:     adminCodeHandler,
+		AdminReferral: adminReferralHandler,
+		AdminExport:   adminExportHandler,
+		AdminReport:   adminReportHandler,
+		AdminClick:    adminClickHandler,
+		AdminWebhook:  adminWebhookHandler,
+	})
+}
+
+// setupCounterConfig return counter config, counter is optional on config and zero value keep counter in memory
+func setupCounterConfig(cfg *config.AppConfig) config.CounterConfig {
+	if cfg.Counter == nil {
+		return config.CounterConfig{}
+	}
+	return *cfg.Counter
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/postgresql"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+// runMigrate apply or revert schema migration of postgresql database on configured schema
+//
+//	referral_service migrate up|down|status
+func runMigrate(cfg *config.AppConfig, args []string) {
+	command := flag.NewFlagSet("migrate", flag.ExitOnError)
+	command.Usage = func() {
+		fmt.Fprintln(command.Output(), "usage: migrate up|down|status")
+		fmt.Fprintln(command.Output(), "  up      apply every pending migration")
+		fmt.Fprintln(command.Output(), "  down    revert the last applied migration")
+		fmt.Fprintln(command.Output(), "  status  list migration and when it is applied")
+	}
+	_ = command.Parse(args)
+
+	if command.NArg() != 1 {
+		command.Usage()
+		os.Exit(2)
+	}
+
+	//only postgresql is migrated, sqlite schema is created when database is opened and memory has no schema
+	if cfg.Storage != nil && cfg.Storage.Driver != "" && cfg.Storage.Driver != storage.DriverPostgreSQL {
+		log.Fatalf("migrate is only for %s storage driver, %s storage does not use migration\n",
+			storage.DriverPostgreSQL, cfg.Storage.Driver)
+	}
+
+	ctx := context.Background()
+	migrator := postgresql.SetupMigrator(postgresql.NewDatabase(cfg.Database))
+	switch command.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, v := range applied {
+			fmt.Printf("applied %d %s\n", v.Version, v.Name)
+		}
+		if err != nil {
+			log.Fatalln("migration stopped: ", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if errors.Is(err, util.ErrorDataNotFound) {
+			fmt.Println("no migration applied")
+			return
+		}
+		if err != nil {
+			log.Fatalln("failed to revert migration: ", err)
+		}
+		fmt.Printf("reverted %d %s\n", reverted.Version, reverted.Name)
+	case "status":
+		result, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalln("failed to read migration: ", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, v := range result {
+			applied := "pending"
+			if v.AppliedDate != nil {
+				applied = v.AppliedDate.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", v.Version, v.Name, applied)
+		}
+		_ = w.Flush()
+	default:
+		command.Usage()
+		os.Exit(2)
+	}
+}
@@ -4,13 +4,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 )
 
 type AppConfig struct {
-	Server   *ServerConfig   `json:"server"`
-	Auth     *AuthConfig     `json:"auth"`
-	Database *DatabaseConfig `json:"database"`
+	Server       *ServerConfig       `json:"server"`
+	Auth         *AuthConfig         `json:"auth"`
+	Admin        *AuthConfig         `json:"admin"`
+	Database     *DatabaseConfig     `json:"database"`
+	Storage      *StorageConfig      `json:"storage"`
+	Referral     *ReferralConfig     `json:"referral"`
+	Fraud        *FraudConfig        `json:"fraud"`
+	Webhook      *WebhookConfig      `json:"webhook"`
+	Outbox       *OutboxConfig       `json:"outbox"`
+	Notification *NotificationConfig `json:"notification"`
+	Counter      *CounterConfig      `json:"counter"`
 }
 
 type ServerConfig struct {
@@ -28,6 +39,21 @@ type DatabaseConfig struct {
 	Port        int    `json:"port"`
 	MaxIdleConn int    `json:"maxIdleConn"`
 	MaxOpenConn int    `json:"maxOpenConn"`
+	// MigrateOnStart apply pending schema migration when service start, otherwise it is applied by migrate command
+	MigrateOnStart bool `json:"migrateOnStart"`
+}
+
+// StorageConfig select where repository store its data, zero value means default
+type StorageConfig struct {
+	// Driver is postgresql, sqlite or memory, memory is not shared between instance and lost on restart.
+	// Default postgresql
+	Driver string        `json:"driver"`
+	SQLite *SQLiteConfig `json:"sqlite"`
+}
+
+type SQLiteConfig struct {
+	// Path is database file, it is created with the schema when it does not exist
+	Path string `json:"path"`
 }
 
 type AuthConfig struct {
@@ -35,6 +61,132 @@ type AuthConfig struct {
 	Password string `json:"password"`
 }
 
+type ReferralConfig struct {
+	CodeGracePeriodHours int         `json:"codeGracePeriodHours"`
+	BlockedCodeWords     []string    `json:"blockedCodeWords"`
+	Code                 *CodeConfig `json:"code"`
+	// MaxLevel is depth of downline earning reward, 1 means only direct referral
+	MaxLevel int `json:"maxLevel"`
+	// PendingExpiryHours is how long referral waits for confirmation before it expires
+	PendingExpiryHours int `json:"pendingExpiryHours"`
+	// PendingCheckMinutes is interval of checking expired pending referral
+	PendingCheckMinutes int `json:"pendingCheckMinutes"`
+	// LeaderboardCacheSeconds is how long monthly leaderboard is cached
+	LeaderboardCacheSeconds int `json:"leaderboardCacheSeconds"`
+	// LandingURL is page of shared referral link, code and channel are appended as query param
+	LandingURL string `json:"landingUrl"`
+	// ShareURL is public base url of this service, referral link of a code is ShareURL/r/:code
+	ShareURL string `json:"shareUrl"`
+}
+
+// CodeConfig is configuration of referral code generator, zero value means default
+type CodeConfig struct {
+	// Strategy is one of hex, base32, pronounceable or alphabet, default hex
+	Strategy string `json:"strategy"`
+	// Length is length of generated code excluding prefix
+	Length int    `json:"length"`
+	Prefix string `json:"prefix"`
+	// Alphabet is characters used by alphabet strategy
+	Alphabet string `json:"alphabet"`
+	// CheckDigit append check digit to generated code so mistyped code is rejected without lookup to db
+	CheckDigit bool `json:"checkDigit"`
+	// MaxRetry is how many times code regenerated when it collides with existing code
+	MaxRetry int `json:"maxRetry"`
+	// MaxLength is max length of referral code accepted when processing referral
+	MaxLength int `json:"maxLength"`
+}
+
+// FraudConfig is velocity rule of referral, zero limit means the rule is disabled
+type FraudConfig struct {
+	// Action is reject or flag, flagged referral is stored but not counted for reward
+	Action             string `json:"action"`
+	MaxPerCodeHour     int    `json:"maxPerCodeHour"`
+	MaxPerCodeDay      int    `json:"maxPerCodeDay"`
+	MaxPerIPDay        int    `json:"maxPerIpDay"`
+	MaxPerDeviceDay    int    `json:"maxPerDeviceDay"`
+	BurstLimit         int    `json:"burstLimit"`
+	BurstWindowMinutes int    `json:"burstWindowMinutes"`
+	CooldownMinutes    int    `json:"cooldownMinutes"`
+}
+
+// WebhookConfig is delivery of webhook, zero value means default
+type WebhookConfig struct {
+	// IntervalSeconds is interval of sending pending delivery, default 5
+	IntervalSeconds int `json:"intervalSeconds"`
+	// TimeoutSeconds is timeout of each callback, default 10
+	TimeoutSeconds int `json:"timeoutSeconds"`
+	// MaxAttempt is how many time delivery is sent before it is dead, default 8
+	MaxAttempt int `json:"maxAttempt"`
+	// BackoffSeconds is delay before first retry, doubled on each retry up to MaxBackoffSeconds
+	BackoffSeconds    int `json:"backoffSeconds"`
+	MaxBackoffSeconds int `json:"maxBackoffSeconds"`
+	// BatchSize is max delivery sent on each interval, default 50
+	BatchSize int `json:"batchSize"`
+}
+
+// OutboxConfig is relay of outbox event to publisher, zero value means default
+type OutboxConfig struct {
+	// IntervalSeconds is interval of publishing pending event, default 2
+	IntervalSeconds int `json:"intervalSeconds"`
+	// BatchSize is max event published on each interval, default 100
+	BatchSize int `json:"batchSize"`
+	// BackoffSeconds is delay before first retry, doubled on each retry up to MaxBackoffSeconds.
+	// Event is retried until it is published
+	BackoffSeconds    int `json:"backoffSeconds"`
+	MaxBackoffSeconds int `json:"maxBackoffSeconds"`
+	// RetentionHours is how long published event is kept, default 168 (7 days)
+	RetentionHours int `json:"retentionHours"`
+}
+
+// NotificationConfig is notification sent to referrer and referee, zero value means default
+type NotificationConfig struct {
+	// Channel is sms or log, log only write notification to log. Default log
+	Channel string `json:"channel"`
+	// DefaultLanguage is id or en, used for msisdn without preference. Default id
+	DefaultLanguage string `json:"defaultLanguage"`
+	// QuietStartHour and QuietEndHour is hour of day in TimeZone when notification is held until the end of it,
+	// start after end means quiet hours pass midnight. Quiet hours is disabled when both are equal
+	QuietStartHour int `json:"quietStartHour"`
+	QuietEndHour   int `json:"quietEndHour"`
+	// TimeZone of quiet hours, default Asia/Jakarta
+	TimeZone string `json:"timeZone"`
+	// IntervalSeconds is interval of sending pending notification, default 10
+	IntervalSeconds int `json:"intervalSeconds"`
+	// MaxAttempt is how many time notification is sent before it is failed, default 5
+	MaxAttempt int `json:"maxAttempt"`
+	// BackoffSeconds is delay before first retry, doubled on each retry up to MaxBackoffSeconds
+	BackoffSeconds    int `json:"backoffSeconds"`
+	MaxBackoffSeconds int `json:"maxBackoffSeconds"`
+	// BatchSize is max notification sent on each interval, default 50
+	BatchSize int        `json:"batchSize"`
+	SMS       *SMSConfig `json:"sms"`
+}
+
+// SMSConfig is HTTP SMS gateway used by sms channel
+type SMSConfig struct {
+	URL    string `json:"url"`
+	APIKey string `json:"apiKey"`
+	// Sender is sender id shown on recipient phone
+	Sender string `json:"sender"`
+	// TimeoutSeconds is timeout of each request, default 10
+	TimeoutSeconds int `json:"timeoutSeconds"`
+}
+
+// CounterConfig is store of monthly referral counter, zero value means default
+type CounterConfig struct {
+	// Driver is memory or redis, memory counter is not shared between instance. Default memory
+	Driver string `json:"driver"`
+	// ReconcileMinutes is interval of repairing counter drifting from referral history, default 60
+	ReconcileMinutes int          `json:"reconcileMinutes"`
+	Redis            *RedisConfig `json:"redis"`
+}
+
+type RedisConfig struct {
+	Address  string `json:"address"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+}
+
 func LoadFile() *AppConfig {
 	path := os.Getenv("CONFIG_PATH")
 	if len(path) == 0 {
@@ -63,3 +215,236 @@ func LoadFile() *AppConfig {
 func (c ServerConfig) AppAddress() string {
 	return fmt.Sprintf(":%v", c.Port)
 }
+
+// CodeGracePeriod is how long a retired referral code still can be used after regenerated
+func (c ReferralConfig) CodeGracePeriod() time.Duration {
+	return time.Duration(c.CodeGracePeriodHours) * time.Hour
+}
+
+// Depth return depth of downline earning reward, at least 1
+func (c ReferralConfig) Depth() int {
+	if c.MaxLevel < 1 {
+		return 1
+	}
+	return c.MaxLevel
+}
+
+// PendingExpiry default to 72 hours
+func (c ReferralConfig) PendingExpiry() time.Duration {
+	if c.PendingExpiryHours < 1 {
+		return 72 * time.Hour
+	}
+	return time.Duration(c.PendingExpiryHours) * time.Hour
+}
+
+// PendingCheckInterval default to 10 minutes
+func (c ReferralConfig) PendingCheckInterval() time.Duration {
+	if c.PendingCheckMinutes < 1 {
+		return 10 * time.Minute
+	}
+	return time.Duration(c.PendingCheckMinutes) * time.Minute
+}
+
+// LeaderboardCacheTTL default to 60 seconds
+func (c ReferralConfig) LeaderboardCacheTTL() time.Duration {
+	if c.LeaderboardCacheSeconds < 1 {
+		return time.Minute
+	}
+	return time.Duration(c.LeaderboardCacheSeconds) * time.Second
+}
+
+// ShareLink return referral link of code, empty when ShareURL is not configured
+func (c ReferralConfig) ShareLink(code string) string {
+	if c.ShareURL == "" {
+		return ""
+	}
+	return strings.TrimRight(c.ShareURL, "/") + "/r/" + url.PathEscape(code)
+}
+
+// CodeConfig return code generator configuration, default one when it is not configured
+func (c ReferralConfig) CodeConfig() CodeConfig {
+	if c.Code == nil {
+		return CodeConfig{}
+	}
+	return *c.Code
+}
+
+func (c CodeConfig) Retry() int {
+	if c.MaxRetry < 1 {
+		return 10
+	}
+	return c.MaxRetry
+}
+
+// MaxCodeLength default to 20, size of referral_code.code column
+func (c CodeConfig) MaxCodeLength() int {
+	if c.MaxLength < 1 {
+		return 20
+	}
+	return c.MaxLength
+}
+
+// Interval default to 5 seconds
+func (c WebhookConfig) Interval() time.Duration {
+	if c.IntervalSeconds < 1 {
+		return 5 * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Timeout default to 10 seconds
+func (c WebhookConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds < 1 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// Attempt default to 8
+func (c WebhookConfig) Attempt() int {
+	if c.MaxAttempt < 1 {
+		return 8
+	}
+	return c.MaxAttempt
+}
+
+// Backoff return delay before next attempt after attempt-th failure, start from 30 seconds and at most 1 hour by default
+func (c WebhookConfig) Backoff(attempt int) time.Duration {
+	base, max := time.Duration(c.BackoffSeconds)*time.Second, time.Duration(c.MaxBackoffSeconds)*time.Second
+	if base <= 0 {
+		base = 30 * time.Second
+	}
+	if max <= 0 {
+		max = time.Hour
+	}
+	return backoff(base, max, attempt)
+}
+
+// backoff return base doubled on each attempt after the first, at most max
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	result := base
+	for i := 1; i < attempt && result < max; i++ {
+		result *= 2
+	}
+	if result > max {
+		return max
+	}
+	return result
+}
+
+// Batch default to 50
+func (c WebhookConfig) Batch() int {
+	if c.BatchSize < 1 {
+		return 50
+	}
+	return c.BatchSize
+}
+
+// Interval default to 2 seconds
+func (c OutboxConfig) Interval() time.Duration {
+	if c.IntervalSeconds < 1 {
+		return 2 * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Batch default to 100
+func (c OutboxConfig) Batch() int {
+	if c.BatchSize < 1 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+// Backoff return delay before next attempt after attempt-th failure, start from 5 seconds and at most 10 minutes by default
+func (c OutboxConfig) Backoff(attempt int) time.Duration {
+	base, max := time.Duration(c.BackoffSeconds)*time.Second, time.Duration(c.MaxBackoffSeconds)*time.Second
+	if base <= 0 {
+		base = 5 * time.Second
+	}
+	if max <= 0 {
+		max = 10 * time.Minute
+	}
+	return backoff(base, max, attempt)
+}
+
+// Retention default to 7 days
+func (c OutboxConfig) Retention() time.Duration {
+	if c.RetentionHours < 1 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(c.RetentionHours) * time.Hour
+}
+
+// Language default to id
+func (c NotificationConfig) Language() string {
+	if c.DefaultLanguage == "" {
+		return "id"
+	}
+	return c.DefaultLanguage
+}
+
+// Location return time zone of quiet hours, default to Asia/Jakarta and fixed UTC+7 when tz database is not available
+func (c NotificationConfig) Location() *time.Location {
+	name := c.TimeZone
+	if name == "" {
+		name = "Asia/Jakarta"
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return location
+}
+
+// Interval default to 10 seconds
+func (c NotificationConfig) Interval() time.Duration {
+	if c.IntervalSeconds < 1 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Attempt default to 5
+func (c NotificationConfig) Attempt() int {
+	if c.MaxAttempt < 1 {
+		return 5
+	}
+	return c.MaxAttempt
+}
+
+// Backoff return delay before next attempt after attempt-th failure, start from 1 minute and at most 1 hour by default
+func (c NotificationConfig) Backoff(attempt int) time.Duration {
+	base, max := time.Duration(c.BackoffSeconds)*time.Second, time.Duration(c.MaxBackoffSeconds)*time.Second
+	if base <= 0 {
+		base = time.Minute
+	}
+	if max <= 0 {
+		max = time.Hour
+	}
+	return backoff(base, max, attempt)
+}
+
+// Batch default to 50
+func (c NotificationConfig) Batch() int {
+	if c.BatchSize < 1 {
+		return 50
+	}
+	return c.BatchSize
+}
+
+// Timeout default to 10 seconds
+func (c SMSConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds < 1 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// ReconcileInterval default to 60 minutes
+func (c CounterConfig) ReconcileInterval() time.Duration {
+	if c.ReconcileMinutes < 1 {
+		return time.Hour
+	}
+	return time.Duration(c.ReconcileMinutes) * time.Minute
+}
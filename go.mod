@@ -4,11 +4,15 @@ go 1.13
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/alicebob/miniredis/v2 v2.30.0
 	github.com/go-playground/universal-translator v0.18.0 // indirect
 	github.com/jmoiron/sqlx v1.3.4
 	github.com/labstack/echo/v4 v4.1.16
 	github.com/leodido/go-urn v1.2.1 // indirect
 	github.com/lib/pq v1.2.0
+	github.com/mattn/go-sqlite3 v1.14.6
+	github.com/redis/go-redis/v9 v9.0.5
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
@@ -0,0 +1,25 @@
+package counter
+
+import (
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage/memory"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/redis"
+)
+
+const (
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// SetupReferralCounter return counter store of configured driver, default to memory
+func SetupReferralCounter(counterConfig config.CounterConfig) model.ReferralCounter {
+	switch counterConfig.Driver {
+	case DriverMemory, "":
+		return memory.SetupReferralCounter()
+	case DriverRedis:
+		return redis.SetupReferralCounter(redis.NewClient(counterConfig.Redis))
+	default:
+		panic("unknown counter driver: " + counterConfig.Driver)
+	}
+}
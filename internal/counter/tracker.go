@@ -0,0 +1,91 @@
+package counter
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+// Tracker keep monthly total referral of referrer in model.ReferralCounter, so it is not counted from referral
+// history on every inquiry. Referral history remain the source of truth
+type Tracker interface {
+	// Total return total referral of msisdn in month, counter which is missing is backfilled from referral history
+	Total(ctx context.Context, msisdn, month string) (int, error)
+	// Add add delta to counter of msisdn in month, failure is only logged since counter is repaired by Reconcile
+	Add(ctx context.Context, msisdn, month string, delta int)
+	// Reconcile repair counter of current month which drift from referral history
+	Reconcile(ctx context.Context) error
+}
+
+type tracker struct {
+	counter           model.ReferralCounter
+	historyRepository model.ReferralHistoryRepository
+}
+
+func SetupTracker(counter model.ReferralCounter, historyRepository model.ReferralHistoryRepository) Tracker {
+	if counter == nil {
+		panic("ReferralCounter is nil")
+	}
+	if historyRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	return &tracker{
+		counter:           counter,
+		historyRepository: historyRepository,
+	}
+}
+
+func (t tracker) Total(ctx context.Context, msisdn, month string) (int, error) {
+	total, found, err := t.counter.Get(ctx, msisdn, month)
+	if err == nil && found {
+		return total, nil
+	}
+	//counter is unavailable, fallback to referral history
+	if err != nil {
+		log.Println("failed to get referral counter: ", msisdn, err.Error())
+	}
+
+	total, err = t.historyRepository.GetTotalByMsisdnAndMonth(ctx, msisdn, month)
+	if err != nil {
+		return 0, err
+	}
+	if err := t.counter.Set(ctx, msisdn, month, total); err != nil {
+		log.Println("failed to backfill referral counter: ", msisdn, err.Error())
+	}
+	return total, nil
+}
+
+func (t tracker) Add(ctx context.Context, msisdn, month string, delta int) {
+	if err := t.counter.Increment(ctx, msisdn, month, delta); err != nil {
+		log.Println("failed to increment referral counter: ", msisdn, err.Error())
+	}
+}
+
+func (t tracker) Reconcile(ctx context.Context) error {
+	month := time.Now().Format("2006-01")
+	counters, err := t.counter.GetAll(ctx, month)
+	if err != nil {
+		return err
+	}
+
+	var repaired int
+	for msisdn, total := range counters {
+		actual, err := t.historyRepository.GetTotalByMsisdnAndMonth(ctx, msisdn, month)
+		if err != nil {
+			return err
+		}
+		if actual == total {
+			continue
+		}
+		if err := t.counter.Set(ctx, msisdn, month, actual); err != nil {
+			return err
+		}
+		repaired++
+	}
+	if repaired > 0 {
+		log.Println("referral counter repaired: ", repaired)
+	}
+	return nil
+}
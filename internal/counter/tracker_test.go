@@ -0,0 +1,116 @@
+package counter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+)
+
+func TestSetupTracker(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupTracker(nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupTracker(&mocks.ReferralCounter{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupTracker(&mocks.ReferralCounter{}, &mocks.ReferralHistoryRepository{})
+	})
+}
+
+func Test_tracker_Total(t *testing.T) {
+	t.Run("counter found", func(t *testing.T) {
+		counterMock := &mocks.ReferralCounter{}
+		counterMock.On("Get", mock.Anything, "62821000000", "2021-08").Return(4, true, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+
+		total, err := SetupTracker(counterMock, historyMock).Total(context.Background(), "62821000000", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, 4, total)
+		historyMock.AssertNotCalled(t, "GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("counter missing is backfilled", func(t *testing.T) {
+		counterMock := &mocks.ReferralCounter{}
+		counterMock.On("Get", mock.Anything, "62821000000", "2021-08").Return(0, false, nil)
+		counterMock.On("Set", mock.Anything, "62821000000", "2021-08", 3).Return(nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(3, nil)
+
+		total, err := SetupTracker(counterMock, historyMock).Total(context.Background(), "62821000000", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		counterMock.AssertExpectations(t)
+	})
+	t.Run("counter unavailable fallback to history", func(t *testing.T) {
+		counterMock := &mocks.ReferralCounter{}
+		counterMock.On("Get", mock.Anything, "62821000000", "2021-08").Return(0, false, context.DeadlineExceeded)
+		counterMock.On("Set", mock.Anything, "62821000000", "2021-08", 3).Return(context.DeadlineExceeded)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(3, nil)
+
+		total, err := SetupTracker(counterMock, historyMock).Total(context.Background(), "62821000000", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+	})
+	t.Run("error get history", func(t *testing.T) {
+		counterMock := &mocks.ReferralCounter{}
+		counterMock.On("Get", mock.Anything, "62821000000", "2021-08").Return(0, false, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(0, context.DeadlineExceeded)
+
+		_, err := SetupTracker(counterMock, historyMock).Total(context.Background(), "62821000000", "2021-08")
+		assert.Equal(t, context.DeadlineExceeded, err)
+		counterMock.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func Test_tracker_Add(t *testing.T) {
+	counterMock := &mocks.ReferralCounter{}
+	counterMock.On("Increment", mock.Anything, "62821000000", "2021-08", -1).Return(context.DeadlineExceeded)
+
+	assert.NotPanics(t, func() {
+		SetupTracker(counterMock, &mocks.ReferralHistoryRepository{}).Add(context.Background(), "62821000000", "2021-08", -1)
+	})
+	counterMock.AssertExpectations(t)
+}
+
+func Test_tracker_Reconcile(t *testing.T) {
+	month := time.Now().Format("2006-01")
+	t.Run("error get counter", func(t *testing.T) {
+		counterMock := &mocks.ReferralCounter{}
+		counterMock.On("GetAll", mock.Anything, month).Return(nil, context.DeadlineExceeded)
+
+		assert.NotNil(t, SetupTracker(counterMock, &mocks.ReferralHistoryRepository{}).Reconcile(context.Background()))
+	})
+	t.Run("error get history", func(t *testing.T) {
+		counterMock := &mocks.ReferralCounter{}
+		counterMock.On("GetAll", mock.Anything, month).Return(map[string]int{"62821000000": 2}, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", month).Return(0, context.DeadlineExceeded)
+
+		assert.NotNil(t, SetupTracker(counterMock, historyMock).Reconcile(context.Background()))
+	})
+	t.Run("repair drifted counter", func(t *testing.T) {
+		counterMock := &mocks.ReferralCounter{}
+		counterMock.On("GetAll", mock.Anything, month).Return(map[string]int{"62821000000": 2, "62821000001": 5}, nil)
+		counterMock.On("Set", mock.Anything, "62821000001", month, 4).Return(nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", month).Return(2, nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000001", month).Return(4, nil)
+
+		assert.Nil(t, SetupTracker(counterMock, historyMock).Reconcile(context.Background()))
+		counterMock.AssertExpectations(t)
+		counterMock.AssertNumberOfCalls(t, "Set", 1)
+	})
+}
@@ -0,0 +1,105 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+const (
+	ActionReject = "reject"
+	ActionFlag   = "flag"
+)
+
+// Referral is referral being evaluated, IP address and device id are optional
+type Referral struct {
+	Code      model.ReferralCode
+	IPAddress string
+	DeviceID  string
+}
+
+// Verdict of rule engine, empty violation means referral pass all rules
+type Verdict struct {
+	Violation string
+	Reject    bool
+}
+
+// Rule return name of violated rule, empty when referral is allowed
+type Rule interface {
+	Evaluate(ctx context.Context, referral Referral, now time.Time) (string, error)
+}
+
+type RuleEngine interface {
+	Evaluate(ctx context.Context, referral Referral) (Verdict, error)
+}
+
+type ruleEngine struct {
+	rules  []Rule
+	reject bool
+}
+
+// SetupRuleEngine build velocity rules from configuration, rule with zero limit is not registered
+func SetupRuleEngine(referralHistoryRepository model.ReferralHistoryRepository,
+	referralCodeRepository model.ReferralCodeRepository,
+	fraudConfig *config.FraudConfig) RuleEngine {
+	if referralHistoryRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	if referralCodeRepository == nil {
+		panic("ReferralCodeRepository is nil")
+	}
+	if fraudConfig == nil {
+		return &ruleEngine{}
+	}
+	if fraudConfig.Action != "" && fraudConfig.Action != ActionReject && fraudConfig.Action != ActionFlag {
+		panic(fmt.Sprintf("unknown fraud action %s", fraudConfig.Action))
+	}
+
+	engine := &ruleEngine{reject: fraudConfig.Action == ActionReject}
+	if fraudConfig.BurstLimit > 0 && fraudConfig.BurstWindowMinutes > 0 {
+		engine.rules = append(engine.rules, cooldownRule{
+			historyRepository: referralHistoryRepository,
+			codeRepository:    referralCodeRepository,
+			burstLimit:        fraudConfig.BurstLimit,
+			burstWindow:       time.Duration(fraudConfig.BurstWindowMinutes) * time.Minute,
+			cooldown:          time.Duration(fraudConfig.CooldownMinutes) * time.Minute,
+		})
+	}
+	engine.addVelocityRule(referralHistoryRepository, "code_hourly_limit", time.Hour, fraudConfig.MaxPerCodeHour, byCode)
+	engine.addVelocityRule(referralHistoryRepository, "code_daily_limit", 24*time.Hour, fraudConfig.MaxPerCodeDay, byCode)
+	engine.addVelocityRule(referralHistoryRepository, "ip_daily_limit", 24*time.Hour, fraudConfig.MaxPerIPDay, byIPAddress)
+	engine.addVelocityRule(referralHistoryRepository, "device_daily_limit", 24*time.Hour, fraudConfig.MaxPerDeviceDay, byDevice)
+	return engine
+}
+
+func (e *ruleEngine) addVelocityRule(historyRepository model.ReferralHistoryRepository, name string, window time.Duration,
+	limit int, filter velocityFilter) {
+	if limit < 1 {
+		return
+	}
+	e.rules = append(e.rules, velocityRule{
+		historyRepository: historyRepository,
+		name:              name,
+		window:            window,
+		limit:             limit,
+		filter:            filter,
+	})
+}
+
+// Evaluate run rules in order and stop at the first violation
+func (e ruleEngine) Evaluate(ctx context.Context, referral Referral) (Verdict, error) {
+	now := time.Now()
+	for _, rule := range e.rules {
+		violation, err := rule.Evaluate(ctx, referral, now)
+		if err != nil {
+			return Verdict{}, err
+		}
+		if violation != "" {
+			return Verdict{Violation: violation, Reject: e.reject}, nil
+		}
+	}
+	return Verdict{}, nil
+}
@@ -0,0 +1,138 @@
+package fraud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupRuleEngine(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRuleEngine(nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRuleEngine(&mocks.ReferralHistoryRepository{}, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRuleEngine(&mocks.ReferralHistoryRepository{}, &mocks.ReferralCodeRepository{}, &config.FraudConfig{Action: "block"})
+	})
+	assert.NotPanics(t, func() {
+		SetupRuleEngine(&mocks.ReferralHistoryRepository{}, &mocks.ReferralCodeRepository{}, nil)
+	})
+
+	e := SetupRuleEngine(&mocks.ReferralHistoryRepository{}, &mocks.ReferralCodeRepository{}, &config.FraudConfig{
+		MaxPerCodeHour:     10,
+		MaxPerIPDay:        5,
+		BurstLimit:         10,
+		BurstWindowMinutes: 5,
+	}).(*ruleEngine)
+	assert.Equal(t, 3, len(e.rules))
+	assert.False(t, e.reject)
+}
+
+func Test_ruleEngine_Evaluate(t *testing.T) {
+	code := model.ReferralCode{Code: "ABCABC123A", Msisdn: "628000001111"}
+
+	t.Run("no rule configured", func(t *testing.T) {
+		e := SetupRuleEngine(&mocks.ReferralHistoryRepository{}, &mocks.ReferralCodeRepository{}, nil)
+		verdict, err := e.Evaluate(context.Background(), Referral{Code: code})
+		assert.Nil(t, err)
+		assert.Empty(t, verdict.Violation)
+	})
+	t.Run("error count velocity", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("CountVelocity", mock.Anything, mock.Anything).Return(0, context.DeadlineExceeded)
+
+		e := SetupRuleEngine(historyMock, &mocks.ReferralCodeRepository{}, &config.FraudConfig{MaxPerCodeHour: 10})
+		_, err := e.Evaluate(context.Background(), Referral{Code: code})
+		assert.NotNil(t, err)
+	})
+	t.Run("below limit", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("CountVelocity", mock.Anything, mock.Anything).Return(9, nil)
+
+		e := SetupRuleEngine(historyMock, &mocks.ReferralCodeRepository{}, &config.FraudConfig{MaxPerCodeHour: 10, MaxPerCodeDay: 50})
+		verdict, err := e.Evaluate(context.Background(), Referral{Code: code})
+		assert.Nil(t, err)
+		assert.Empty(t, verdict.Violation)
+		historyMock.AssertNumberOfCalls(t, "CountVelocity", 2)
+	})
+	t.Run("code hourly limit reached and rejected", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("CountVelocity", mock.Anything, mock.MatchedBy(func(f model.ReferralVelocityFilter) bool {
+			return f.Code == "ABCABC123A" && f.Since.Before(time.Now().Add(-59*time.Minute)) &&
+				f.Since.After(time.Now().Add(-61*time.Minute))
+		})).Return(10, nil)
+
+		e := SetupRuleEngine(historyMock, &mocks.ReferralCodeRepository{}, &config.FraudConfig{Action: ActionReject, MaxPerCodeHour: 10})
+		verdict, err := e.Evaluate(context.Background(), Referral{Code: code})
+		assert.Nil(t, err)
+		assert.Equal(t, Verdict{Violation: "code_hourly_limit", Reject: true}, verdict)
+	})
+	t.Run("ip rule skipped without ip address", func(t *testing.T) {
+		e := SetupRuleEngine(&mocks.ReferralHistoryRepository{}, &mocks.ReferralCodeRepository{}, &config.FraudConfig{MaxPerIPDay: 5,
+			MaxPerDeviceDay: 3})
+		verdict, err := e.Evaluate(context.Background(), Referral{Code: code})
+		assert.Nil(t, err)
+		assert.Empty(t, verdict.Violation)
+	})
+	t.Run("device daily limit reached and flagged", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("CountVelocity", mock.Anything, mock.MatchedBy(func(f model.ReferralVelocityFilter) bool {
+			return f.IPAddress == "10.1.2.3"
+		})).Return(1, nil)
+		historyMock.On("CountVelocity", mock.Anything, mock.MatchedBy(func(f model.ReferralVelocityFilter) bool {
+			return f.DeviceID == "device-1"
+		})).Return(3, nil)
+
+		e := SetupRuleEngine(historyMock, &mocks.ReferralCodeRepository{}, &config.FraudConfig{Action: ActionFlag, MaxPerIPDay: 5,
+			MaxPerDeviceDay: 3})
+		verdict, err := e.Evaluate(context.Background(), Referral{Code: code, IPAddress: "10.1.2.3", DeviceID: "device-1"})
+		assert.Nil(t, err)
+		assert.Equal(t, Verdict{Violation: "device_daily_limit"}, verdict)
+	})
+	t.Run("code in cooldown", func(t *testing.T) {
+		cooldownUntil := time.Now().Add(time.Minute)
+		e := SetupRuleEngine(&mocks.ReferralHistoryRepository{}, &mocks.ReferralCodeRepository{}, &config.FraudConfig{BurstLimit: 10,
+			BurstWindowMinutes: 5, CooldownMinutes: 60})
+		verdict, err := e.Evaluate(context.Background(), Referral{Code: model.ReferralCode{Code: "ABCABC123A",
+			CooldownUntil: &cooldownUntil}})
+		assert.Nil(t, err)
+		assert.Equal(t, "code_cooldown", verdict.Violation)
+	})
+	t.Run("burst start cooldown", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("CountVelocity", mock.Anything, mock.Anything).Return(10, nil)
+
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateCooldown", mock.Anything, "ABCABC123A", mock.MatchedBy(func(until time.Time) bool {
+			return until.After(time.Now().Add(59*time.Minute))
+		})).Return(nil)
+
+		past := time.Now().Add(-time.Minute)
+		e := SetupRuleEngine(historyMock, codeMock, &config.FraudConfig{BurstLimit: 10, BurstWindowMinutes: 5, CooldownMinutes: 60})
+		verdict, err := e.Evaluate(context.Background(), Referral{Code: model.ReferralCode{Code: "ABCABC123A",
+			CooldownUntil: &past}})
+		assert.Nil(t, err)
+		assert.Equal(t, "code_burst", verdict.Violation)
+		codeMock.AssertExpectations(t)
+	})
+	t.Run("error start cooldown", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("CountVelocity", mock.Anything, mock.Anything).Return(10, nil)
+
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateCooldown", mock.Anything, mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		e := SetupRuleEngine(historyMock, codeMock, &config.FraudConfig{BurstLimit: 10, BurstWindowMinutes: 5, CooldownMinutes: 60})
+		_, err := e.Evaluate(context.Background(), Referral{Code: code})
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,80 @@
+package fraud
+
+import (
+	"context"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+// velocityFilter return false when referral does not have the attribute filtered by the rule
+type velocityFilter func(referral Referral) (model.ReferralVelocityFilter, bool)
+
+func byCode(referral Referral) (model.ReferralVelocityFilter, bool) {
+	return model.ReferralVelocityFilter{Code: referral.Code.Code}, true
+}
+
+func byIPAddress(referral Referral) (model.ReferralVelocityFilter, bool) {
+	return model.ReferralVelocityFilter{IPAddress: referral.IPAddress}, referral.IPAddress != ""
+}
+
+func byDevice(referral Referral) (model.ReferralVelocityFilter, bool) {
+	return model.ReferralVelocityFilter{DeviceID: referral.DeviceID}, referral.DeviceID != ""
+}
+
+// velocityRule is violated when total referral within window already reach the limit
+type velocityRule struct {
+	historyRepository model.ReferralHistoryRepository
+	name              string
+	window            time.Duration
+	limit             int
+	filter            velocityFilter
+}
+
+func (r velocityRule) Evaluate(ctx context.Context, referral Referral, now time.Time) (string, error) {
+	filter, ok := r.filter(referral)
+	if !ok {
+		return "", nil
+	}
+	filter.Since = now.Add(-r.window)
+	total, err := r.historyRepository.CountVelocity(ctx, filter)
+	if err != nil {
+		return "", err
+	}
+	if total >= r.limit {
+		return r.name, nil
+	}
+	return "", nil
+}
+
+// cooldownRule put code in cooldown when it receive burst of referral, every referral during cooldown is violation
+type cooldownRule struct {
+	historyRepository model.ReferralHistoryRepository
+	codeRepository    model.ReferralCodeRepository
+	burstLimit        int
+	burstWindow       time.Duration
+	cooldown          time.Duration
+}
+
+func (r cooldownRule) Evaluate(ctx context.Context, referral Referral, now time.Time) (string, error) {
+	if referral.Code.InCooldown(now) {
+		return "code_cooldown", nil
+	}
+
+	total, err := r.historyRepository.CountVelocity(ctx, model.ReferralVelocityFilter{
+		Code:  referral.Code.Code,
+		Since: now.Add(-r.burstWindow),
+	})
+	if err != nil {
+		return "", err
+	}
+	if total < r.burstLimit {
+		return "", nil
+	}
+	if r.cooldown > 0 {
+		if err := r.codeRepository.UpdateCooldown(ctx, referral.Code.Code, now.Add(r.cooldown)); err != nil {
+			return "", err
+		}
+	}
+	return "code_burst", nil
+}
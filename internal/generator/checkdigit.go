@@ -0,0 +1,94 @@
+package generator
+
+import "strings"
+
+const (
+	// CheckDigitSeparator separate code and its check digit, code without separator is legacy or vanity code
+	CheckDigitSeparator = "-"
+	// check digit is calculated by luhn mod 36 over this alphabet
+	checkDigitAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// checkDigitGenerator append check digit to code generated by underlying generator, e.g. 4C7AE71563-2
+type checkDigitGenerator struct {
+	generator CodeGenerator
+}
+
+func (g checkDigitGenerator) Generate() (string, error) {
+	code, err := g.generator.Generate()
+	if err != nil {
+		return "", err
+	}
+	check, ok := luhnModN(code)
+	if !ok {
+		return "", errInvalidCheckDigitInput
+	}
+	return code + CheckDigitSeparator + string(check), nil
+}
+
+// ValidCheckDigit return false when code contains character outside alphabet or code has check digit and it does
+// not match the code, code without check digit is valid
+func ValidCheckDigit(code string) bool {
+	if !ValidCodeCharacter(code) {
+		return false
+	}
+	code = strings.ToUpper(code)
+	idx := strings.LastIndex(code, CheckDigitSeparator)
+	if idx < 0 {
+		return true
+	}
+	payload, check := code[:idx], code[idx+len(CheckDigitSeparator):]
+	if len(check) != 1 {
+		return false
+	}
+	expected, ok := luhnModN(payload)
+	return ok && expected == check[0]
+}
+
+// luhnModN calculate check character of code, return false when code contains character outside alphabet
+func luhnModN(code string) (byte, bool) {
+	n := len(checkDigitAlphabet)
+	if code == "" {
+		return 0, false
+	}
+
+	factor, sum := 2, 0
+	for i := len(code) - 1; i >= 0; i-- {
+		codePoint := strings.IndexByte(checkDigitAlphabet, code[i])
+		if codePoint < 0 {
+			return 0, false
+		}
+		addend := factor * codePoint
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+		sum += addend/n + addend%n
+	}
+	return checkDigitAlphabet[(n-sum%n)%n], true
+}
+
+func isCheckDigitAlphabet(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(checkDigitAlphabet, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidCodeCharacter return true when s only contains character of alphabet in any case or check digit separator,
+// so upper case of s has the same length
+func ValidCodeCharacter(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c != CheckDigitSeparator[0] && strings.IndexByte(checkDigitAlphabet, c) < 0 {
+			return false
+		}
+	}
+	return true
+}
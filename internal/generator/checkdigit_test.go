@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+func TestSetupCodeGenerator_CheckDigit(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{CheckDigit: true, Prefix: "TS_"})
+	})
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{CheckDigit: true, Strategy: StrategyAlphabet, Alphabet: "AB#"})
+	})
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{CheckDigit: true, Length: 19})
+	})
+	assert.NotPanics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{CheckDigit: true, Prefix: "TS"})
+	})
+}
+
+func TestCheckDigitGenerator_Generate(t *testing.T) {
+	for _, strategy := range []string{StrategyHex, StrategyBase32, StrategyPronounceable} {
+		t.Run(strategy, func(t *testing.T) {
+			g := SetupCodeGenerator(config.CodeConfig{Strategy: strategy, CheckDigit: true})
+			code, err := g.Generate()
+			assert.Nil(t, err)
+			assert.Equal(t, 12, len(code))
+			assert.Equal(t, 10, strings.Index(code, CheckDigitSeparator))
+			assert.True(t, ValidCheckDigit(code), code)
+		})
+	}
+}
+
+func TestValidCheckDigit(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "legacy code", code: "4C7AE71563", want: true},
+		{name: "vanity code", code: "BUDI2021", want: true},
+		{name: "valid check digit", code: "4C7AE71563-2", want: true},
+		{name: "valid check digit lower case", code: "4c7ae71563-2", want: true},
+		{name: "wrong check digit", code: "4C7AE71563-3", want: false},
+		{name: "mistyped code", code: "4C7AE71568-2", want: false},
+		{name: "missing check digit", code: "4C7AE71563-", want: false},
+		{name: "too long check digit", code: "4C7AE71563-22", want: false},
+		{name: "invalid character", code: "4C7AE_1563-2", want: false},
+		{name: "empty code", code: "-3", want: false},
+		{name: "invalid character without check digit", code: "BUDI_2021", want: false},
+		{name: "non ascii code changing length on upper case", code: "ſſ-A", want: false},
+		{name: "non ascii code", code: "kode∞", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidCheckDigit(tt.code))
+		})
+	}
+}
+
+func TestValidCheckDigit_DetectSingleCharacterError(t *testing.T) {
+	code := "4C7AE71563"
+	check, _ := luhnModN(code)
+	for i := 0; i < len(code); i++ {
+		for j := 0; j < len(checkDigitAlphabet); j++ {
+			if checkDigitAlphabet[j] == code[i] {
+				continue
+			}
+			mistyped := code[:i] + string(checkDigitAlphabet[j]) + code[i+1:]
+			assert.False(t, ValidCheckDigit(mistyped+CheckDigitSeparator+string(check)), mistyped)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+const (
+	StrategyHex           = "hex"
+	StrategyBase32        = "base32"
+	StrategyPronounceable = "pronounceable"
+	StrategyAlphabet      = "alphabet"
+
+	defaultLength = 10
+
+	hexAlphabet = "0123456789ABCDEF"
+	// crockford base32 alphabet, I L O U are excluded to avoid confusion with 1 1 0 V
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// consonant and vowel of pronounceable code, ambiguous character (I L O Q) excluded
+	consonants = "BCDFGHJKMNPRSTVWXZ"
+	vowels     = "AEU"
+)
+
+var errInvalidCheckDigitInput = errors.New("code contains character without check digit value")
+
+// CodeGenerator generate random referral code, uniqueness is not guaranteed
+type CodeGenerator interface {
+	Generate() (string, error)
+}
+
+// SetupCodeGenerator return generator of configured strategy, default is 10 characters of hex.
+// Prefix and alphabet must be alphanumeric even when check digit is disabled, code containing other character is
+// rejected as malformed and code containing check digit separator is always validated as code with check digit
+func SetupCodeGenerator(cfg config.CodeConfig) CodeGenerator {
+	if !isCheckDigitAlphabet(strings.ToUpper(cfg.Prefix)) || (cfg.Strategy == StrategyAlphabet &&
+		!isCheckDigitAlphabet(strings.ToUpper(cfg.Alphabet))) {
+		panic("referral code prefix and alphabet must be alphanumeric")
+	}
+	g := setupStrategy(cfg)
+	if !cfg.CheckDigit {
+		return g
+	}
+	return checkDigitGenerator{generator: g}
+}
+
+func setupStrategy(cfg config.CodeConfig) CodeGenerator {
+	length := cfg.Length
+	if length < 1 {
+		length = defaultLength
+	}
+	total := len(cfg.Prefix) + length
+	if cfg.CheckDigit {
+		total += len(CheckDigitSeparator) + 1
+	}
+	if total > cfg.MaxCodeLength() {
+		panic("referral code length exceed max length")
+	}
+
+	prefix := strings.ToUpper(cfg.Prefix)
+	switch cfg.Strategy {
+	case "", StrategyHex:
+		return alphabetGenerator{prefix: prefix, length: length, alphabet: hexAlphabet}
+	case StrategyBase32:
+		return alphabetGenerator{prefix: prefix, length: length, alphabet: crockfordAlphabet}
+	case StrategyPronounceable:
+		return pronounceableGenerator{prefix: prefix, length: length}
+	case StrategyAlphabet:
+		if len(cfg.Alphabet) < 2 {
+			panic("referral code alphabet is too short")
+		}
+		return alphabetGenerator{prefix: prefix, length: length, alphabet: strings.ToUpper(cfg.Alphabet)}
+	}
+	panic(fmt.Sprintf("unknown referral code strategy %s", cfg.Strategy))
+}
+
+// alphabetGenerator pick each character uniformly from alphabet
+type alphabetGenerator struct {
+	prefix   string
+	length   int
+	alphabet string
+}
+
+func (g alphabetGenerator) Generate() (string, error) {
+	var sb strings.Builder
+	sb.WriteString(g.prefix)
+	for i := 0; i < g.length; i++ {
+		c, err := randomChar(g.alphabet)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), nil
+}
+
+// pronounceableGenerator alternate consonant and vowel so code can be read aloud e.g. BAKUSEDA
+type pronounceableGenerator struct {
+	prefix string
+	length int
+}
+
+func (g pronounceableGenerator) Generate() (string, error) {
+	var sb strings.Builder
+	sb.WriteString(g.prefix)
+	for i := 0; i < g.length; i++ {
+		alphabet := consonants
+		if i%2 == 1 {
+			alphabet = vowels
+		}
+		c, err := randomChar(alphabet)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), nil
+}
+
+func randomChar(alphabet string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+	if err != nil {
+		return 0, err
+	}
+	return alphabet[n.Int64()], nil
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+func TestSetupCodeGenerator(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{Strategy: "unknown"})
+	})
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{Strategy: StrategyAlphabet})
+	})
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{Prefix: "PROMO", Length: 16})
+	})
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{Prefix: "TSEL-"})
+	})
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{Strategy: StrategyAlphabet, Alphabet: "AB-"})
+	})
+	assert.Panics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{Strategy: StrategyAlphabet, Alphabet: "AB#"})
+	})
+	assert.NotPanics(t, func() {
+		SetupCodeGenerator(config.CodeConfig{})
+	})
+}
+
+func TestCodeGenerator_Generate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.CodeConfig
+		length   int
+		alphabet string
+	}{
+		{name: "default hex", cfg: config.CodeConfig{}, length: 10, alphabet: hexAlphabet},
+		{name: "base32", cfg: config.CodeConfig{Strategy: StrategyBase32, Length: 8}, length: 8, alphabet: crockfordAlphabet},
+		{name: "pronounceable", cfg: config.CodeConfig{Strategy: StrategyPronounceable, Length: 8}, length: 8,
+			alphabet: consonants + vowels},
+		{name: "alphabet", cfg: config.CodeConfig{Strategy: StrategyAlphabet, Alphabet: "xyz", Length: 6}, length: 6,
+			alphabet: "XYZ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := SetupCodeGenerator(tt.cfg)
+			code, err := g.Generate()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.length, len(code))
+			for _, c := range code {
+				assert.True(t, strings.ContainsRune(tt.alphabet, c), code)
+			}
+		})
+	}
+	t.Run("with prefix", func(t *testing.T) {
+		g := SetupCodeGenerator(config.CodeConfig{Strategy: StrategyBase32, Prefix: "ts", Length: 6})
+		code, err := g.Generate()
+		assert.Nil(t, err)
+		assert.Equal(t, 8, len(code))
+		assert.True(t, strings.HasPrefix(code, "TS"))
+	})
+	t.Run("pronounceable alternate consonant and vowel", func(t *testing.T) {
+		g := SetupCodeGenerator(config.CodeConfig{Strategy: StrategyPronounceable, Length: 6})
+		code, err := g.Generate()
+		assert.Nil(t, err)
+		for i, c := range code {
+			if i%2 == 0 {
+				assert.True(t, strings.ContainsRune(consonants, c), code)
+			} else {
+				assert.True(t, strings.ContainsRune(vowels, c), code)
+			}
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Task is unit of work run by scheduler
+type Task func(ctx context.Context) error
+
+// Schedule run task every interval until ctx is done, failed run is only logged so next run is still scheduled
+func Schedule(ctx context.Context, name string, interval time.Duration, task Task) {
+	if task == nil {
+		panic("task is nil")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("job stopped: ", name)
+			return
+		case <-ticker.C:
+			if err := task(ctx); err != nil {
+				log.Println("job failed: ", name, err)
+			}
+		}
+	}
+}
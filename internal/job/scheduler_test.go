@@ -0,0 +1,42 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	t.Run("nil task", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Schedule(context.Background(), "nil", time.Millisecond, nil)
+		})
+	})
+	t.Run("run until context done", func(t *testing.T) {
+		var count int32
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan struct{})
+		go func() {
+			Schedule(ctx, "count", time.Millisecond, func(ctx context.Context) error {
+				//failed run does not stop the schedule
+				if atomic.AddInt32(&count, 1) == 3 {
+					cancel()
+				}
+				return errors.New("failed")
+			})
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("schedule is not stopped")
+		}
+		assert.True(t, atomic.LoadInt32(&count) >= 3)
+	})
+}
@@ -3,6 +3,7 @@
 package mocks
 
 import context "context"
+import time "time"
 import mock "github.com/stretchr/testify/mock"
 import model "github.com/candraalim/be_tsel_candra/internal/storage/model"
 
@@ -11,6 +12,29 @@ type ReferralCodeRepository struct {
 	mock.Mock
 }
 
+// FindAllByMsisdn provides a mock function with given fields: ctx, msisdn
+func (_m *ReferralCodeRepository) FindAllByMsisdn(ctx context.Context, msisdn string) ([]model.ReferralCode, error) {
+	ret := _m.Called(ctx, msisdn)
+
+	var r0 []model.ReferralCode
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.ReferralCode); ok {
+		r0 = rf(ctx, msisdn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ReferralCode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, msisdn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByCode provides a mock function with given fields: ctx, code
 func (_m *ReferralCodeRepository) FindByCode(ctx context.Context, code string) (model.ReferralCode, error) {
 	ret := _m.Called(ctx, code)
@@ -66,3 +90,59 @@ func (_m *ReferralCodeRepository) Insert(ctx context.Context, code *model.Referr
 
 	return r0
 }
+
+// Retire provides a mock function with given fields: ctx, code, expiresAt
+func (_m *ReferralCodeRepository) Retire(ctx context.Context, code string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, code, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, code, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCooldown provides a mock function with given fields: ctx, code, cooldownUntil
+func (_m *ReferralCodeRepository) UpdateCooldown(ctx context.Context, code string, cooldownUntil time.Time) error {
+	ret := _m.Called(ctx, code, cooldownUntil)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, code, cooldownUntil)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateExpiry provides a mock function with given fields: ctx, code, expiresAt
+func (_m *ReferralCodeRepository) UpdateExpiry(ctx context.Context, code string, expiresAt *time.Time) error {
+	ret := _m.Called(ctx, code, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time) error); ok {
+		r0 = rf(ctx, code, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, code, status
+func (_m *ReferralCodeRepository) UpdateStatus(ctx context.Context, code string, status int) error {
+	ret := _m.Called(ctx, code, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, code, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -3,6 +3,7 @@
 package mocks
 
 import context "context"
+import time "time"
 import mock "github.com/stretchr/testify/mock"
 import model "github.com/candraalim/be_tsel_candra/internal/storage/model"
 
@@ -11,6 +12,20 @@ type ReferralHistoryRepository struct {
 	mock.Mock
 }
 
+// Confirm provides a mock function with given fields: ctx, ID
+func (_m *ReferralHistoryRepository) Confirm(ctx context.Context, ID int64) error {
+	ret := _m.Called(ctx, ID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CountByMsisdn provides a mock function with given fields: ctx, msisdn
 func (_m *ReferralHistoryRepository) CountByMsisdn(ctx context.Context, msisdn string) (int, error) {
 	ret := _m.Called(ctx, msisdn)
@@ -32,6 +47,48 @@ func (_m *ReferralHistoryRepository) CountByMsisdn(ctx context.Context, msisdn s
 	return r0, r1
 }
 
+// CountVelocity provides a mock function with given fields: ctx, filter
+func (_m *ReferralHistoryRepository) CountVelocity(ctx context.Context, filter model.ReferralVelocityFilter) (int, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReferralVelocityFilter) int); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, model.ReferralVelocityFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpirePending provides a mock function with given fields: ctx, before
+func (_m *ReferralHistoryRepository) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByMsisdn provides a mock function with given fields: ctx, msisdn, offset, limit
 func (_m *ReferralHistoryRepository) FindByMsisdn(ctx context.Context, msisdn string, offset int, limit int) ([]model.ReferralHistory, error) {
 	ret := _m.Called(ctx, msisdn, offset, limit)
@@ -76,6 +133,140 @@ func (_m *ReferralHistoryRepository) FindByMsisdnReferee(ctx context.Context, ms
 	return r0, r1
 }
 
+// FindDownline provides a mock function with given fields: ctx, msisdn, maxLevel
+func (_m *ReferralHistoryRepository) FindDownline(ctx context.Context, msisdn string, maxLevel int) ([]model.ReferralDownline, error) {
+	ret := _m.Called(ctx, msisdn, maxLevel)
+
+	var r0 []model.ReferralDownline
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []model.ReferralDownline); ok {
+		r0 = rf(ctx, msisdn, maxLevel)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ReferralDownline)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, msisdn, maxLevel)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindTopReferrer provides a mock function with given fields: ctx, month, limit
+func (_m *ReferralHistoryRepository) FindTopReferrer(ctx context.Context, month string, limit int) ([]model.ReferralRank, error) {
+	ret := _m.Called(ctx, month, limit)
+
+	var r0 []model.ReferralRank
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []model.ReferralRank); ok {
+		r0 = rf(ctx, month, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ReferralRank)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, month, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRankByMsisdnAndMonth provides a mock function with given fields: ctx, msisdn, month
+func (_m *ReferralHistoryRepository) GetRankByMsisdnAndMonth(ctx context.Context, msisdn string, month string) (model.ReferralRank, error) {
+	ret := _m.Called(ctx, msisdn, month)
+
+	var r0 model.ReferralRank
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) model.ReferralRank); ok {
+		r0 = rf(ctx, msisdn, month)
+	} else {
+		r0 = ret.Get(0).(model.ReferralRank)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, msisdn, month)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatistic provides a mock function with given fields: ctx, from, to, interval
+func (_m *ReferralHistoryRepository) GetStatistic(ctx context.Context, from string, to string, interval string) ([]model.ReferralStatistic, error) {
+	ret := _m.Called(ctx, from, to, interval)
+
+	var r0 []model.ReferralStatistic
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []model.ReferralStatistic); ok {
+		r0 = rf(ctx, from, to, interval)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ReferralStatistic)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, from, to, interval)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTierDistribution provides a mock function with given fields: ctx, from, to
+func (_m *ReferralHistoryRepository) GetTierDistribution(ctx context.Context, from string, to string) ([]model.ReferralTierDistribution, error) {
+	ret := _m.Called(ctx, from, to)
+
+	var r0 []model.ReferralTierDistribution
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []model.ReferralTierDistribution); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ReferralTierDistribution)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTotalByMsisdnAndCampaign provides a mock function with given fields: ctx, msisdn, campaignID
+func (_m *ReferralHistoryRepository) GetTotalByMsisdnAndCampaign(ctx context.Context, msisdn string, campaignID int64) (int, error) {
+	ret := _m.Called(ctx, msisdn, campaignID)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) int); ok {
+		r0 = rf(ctx, msisdn, campaignID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, msisdn, campaignID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTotalByMsisdnAndMonth provides a mock function with given fields: ctx, msisdn, month
 func (_m *ReferralHistoryRepository) GetTotalByMsisdnAndMonth(ctx context.Context, msisdn string, month string) (int, error) {
 	ret := _m.Called(ctx, msisdn, month)
@@ -97,6 +288,29 @@ func (_m *ReferralHistoryRepository) GetTotalByMsisdnAndMonth(ctx context.Contex
 	return r0, r1
 }
 
+// GetTotalPerLevelByMsisdnAndMonth provides a mock function with given fields: ctx, msisdn, month, maxLevel
+func (_m *ReferralHistoryRepository) GetTotalPerLevelByMsisdnAndMonth(ctx context.Context, msisdn string, month string, maxLevel int) ([]model.ReferralLevelTotal, error) {
+	ret := _m.Called(ctx, msisdn, month, maxLevel)
+
+	var r0 []model.ReferralLevelTotal
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []model.ReferralLevelTotal); ok {
+		r0 = rf(ctx, msisdn, month, maxLevel)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ReferralLevelTotal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, msisdn, month, maxLevel)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Insert provides a mock function with given fields: ctx, referral
 func (_m *ReferralHistoryRepository) Insert(ctx context.Context, referral *model.ReferralHistory) error {
 	ret := _m.Called(ctx, referral)
@@ -110,3 +324,66 @@ func (_m *ReferralHistoryRepository) Insert(ctx context.Context, referral *model
 
 	return r0
 }
+
+// InsertBatch provides a mock function with given fields: ctx, referrals
+func (_m *ReferralHistoryRepository) InsertBatch(ctx context.Context, referrals []model.ReferralHistory) error {
+	ret := _m.Called(ctx, referrals)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.ReferralHistory) error); ok {
+		r0 = rf(ctx, referrals)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkRewarded provides a mock function with given fields: ctx, msisdn, month
+func (_m *ReferralHistoryRepository) MarkRewarded(ctx context.Context, msisdn string, month string) (int64, error) {
+	ret := _m.Called(ctx, msisdn, month)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, msisdn, month)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, msisdn, month)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reverse provides a mock function with given fields: ctx, ID, reason
+func (_m *ReferralHistoryRepository) Reverse(ctx context.Context, ID int64, reason string) error {
+	ret := _m.Called(ctx, ID, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, ID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StreamExport provides a mock function with given fields: ctx, filter, fn
+func (_m *ReferralHistoryRepository) StreamExport(ctx context.Context, filter model.ReferralExportFilter, fn func(model.ReferralExport) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReferralExportFilter, func(model.ReferralExport) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -25,6 +25,92 @@ func (_m *RewardRepository) Delete(ctx context.Context, ID int64) error {
 	return r0
 }
 
+// FindAll provides a mock function with given fields: ctx
+func (_m *RewardRepository) FindAll(ctx context.Context) ([]model.Reward, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.Reward
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Reward); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Reward)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByCampaignAndTotalReferral provides a mock function with given fields: ctx, campaignID, totalReferral
+func (_m *RewardRepository) FindByCampaignAndTotalReferral(ctx context.Context, campaignID int64, totalReferral int) (model.Reward, error) {
+	ret := _m.Called(ctx, campaignID, totalReferral)
+
+	var r0 model.Reward
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) model.Reward); ok {
+		r0 = rf(ctx, campaignID, totalReferral)
+	} else {
+		r0 = ret.Get(0).(model.Reward)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, campaignID, totalReferral)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: ctx, ID
+func (_m *RewardRepository) FindByID(ctx context.Context, ID int64) (model.Reward, error) {
+	ret := _m.Called(ctx, ID)
+
+	var r0 model.Reward
+	if rf, ok := ret.Get(0).(func(context.Context, int64) model.Reward); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Get(0).(model.Reward)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByLevelAndTotalReferral provides a mock function with given fields: ctx, level, totalReferral
+func (_m *RewardRepository) FindByLevelAndTotalReferral(ctx context.Context, level int, totalReferral int) (model.Reward, error) {
+	ret := _m.Called(ctx, level, totalReferral)
+
+	var r0 model.Reward
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) model.Reward); ok {
+		r0 = rf(ctx, level, totalReferral)
+	} else {
+		r0 = ret.Get(0).(model.Reward)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, level, totalReferral)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByTotalReferral provides a mock function with given fields: ctx, totalReferral
 func (_m *RewardRepository) FindByTotalReferral(ctx context.Context, totalReferral int) (model.Reward, error) {
 	ret := _m.Called(ctx, totalReferral)
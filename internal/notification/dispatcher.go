@@ -0,0 +1,235 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+// maxErrorLength is size of notification.last_error column
+const maxErrorLength = 255
+
+// claimLease is how long claimed notification is not picked up again, long enough for every message to time out
+const claimLease = 2 * time.Minute
+
+// Dispatcher is outbox.EventPublisher notifying referrer and referee of referral event
+type Dispatcher interface {
+	// Publish queue notification of event for each recipient in its preferred language, recipient which opted out
+	// is skipped. Event without notification is ignored and recipient already queued for the event is not queued again
+	Publish(ctx context.Context, event outbox.Event) error
+	// SendPending send due notification, message due within quiet hours is held until it ends.
+	// Failed notification is retried with exponential backoff until max attempt
+	SendPending(ctx context.Context) error
+}
+
+type recipient struct {
+	msisdn   string
+	template string
+	data     interface{}
+}
+
+type dispatcher struct {
+	notificationRepository model.NotificationRepository
+	preferenceRepository   model.NotificationPreferenceRepository
+	notifier               Notifier
+	config                 config.NotificationConfig
+	location               *time.Location
+}
+
+func SetupDispatcher(notificationRepository model.NotificationRepository,
+	preferenceRepository model.NotificationPreferenceRepository, notifier Notifier,
+	notificationConfig *config.NotificationConfig) Dispatcher {
+	if notificationRepository == nil {
+		panic("NotificationRepository is nil")
+	}
+	if preferenceRepository == nil {
+		panic("NotificationPreferenceRepository is nil")
+	}
+	if notifier == nil {
+		panic("Notifier is nil")
+	}
+	if notificationConfig == nil {
+		notificationConfig = &config.NotificationConfig{}
+	}
+	return &dispatcher{
+		notificationRepository: notificationRepository,
+		preferenceRepository:   preferenceRepository,
+		notifier:               notifier,
+		config:                 *notificationConfig,
+		location:               notificationConfig.Location(),
+	}
+}
+
+func (d dispatcher) Publish(ctx context.Context, event outbox.Event) error {
+	recipients, err := eventRecipients(event)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range recipients {
+		preference, err := d.findPreference(ctx, v.msisdn)
+		if err != nil {
+			return err
+		}
+		if preference.OptOut {
+			log.Println("notification skipped, msisdn opted out: ", util.MaskMsisdn(v.msisdn), v.template)
+			continue
+		}
+
+		message, err := Render(preference.Language, v.template, v.data)
+		if err != nil {
+			return err
+		}
+		err = d.notificationRepository.Insert(ctx, &model.Notification{
+			EventID:         event.ID,
+			Msisdn:          v.msisdn,
+			Template:        v.template,
+			Message:         message,
+			NextAttemptDate: d.quietUntil(time.Now()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventRecipients return recipient notified of event
+func eventRecipients(event outbox.Event) ([]recipient, error) {
+	switch event.Name {
+	case outbox.EventReferralCreated:
+		var data outbox.ReferralEvent
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, err
+		}
+		return []recipient{
+			{msisdn: data.Msisdn, template: TemplateReferralUsed, data: data},
+			{msisdn: data.MsisdnReferee, template: TemplateReferralWelcome, data: data},
+		}, nil
+	case outbox.EventRewardTierReached:
+		var data outbox.TierEvent
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, err
+		}
+		return []recipient{
+			{msisdn: data.Msisdn, template: TemplateRewardTierReached, data: data},
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (d dispatcher) SendPending(ctx context.Context) error {
+	notifications, err := d.notificationRepository.ClaimDue(ctx, claimLease, d.config.Batch())
+	if err != nil || len(notifications) == 0 {
+		return err
+	}
+
+	wg := sync.WaitGroup{}
+	for _, v := range notifications {
+		wg.Add(1)
+		go func(notification model.Notification) {
+			defer wg.Done()
+			d.send(ctx, notification)
+		}(v)
+	}
+	wg.Wait()
+	return nil
+}
+
+// send notification once and store the result
+func (d dispatcher) send(ctx context.Context, notification model.Notification) {
+	now := time.Now()
+	//retry may fall within quiet hours, it is held without counting as attempt
+	if until := d.quietUntil(now); until.After(now) {
+		notification.NextAttemptDate = until
+		d.updateResult(ctx, notification)
+		return
+	}
+
+	//msisdn may opt out after notification is queued
+	preference, err := d.findPreference(ctx, notification.Msisdn)
+	if err != nil {
+		log.Println("failed to find notification preference: ", notification.ID, err)
+		return
+	}
+	if preference.OptOut {
+		notification.Status = model.NotificationStatusCancelled
+		d.updateResult(ctx, notification)
+		return
+	}
+
+	notification.Attempt++
+	notification.LastError = ""
+	err = d.notifier.Send(ctx, notification.Msisdn, notification.Message)
+	switch {
+	case err == nil:
+		notification.Status = model.NotificationStatusSent
+	case notification.Attempt >= d.config.Attempt():
+		notification.Status = model.NotificationStatusFailed
+		log.Println("notification failed: ", notification.ID, err)
+	default:
+		notification.Status = model.NotificationStatusPending
+		notification.NextAttemptDate = now.Add(d.config.Backoff(notification.Attempt))
+	}
+	if err != nil {
+		notification.LastError = truncate(err.Error(), maxErrorLength)
+	}
+	d.updateResult(ctx, notification)
+}
+
+func (d dispatcher) updateResult(ctx context.Context, notification model.Notification) {
+	if err := d.notificationRepository.UpdateResult(ctx, notification); err != nil {
+		log.Println("failed to update notification: ", notification.ID, err)
+	}
+}
+
+// findPreference return preference of msisdn, msisdn without preference receive notification in default language
+func (d dispatcher) findPreference(ctx context.Context, msisdn string) (model.NotificationPreference, error) {
+	preference, err := d.preferenceRepository.FindByMsisdn(ctx, msisdn)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.NotificationPreference{Msisdn: msisdn, Language: d.config.Language()}, nil
+	}
+	return preference, err
+}
+
+// quietUntil return end of quiet hours when t is within it, otherwise t itself
+func (d dispatcher) quietUntil(t time.Time) time.Time {
+	start, end := d.config.QuietStartHour, d.config.QuietEndHour
+	if start == end {
+		return t
+	}
+
+	local := t.In(d.location)
+	hour := local.Hour()
+	quiet := hour >= start && hour < end
+	//quiet hours pass midnight
+	if start > end {
+		quiet = hour >= start || hour < end
+	}
+	if !quiet {
+		return t
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end, 0, 0, 0, d.location)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,225 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+// notifierFunc is Notifier returning result of function
+type notifierFunc func(ctx context.Context, msisdn, message string) error
+
+func (f notifierFunc) Send(ctx context.Context, msisdn, message string) error {
+	return f(ctx, msisdn, message)
+}
+
+// activeConfig has quiet hours which does not include current hour
+func activeConfig() *config.NotificationConfig {
+	hour := time.Now().UTC().Hour()
+	return &config.NotificationConfig{TimeZone: "UTC", QuietStartHour: (hour + 1) % 24, QuietEndHour: (hour + 2) % 24}
+}
+
+// quietConfig has quiet hours which include current hour
+func quietConfig() *config.NotificationConfig {
+	hour := time.Now().UTC().Hour()
+	return &config.NotificationConfig{TimeZone: "UTC", QuietStartHour: hour, QuietEndHour: (hour + 1) % 24}
+}
+
+func TestSetupDispatcher(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupDispatcher(nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupDispatcher(&mocks.NotificationRepository{}, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupDispatcher(&mocks.NotificationRepository{}, &mocks.NotificationPreferenceRepository{}, nil, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupDispatcher(&mocks.NotificationRepository{}, &mocks.NotificationPreferenceRepository{}, &MemoryNotifier{}, nil)
+	})
+}
+
+func Test_dispatcher_quietUntil(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	tests := []struct {
+		name       string
+		start, end int
+		at         time.Time
+		want       time.Time
+	}{
+		{"disabled", 0, 0, time.Date(2021, 8, 12, 23, 0, 0, 0, wib), time.Date(2021, 8, 12, 23, 0, 0, 0, wib)},
+		{"before quiet hours", 21, 8, time.Date(2021, 8, 12, 20, 59, 0, 0, wib), time.Date(2021, 8, 12, 20, 59, 0, 0, wib)},
+		{"quiet hours before midnight", 21, 8, time.Date(2021, 8, 12, 22, 30, 0, 0, wib), time.Date(2021, 8, 13, 8, 0, 0, 0, wib)},
+		{"quiet hours after midnight", 21, 8, time.Date(2021, 8, 13, 3, 0, 0, 0, wib), time.Date(2021, 8, 13, 8, 0, 0, 0, wib)},
+		{"end of quiet hours", 21, 8, time.Date(2021, 8, 13, 8, 0, 0, 0, wib), time.Date(2021, 8, 13, 8, 0, 0, 0, wib)},
+		{"quiet hours within a day", 12, 14, time.Date(2021, 8, 12, 13, 0, 0, 0, wib), time.Date(2021, 8, 12, 14, 0, 0, 0, wib)},
+		{"other time zone", 21, 8, time.Date(2021, 8, 12, 16, 0, 0, 0, time.UTC), time.Date(2021, 8, 13, 8, 0, 0, 0, wib)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := dispatcher{config: config.NotificationConfig{QuietStartHour: tt.start, QuietEndHour: tt.end}, location: wib}
+			assert.True(t, tt.want.Equal(d.quietUntil(tt.at)), d.quietUntil(tt.at).String())
+		})
+	}
+}
+
+func Test_dispatcher_Publish(t *testing.T) {
+	referralEvent := outbox.Event{ID: "a1", Name: outbox.EventReferralCreated,
+		Data: json.RawMessage(`{"msisdn":"6280000011","msisdnReferee":"6282100110011","referralCode":"4C7AE71563"}`)}
+
+	t.Run("event without notification", func(t *testing.T) {
+		d := SetupDispatcher(&mocks.NotificationRepository{}, &mocks.NotificationPreferenceRepository{}, &MemoryNotifier{}, nil)
+		err := d.Publish(context.Background(), outbox.Event{ID: "a1", Name: outbox.EventCodeGenerated, Data: json.RawMessage(`{}`)})
+		assert.Nil(t, err)
+	})
+	t.Run("malformed event", func(t *testing.T) {
+		d := SetupDispatcher(&mocks.NotificationRepository{}, &mocks.NotificationPreferenceRepository{}, &MemoryNotifier{}, nil)
+		err := d.Publish(context.Background(), outbox.Event{ID: "a1", Name: outbox.EventRewardTierReached, Data: json.RawMessage(`[`)})
+		assert.NotNil(t, err)
+	})
+	t.Run("error find preference", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{}, context.DeadlineExceeded)
+
+		d := SetupDispatcher(&mocks.NotificationRepository{}, preferenceMock, &MemoryNotifier{}, nil)
+		assert.NotNil(t, d.Publish(context.Background(), referralEvent))
+	})
+	t.Run("error queue notification", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.NotificationPreference{}, sql.ErrNoRows)
+		notificationMock := &mocks.NotificationRepository{}
+		notificationMock.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		d := SetupDispatcher(notificationMock, preferenceMock, &MemoryNotifier{}, nil)
+		assert.NotNil(t, d.Publish(context.Background(), referralEvent))
+	})
+	t.Run("notify referrer and referee in their language", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{
+			Msisdn: "6280000011", Language: LanguageEnglish}, nil)
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6282100110011").Return(model.NotificationPreference{}, sql.ErrNoRows)
+
+		var notifications []*model.Notification
+		notificationMock := &mocks.NotificationRepository{}
+		notificationMock.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			notifications = append(notifications, args.Get(1).(*model.Notification))
+		}).Return(nil)
+
+		d := SetupDispatcher(notificationMock, preferenceMock, &MemoryNotifier{}, activeConfig())
+		assert.Nil(t, d.Publish(context.Background(), referralEvent))
+
+		assert.Len(t, notifications, 2)
+		assert.Equal(t, model.Notification{EventID: "a1", Msisdn: "6280000011", Template: TemplateReferralUsed,
+			Message: "Good news! 6282******011 has used your referral code 4C7AE71563.",
+			NextAttemptDate: notifications[0].NextAttemptDate}, *notifications[0])
+		assert.WithinDuration(t, time.Now(), notifications[0].NextAttemptDate, time.Second)
+		assert.Equal(t, "6282100110011", notifications[1].Msisdn)
+		assert.Equal(t, TemplateReferralWelcome, notifications[1].Template)
+		assert.True(t, strings.HasPrefix(notifications[1].Message, "Terima kasih"))
+	})
+	t.Run("skip msisdn opted out", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{
+			Msisdn: "6280000011", OptOut: true}, nil)
+
+		notificationMock := &mocks.NotificationRepository{}
+
+		d := SetupDispatcher(notificationMock, preferenceMock, &MemoryNotifier{}, nil)
+		err := d.Publish(context.Background(), outbox.Event{ID: "a2", Name: outbox.EventRewardTierReached,
+			Data: json.RawMessage(`{"msisdn":"6280000011","totalReferral":3,"reward":"Bonus Quota 3GB"}`)})
+		assert.Nil(t, err)
+		notificationMock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	})
+	t.Run("held until quiet hours end", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{}, sql.ErrNoRows)
+
+		notificationMock := &mocks.NotificationRepository{}
+		notificationMock.On("Insert", mock.Anything, mock.MatchedBy(func(n *model.Notification) bool {
+			return n.Template == TemplateRewardTierReached && n.NextAttemptDate.After(time.Now()) &&
+				n.NextAttemptDate.Minute() == 0
+		})).Return(nil)
+
+		d := SetupDispatcher(notificationMock, preferenceMock, &MemoryNotifier{}, quietConfig())
+		err := d.Publish(context.Background(), outbox.Event{ID: "a2", Name: outbox.EventRewardTierReached,
+			Data: json.RawMessage(`{"msisdn":"6280000011","totalReferral":3,"reward":"Bonus Quota 3GB"}`)})
+		assert.Nil(t, err)
+		notificationMock.AssertExpectations(t)
+	})
+}
+
+func Test_dispatcher_SendPending(t *testing.T) {
+	t.Run("error claim", func(t *testing.T) {
+		notificationMock := &mocks.NotificationRepository{}
+		notificationMock.On("ClaimDue", mock.Anything, claimLease, 50).Return(nil, context.DeadlineExceeded)
+
+		d := SetupDispatcher(notificationMock, &mocks.NotificationPreferenceRepository{}, &MemoryNotifier{}, nil)
+		assert.NotNil(t, d.SendPending(context.Background()))
+	})
+	t.Run("send, retry, fail and cancel", func(t *testing.T) {
+		notificationMock := &mocks.NotificationRepository{}
+		notificationMock.On("ClaimDue", mock.Anything, claimLease, 10).Return([]model.Notification{
+			{ID: 1, Msisdn: "6280000011", Message: "sent"},
+			{ID: 2, Msisdn: "6280000022", Message: "retry", Attempt: 1},
+			{ID: 3, Msisdn: "6280000033", Message: "failed", Attempt: 2},
+			{ID: 4, Msisdn: "6280000044", Message: "cancelled"},
+		}, nil)
+		notificationMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(n model.Notification) bool {
+			return n.ID == 1 && n.Status == model.NotificationStatusSent && n.Attempt == 1 && n.LastError == ""
+		})).Return(nil)
+		notificationMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(n model.Notification) bool {
+			return n.ID == 2 && n.Status == model.NotificationStatusPending && n.Attempt == 2 &&
+				n.LastError == "unexpected status 503" && n.NextAttemptDate.After(time.Now().Add(119*time.Second))
+		})).Return(nil)
+		notificationMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(n model.Notification) bool {
+			return n.ID == 3 && n.Status == model.NotificationStatusFailed && n.Attempt == 3
+		})).Return(nil)
+		notificationMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(n model.Notification) bool {
+			return n.ID == 4 && n.Status == model.NotificationStatusCancelled && n.Attempt == 0
+		})).Return(context.DeadlineExceeded)
+
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000044").Return(model.NotificationPreference{OptOut: true}, nil)
+		preferenceMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.NotificationPreference{}, sql.ErrNoRows)
+
+		notifier := notifierFunc(func(ctx context.Context, msisdn, message string) error {
+			if message == "sent" {
+				return nil
+			}
+			return errors.New("unexpected status 503")
+		})
+		notificationConfig := activeConfig()
+		notificationConfig.BatchSize, notificationConfig.MaxAttempt = 10, 3
+		d := SetupDispatcher(notificationMock, preferenceMock, notifier, notificationConfig)
+		assert.Nil(t, d.SendPending(context.Background()))
+		notificationMock.AssertExpectations(t)
+	})
+	t.Run("held within quiet hours", func(t *testing.T) {
+		notificationMock := &mocks.NotificationRepository{}
+		notificationMock.On("ClaimDue", mock.Anything, claimLease, 50).Return([]model.Notification{
+			{ID: 1, Msisdn: "6280000011", Message: "held", Attempt: 1},
+		}, nil)
+		notificationMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(n model.Notification) bool {
+			return n.Status == model.NotificationStatusPending && n.Attempt == 1 && n.NextAttemptDate.After(time.Now())
+		})).Return(nil)
+
+		notifier := &MemoryNotifier{}
+		d := SetupDispatcher(notificationMock, &mocks.NotificationPreferenceRepository{}, notifier, quietConfig())
+		assert.Nil(t, d.SendPending(context.Background()))
+		assert.Empty(t, notifier.Messages())
+		notificationMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const (
+	ChannelSMS    = "sms"
+	ChannelLog    = "log"
+	ChannelMemory = "memory"
+)
+
+// Notifier send message to msisdn through a channel, returning error make the message sent again later
+type Notifier interface {
+	Send(ctx context.Context, msisdn, message string) error
+}
+
+// SetupNotifier return notifier of configured channel, default to log
+func SetupNotifier(notificationConfig config.NotificationConfig) Notifier {
+	switch notificationConfig.Channel {
+	case ChannelSMS:
+		return SetupSMSNotifier(notificationConfig.SMS)
+	case ChannelLog, "":
+		return logNotifier{}
+	case ChannelMemory:
+		return &MemoryNotifier{}
+	default:
+		panic("unknown notification channel: " + notificationConfig.Channel)
+	}
+}
+
+type smsRequest struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+type smsNotifier struct {
+	config config.SMSConfig
+	client *http.Client
+}
+
+// SetupSMSNotifier return notifier posting message to HTTP SMS gateway as JSON {"from", "to", "text"},
+// api key is sent as bearer token
+func SetupSMSNotifier(smsConfig *config.SMSConfig) Notifier {
+	if smsConfig == nil || smsConfig.URL == "" {
+		panic("SMSConfig is nil")
+	}
+	return &smsNotifier{
+		config: *smsConfig,
+		client: &http.Client{Timeout: smsConfig.Timeout()},
+	}
+}
+
+func (s smsNotifier) Send(ctx context.Context, msisdn, message string) error {
+	body, err := json.Marshal(smsRequest{
+		From: s.config.Sender,
+		To:   msisdn,
+		Text: message,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if s.config.APIKey != "" {
+		request.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	//drain body so connection is reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4096))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// logNotifier only write message to log, used when there is no gateway
+type logNotifier struct{}
+
+func (l logNotifier) Send(ctx context.Context, msisdn, message string) error {
+	log.Println("notification: ", util.MaskMsisdn(msisdn), message)
+	return nil
+}
+
+type Message struct {
+	Msisdn string
+	Text   string
+}
+
+// MemoryNotifier keep sent message in memory so test can inspect it
+type MemoryNotifier struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (m *MemoryNotifier) Send(ctx context.Context, msisdn, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, Message{Msisdn: msisdn, Text: message})
+	return nil
+}
+
+// Messages return sent message in order they are sent
+func (m *MemoryNotifier) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+func TestSetupNotifier(t *testing.T) {
+	assert.IsType(t, logNotifier{}, SetupNotifier(config.NotificationConfig{}))
+	assert.IsType(t, &MemoryNotifier{}, SetupNotifier(config.NotificationConfig{Channel: ChannelMemory}))
+	assert.IsType(t, &smsNotifier{}, SetupNotifier(config.NotificationConfig{Channel: ChannelSMS,
+		SMS: &config.SMSConfig{URL: "http://localhost"}}))
+	assert.Panics(t, func() {
+		SetupNotifier(config.NotificationConfig{Channel: ChannelSMS})
+	})
+	assert.Panics(t, func() {
+		SetupNotifier(config.NotificationConfig{Channel: "push"})
+	})
+}
+
+func Test_smsNotifier_Send(t *testing.T) {
+	t.Run("unexpected status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		n := SetupSMSNotifier(&config.SMSConfig{URL: server.URL})
+		err := n.Send(context.Background(), "6280000011", "hello")
+		assert.EqualError(t, err, "unexpected status 503")
+	})
+	t.Run("gateway unreachable", func(t *testing.T) {
+		n := SetupSMSNotifier(&config.SMSConfig{URL: "http://127.0.0.1:1"})
+		assert.NotNil(t, n.Send(context.Background(), "6280000011", "hello"))
+	})
+	t.Run("success", func(t *testing.T) {
+		var (
+			request       smsRequest
+			authorization string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		n := SetupSMSNotifier(&config.SMSConfig{URL: server.URL, APIKey: "k3y", Sender: "TSEL"})
+		err := n.Send(context.Background(), "6280000011", "hello")
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer k3y", authorization)
+		assert.Equal(t, smsRequest{From: "TSEL", To: "6280000011", Text: "hello"}, request)
+	})
+}
+
+func TestMemoryNotifier(t *testing.T) {
+	n := &MemoryNotifier{}
+	assert.Nil(t, n.Send(context.Background(), "6280000011", "first"))
+	assert.Nil(t, n.Send(context.Background(), "6280000022", "second"))
+	assert.Equal(t, []Message{{Msisdn: "6280000011", Text: "first"}, {Msisdn: "6280000022", Text: "second"}}, n.Messages())
+}
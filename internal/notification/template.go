@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+)
+
+const (
+	// TemplateReferralUsed is sent to referrer when referee use its code
+	TemplateReferralUsed = "referral_used"
+	// TemplateReferralWelcome is sent to referee after its referral is submitted
+	TemplateReferralWelcome = "referral_welcome"
+	// TemplateRewardTierReached is sent to referrer reaching reward tier of the month
+	TemplateRewardTierReached = "reward_tier_reached"
+)
+
+var templateText = map[string]map[string]string{
+	LanguageIndonesian: {
+		TemplateReferralUsed:      "Selamat! {{mask .MsisdnReferee}} telah menggunakan kode referral {{.ReferralCode}} milik Anda.",
+		TemplateReferralWelcome:   "Terima kasih telah bergabung dengan kode referral {{.ReferralCode}}. Aktifkan kartu Anda untuk mengonfirmasi referral.",
+		TemplateRewardTierReached: "Selamat! Anda telah mengajak {{.TotalReferral}} teman bulan ini dan berhak mendapatkan {{.Reward}}.",
+	},
+	LanguageEnglish: {
+		TemplateReferralUsed:      "Good news! {{mask .MsisdnReferee}} has used your referral code {{.ReferralCode}}.",
+		TemplateReferralWelcome:   "Thank you for joining with referral code {{.ReferralCode}}. Activate your SIM to confirm the referral.",
+		TemplateRewardTierReached: "Congratulations! You have referred {{.TotalReferral}} friends this month and earned {{.Reward}}.",
+	},
+}
+
+var templates = parseTemplates()
+
+func parseTemplates() map[string]map[string]*template.Template {
+	funcs := template.FuncMap{"mask": util.MaskMsisdn}
+	result := make(map[string]map[string]*template.Template, len(templateText))
+	for language, texts := range templateText {
+		result[language] = make(map[string]*template.Template, len(texts))
+		for name, text := range texts {
+			result[language][name] = template.Must(template.New(name).Funcs(funcs).Parse(text))
+		}
+	}
+	return result
+}
+
+// ValidLanguage return true when message is available in language
+func ValidLanguage(language string) bool {
+	_, ok := templates[language]
+	return ok
+}
+
+// Render return message of template in language, unknown language fall back to Indonesian
+func Render(language, name string, data interface{}) (string, error) {
+	if !ValidLanguage(language) {
+		language = LanguageIndonesian
+	}
+	tmpl, ok := templates[language][name]
+	if !ok {
+		return "", fmt.Errorf("unknown notification template %s", name)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+)
+
+func TestRender(t *testing.T) {
+	data := outbox.ReferralEvent{Msisdn: "6280000011", MsisdnReferee: "6282100110011", ReferralCode: "4C7AE71563"}
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := Render(LanguageEnglish, "unknown", data)
+		assert.NotNil(t, err)
+	})
+	t.Run("indonesian", func(t *testing.T) {
+		message, err := Render(LanguageIndonesian, TemplateReferralUsed, data)
+		assert.Nil(t, err)
+		assert.Equal(t, "Selamat! 6282******011 telah menggunakan kode referral 4C7AE71563 milik Anda.", message)
+	})
+	t.Run("english", func(t *testing.T) {
+		message, err := Render(LanguageEnglish, TemplateRewardTierReached, outbox.TierEvent{TotalReferral: 3,
+			Reward: "Bonus Quota 3GB"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Congratulations! You have referred 3 friends this month and earned Bonus Quota 3GB.", message)
+	})
+	t.Run("unknown language fall back to indonesian", func(t *testing.T) {
+		message, err := Render("jv", TemplateReferralWelcome, data)
+		assert.Nil(t, err)
+		assert.Contains(t, message, "Terima kasih")
+	})
+	t.Run("every template is available in every language", func(t *testing.T) {
+		for language := range templateText {
+			for _, name := range []string{TemplateReferralUsed, TemplateReferralWelcome, TemplateRewardTierReached} {
+				_, ok := templates[language][name]
+				assert.True(t, ok, language+" "+name)
+			}
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package outbox
+
+const (
+	EventReferralCreated   = "referral.created"
+	EventReferralReversed  = "referral.reversed"
+	EventRewardTierReached = "reward.tier_reached"
+	EventCodeGenerated     = "code.generated"
+)
+
+// Events is every event can be subscribed
+var Events = []string{EventReferralCreated, EventReferralReversed, EventRewardTierReached, EventCodeGenerated}
+
+// ReferralEvent is data of referral.created and referral.reversed
+type ReferralEvent struct {
+	Msisdn        string `json:"msisdn"`
+	MsisdnReferee string `json:"msisdnReferee"`
+	ReferralCode  string `json:"referralCode"`
+	ReferralDate  string `json:"referralDate"`
+	CampaignID    int64  `json:"campaignId,omitempty"`
+	Status        int    `json:"status"`
+	Flagged       bool   `json:"flagged"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// TierEvent is data of reward.tier_reached, referrer total referral of the month reach reward tier
+type TierEvent struct {
+	Msisdn        string `json:"msisdn"`
+	Month         string `json:"month"`
+	TotalReferral int    `json:"totalReferral"`
+	RewardID      int64  `json:"rewardId"`
+	Reward        string `json:"reward"`
+}
+
+// CodeEvent is data of code.generated, PreviousCode is set when code is regenerated or replaced by custom code
+type CodeEvent struct {
+	Msisdn       string `json:"msisdn"`
+	ReferralCode string `json:"referralCode"`
+	PreviousCode string `json:"previousCode,omitempty"`
+}
+
+// ValidEvent return true when event can be subscribed
+func ValidEvent(event string) bool {
+	for _, v := range Events {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
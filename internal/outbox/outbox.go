@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+// maxErrorLength is size of outbox.last_error column
+const maxErrorLength = 255
+
+// Event is domain event published by relay. ID does not change when the same event is published again,
+// publisher and its consumer use it to drop duplicate
+type Event struct {
+	ID        string
+	Name      string
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// EventPublisher deliver event outside the service, returning error make the event published again later
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type publishers []EventPublisher
+
+// Publishers return publisher handing event to every publisher, event is published again to all of them
+// when any fails so each publisher must drop event it already received by id
+func Publishers(eventPublishers ...EventPublisher) EventPublisher {
+	for _, v := range eventPublishers {
+		if v == nil {
+			panic("EventPublisher is nil")
+		}
+	}
+	return publishers(eventPublishers)
+}
+
+func (p publishers) Publish(ctx context.Context, event Event) error {
+	var result error
+	for _, v := range p {
+		//failing publisher does not hold the others
+		if err := v.Publish(ctx, event); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+type Relay interface {
+	// Relay publish due event in order they are written, failed event is retried with exponential backoff
+	Relay(ctx context.Context) error
+	// Purge remove published event older than retention
+	Purge(ctx context.Context) error
+}
+
+type relay struct {
+	outboxRepository model.OutboxRepository
+	publisher        EventPublisher
+	config           config.OutboxConfig
+}
+
+func SetupRelay(outboxRepository model.OutboxRepository, publisher EventPublisher, outboxConfig *config.OutboxConfig) Relay {
+	if outboxRepository == nil {
+		panic("OutboxRepository is nil")
+	}
+	if publisher == nil {
+		panic("EventPublisher is nil")
+	}
+	if outboxConfig == nil {
+		outboxConfig = &config.OutboxConfig{}
+	}
+	return &relay{
+		outboxRepository: outboxRepository,
+		publisher:        publisher,
+		config:           *outboxConfig,
+	}
+}
+
+// Write store event of data in outbox, ctx carry the transaction of the change producing the event
+// so both are committed or rolled back together
+func Write(ctx context.Context, outboxRepository model.OutboxRepository, name string, data interface{}) error {
+	eventID, err := newEventID()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return outboxRepository.Insert(ctx, &model.OutboxEvent{
+		EventID: eventID,
+		Event:   name,
+		Payload: string(payload),
+	})
+}
+
+func (r relay) Relay(ctx context.Context) error {
+	//claimed event is not picked up by other instance until lease passed
+	events, err := r.outboxRepository.ClaimDue(ctx, time.Minute, r.config.Batch())
+	if err != nil {
+		return err
+	}
+
+	for _, v := range events {
+		err = r.publisher.Publish(ctx, Event{
+			ID:        v.EventID,
+			Name:      v.Event,
+			Data:      json.RawMessage(v.Payload),
+			CreatedAt: v.CreatedDate,
+		})
+		if err == nil {
+			//event published but not marked is published again after lease, consumer drop it by id
+			if err = r.outboxRepository.MarkPublished(ctx, v.ID); err != nil {
+				log.Println("failed to mark outbox event published: ", v.ID, err)
+			}
+			continue
+		}
+
+		log.Println("failed to publish outbox event: ", v.ID, v.Event, err)
+		v.Attempt++
+		v.NextAttemptDate = time.Now().Add(r.config.Backoff(v.Attempt))
+		v.LastError = truncate(err.Error(), maxErrorLength)
+		if err = r.outboxRepository.UpdateAttempt(ctx, v); err != nil {
+			log.Println("failed to update outbox event: ", v.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r relay) Purge(ctx context.Context) error {
+	total, err := r.outboxRepository.DeletePublished(ctx, time.Now().Add(-r.config.Retention()))
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		log.Println("published outbox event purged: ", total)
+	}
+	return nil
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+// stubPublisher record published event, event listed in fail is rejected with err
+type stubPublisher struct {
+	published []Event
+	fail      map[string]error
+}
+
+func (s *stubPublisher) Publish(ctx context.Context, event Event) error {
+	if err, ok := s.fail[event.ID]; ok {
+		return err
+	}
+	s.published = append(s.published, event)
+	return nil
+}
+
+func TestSetupRelay(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRelay(nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRelay(&mocks.OutboxRepository{}, nil, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRelay(&mocks.OutboxRepository{}, &stubPublisher{}, nil)
+	})
+}
+
+func TestPublishers(t *testing.T) {
+	assert.Panics(t, func() {
+		Publishers(&stubPublisher{}, nil)
+	})
+
+	event := Event{ID: "a1", Name: EventReferralCreated}
+	t.Run("every publisher receive event", func(t *testing.T) {
+		first, second := &stubPublisher{}, &stubPublisher{}
+		assert.Nil(t, Publishers(first, second).Publish(context.Background(), event))
+		assert.Equal(t, []Event{event}, first.published)
+		assert.Equal(t, []Event{event}, second.published)
+	})
+	t.Run("failing publisher does not hold the others", func(t *testing.T) {
+		first, second := &stubPublisher{fail: map[string]error{"a1": context.DeadlineExceeded}}, &stubPublisher{}
+		err := Publishers(first, second).Publish(context.Background(), event)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, []Event{event}, second.published)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("error insert", func(t *testing.T) {
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		err := Write(context.Background(), outboxMock, EventCodeGenerated, CodeEvent{Msisdn: "6280000011"})
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		var events []*model.OutboxEvent
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			events = append(events, args.Get(1).(*model.OutboxEvent))
+		}).Return(nil)
+
+		assert.Nil(t, Write(context.Background(), outboxMock, EventCodeGenerated, CodeEvent{Msisdn: "6280000011",
+			ReferralCode: "ABCABC123A"}))
+		assert.Nil(t, Write(context.Background(), outboxMock, EventCodeGenerated, CodeEvent{Msisdn: "6280000011",
+			ReferralCode: "ABCABC123A"}))
+
+		assert.Len(t, events, 2)
+		assert.Equal(t, EventCodeGenerated, events[0].Event)
+		assert.Len(t, events[0].EventID, 32)
+		assert.NotEqual(t, events[0].EventID, events[1].EventID)
+
+		var data CodeEvent
+		assert.Nil(t, json.Unmarshal([]byte(events[0].Payload), &data))
+		assert.Equal(t, "ABCABC123A", data.ReferralCode)
+	})
+}
+
+func Test_relay_Relay(t *testing.T) {
+	t.Run("error claim", func(t *testing.T) {
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("ClaimDue", mock.Anything, time.Minute, 100).Return(nil, context.DeadlineExceeded)
+
+		r := SetupRelay(outboxMock, &stubPublisher{}, nil)
+		assert.NotNil(t, r.Relay(context.Background()))
+	})
+	t.Run("publish in order and retry failed event", func(t *testing.T) {
+		createdDate := time.Date(2021, 8, 12, 7, 0, 0, 0, time.UTC)
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("ClaimDue", mock.Anything, time.Minute, 10).Return([]model.OutboxEvent{
+			{ID: 1, EventID: "a1", Event: EventReferralCreated, Payload: `{"msisdn":"6280000011"}`, CreatedDate: createdDate},
+			{ID: 2, EventID: "a2", Event: EventReferralCreated, Payload: `{}`, Attempt: 2},
+			{ID: 3, EventID: "a3", Event: EventReferralReversed, Payload: `{}`},
+		}, nil)
+		outboxMock.On("MarkPublished", mock.Anything, int64(1)).Return(nil)
+		//failed mark is published again after lease
+		outboxMock.On("MarkPublished", mock.Anything, int64(3)).Return(context.DeadlineExceeded)
+		outboxMock.On("UpdateAttempt", mock.Anything, mock.MatchedBy(func(e model.OutboxEvent) bool {
+			return e.ID == 2 && e.Attempt == 3 && len(e.LastError) == 255 &&
+				e.NextAttemptDate.After(time.Now().Add(19*time.Second)) && e.NextAttemptDate.Before(time.Now().Add(21*time.Second))
+		})).Return(nil)
+
+		publisher := &stubPublisher{fail: map[string]error{"a2": errors.New(strings.Repeat("x", 300))}}
+		r := SetupRelay(outboxMock, publisher, &config.OutboxConfig{BatchSize: 10, BackoffSeconds: 5})
+		assert.Nil(t, r.Relay(context.Background()))
+
+		assert.Len(t, publisher.published, 2)
+		assert.Equal(t, Event{ID: "a1", Name: EventReferralCreated, Data: json.RawMessage(`{"msisdn":"6280000011"}`),
+			CreatedAt: createdDate}, publisher.published[0])
+		assert.Equal(t, "a3", publisher.published[1].ID)
+		outboxMock.AssertExpectations(t)
+	})
+}
+
+func Test_relay_Purge(t *testing.T) {
+	t.Run("error delete", func(t *testing.T) {
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("DeletePublished", mock.Anything, mock.Anything).Return(int64(0), context.DeadlineExceeded)
+
+		r := SetupRelay(outboxMock, &stubPublisher{}, nil)
+		assert.NotNil(t, r.Purge(context.Background()))
+	})
+	t.Run("success", func(t *testing.T) {
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("DeletePublished", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			return before.Before(time.Now().Add(-23*time.Hour)) && before.After(time.Now().Add(-25*time.Hour))
+		})).Return(int64(4), nil)
+
+		r := SetupRelay(outboxMock, &stubPublisher{}, &config.OutboxConfig{RetentionHours: 24})
+		assert.Nil(t, r.Purge(context.Background()))
+		outboxMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,110 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	DefaultSize = 256
+	MinSize     = 64
+	MaxSize     = 2048
+)
+
+var (
+	ErrInvalidFormat = errors.New("invalid qr format")
+	ErrInvalidLevel  = errors.New("invalid qr error correction level")
+	ErrInvalidSize   = errors.New("invalid qr size")
+)
+
+// levels is error correction level, higher level survive more damage at the cost of denser image
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Option of generated image, zero value means default png of 256 pixel with level M
+type Option struct {
+	// Format is png or svg
+	Format string
+	// Size is width and height of image in pixel, include quiet zone
+	Size int
+	// Level is error correction level L, M, Q or H
+	Level string
+}
+
+type Image struct {
+	ContentType string
+	Data        []byte
+}
+
+// Validate option, zero value is valid as it means default
+func (o Option) Validate() error {
+	if o.Format != "" && o.Format != FormatPNG && o.Format != FormatSVG {
+		return ErrInvalidFormat
+	}
+	if o.Size != 0 && (o.Size < MinSize || o.Size > MaxSize) {
+		return ErrInvalidSize
+	}
+	if _, ok := levels[o.Level]; o.Level != "" && !ok {
+		return ErrInvalidLevel
+	}
+	return nil
+}
+
+// Encode content as QR code image
+func Encode(content string, option Option) (Image, error) {
+	if err := option.Validate(); err != nil {
+		return Image{}, err
+	}
+	if option.Size == 0 {
+		option.Size = DefaultSize
+	}
+	if option.Level == "" {
+		option.Level = "M"
+	}
+
+	code, err := qrcode.New(content, levels[option.Level])
+	if err != nil {
+		return Image{}, err
+	}
+
+	if option.Format == FormatSVG {
+		return Image{ContentType: "image/svg+xml", Data: svg(code.Bitmap(), option.Size)}, nil
+	}
+	data, err := code.PNG(option.Size)
+	if err != nil {
+		return Image{}, err
+	}
+	return Image{ContentType: "image/png", Data: data}, nil
+}
+
+// svg draw each run of dark module in a row as one rectangle in a single path, scaled by viewBox
+func svg(bitmap [][]bool, size int) []byte {
+	buf := bytes.Buffer{}
+	buf.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`shape-rendering="crispEdges">`, size, size, len(bitmap), len(bitmap)))
+	buf.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			buf.WriteString(fmt.Sprintf("M%d %dh%dv1h-%dz", start, y, x-start, x-start))
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
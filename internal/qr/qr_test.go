@@ -0,0 +1,57 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const link = "https://ref.telkomsel.com/r/4C7AE71563-2"
+
+func TestEncode(t *testing.T) {
+	t.Run("invalid option", func(t *testing.T) {
+		_, err := Encode(link, Option{Format: "gif"})
+		assert.Equal(t, ErrInvalidFormat, err)
+		_, err = Encode(link, Option{Level: "X"})
+		assert.Equal(t, ErrInvalidLevel, err)
+		_, err = Encode(link, Option{Size: 10})
+		assert.Equal(t, ErrInvalidSize, err)
+		_, err = Encode(link, Option{Size: MaxSize + 1})
+		assert.Equal(t, ErrInvalidSize, err)
+	})
+	t.Run("default png", func(t *testing.T) {
+		img, err := Encode(link, Option{})
+		assert.Nil(t, err)
+		assert.Equal(t, "image/png", img.ContentType)
+
+		decoded, err := png.Decode(bytes.NewReader(img.Data))
+		assert.Nil(t, err)
+		assert.Equal(t, DefaultSize, decoded.Bounds().Dx())
+		assert.Equal(t, DefaultSize, decoded.Bounds().Dy())
+	})
+	t.Run("svg with higher level is denser", func(t *testing.T) {
+		low, err := Encode(link, Option{Format: FormatSVG, Size: 512, Level: "L"})
+		assert.Nil(t, err)
+		assert.Equal(t, "image/svg+xml", low.ContentType)
+		assert.True(t, strings.HasPrefix(string(low.Data), `<svg xmlns="http://www.w3.org/2000/svg" width="512" height="512"`))
+		assert.True(t, strings.HasSuffix(string(low.Data), `"/></svg>`))
+
+		high, err := Encode(link, Option{Format: FormatSVG, Size: 512, Level: "H"})
+		assert.Nil(t, err)
+		assert.Greater(t, len(high.Data), len(low.Data))
+	})
+}
+
+func Test_svg(t *testing.T) {
+	bitmap := [][]bool{
+		{true, true, false},
+		{false, true, true},
+		{true, false, true},
+	}
+	assert.Equal(t, `<svg xmlns="http://www.w3.org/2000/svg" width="90" height="90" viewBox="0 0 3 3" shape-rendering="crispEdges">`+
+		`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="M0 0h2v1h-2zM1 1h2v1h-2zM0 2h1v1h-1zM2 2h1v1h-1z"/></svg>`,
+		string(svg(bitmap, 90)))
+}
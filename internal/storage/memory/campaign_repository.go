@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type campaignRepository struct {
+	store *Store
+}
+
+func SetupCampaignRepository(store *Store) *campaignRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &campaignRepository{
+		store: store,
+	}
+}
+
+// sortCampaign order campaign by latest start date, then newest
+func sortCampaign(campaigns []model.Campaign) {
+	sort.Slice(campaigns, func(i, j int) bool {
+		if campaigns[i].StartDate != campaigns[j].StartDate {
+			return campaigns[i].StartDate > campaigns[j].StartDate
+		}
+		return campaigns[i].ID > campaigns[j].ID
+	})
+}
+
+func (r campaignRepository) FindAll(ctx context.Context) ([]model.Campaign, error) {
+	defer r.store.lock(ctx)()
+	result := append([]model.Campaign(nil), r.store.campaigns...)
+	sortCampaign(result)
+	return result, nil
+}
+
+func (r campaignRepository) FindByID(ctx context.Context, ID int64) (model.Campaign, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.campaigns {
+		if v.ID == ID {
+			return v, nil
+		}
+	}
+	return model.Campaign{}, sql.ErrNoRows
+}
+
+// FindActiveByDate return active campaign running on date (yyyy-MM-dd)
+func (r campaignRepository) FindActiveByDate(ctx context.Context, date string) (result []model.Campaign, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.campaigns {
+		if v.Status == 1 && v.StartDate <= date && v.EndDate >= date {
+			result = append(result, v)
+		}
+	}
+	sortCampaign(result)
+	return result, nil
+}
+
+func (r campaignRepository) Insert(ctx context.Context, campaign *model.Campaign) error {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	campaign.ID = r.store.nextID("campaign")
+	r.store.campaigns = append(r.store.campaigns, model.Campaign{
+		ID:          campaign.ID,
+		Name:        campaign.Name,
+		StartDate:   campaign.StartDate,
+		EndDate:     campaign.EndDate,
+		Status:      1,
+		CreatedDate: now,
+		UpdatedDate: now,
+	})
+	return nil
+}
+
+// Update change name and period of campaign, empty value is not changed
+func (r campaignRepository) Update(ctx context.Context, campaign model.Campaign) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.campaigns {
+		v := &r.store.campaigns[i]
+		if v.ID != campaign.ID {
+			continue
+		}
+		if campaign.Name != "" {
+			v.Name = campaign.Name
+		}
+		if campaign.StartDate != "" {
+			v.StartDate = campaign.StartDate
+		}
+		if campaign.EndDate != "" {
+			v.EndDate = campaign.EndDate
+		}
+		v.UpdatedDate = time.Now()
+		return nil
+	}
+	return util.ErrorDataNotFound
+}
+
+func (r campaignRepository) Delete(ctx context.Context, ID int64) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.campaigns {
+		if r.store.campaigns[i].ID == ID {
+			r.store.campaigns[i].Status = 0
+			r.store.campaigns[i].UpdatedDate = time.Now()
+			return nil
+		}
+	}
+	return util.ErrorDataNotFound
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupCampaignRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCampaignRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupCampaignRepository(NewStore())
+	})
+}
+
+func Test_campaignRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupCampaignRepository(NewStore())
+	for _, v := range []model.Campaign{
+		{Name: "august", StartDate: "2021-08-01", EndDate: "2021-08-31"},
+		{Name: "independence day", StartDate: "2021-08-17", EndDate: "2021-08-17"},
+		{Name: "september", StartDate: "2021-09-01", EndDate: "2021-09-30"},
+	} {
+		campaign := v
+		assert.Nil(t, repo.Insert(ctx, &campaign))
+	}
+
+	t.Run("FindAll latest start first", func(t *testing.T) {
+		result, err := repo.FindAll(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, result, 3)
+		assert.Equal(t, "september", result[0].Name)
+		assert.Equal(t, "august", result[2].Name)
+	})
+	t.Run("FindActiveByDate", func(t *testing.T) {
+		assert.Nil(t, repo.Delete(ctx, 3))
+		result, err := repo.FindActiveByDate(ctx, "2021-08-17")
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "independence day", result[0].Name)
+
+		result, _ = repo.FindActiveByDate(ctx, "2021-09-01")
+		assert.Empty(t, result)
+	})
+	t.Run("Update keep empty value", func(t *testing.T) {
+		assert.Nil(t, repo.Update(ctx, model.Campaign{ID: 1, EndDate: "2021-08-30"}))
+		result, err := repo.FindByID(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "august", result.Name)
+		assert.Equal(t, "2021-08-30", result.EndDate)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.FindByID(ctx, 9)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Equal(t, util.ErrorDataNotFound, repo.Update(ctx, model.Campaign{ID: 9}))
+		assert.Equal(t, util.ErrorDataNotFound, repo.Delete(ctx, 9))
+	})
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+type notificationPreferenceRepository struct {
+	store *Store
+}
+
+func SetupNotificationPreferenceRepository(store *Store) *notificationPreferenceRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &notificationPreferenceRepository{
+		store: store,
+	}
+}
+
+func (r notificationPreferenceRepository) FindByMsisdn(ctx context.Context, msisdn string) (model.NotificationPreference, error) {
+	defer r.store.lock(ctx)()
+	if result, ok := r.store.notificationPreferences[msisdn]; ok {
+		return result, nil
+	}
+	return model.NotificationPreference{}, sql.ErrNoRows
+}
+
+// Upsert store preference of msisdn, replacing language and opt out of the existing one
+func (r notificationPreferenceRepository) Upsert(ctx context.Context, preference model.NotificationPreference) error {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	current, ok := r.store.notificationPreferences[preference.Msisdn]
+	if !ok {
+		current = model.NotificationPreference{Msisdn: preference.Msisdn, CreatedDate: now}
+	}
+	current.Language = preference.Language
+	current.OptOut = preference.OptOut
+	current.UpdatedDate = now
+	r.store.notificationPreferences[preference.Msisdn] = current
+	return nil
+}
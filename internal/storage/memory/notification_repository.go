@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type notificationRepository struct {
+	store *Store
+}
+
+func SetupNotificationRepository(store *Store) *notificationRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &notificationRepository{
+		store: store,
+	}
+}
+
+// Insert queue notification, notification of the same event and msisdn is ignored
+func (r notificationRepository) Insert(ctx context.Context, notification *model.Notification) error {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.notifications {
+		if v.EventID == notification.EventID && v.Msisdn == notification.Msisdn {
+			return nil
+		}
+	}
+	now := time.Now()
+	notification.ID = r.store.nextID("notification")
+	r.store.notifications = append(r.store.notifications, model.Notification{
+		ID:              notification.ID,
+		EventID:         notification.EventID,
+		Msisdn:          notification.Msisdn,
+		Template:        notification.Template,
+		Message:         notification.Message,
+		Status:          model.NotificationStatusPending,
+		NextAttemptDate: notification.NextAttemptDate,
+		CreatedDate:     now,
+		UpdatedDate:     now,
+	})
+	return nil
+}
+
+// ClaimDue return pending notification whose next attempt already passed, earliest first, and postpone it by lease
+func (r notificationRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.Notification, err error) {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	var due []int
+	for i, v := range r.store.notifications {
+		if v.Status == model.NotificationStatusPending && !v.NextAttemptDate.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return r.store.notifications[due[i]].NextAttemptDate.Before(r.store.notifications[due[j]].NextAttemptDate)
+	})
+	for _, i := range due {
+		if len(result) == limit {
+			break
+		}
+		r.store.notifications[i].NextAttemptDate = now.Add(lease)
+		result = append(result, r.store.notifications[i])
+	}
+	return result, nil
+}
+
+func (r notificationRepository) UpdateResult(ctx context.Context, notification model.Notification) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.notifications {
+		v := &r.store.notifications[i]
+		if v.ID != notification.ID {
+			continue
+		}
+		v.Status = notification.Status
+		v.Attempt = notification.Attempt
+		v.NextAttemptDate = notification.NextAttemptDate
+		v.LastError = notification.LastError
+		v.UpdatedDate = time.Now()
+		return nil
+	}
+	return util.ErrorDataNotFound
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupNotificationRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupNotificationRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupNotificationRepository(NewStore())
+	})
+}
+
+func Test_notificationRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupNotificationRepository(NewStore())
+	now := time.Now()
+	for _, v := range []model.Notification{
+		{EventID: "a1", Msisdn: "6280000011", NextAttemptDate: now.Add(-time.Minute)},
+		{EventID: "a1", Msisdn: "6280000011", NextAttemptDate: now.Add(-time.Hour)},
+		{EventID: "a1", Msisdn: "6280000012", NextAttemptDate: now.Add(-time.Hour)},
+		//held by quiet hour
+		{EventID: "a2", Msisdn: "6280000011", NextAttemptDate: now.Add(time.Hour)},
+	} {
+		notification := v
+		assert.Nil(t, repo.Insert(ctx, &notification))
+	}
+
+	t.Run("ClaimDue earliest first", func(t *testing.T) {
+		result, err := repo.ClaimDue(ctx, time.Minute, 10)
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "6280000012", result[0].Msisdn)
+		assert.Equal(t, "6280000011", result[1].Msisdn)
+
+		result, _ = repo.ClaimDue(ctx, time.Minute, 10)
+		assert.Empty(t, result)
+	})
+	t.Run("UpdateResult", func(t *testing.T) {
+		assert.Nil(t, repo.UpdateResult(ctx, model.Notification{ID: 1, Status: model.NotificationStatusPending, Attempt: 1,
+			NextAttemptDate: now.Add(-time.Second)}))
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateResult(ctx, model.Notification{ID: 9}))
+
+		result, _ := repo.ClaimDue(ctx, time.Minute, 10)
+		assert.Len(t, result, 1)
+		assert.Equal(t, 1, result[0].Attempt)
+	})
+}
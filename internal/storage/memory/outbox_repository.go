@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type outboxRepository struct {
+	store *Store
+}
+
+func SetupOutboxRepository(store *Store) *outboxRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &outboxRepository{
+		store: store,
+	}
+}
+
+// Insert store event, it joins transaction of ctx so event is kept only when the change producing it is committed
+func (r outboxRepository) Insert(ctx context.Context, event *model.OutboxEvent) error {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.outboxEvents {
+		if v.EventID == event.EventID {
+			return uniqueViolation("outbox_event_key")
+		}
+	}
+	now := time.Now()
+	event.ID = r.store.nextID("outbox")
+	r.store.outboxEvents = append(r.store.outboxEvents, model.OutboxEvent{
+		ID:              event.ID,
+		EventID:         event.EventID,
+		Event:           event.Event,
+		Payload:         event.Payload,
+		Status:          model.OutboxStatusPending,
+		NextAttemptDate: now,
+		CreatedDate:     now,
+	})
+	return nil
+}
+
+// ClaimDue return pending event whose next attempt already passed in the order they are written,
+// and postpone it by lease
+func (r outboxRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.OutboxEvent, err error) {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	for i := range r.store.outboxEvents {
+		if len(result) == limit {
+			break
+		}
+		v := &r.store.outboxEvents[i]
+		if v.Status != model.OutboxStatusPending || v.NextAttemptDate.After(now) {
+			continue
+		}
+		v.NextAttemptDate = now.Add(lease)
+		result = append(result, *v)
+	}
+	return result, nil
+}
+
+// pending apply fn to pending event of ID, return util.ErrorDataNotFound when there is none
+func (r outboxRepository) pending(ctx context.Context, ID int64, fn func(v *model.OutboxEvent)) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.outboxEvents {
+		if v := &r.store.outboxEvents[i]; v.ID == ID && v.Status == model.OutboxStatusPending {
+			fn(v)
+			return nil
+		}
+	}
+	return util.ErrorDataNotFound
+}
+
+func (r outboxRepository) MarkPublished(ctx context.Context, ID int64) error {
+	return r.pending(ctx, ID, func(v *model.OutboxEvent) {
+		now := time.Now()
+		v.Status = model.OutboxStatusPublished
+		v.Attempt++
+		v.LastError = ""
+		v.PublishedDate = &now
+	})
+}
+
+// UpdateAttempt store failed attempt of pending event and when it is published again
+func (r outboxRepository) UpdateAttempt(ctx context.Context, event model.OutboxEvent) error {
+	return r.pending(ctx, event.ID, func(v *model.OutboxEvent) {
+		v.Attempt = event.Attempt
+		v.NextAttemptDate = event.NextAttemptDate
+		v.LastError = event.LastError
+	})
+}
+
+// DeletePublished remove event published before the time, pending event is kept
+func (r outboxRepository) DeletePublished(ctx context.Context, before time.Time) (total int64, err error) {
+	defer r.store.lock(ctx)()
+	kept := r.store.outboxEvents[:0]
+	for _, v := range r.store.outboxEvents {
+		if v.Status == model.OutboxStatusPublished && v.PublishedDate != nil && v.PublishedDate.Before(before) {
+			total++
+			continue
+		}
+		kept = append(kept, v)
+	}
+	r.store.outboxEvents = kept
+	return total, nil
+}
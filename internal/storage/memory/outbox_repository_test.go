@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupOutboxRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupOutboxRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupOutboxRepository(NewStore())
+	})
+}
+
+func Test_outboxRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupOutboxRepository(NewStore())
+	for _, id := range []string{"a1", "a2", "a3"} {
+		assert.Nil(t, repo.Insert(ctx, &model.OutboxEvent{EventID: id, Event: "referral.created", Payload: "{}"}))
+	}
+
+	t.Run("duplicate event", func(t *testing.T) {
+		assert.NotNil(t, repo.Insert(ctx, &model.OutboxEvent{EventID: "a1"}))
+	})
+	t.Run("ClaimDue in written order", func(t *testing.T) {
+		result, err := repo.ClaimDue(ctx, time.Minute, 2)
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "a1", result[0].EventID)
+		assert.Equal(t, "a2", result[1].EventID)
+	})
+	t.Run("MarkPublished and UpdateAttempt", func(t *testing.T) {
+		assert.Nil(t, repo.MarkPublished(ctx, 1))
+		assert.Equal(t, util.ErrorDataNotFound, repo.MarkPublished(ctx, 1))
+		assert.Nil(t, repo.UpdateAttempt(ctx, model.OutboxEvent{ID: 2, Attempt: 1, LastError: "timeout",
+			NextAttemptDate: time.Now().Add(-time.Second)}))
+
+		result, _ := repo.ClaimDue(ctx, time.Minute, 10)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "timeout", result[0].LastError)
+	})
+	t.Run("DeletePublished", func(t *testing.T) {
+		total, err := repo.DeletePublished(ctx, time.Now().Add(-time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), total)
+
+		total, err = repo.DeletePublished(ctx, time.Now().Add(time.Second))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateAttempt(ctx, model.OutboxEvent{ID: 1}))
+	})
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+type referralClickRepository struct {
+	store *Store
+}
+
+func SetupReferralClickRepository(store *Store) *referralClickRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &referralClickRepository{
+		store: store,
+	}
+}
+
+func (r referralClickRepository) Insert(ctx context.Context, click *model.ReferralClick) error {
+	defer r.store.lock(ctx)()
+	click.ID = r.store.nextID("referral_click")
+	r.store.referralClicks = append(r.store.referralClicks, model.ReferralClick{
+		ID:          click.ID,
+		Code:        click.Code,
+		Channel:     click.Channel,
+		UserAgent:   click.UserAgent,
+		CreatedDate: time.Now(),
+	})
+	return nil
+}
+
+// GetStatistic return click and counted referral of each code between filter.From and filter.To, most clicked first.
+// Code has neither click nor referral in the range is not listed
+func (r referralClickRepository) GetStatistic(ctx context.Context, filter model.ReferralClickFilter) (result []model.ReferralClickStatistic, err error) {
+	from, err := time.ParseInLocation("2006-01-02", filter.From, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.ParseInLocation("2006-01-02", filter.To, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	to = to.AddDate(0, 0, 1)
+
+	defer r.store.lock(ctx)()
+	statistic := make(map[string]*model.ReferralClickStatistic)
+	get := func(code string) *model.ReferralClickStatistic {
+		if _, ok := statistic[code]; !ok {
+			statistic[code] = &model.ReferralClickStatistic{Code: code}
+		}
+		return statistic[code]
+	}
+	for _, v := range r.store.referralClicks {
+		if v.CreatedDate.Before(from) || !v.CreatedDate.Before(to) ||
+			(filter.Code != "" && !strings.EqualFold(v.Code, filter.Code)) {
+			continue
+		}
+		get(v.Code).TotalClick++
+	}
+	for _, v := range r.store.referralHistories {
+		if v.ReferralDate < filter.From || v.ReferralDate > filter.To || !counted(v) ||
+			(filter.Code != "" && !strings.EqualFold(v.Code, filter.Code)) {
+			continue
+		}
+		get(v.Code).TotalReferral++
+	}
+
+	for code, v := range statistic {
+		for _, c := range r.store.referralCodes {
+			if strings.EqualFold(c.Code, code) {
+				v.Msisdn = c.Msisdn
+				break
+			}
+		}
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalClick != result[j].TotalClick {
+			return result[i].TotalClick > result[j].TotalClick
+		}
+		if result[i].TotalReferral != result[j].TotalReferral {
+			return result[i].TotalReferral > result[j].TotalReferral
+		}
+		return result[i].Code < result[j].Code
+	})
+	if filter.Limit >= 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupReferralClickRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReferralClickRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupReferralClickRepository(NewStore())
+	})
+}
+
+func Test_referralClickRepository_GetStatistic(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	repo := SetupReferralClickRepository(store)
+	_ = SetupReferralCodeRepository(store).Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+	_ = SetupReferralHistoryRepository(store).InsertBatch(ctx, []model.ReferralHistory{
+		{Msisdn: "6280000011", Code: "ABCABC123A", MsisdnReferee: "6280000012", ReferralDate: time.Now().Format("2006-01-02"),
+			Status: model.ReferralStatusConfirmed},
+	})
+	for _, code := range []string{"ABCABC123A", "XYZXYZ123A", "XYZXYZ123A"} {
+		assert.Nil(t, repo.Insert(ctx, &model.ReferralClick{Code: code, Channel: "whatsapp"}))
+	}
+	today := time.Now().Format("2006-01-02")
+
+	t.Run("invalid date", func(t *testing.T) {
+		_, err := repo.GetStatistic(ctx, model.ReferralClickFilter{From: "today", To: today})
+		assert.NotNil(t, err)
+	})
+	t.Run("most clicked first", func(t *testing.T) {
+		result, err := repo.GetStatistic(ctx, model.ReferralClickFilter{From: today, To: today, Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralClickStatistic{
+			{Code: "XYZXYZ123A", TotalClick: 2},
+			{Code: "ABCABC123A", Msisdn: "6280000011", TotalClick: 1, TotalReferral: 1},
+		}, result)
+	})
+	t.Run("filter by code and limit", func(t *testing.T) {
+		result, err := repo.GetStatistic(ctx, model.ReferralClickFilter{Code: "abcabc123a", From: today, To: today, Limit: 1})
+		assert.Nil(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "ABCABC123A", result[0].Code)
+	})
+	t.Run("out of range", func(t *testing.T) {
+		yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+		result, err := repo.GetStatistic(ctx, model.ReferralClickFilter{From: yesterday, To: yesterday, Limit: 10})
+		assert.Nil(t, err)
+		assert.Empty(t, result)
+	})
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type referralCodeRepository struct {
+	store *Store
+}
+
+func SetupReferralCodeRepository(store *Store) *referralCodeRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &referralCodeRepository{
+		store: store,
+	}
+}
+
+func (r referralCodeRepository) FindByMsisdn(ctx context.Context, msisdn string) (model.ReferralCode, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralCodes {
+		if v.Msisdn != msisdn || v.Status == model.ReferralCodeStatusRetired {
+			continue
+		}
+		if v.Status == model.ReferralCodeStatusInactive {
+			return model.ReferralCode{}, util.ErrorDataNotFound
+		}
+		return v, nil
+	}
+	return model.ReferralCode{}, sql.ErrNoRows
+}
+
+// FindByCode lookup code regardless of its case
+func (r referralCodeRepository) FindByCode(ctx context.Context, code string) (model.ReferralCode, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralCodes {
+		if !strings.EqualFold(v.Code, code) {
+			continue
+		}
+		if v.Status == model.ReferralCodeStatusInactive {
+			return model.ReferralCode{}, util.ErrorDataNotFound
+		}
+		return v, nil
+	}
+	return model.ReferralCode{}, sql.ErrNoRows
+}
+
+// FindAllByMsisdn return every code of msisdn including retired one, newest first
+func (r referralCodeRepository) FindAllByMsisdn(ctx context.Context, msisdn string) (result []model.ReferralCode, err error) {
+	defer r.store.lock(ctx)()
+	for i := len(r.store.referralCodes) - 1; i >= 0; i-- {
+		if v := r.store.referralCodes[i]; v.Msisdn == msisdn {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// Insert store active code, code is unique regardless of its case and msisdn has only one non retired code
+func (r referralCodeRepository) Insert(ctx context.Context, code *model.ReferralCode) error {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralCodes {
+		if strings.EqualFold(v.Code, code.Code) {
+			return util.ErrorReferralCodeUnavailable
+		}
+		if v.Msisdn == code.Msisdn && v.Status != model.ReferralCodeStatusRetired {
+			return uniqueViolation("referral_code_msisdn_idx")
+		}
+	}
+	code.ID = r.store.nextID("referral_code")
+	r.store.referralCodes = append(r.store.referralCodes, model.ReferralCode{
+		ID:          code.ID,
+		Msisdn:      code.Msisdn,
+		Code:        code.Code,
+		CreatedDate: time.Now(),
+		Status:      model.ReferralCodeStatusActive,
+	})
+	return nil
+}
+
+// update apply fn to code regardless of its case, return util.ErrorDataNotFound when fn changes nothing
+func (r referralCodeRepository) update(ctx context.Context, code string, fn func(v *model.ReferralCode) bool) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.referralCodes {
+		if strings.EqualFold(r.store.referralCodes[i].Code, code) && fn(&r.store.referralCodes[i]) {
+			return nil
+		}
+	}
+	return util.ErrorDataNotFound
+}
+
+// UpdateStatus change status of code, retired code is never changed
+func (r referralCodeRepository) UpdateStatus(ctx context.Context, code string, status int) error {
+	return r.update(ctx, code, func(v *model.ReferralCode) bool {
+		if v.Status == model.ReferralCodeStatusRetired {
+			return false
+		}
+		v.Status = status
+		return true
+	})
+}
+
+func (r referralCodeRepository) UpdateExpiry(ctx context.Context, code string, expiresAt *time.Time) error {
+	return r.update(ctx, code, func(v *model.ReferralCode) bool {
+		v.ExpiresAt = expiresAt
+		return true
+	})
+}
+
+// Retire mark code as replaced by newer code, code still resolvable until expiresAt
+// unless it already has earlier expiry
+func (r referralCodeRepository) Retire(ctx context.Context, code string, expiresAt time.Time) error {
+	return r.update(ctx, code, func(v *model.ReferralCode) bool {
+		if v.Status == model.ReferralCodeStatusRetired {
+			return false
+		}
+		v.Status = model.ReferralCodeStatusRetired
+		if v.ExpiresAt == nil || expiresAt.Before(*v.ExpiresAt) {
+			v.ExpiresAt = &expiresAt
+		}
+		return true
+	})
+}
+
+func (r referralCodeRepository) UpdateCooldown(ctx context.Context, code string, cooldownUntil time.Time) error {
+	return r.update(ctx, code, func(v *model.ReferralCode) bool {
+		v.CooldownUntil = &cooldownUntil
+		return true
+	})
+}
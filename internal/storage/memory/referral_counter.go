@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// referralCounter keep counter in process memory, it is not shared between instance and lost on restart
+type referralCounter struct {
+	mu       sync.RWMutex
+	counters map[string]map[string]int
+}
+
+func SetupReferralCounter() *referralCounter {
+	return &referralCounter{
+		counters: make(map[string]map[string]int),
+	}
+}
+
+func (r *referralCounter) Get(ctx context.Context, msisdn, month string) (int, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	total, found := r.counters[month][msisdn]
+	return total, found, nil
+}
+
+func (r *referralCounter) Set(ctx context.Context, msisdn, month string, total int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.counters[month]; !ok {
+		r.counters[month] = make(map[string]int)
+	}
+	r.counters[month][msisdn] = total
+	return nil
+}
+
+func (r *referralCounter) Increment(ctx context.Context, msisdn, month string, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if total, found := r.counters[month][msisdn]; found {
+		r.counters[month][msisdn] = total + delta
+	}
+	return nil
+}
+
+func (r *referralCounter) GetAll(ctx context.Context, month string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make(map[string]int, len(r.counters[month]))
+	for msisdn, total := range r.counters[month] {
+		result[msisdn] = total
+	}
+	return result, nil
+}
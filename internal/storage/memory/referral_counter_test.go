@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_referralCounter(t *testing.T) {
+	ctx := context.Background()
+	r := SetupReferralCounter()
+
+	//counter is not started by increment
+	assert.Nil(t, r.Increment(ctx, "62821000000", "2021-08", 1))
+	_, found, err := r.Get(ctx, "62821000000", "2021-08")
+	assert.Nil(t, err)
+	assert.False(t, found)
+
+	assert.Nil(t, r.Set(ctx, "62821000000", "2021-08", 3))
+	assert.Nil(t, r.Increment(ctx, "62821000000", "2021-08", 1))
+	assert.Nil(t, r.Set(ctx, "62821000001", "2021-08", 1))
+	assert.Nil(t, r.Increment(ctx, "62821000001", "2021-08", -1))
+	assert.Nil(t, r.Set(ctx, "62821000000", "2021-07", 8))
+
+	total, found, err := r.Get(ctx, "62821000000", "2021-08")
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 4, total)
+
+	all, err := r.GetAll(ctx, "2021-08")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"62821000000": 4, "62821000001": 0}, all)
+
+	all, err = r.GetAll(ctx, "2021-09")
+	assert.Nil(t, err)
+	assert.Empty(t, all)
+}
@@ -0,0 +1,480 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type referralHistoryRepository struct {
+	store *Store
+}
+
+func SetupReferralHistoryRepository(store *Store) *referralHistoryRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &referralHistoryRepository{
+		store: store,
+	}
+}
+
+// counted is referral counted for reward, confirmed or rewarded and not flagged
+func counted(history model.ReferralHistory) bool {
+	return !history.Flagged &&
+		(history.Status == model.ReferralStatusConfirmed || history.Status == model.ReferralStatusRewarded)
+}
+
+// FindByMsisdn return referral made by msisdn newest first, reversed referral is kept as history but excluded
+func (r referralHistoryRepository) FindByMsisdn(ctx context.Context, msisdn string, offset, limit int) (result []model.ReferralHistory, err error) {
+	defer r.store.lock(ctx)()
+	for i := len(r.store.referralHistories) - 1; i >= 0 && len(result) < limit; i-- {
+		v := r.store.referralHistories[i]
+		if v.Msisdn != msisdn || v.Status == model.ReferralStatusReversed {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (r referralHistoryRepository) CountByMsisdn(ctx context.Context, msisdn string) (total int, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralHistories {
+		if v.Msisdn == msisdn && v.Status != model.ReferralStatusReversed {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (r referralHistoryRepository) FindByMsisdnReferee(ctx context.Context, msisdnReferee string) (model.ReferralHistory, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralHistories {
+		if v.MsisdnReferee == msisdnReferee {
+			return v, nil
+		}
+	}
+	return model.ReferralHistory{}, sql.ErrNoRows
+}
+
+func (r referralHistoryRepository) GetTotalByMsisdnAndMonth(ctx context.Context, msisdn, month string) (total int, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralHistories {
+		if v.Msisdn == msisdn && strings.HasPrefix(v.ReferralDate, month) && counted(v) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (r referralHistoryRepository) GetTotalByMsisdnAndCampaign(ctx context.Context, msisdn string, campaignID int64) (total int, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralHistories {
+		//referral without campaign never match
+		if v.Msisdn == msisdn && v.CampaignID > 0 && v.CampaignID == campaignID && counted(v) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+// downlineReferral is referral within downline of a msisdn and its level
+type downlineReferral struct {
+	model.ReferralHistory
+	level int
+}
+
+// downline walk down referral chain of every status up to maxLevel, each referee can only be referred once
+// so the chain is a tree
+func (r referralHistoryRepository) downline(msisdn string, maxLevel int) (result []downlineReferral) {
+	upline := map[string]bool{msisdn: true}
+	for level := 1; level <= maxLevel && len(upline) > 0; level++ {
+		next := make(map[string]bool)
+		for _, v := range r.store.referralHistories {
+			if upline[v.Msisdn] {
+				result = append(result, downlineReferral{ReferralHistory: v, level: level})
+				next[v.MsisdnReferee] = true
+			}
+		}
+		upline = next
+	}
+	return result
+}
+
+// GetTotalPerLevelByMsisdnAndMonth count referral made in month by each level of msisdn downline,
+// level without referral is not returned
+func (r referralHistoryRepository) GetTotalPerLevelByMsisdnAndMonth(ctx context.Context, msisdn, month string, maxLevel int) (result []model.ReferralLevelTotal, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.downline(msisdn, maxLevel) {
+		if !strings.HasPrefix(v.ReferralDate, month) || !counted(v.ReferralHistory) {
+			continue
+		}
+		if n := len(result); n > 0 && result[n-1].Level == v.level {
+			result[n-1].Total++
+			continue
+		}
+		result = append(result, model.ReferralLevelTotal{Level: v.level, Total: 1})
+	}
+	return result, nil
+}
+
+func (r referralHistoryRepository) FindDownline(ctx context.Context, msisdn string, maxLevel int) (result []model.ReferralDownline, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.downline(msisdn, maxLevel) {
+		if v.Status == model.ReferralStatusReversed || v.Status == model.ReferralStatusExpired {
+			continue
+		}
+		result = append(result, model.ReferralDownline{
+			Msisdn:        v.Msisdn,
+			MsisdnReferee: v.MsisdnReferee,
+			ReferralDate:  v.ReferralDate,
+			Level:         v.level,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Level != result[j].Level {
+			return result[i].Level < result[j].Level
+		}
+		if result[i].ReferralDate != result[j].ReferralDate {
+			return result[i].ReferralDate < result[j].ReferralDate
+		}
+		return result[i].MsisdnReferee < result[j].MsisdnReferee
+	})
+	return result, nil
+}
+
+// CountVelocity count referral matching filter, flagged referral is counted as well
+func (r referralHistoryRepository) CountVelocity(ctx context.Context, filter model.ReferralVelocityFilter) (total int, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.referralHistories {
+		if v.CreatedDate.Before(filter.Since) {
+			continue
+		}
+		if (filter.Code != "" && v.Code != filter.Code) || (filter.IPAddress != "" && v.IPAddress != filter.IPAddress) ||
+			(filter.DeviceID != "" && v.DeviceID != filter.DeviceID) {
+			continue
+		}
+		total++
+	}
+	return total, nil
+}
+
+// ranking rank referrer of month by counted referral, referrer with same total is ranked by who reach
+// the total first, then by msisdn
+func (r referralHistoryRepository) ranking(month string) []model.ReferralRank {
+	lastID := make(map[string]int64)
+	total := make(map[string]int)
+	for _, v := range r.store.referralHistories {
+		if strings.HasPrefix(v.ReferralDate, month) && counted(v) {
+			total[v.Msisdn]++
+			lastID[v.Msisdn] = v.ID
+		}
+	}
+
+	result := make([]model.ReferralRank, 0, len(total))
+	for msisdn, t := range total {
+		result = append(result, model.ReferralRank{Msisdn: msisdn, Total: t})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		if lastID[result[i].Msisdn] != lastID[result[j].Msisdn] {
+			return lastID[result[i].Msisdn] < lastID[result[j].Msisdn]
+		}
+		return result[i].Msisdn < result[j].Msisdn
+	})
+	for i := range result {
+		result[i].Rank = i + 1
+	}
+	return result
+}
+
+// FindTopReferrer return referrer with most counted referral in month
+func (r referralHistoryRepository) FindTopReferrer(ctx context.Context, month string, limit int) (result []model.ReferralRank, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.ranking(month) {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (r referralHistoryRepository) GetRankByMsisdnAndMonth(ctx context.Context, msisdn, month string) (model.ReferralRank, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.ranking(month) {
+		if v.Msisdn == msisdn {
+			return v, nil
+		}
+	}
+	return model.ReferralRank{}, sql.ErrNoRows
+}
+
+// statisticPeriod return period of referral date for each report interval, week period is its monday
+var statisticPeriod = map[string]func(date string) (string, error){
+	model.ReportIntervalDay: func(date string) (string, error) {
+		return date, nil
+	},
+	model.ReportIntervalWeek: func(date string) (string, error) {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return "", err
+		}
+		return t.AddDate(0, 0, -(int(t.Weekday())+6)%7).Format("2006-01-02"), nil
+	},
+	model.ReportIntervalMonth: func(date string) (string, error) {
+		return date[:7], nil
+	},
+}
+
+// GetStatistic count referral and distinct referrer of each period between from and to (inclusive),
+// period without referral is not returned
+func (r referralHistoryRepository) GetStatistic(ctx context.Context, from, to, interval string) (result []model.ReferralStatistic, err error) {
+	periodOf, ok := statisticPeriod[interval]
+	if !ok {
+		return nil, util.ErrorInvalidRequest
+	}
+	defer r.store.lock(ctx)()
+
+	statistic := make(map[string]*model.ReferralStatistic)
+	referrer := make(map[string]map[string]bool)
+	for _, v := range r.store.referralHistories {
+		if v.ReferralDate < from || v.ReferralDate > to || !counted(v) {
+			continue
+		}
+		period, err := periodOf(v.ReferralDate)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := statistic[period]; !ok {
+			statistic[period] = &model.ReferralStatistic{Period: period}
+			referrer[period] = make(map[string]bool)
+		}
+		statistic[period].TotalReferral++
+		referrer[period][v.Msisdn] = true
+	}
+
+	for period, v := range statistic {
+		v.ActiveReferrer = len(referrer[period])
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Period < result[j].Period
+	})
+	return result, nil
+}
+
+// GetTierDistribution count referrer reaching each default reward tier, total of each referrer is counted per month
+// so referrer active in two months is counted in both
+func (r referralHistoryRepository) GetTierDistribution(ctx context.Context, from, to string) (result []model.ReferralTierDistribution, err error) {
+	defer r.store.lock(ctx)()
+
+	type referrer struct {
+		month  string
+		msisdn string
+	}
+	total := make(map[referrer]int)
+	for _, v := range r.store.referralHistories {
+		if v.ReferralDate >= from && v.ReferralDate <= to && counted(v) {
+			total[referrer{month: v.ReferralDate[:7], msisdn: v.Msisdn}]++
+		}
+	}
+
+	distribution := make(map[int64]*model.ReferralTierDistribution)
+	for _, t := range total {
+		reward, _ := r.store.findReward(1, t)
+		if _, ok := distribution[reward.ID]; !ok {
+			distribution[reward.ID] = &model.ReferralTierDistribution{RewardID: reward.ID,
+				TotalReferral: reward.TotalReferral, Reward: reward.Description}
+		}
+		distribution[reward.ID].TotalReferrer++
+	}
+
+	for _, v := range distribution {
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalReferral != result[j].TotalReferral {
+			return result[i].TotalReferral < result[j].TotalReferral
+		}
+		return result[i].RewardID < result[j].RewardID
+	})
+	return result, nil
+}
+
+// StreamExport call fn for each referral matching filter ordered by id. Running total is counted from the start
+// of month so tier at the time is correct when range start mid month. Error returned by fn stop the export.
+func (r referralHistoryRepository) StreamExport(ctx context.Context, filter model.ReferralExportFilter, fn func(model.ReferralExport) error) error {
+	var monthStart string
+	if len(filter.From) >= 7 {
+		monthStart = filter.From[:7]
+	}
+
+	//rows are collected first so fn is not called while holding the store
+	var rows []model.ReferralExport
+	func() {
+		defer r.store.lock(ctx)()
+
+		monthTotal := make(map[string]int)
+		for _, v := range r.store.referralHistories {
+			if v.ReferralDate < monthStart || (filter.Msisdn != "" && v.Msisdn != filter.Msisdn) {
+				continue
+			}
+			key := v.Msisdn + "|" + v.ReferralDate[:7]
+			if counted(v) {
+				monthTotal[key]++
+			}
+			if v.ReferralDate < filter.From || (filter.To != "" && v.ReferralDate > filter.To) ||
+				(filter.Code != "" && !strings.EqualFold(v.Code, filter.Code)) {
+				continue
+			}
+
+			row := model.ReferralExport{
+				ID:            v.ID,
+				Msisdn:        v.Msisdn,
+				Code:          v.Code,
+				MsisdnReferee: v.MsisdnReferee,
+				ReferralDate:  v.ReferralDate,
+				CampaignID:    v.CampaignID,
+				Status:        v.Status,
+				Flagged:       v.Flagged,
+				MonthTotal:    monthTotal[key],
+				CreatedDate:   v.CreatedDate,
+			}
+			for _, c := range r.store.referralCodes {
+				if strings.EqualFold(c.Code, v.Code) {
+					row.CodeStatus = c.Status
+					break
+				}
+			}
+			if reward, ok := r.store.findReward(1, row.MonthTotal); ok {
+				row.Reward = reward.Description
+			}
+			rows = append(rows, row)
+		}
+	}()
+
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r referralHistoryRepository) Insert(ctx context.Context, referral *model.ReferralHistory) error {
+	defer r.store.lock(ctx)()
+	referral.ID = r.store.nextID("referral_history")
+	r.store.referralHistories = append(r.store.referralHistories, model.ReferralHistory{
+		ID:            referral.ID,
+		Msisdn:        referral.Msisdn,
+		Code:          referral.Code,
+		ReferralDate:  referral.ReferralDate,
+		MsisdnReferee: referral.MsisdnReferee,
+		CampaignID:    referral.CampaignID,
+		IPAddress:     referral.IPAddress,
+		DeviceID:      referral.DeviceID,
+		Flagged:       referral.Flagged,
+		FlagReason:    referral.FlagReason,
+		Status:        referral.Status,
+		CreatedDate:   time.Now(),
+	})
+	return nil
+}
+
+// InsertBatch insert referrals at once keeping their status and dates, used to import referral in bulk
+func (r referralHistoryRepository) InsertBatch(ctx context.Context, referrals []model.ReferralHistory) error {
+	defer r.store.lock(ctx)()
+	for _, v := range referrals {
+		r.store.referralHistories = append(r.store.referralHistories, model.ReferralHistory{
+			ID:            r.store.nextID("referral_history"),
+			Msisdn:        v.Msisdn,
+			Code:          v.Code,
+			ReferralDate:  v.ReferralDate,
+			MsisdnReferee: v.MsisdnReferee,
+			CampaignID:    v.CampaignID,
+			Status:        v.Status,
+			ConfirmedDate: v.ConfirmedDate,
+			CreatedDate:   v.CreatedDate,
+		})
+	}
+	return nil
+}
+
+// update apply fn to referral matching where, return total referral changed
+func (r referralHistoryRepository) update(ctx context.Context, where func(v model.ReferralHistory) bool,
+	fn func(v *model.ReferralHistory)) (total int64) {
+	defer r.store.lock(ctx)()
+	for i := range r.store.referralHistories {
+		if where(r.store.referralHistories[i]) {
+			fn(&r.store.referralHistories[i])
+			total++
+		}
+	}
+	return total
+}
+
+// Confirm change pending referral to confirmed, referral in other status is not found
+func (r referralHistoryRepository) Confirm(ctx context.Context, ID int64) error {
+	total := r.update(ctx, func(v model.ReferralHistory) bool {
+		return v.ID == ID && v.Status == model.ReferralStatusPending
+	}, func(v *model.ReferralHistory) {
+		now := time.Now()
+		v.Status = model.ReferralStatusConfirmed
+		v.ConfirmedDate = &now
+	})
+	if total == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// Reverse mark referral as reversed, reversed referral is not counted anymore
+func (r referralHistoryRepository) Reverse(ctx context.Context, ID int64, reason string) error {
+	total := r.update(ctx, func(v model.ReferralHistory) bool {
+		return v.ID == ID && v.CanTransitTo(model.ReferralStatusReversed)
+	}, func(v *model.ReferralHistory) {
+		now := time.Now()
+		v.Status = model.ReferralStatusReversed
+		v.ReversalReason = reason
+		v.ReversedDate = &now
+	})
+	if total == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// MarkRewarded change confirmed referral of msisdn in month to rewarded, return total referral changed
+func (r referralHistoryRepository) MarkRewarded(ctx context.Context, msisdn, month string) (int64, error) {
+	return r.update(ctx, func(v model.ReferralHistory) bool {
+		return v.Msisdn == msisdn && strings.HasPrefix(v.ReferralDate, month) && !v.Flagged &&
+			v.Status == model.ReferralStatusConfirmed
+	}, func(v *model.ReferralHistory) {
+		v.Status = model.ReferralStatusRewarded
+	}), nil
+}
+
+// ExpirePending change pending referral created before given time to expired, return total referral expired
+func (r referralHistoryRepository) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	return r.update(ctx, func(v model.ReferralHistory) bool {
+		return v.Status == model.ReferralStatusPending && v.CreatedDate.Before(before)
+	}, func(v *model.ReferralHistory) {
+		v.Status = model.ReferralStatusExpired
+	}), nil
+}
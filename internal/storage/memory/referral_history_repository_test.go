@@ -0,0 +1,205 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupReferralHistoryRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReferralHistoryRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupReferralHistoryRepository(NewStore())
+	})
+}
+
+// setupHistory return repository of store filled with referrals, 11 refer 12 and 13, 12 refer 14 and 15
+func setupHistory(t *testing.T) (*Store, *referralHistoryRepository) {
+	store := NewStore()
+	repo := SetupReferralHistoryRepository(store)
+	assert.Nil(t, repo.InsertBatch(context.Background(), []model.ReferralHistory{
+		{Msisdn: "6280000011", Code: "ABCABC123A", MsisdnReferee: "6280000012", ReferralDate: "2021-08-02",
+			Status: model.ReferralStatusConfirmed},
+		{Msisdn: "6280000011", Code: "ABCABC123A", MsisdnReferee: "6280000013", ReferralDate: "2021-08-10",
+			Status: model.ReferralStatusReversed},
+		{Msisdn: "6280000012", Code: "XYZXYZ123A", MsisdnReferee: "6280000014", ReferralDate: "2021-08-03",
+			Status: model.ReferralStatusConfirmed},
+		{Msisdn: "6280000012", Code: "XYZXYZ123A", MsisdnReferee: "6280000015", ReferralDate: "2021-08-12",
+			Status: model.ReferralStatusRewarded},
+		{Msisdn: "6280000012", Code: "XYZXYZ123A", MsisdnReferee: "6280000016", ReferralDate: "2021-09-01",
+			Status: model.ReferralStatusPending},
+	}))
+	return store, repo
+}
+
+func Test_referralHistoryRepository_Find(t *testing.T) {
+	ctx := context.Background()
+	_, repo := setupHistory(t)
+
+	t.Run("FindByMsisdn exclude reversed, newest first", func(t *testing.T) {
+		result, err := repo.FindByMsisdn(ctx, "6280000012", 1, 10)
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "6280000015", result[0].MsisdnReferee)
+		assert.Equal(t, "6280000014", result[1].MsisdnReferee)
+
+		total, err := repo.CountByMsisdn(ctx, "6280000011")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+	})
+	t.Run("FindByMsisdnReferee", func(t *testing.T) {
+		result, err := repo.FindByMsisdnReferee(ctx, "6280000013")
+		assert.Nil(t, err)
+		assert.Equal(t, model.ReferralStatusReversed, result.Status)
+
+		_, err = repo.FindByMsisdnReferee(ctx, "6280000099")
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("GetTotalByMsisdnAndMonth count confirmed and rewarded", func(t *testing.T) {
+		total, err := repo.GetTotalByMsisdnAndMonth(ctx, "6280000012", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, total)
+	})
+	t.Run("GetTotalPerLevelByMsisdnAndMonth", func(t *testing.T) {
+		result, err := repo.GetTotalPerLevelByMsisdnAndMonth(ctx, "6280000011", "2021-08", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralLevelTotal{{Level: 1, Total: 1}, {Level: 2, Total: 2}}, result)
+
+		result, _ = repo.GetTotalPerLevelByMsisdnAndMonth(ctx, "6280000011", "2021-08", 1)
+		assert.Equal(t, []model.ReferralLevelTotal{{Level: 1, Total: 1}}, result)
+	})
+	t.Run("FindDownline exclude reversed", func(t *testing.T) {
+		result, err := repo.FindDownline(ctx, "6280000011", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralDownline{
+			{Msisdn: "6280000011", MsisdnReferee: "6280000012", ReferralDate: "2021-08-02", Level: 1},
+			{Msisdn: "6280000012", MsisdnReferee: "6280000014", ReferralDate: "2021-08-03", Level: 2},
+			{Msisdn: "6280000012", MsisdnReferee: "6280000015", ReferralDate: "2021-08-12", Level: 2},
+			{Msisdn: "6280000012", MsisdnReferee: "6280000016", ReferralDate: "2021-09-01", Level: 2},
+		}, result)
+	})
+	t.Run("CountVelocity", func(t *testing.T) {
+		total, err := repo.CountVelocity(ctx, model.ReferralVelocityFilter{Code: "XYZXYZ123A"})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+	})
+}
+
+func Test_referralHistoryRepository_Ranking(t *testing.T) {
+	ctx := context.Background()
+	_, repo := setupHistory(t)
+	_ = repo.InsertBatch(ctx, []model.ReferralHistory{
+		{Msisdn: "6280000011", MsisdnReferee: "6280000017", ReferralDate: "2021-08-20", Status: model.ReferralStatusConfirmed},
+	})
+
+	t.Run("same total ranked by who reach it first", func(t *testing.T) {
+		result, err := repo.FindTopReferrer(ctx, "2021-08", 10)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralRank{
+			{Rank: 1, Msisdn: "6280000012", Total: 2},
+			{Rank: 2, Msisdn: "6280000011", Total: 2},
+		}, result)
+	})
+	t.Run("GetRankByMsisdnAndMonth", func(t *testing.T) {
+		result, err := repo.GetRankByMsisdnAndMonth(ctx, "6280000011", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, result.Rank)
+
+		_, err = repo.GetRankByMsisdnAndMonth(ctx, "6280000011", "2021-09")
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+}
+
+func Test_referralHistoryRepository_Report(t *testing.T) {
+	ctx := context.Background()
+	store, repo := setupHistory(t)
+	_ = SetupRewardRepository(store).Insert(ctx, &model.Reward{TotalReferral: 2, Description: "bonus 2 GB", Level: 1})
+	_ = SetupReferralCodeRepository(store).Insert(ctx, &model.ReferralCode{Msisdn: "6280000012", Code: "XYZXYZ123A"})
+
+	t.Run("GetStatistic invalid interval", func(t *testing.T) {
+		_, err := repo.GetStatistic(ctx, "2021-08-01", "2021-08-31", "year")
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("GetStatistic per week", func(t *testing.T) {
+		result, err := repo.GetStatistic(ctx, "2021-08-01", "2021-08-31", model.ReportIntervalWeek)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralStatistic{
+			{Period: "2021-08-02", TotalReferral: 2, ActiveReferrer: 2},
+			{Period: "2021-08-09", TotalReferral: 1, ActiveReferrer: 1},
+		}, result)
+	})
+	t.Run("GetTierDistribution", func(t *testing.T) {
+		result, err := repo.GetTierDistribution(ctx, "2021-08-01", "2021-08-31")
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralTierDistribution{
+			{TotalReferrer: 1},
+			{RewardID: 1, TotalReferral: 2, Reward: "bonus 2 GB", TotalReferrer: 1},
+		}, result)
+	})
+	t.Run("StreamExport count month total from start of month", func(t *testing.T) {
+		var result []model.ReferralExport
+		err := repo.StreamExport(ctx, model.ReferralExportFilter{Msisdn: "6280000012", From: "2021-08-05"},
+			func(v model.ReferralExport) error {
+				result = append(result, v)
+				return nil
+			})
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, 2, result[0].MonthTotal)
+		assert.Equal(t, "bonus 2 GB", result[0].Reward)
+		assert.Equal(t, model.ReferralCodeStatusActive, result[0].CodeStatus)
+		assert.Equal(t, 0, result[1].MonthTotal)
+	})
+	t.Run("StreamExport stopped by fn", func(t *testing.T) {
+		err := repo.StreamExport(ctx, model.ReferralExportFilter{}, func(v model.ReferralExport) error {
+			return errors.New("failed")
+		})
+		assert.NotNil(t, err)
+	})
+}
+
+func Test_referralHistoryRepository_Update(t *testing.T) {
+	ctx := context.Background()
+	_, repo := setupHistory(t)
+
+	t.Run("Confirm non pending referral", func(t *testing.T) {
+		assert.Equal(t, util.ErrorDataNotFound, repo.Confirm(ctx, 1))
+	})
+	t.Run("Confirm", func(t *testing.T) {
+		assert.Nil(t, repo.Confirm(ctx, 5))
+		result, _ := repo.FindByMsisdnReferee(ctx, "6280000016")
+		assert.Equal(t, model.ReferralStatusConfirmed, result.Status)
+		assert.NotNil(t, result.ConfirmedDate)
+	})
+	t.Run("Reverse reversed referral", func(t *testing.T) {
+		assert.Equal(t, util.ErrorDataNotFound, repo.Reverse(ctx, 2, "fraud"))
+	})
+	t.Run("Reverse", func(t *testing.T) {
+		assert.Nil(t, repo.Reverse(ctx, 4, "fraud"))
+		result, _ := repo.FindByMsisdnReferee(ctx, "6280000015")
+		assert.Equal(t, "fraud", result.ReversalReason)
+	})
+	t.Run("MarkRewarded", func(t *testing.T) {
+		total, err := repo.MarkRewarded(ctx, "6280000012", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), total)
+	})
+	t.Run("ExpirePending", func(t *testing.T) {
+		referral := &model.ReferralHistory{Msisdn: "6280000011", MsisdnReferee: "6280000018", ReferralDate: "2021-09-02"}
+		assert.Nil(t, repo.Insert(ctx, referral))
+		assert.Equal(t, int64(6), referral.ID)
+
+		total, err := repo.ExpirePending(ctx, time.Now().Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), total)
+	})
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type rewardClaimRepository struct {
+	store *Store
+}
+
+func SetupRewardClaimRepository(store *Store) *rewardClaimRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &rewardClaimRepository{
+		store: store,
+	}
+}
+
+// FindByMsisdn return every claim of msisdn, newest first
+func (r rewardClaimRepository) FindByMsisdn(ctx context.Context, msisdn string) (result []model.RewardClaim, err error) {
+	defer r.store.lock(ctx)()
+	for i := len(r.store.rewardClaims) - 1; i >= 0; i-- {
+		if v := r.store.rewardClaims[i]; v.Msisdn == msisdn {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+func (r rewardClaimRepository) FindByMsisdnRewardAndPeriod(ctx context.Context, msisdn string, rewardID int64, period string) (model.RewardClaim, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.rewardClaims {
+		if v.Msisdn == msisdn && v.RewardID == rewardID && v.Period == period {
+			return v, nil
+		}
+	}
+	return model.RewardClaim{}, sql.ErrNoRows
+}
+
+// Insert store claim, reward is claimed once per msisdn and period
+func (r rewardClaimRepository) Insert(ctx context.Context, claim *model.RewardClaim) error {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.rewardClaims {
+		if v.Msisdn == claim.Msisdn && v.RewardID == claim.RewardID && v.Period == claim.Period {
+			return util.ErrorRewardAlreadyClaimed
+		}
+	}
+	claim.ID = r.store.nextID("reward_claim")
+	r.store.rewardClaims = append(r.store.rewardClaims, model.RewardClaim{
+		ID:            claim.ID,
+		Msisdn:        claim.Msisdn,
+		RewardID:      claim.RewardID,
+		TotalReferral: claim.TotalReferral,
+		Description:   claim.Description,
+		Period:        claim.Period,
+		Level:         claim.Level,
+		Status:        model.RewardClaimStatusClaimed,
+		CreatedDate:   time.Now(),
+	})
+	return nil
+}
+
+func (r rewardClaimRepository) UpdateStatus(ctx context.Context, ID int64, status int) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.rewardClaims {
+		if r.store.rewardClaims[i].ID == ID {
+			r.store.rewardClaims[i].Status = status
+			return nil
+		}
+	}
+	return util.ErrorDataNotFound
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupRewardClaimRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardClaimRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRewardClaimRepository(NewStore())
+	})
+}
+
+func Test_rewardClaimRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupRewardClaimRepository(NewStore())
+	assert.Nil(t, repo.Insert(ctx, &model.RewardClaim{Msisdn: "6280000011", RewardID: 1, Period: "2021-07"}))
+	assert.Nil(t, repo.Insert(ctx, &model.RewardClaim{Msisdn: "6280000011", RewardID: 1, Period: "2021-08"}))
+
+	t.Run("claim reward twice in period", func(t *testing.T) {
+		err := repo.Insert(ctx, &model.RewardClaim{Msisdn: "6280000011", RewardID: 1, Period: "2021-08"})
+		assert.Equal(t, util.ErrorRewardAlreadyClaimed, err)
+	})
+	t.Run("FindByMsisdn newest first", func(t *testing.T) {
+		result, err := repo.FindByMsisdn(ctx, "6280000011")
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "2021-08", result[0].Period)
+		assert.Equal(t, model.RewardClaimStatusClaimed, result[0].Status)
+	})
+	t.Run("UpdateStatus", func(t *testing.T) {
+		assert.Nil(t, repo.UpdateStatus(ctx, 2, model.RewardClaimStatusClawback))
+		result, err := repo.FindByMsisdnRewardAndPeriod(ctx, "6280000011", 1, "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, model.RewardClaimStatusClawback, result.Status)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.FindByMsisdnRewardAndPeriod(ctx, "6280000011", 2, "2021-08")
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateStatus(ctx, 9, model.RewardClaimStatusClawback))
+	})
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type rewardRepository struct {
+	store *Store
+}
+
+func SetupRewardRepository(store *Store) *rewardRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &rewardRepository{
+		store: store,
+	}
+}
+
+// findReward return active reward without campaign of the level with highest tier reached by totalReferral
+func (t tables) findReward(level, totalReferral int) (result model.Reward, found bool) {
+	for _, v := range t.rewards {
+		if v.CampaignID != 0 || v.Level != level || v.Status != 1 || v.TotalReferral > totalReferral {
+			continue
+		}
+		if !found || v.TotalReferral > result.TotalReferral {
+			result, found = v, true
+		}
+	}
+	return result, found
+}
+
+// FindAll return every reward including deleted one, reward without campaign is listed last
+func (r rewardRepository) FindAll(ctx context.Context) ([]model.Reward, error) {
+	defer r.store.lock(ctx)()
+	result := append([]model.Reward(nil), r.store.rewards...)
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].CampaignID != result[j].CampaignID {
+			return result[j].CampaignID == 0 || (result[i].CampaignID != 0 && result[i].CampaignID < result[j].CampaignID)
+		}
+		if result[i].Level != result[j].Level {
+			return result[i].Level < result[j].Level
+		}
+		return result[i].TotalReferral < result[j].TotalReferral
+	})
+	return result, nil
+}
+
+func (r rewardRepository) FindByID(ctx context.Context, ID int64) (model.Reward, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.rewards {
+		if v.ID == ID {
+			return v, nil
+		}
+	}
+	return model.Reward{}, sql.ErrNoRows
+}
+
+func (r rewardRepository) FindByTotalReferral(ctx context.Context, totalReferral int) (model.Reward, error) {
+	return r.FindByLevelAndTotalReferral(ctx, 1, totalReferral)
+}
+
+func (r rewardRepository) FindByLevelAndTotalReferral(ctx context.Context, level, totalReferral int) (model.Reward, error) {
+	defer r.store.lock(ctx)()
+	if result, found := r.store.findReward(level, totalReferral); found {
+		return result, nil
+	}
+	return model.Reward{}, sql.ErrNoRows
+}
+
+func (r rewardRepository) FindByCampaignAndTotalReferral(ctx context.Context, campaignID int64, totalReferral int) (result model.Reward, err error) {
+	defer r.store.lock(ctx)()
+	found := false
+	for _, v := range r.store.rewards {
+		if v.CampaignID == 0 || v.CampaignID != campaignID || v.Status != 1 || v.TotalReferral > totalReferral {
+			continue
+		}
+		if !found || v.TotalReferral > result.TotalReferral {
+			result, found = v, true
+		}
+	}
+	if !found {
+		return model.Reward{}, sql.ErrNoRows
+	}
+	return result, nil
+}
+
+func (r rewardRepository) Insert(ctx context.Context, reward *model.Reward) error {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	reward.ID = r.store.nextID("reward")
+	r.store.rewards = append(r.store.rewards, model.Reward{
+		ID:            reward.ID,
+		TotalReferral: reward.TotalReferral,
+		Description:   reward.Description,
+		CampaignID:    reward.CampaignID,
+		Level:         reward.Level,
+		CreatedDate:   now,
+		UpdatedDate:   now,
+		Status:        1,
+	})
+	return nil
+}
+
+// Update change total referral and description of reward, empty value is not changed
+func (r rewardRepository) Update(ctx context.Context, reward model.Reward) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.rewards {
+		v := &r.store.rewards[i]
+		if v.ID != reward.ID {
+			continue
+		}
+		if reward.TotalReferral > 0 {
+			v.TotalReferral = reward.TotalReferral
+		}
+		if reward.Description != "" {
+			v.Description = reward.Description
+		}
+		v.UpdatedDate = time.Now()
+		return nil
+	}
+	return util.ErrorDataNotFound
+}
+
+// Delete deactivate reward, it is kept for claimed reward
+func (r rewardRepository) Delete(ctx context.Context, ID int64) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.rewards {
+		if r.store.rewards[i].ID == ID {
+			r.store.rewards[i].Status = 0
+			return nil
+		}
+	}
+	return util.ErrorDataNotFound
+}
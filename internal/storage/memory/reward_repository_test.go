@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupRewardRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRewardRepository(NewStore())
+	})
+}
+
+func Test_rewardRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupRewardRepository(NewStore())
+	for _, v := range []model.Reward{
+		{TotalReferral: 5, Description: "bonus 12 GB", Level: 1},
+		{TotalReferral: 1, Description: "bonus 2 GB", Level: 1},
+		{TotalReferral: 1, Description: "bonus 500 MB", Level: 2},
+		{TotalReferral: 3, Description: "bonus campaign", CampaignID: 1, Level: 1},
+		{TotalReferral: 2, Description: "bonus 5 GB", Level: 1},
+	} {
+		reward := v
+		assert.Nil(t, repo.Insert(ctx, &reward))
+	}
+	assert.Nil(t, repo.Delete(ctx, 5))
+
+	t.Run("FindAll list reward without campaign last", func(t *testing.T) {
+		result, err := repo.FindAll(ctx)
+		assert.Nil(t, err)
+		var ids []int64
+		for _, v := range result {
+			ids = append(ids, v.ID)
+		}
+		assert.Equal(t, []int64{4, 2, 5, 1, 3}, ids)
+	})
+	t.Run("FindByLevelAndTotalReferral highest tier reached, deleted is skipped", func(t *testing.T) {
+		result, err := repo.FindByTotalReferral(ctx, 4)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), result.ID)
+
+		result, err = repo.FindByLevelAndTotalReferral(ctx, 2, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, "bonus 500 MB", result.Description)
+
+		_, err = repo.FindByTotalReferral(ctx, 0)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("FindByCampaignAndTotalReferral", func(t *testing.T) {
+		result, err := repo.FindByCampaignAndTotalReferral(ctx, 1, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), result.ID)
+
+		_, err = repo.FindByCampaignAndTotalReferral(ctx, 1, 2)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("Update keep empty value", func(t *testing.T) {
+		assert.Nil(t, repo.Update(ctx, model.Reward{ID: 1, Description: "bonus 15 GB"}))
+		result, err := repo.FindByID(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, result.TotalReferral)
+		assert.Equal(t, "bonus 15 GB", result.Description)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.FindByID(ctx, 9)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Equal(t, util.ErrorDataNotFound, repo.Update(ctx, model.Reward{ID: 9}))
+		assert.Equal(t, util.ErrorDataNotFound, repo.Delete(ctx, 9))
+	})
+}
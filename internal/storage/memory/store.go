@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+// Store keep table of every repository in process memory, it is meant for local development and test.
+// Data is not shared between instance and lost on restart
+type Store struct {
+	mu sync.Mutex
+	tables
+}
+
+// tables is row of each table ordered by id, the same way it is inserted
+type tables struct {
+	referralCodes           []model.ReferralCode
+	referralHistories       []model.ReferralHistory
+	rewards                 []model.Reward
+	campaigns               []model.Campaign
+	rewardClaims            []model.RewardClaim
+	referralClicks          []model.ReferralClick
+	webhookSubscriptions    []model.WebhookSubscription
+	webhookDeliveries       []model.WebhookDelivery
+	outboxEvents            []model.OutboxEvent
+	notifications           []model.Notification
+	notificationPreferences map[string]model.NotificationPreference
+	// sequence is last id of each table
+	sequence map[string]int64
+}
+
+func NewStore() *Store {
+	return &Store{
+		tables: tables{
+			notificationPreferences: make(map[string]model.NotificationPreference),
+			sequence:                make(map[string]int64),
+		},
+	}
+}
+
+// clone copy every table, row is copied by value so changing the copy does not change the original
+func (t tables) clone() tables {
+	result := tables{
+		referralCodes:           append([]model.ReferralCode(nil), t.referralCodes...),
+		referralHistories:       append([]model.ReferralHistory(nil), t.referralHistories...),
+		rewards:                 append([]model.Reward(nil), t.rewards...),
+		campaigns:               append([]model.Campaign(nil), t.campaigns...),
+		rewardClaims:            append([]model.RewardClaim(nil), t.rewardClaims...),
+		referralClicks:          append([]model.ReferralClick(nil), t.referralClicks...),
+		webhookSubscriptions:    append([]model.WebhookSubscription(nil), t.webhookSubscriptions...),
+		webhookDeliveries:       append([]model.WebhookDelivery(nil), t.webhookDeliveries...),
+		outboxEvents:            append([]model.OutboxEvent(nil), t.outboxEvents...),
+		notifications:           append([]model.Notification(nil), t.notifications...),
+		notificationPreferences: make(map[string]model.NotificationPreference, len(t.notificationPreferences)),
+		sequence:                make(map[string]int64, len(t.sequence)),
+	}
+	for k, v := range t.notificationPreferences {
+		result.notificationPreferences[k] = v
+	}
+	for k, v := range t.sequence {
+		result.sequence[k] = v
+	}
+	return result
+}
+
+// nextID return next id of table, id is not reused even when insert is rolled back
+func (t tables) nextID(table string) int64 {
+	t.sequence[table]++
+	return t.sequence[table]
+}
+
+type txKey struct{}
+
+// WithinTransaction run fn while holding the store, so transaction is serialized. Change made by fn is discarded
+// when fn return error. Nested call join the outer transaction.
+func (s *Store) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if s.inTransaction(ctx) {
+		return fn(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.tables.clone()
+	defer func() {
+		if p := recover(); p != nil {
+			s.rollback(snapshot)
+			panic(p)
+		}
+		if err != nil {
+			s.rollback(snapshot)
+		}
+	}()
+	return fn(context.WithValue(ctx, txKey{}, s))
+}
+
+// rollback restore table to snapshot, sequence is kept like the sequence of postgresql
+func (s *Store) rollback(snapshot tables) {
+	snapshot.sequence = s.tables.sequence
+	s.tables = snapshot
+}
+
+func (s *Store) inTransaction(ctx context.Context) bool {
+	tx, ok := ctx.Value(txKey{}).(*Store)
+	return ok && tx == s
+}
+
+// lock hold the store until returned func is called, transaction of ctx already holds it
+func (s *Store) lock(ctx context.Context) (unlock func()) {
+	if s.inTransaction(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// uniqueViolation is error of insert breaking unique constraint which is not translated to application error,
+// the same as returned by postgresql
+func uniqueViolation(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint %q", constraint)
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestStore_WithinTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commit", func(t *testing.T) {
+		store := NewStore()
+		repo := SetupReferralCodeRepository(store)
+		err := store.WithinTransaction(ctx, func(ctx context.Context) error {
+			return repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+		})
+		assert.Nil(t, err)
+
+		result, err := repo.FindByCode(ctx, "ABCABC123A")
+		assert.Nil(t, err)
+		assert.Equal(t, "6280000011", result.Msisdn)
+	})
+	t.Run("rollback on error", func(t *testing.T) {
+		store := NewStore()
+		repo := SetupReferralCodeRepository(store)
+		err := store.WithinTransaction(ctx, func(ctx context.Context) error {
+			assert.Nil(t, repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"}))
+			return context.DeadlineExceeded
+		})
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		_, err = repo.FindByCode(ctx, "ABCABC123A")
+		assert.NotNil(t, err)
+	})
+	t.Run("rollback on panic", func(t *testing.T) {
+		store := NewStore()
+		repo := SetupReferralCodeRepository(store)
+		assert.Panics(t, func() {
+			_ = store.WithinTransaction(ctx, func(ctx context.Context) error {
+				assert.Nil(t, repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"}))
+				panic("failed")
+			})
+		})
+
+		_, err := repo.FindByCode(ctx, "ABCABC123A")
+		assert.NotNil(t, err)
+	})
+	t.Run("nested transaction join the outer", func(t *testing.T) {
+		store := NewStore()
+		repo := SetupReferralCodeRepository(store)
+		err := store.WithinTransaction(ctx, func(ctx context.Context) error {
+			err := store.WithinTransaction(ctx, func(ctx context.Context) error {
+				return repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+			})
+			assert.Nil(t, err)
+			return errors.New("failed")
+		})
+		assert.NotNil(t, err)
+
+		_, err = repo.FindByCode(ctx, "ABCABC123A")
+		assert.NotNil(t, err)
+	})
+	t.Run("id is not reused after rollback", func(t *testing.T) {
+		store := NewStore()
+		repo := SetupReferralCodeRepository(store)
+		_ = store.WithinTransaction(ctx, func(ctx context.Context) error {
+			_ = repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+			return errors.New("failed")
+		})
+
+		code := &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"}
+		assert.Nil(t, repo.Insert(ctx, code))
+		assert.Equal(t, int64(2), code.ID)
+	})
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type webhookDeliveryRepository struct {
+	store *Store
+}
+
+func SetupWebhookDeliveryRepository(store *Store) *webhookDeliveryRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &webhookDeliveryRepository{
+		store: store,
+	}
+}
+
+// Insert queue delivery, event already queued for the subscription is ignored and keep its delivery
+func (r webhookDeliveryRepository) Insert(ctx context.Context, delivery *model.WebhookDelivery) error {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.webhookDeliveries {
+		if v.SubscriptionID == delivery.SubscriptionID && v.EventID == delivery.EventID {
+			return nil
+		}
+	}
+	now := time.Now()
+	delivery.ID = r.store.nextID("webhook_delivery")
+	r.store.webhookDeliveries = append(r.store.webhookDeliveries, model.WebhookDelivery{
+		ID:              delivery.ID,
+		SubscriptionID:  delivery.SubscriptionID,
+		EventID:         delivery.EventID,
+		Event:           delivery.Event,
+		Payload:         delivery.Payload,
+		Status:          model.WebhookDeliveryStatusPending,
+		NextAttemptDate: now,
+		CreatedDate:     now,
+		UpdatedDate:     now,
+	})
+	return nil
+}
+
+// ClaimDue return pending delivery whose next attempt already passed, earliest first, and postpone it by lease
+func (r webhookDeliveryRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.WebhookDelivery, err error) {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	var due []int
+	for i, v := range r.store.webhookDeliveries {
+		if v.Status == model.WebhookDeliveryStatusPending && !v.NextAttemptDate.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return r.store.webhookDeliveries[due[i]].NextAttemptDate.Before(r.store.webhookDeliveries[due[j]].NextAttemptDate)
+	})
+	for _, i := range due {
+		if len(result) == limit {
+			break
+		}
+		r.store.webhookDeliveries[i].NextAttemptDate = now.Add(lease)
+		result = append(result, r.store.webhookDeliveries[i])
+	}
+	return result, nil
+}
+
+func (r webhookDeliveryRepository) UpdateResult(ctx context.Context, delivery model.WebhookDelivery) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.webhookDeliveries {
+		v := &r.store.webhookDeliveries[i]
+		if v.ID != delivery.ID {
+			continue
+		}
+		v.Status = delivery.Status
+		v.Attempt = delivery.Attempt
+		v.NextAttemptDate = delivery.NextAttemptDate
+		v.ResponseCode = delivery.ResponseCode
+		v.LastError = delivery.LastError
+		v.UpdatedDate = time.Now()
+		return nil
+	}
+	return util.ErrorDataNotFound
+}
+
+// FindByStatus return delivery in status, newest first
+func (r webhookDeliveryRepository) FindByStatus(ctx context.Context, status, offset, limit int) (result []model.WebhookDelivery, err error) {
+	defer r.store.lock(ctx)()
+	for i := len(r.store.webhookDeliveries) - 1; i >= 0 && len(result) < limit; i-- {
+		if r.store.webhookDeliveries[i].Status != status {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		result = append(result, r.store.webhookDeliveries[i])
+	}
+	return result, nil
+}
+
+func (r webhookDeliveryRepository) CountByStatus(ctx context.Context, status int) (total int, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.webhookDeliveries {
+		if v.Status == status {
+			total++
+		}
+	}
+	return total, nil
+}
+
+// replay set dead delivery matching where back to pending, return total delivery replayed
+func (r webhookDeliveryRepository) replay(ctx context.Context, where func(v model.WebhookDelivery) bool) (total int64) {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	for i := range r.store.webhookDeliveries {
+		v := &r.store.webhookDeliveries[i]
+		if v.Status != model.WebhookDeliveryStatusDead || !where(*v) {
+			continue
+		}
+		v.Status = model.WebhookDeliveryStatusPending
+		v.Attempt = 0
+		v.NextAttemptDate = now
+		v.UpdatedDate = now
+		total++
+	}
+	return total
+}
+
+// Replay set dead delivery back to pending so it is sent on next run, delivery in other status is not found
+func (r webhookDeliveryRepository) Replay(ctx context.Context, ID int64) error {
+	total := r.replay(ctx, func(v model.WebhookDelivery) bool {
+		return v.ID == ID
+	})
+	if total == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// ReplayDead set every dead delivery of subscription back to pending, all subscription when subscriptionID is 0
+func (r webhookDeliveryRepository) ReplayDead(ctx context.Context, subscriptionID int64) (int64, error) {
+	return r.replay(ctx, func(v model.WebhookDelivery) bool {
+		return subscriptionID == 0 || v.SubscriptionID == subscriptionID
+	}), nil
+}
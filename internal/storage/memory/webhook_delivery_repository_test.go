@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupWebhookDeliveryRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupWebhookDeliveryRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupWebhookDeliveryRepository(NewStore())
+	})
+}
+
+func Test_webhookDeliveryRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupWebhookDeliveryRepository(NewStore())
+	for _, v := range []model.WebhookDelivery{
+		{SubscriptionID: 1, EventID: "a1"},
+		{SubscriptionID: 1, EventID: "a1"},
+		{SubscriptionID: 2, EventID: "a1"},
+		{SubscriptionID: 1, EventID: "a2"},
+	} {
+		delivery := v
+		assert.Nil(t, repo.Insert(ctx, &delivery))
+	}
+
+	t.Run("ClaimDue ignore duplicate event and lease claimed delivery", func(t *testing.T) {
+		result, err := repo.ClaimDue(ctx, time.Minute, 2)
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, int64(1), result[0].ID)
+		assert.Equal(t, int64(2), result[1].ID)
+
+		result, _ = repo.ClaimDue(ctx, time.Minute, 10)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "a2", result[0].EventID)
+
+		result, _ = repo.ClaimDue(ctx, time.Minute, 10)
+		assert.Empty(t, result)
+	})
+	t.Run("UpdateResult", func(t *testing.T) {
+		for _, id := range []int64{1, 2} {
+			assert.Nil(t, repo.UpdateResult(ctx, model.WebhookDelivery{ID: id, Status: model.WebhookDeliveryStatusDead,
+				Attempt: 8, ResponseCode: 500}))
+		}
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateResult(ctx, model.WebhookDelivery{ID: 9}))
+
+		total, err := repo.CountByStatus(ctx, model.WebhookDeliveryStatusDead)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, total)
+
+		result, err := repo.FindByStatus(ctx, model.WebhookDeliveryStatusDead, 0, 1)
+		assert.Nil(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, int64(2), result[0].ID)
+	})
+	t.Run("Replay", func(t *testing.T) {
+		assert.Nil(t, repo.Replay(ctx, 1))
+		assert.Equal(t, util.ErrorDataNotFound, repo.Replay(ctx, 1))
+
+		result, _ := repo.ClaimDue(ctx, time.Minute, 10)
+		assert.Len(t, result, 1)
+		assert.Equal(t, 0, result[0].Attempt)
+	})
+	t.Run("ReplayDead", func(t *testing.T) {
+		total, err := repo.ReplayDead(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), total)
+
+		total, err = repo.ReplayDead(ctx, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), total)
+	})
+}
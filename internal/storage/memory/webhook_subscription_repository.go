@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type webhookSubscriptionRepository struct {
+	store *Store
+}
+
+func SetupWebhookSubscriptionRepository(store *Store) *webhookSubscriptionRepository {
+	if store == nil {
+		panic("memory store is nil")
+	}
+	return &webhookSubscriptionRepository{
+		store: store,
+	}
+}
+
+// FindAll return every subscription, newest first
+func (r webhookSubscriptionRepository) FindAll(ctx context.Context) (result []model.WebhookSubscription, err error) {
+	defer r.store.lock(ctx)()
+	for i := len(r.store.webhookSubscriptions) - 1; i >= 0; i-- {
+		result = append(result, r.store.webhookSubscriptions[i])
+	}
+	return result, nil
+}
+
+func (r webhookSubscriptionRepository) FindByID(ctx context.Context, ID int64) (model.WebhookSubscription, error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.webhookSubscriptions {
+		if v.ID == ID {
+			return v, nil
+		}
+	}
+	return model.WebhookSubscription{}, sql.ErrNoRows
+}
+
+// FindActiveByEvent return active subscription receiving event, oldest first
+func (r webhookSubscriptionRepository) FindActiveByEvent(ctx context.Context, event string) (result []model.WebhookSubscription, err error) {
+	defer r.store.lock(ctx)()
+	for _, v := range r.store.webhookSubscriptions {
+		if v.Status != model.WebhookSubscriptionStatusActive {
+			continue
+		}
+		for _, e := range v.EventList() {
+			if e == event {
+				result = append(result, v)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r webhookSubscriptionRepository) Insert(ctx context.Context, subscription *model.WebhookSubscription) error {
+	defer r.store.lock(ctx)()
+	now := time.Now()
+	subscription.ID = r.store.nextID("webhook_subscription")
+	r.store.webhookSubscriptions = append(r.store.webhookSubscriptions, model.WebhookSubscription{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		Secret:      subscription.Secret,
+		Events:      subscription.Events,
+		Status:      model.WebhookSubscriptionStatusActive,
+		CreatedDate: now,
+		UpdatedDate: now,
+	})
+	return nil
+}
+
+func (r webhookSubscriptionRepository) UpdateStatus(ctx context.Context, ID int64, status int) error {
+	defer r.store.lock(ctx)()
+	for i := range r.store.webhookSubscriptions {
+		if r.store.webhookSubscriptions[i].ID == ID {
+			r.store.webhookSubscriptions[i].Status = status
+			r.store.webhookSubscriptions[i].UpdatedDate = time.Now()
+			return nil
+		}
+	}
+	return util.ErrorDataNotFound
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupWebhookSubscriptionRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupWebhookSubscriptionRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupWebhookSubscriptionRepository(NewStore())
+	})
+}
+
+func Test_webhookSubscriptionRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupWebhookSubscriptionRepository(NewStore())
+	assert.Nil(t, repo.Insert(ctx, &model.WebhookSubscription{URL: "http://a", Events: "referral.created,referral.reversed"}))
+	assert.Nil(t, repo.Insert(ctx, &model.WebhookSubscription{URL: "http://b", Events: "referral.created_batch"}))
+	assert.Nil(t, repo.Insert(ctx, &model.WebhookSubscription{URL: "http://c", Events: "referral.created"}))
+	assert.Nil(t, repo.UpdateStatus(ctx, 3, model.WebhookSubscriptionStatusInactive))
+
+	t.Run("FindAll newest first", func(t *testing.T) {
+		result, err := repo.FindAll(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, result, 3)
+		assert.Equal(t, "http://c", result[0].URL)
+		assert.Equal(t, model.WebhookSubscriptionStatusInactive, result[0].Status)
+	})
+	t.Run("FindActiveByEvent match whole event", func(t *testing.T) {
+		result, err := repo.FindActiveByEvent(ctx, "referral.created")
+		assert.Nil(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "http://a", result[0].URL)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.FindByID(ctx, 9)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateStatus(ctx, 9, model.WebhookSubscriptionStatusActive))
+	})
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type Campaign struct {
+	ID          int64     `db:"id"`
+	Name        string    `db:"name"`
+	StartDate   string    `db:"start_date"`
+	EndDate     string    `db:"end_date"`
+	Status      int       `db:"status"`
+	CreatedDate time.Time `db:"created_date"`
+	UpdatedDate time.Time `db:"updated_date"`
+}
+
+type CampaignRepository interface {
+	FindAll(ctx context.Context) ([]Campaign, error)
+	FindByID(ctx context.Context, ID int64) (Campaign, error)
+	FindActiveByDate(ctx context.Context, date string) ([]Campaign, error)
+	Insert(ctx context.Context, campaign *Campaign) error
+	Update(ctx context.Context, campaign Campaign) error
+	Delete(ctx context.Context, ID int64) error
+}
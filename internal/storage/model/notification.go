@@ -0,0 +1,52 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	NotificationStatusPending = 0
+	NotificationStatusSent    = 1
+	// NotificationStatusFailed is notification still failing after max attempt
+	NotificationStatusFailed = 2
+	// NotificationStatusCancelled is notification of msisdn which opted out before it is sent
+	NotificationStatusCancelled = 3
+)
+
+type Notification struct {
+	ID              int64     `db:"id"`
+	EventID         string    `db:"event_id"`
+	Msisdn          string    `db:"msisdn"`
+	Template        string    `db:"template"`
+	Message         string    `db:"message"`
+	Status          int       `db:"status"`
+	Attempt         int       `db:"attempt"`
+	NextAttemptDate time.Time `db:"next_attempt_date"`
+	LastError       string    `db:"last_error"`
+	CreatedDate     time.Time `db:"created_date"`
+	UpdatedDate     time.Time `db:"updated_date"`
+}
+
+type NotificationPreference struct {
+	Msisdn      string    `db:"msisdn"`
+	Language    string    `db:"language"`
+	OptOut      bool      `db:"opt_out"`
+	CreatedDate time.Time `db:"created_date"`
+	UpdatedDate time.Time `db:"updated_date"`
+}
+
+type NotificationRepository interface {
+	// Insert queue notification, notification of the same event and msisdn is ignored
+	Insert(ctx context.Context, notification *Notification) error
+	// ClaimDue return pending notification whose next attempt already passed and postpone it by lease,
+	// so the same notification is not sent by other instance in the meantime
+	ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]Notification, error)
+	UpdateResult(ctx context.Context, notification Notification) error
+}
+
+type NotificationPreferenceRepository interface {
+	FindByMsisdn(ctx context.Context, msisdn string) (NotificationPreference, error)
+	// Upsert store preference of msisdn, replacing the existing one
+	Upsert(ctx context.Context, preference NotificationPreference) error
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OutboxStatusPending   = 0
+	OutboxStatusPublished = 1
+)
+
+// OutboxEvent is domain event stored in the same transaction as the change producing it, then published by relay
+type OutboxEvent struct {
+	ID int64 `db:"id"`
+	// EventID is dedup id of event, consumer use it to drop event published more than once
+	EventID         string     `db:"event_id"`
+	Event           string     `db:"event"`
+	Payload         string     `db:"payload"`
+	Status          int        `db:"status"`
+	Attempt         int        `db:"attempt"`
+	NextAttemptDate time.Time  `db:"next_attempt_date"`
+	LastError       string     `db:"last_error"`
+	CreatedDate     time.Time  `db:"created_date"`
+	PublishedDate   *time.Time `db:"published_date"`
+}
+
+type OutboxRepository interface {
+	Insert(ctx context.Context, event *OutboxEvent) error
+	// ClaimDue return pending event whose next attempt already passed and postpone it by lease,
+	// so the same event is not published by other instance in the meantime
+	ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]OutboxEvent, error)
+	MarkPublished(ctx context.Context, ID int64) error
+	UpdateAttempt(ctx context.Context, event OutboxEvent) error
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
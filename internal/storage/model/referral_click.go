@@ -0,0 +1,35 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// ReferralClick is a visit of shared referral link
+type ReferralClick struct {
+	ID          int64     `db:"id"`
+	Code        string    `db:"code"`
+	Channel     string    `db:"channel"`
+	UserAgent   string    `db:"user_agent"`
+	CreatedDate time.Time `db:"created_date"`
+}
+
+// ReferralClickFilter filter statistic by click and referral date (yyyy-MM-dd), code is optional
+type ReferralClickFilter struct {
+	Code  string
+	From  string
+	To    string
+	Limit int
+}
+
+type ReferralClickStatistic struct {
+	Code          string `db:"code"`
+	Msisdn        string `db:"msisdn"`
+	TotalClick    int    `db:"total_click"`
+	TotalReferral int    `db:"total_referral"`
+}
+
+type ReferralClickRepository interface {
+	Insert(ctx context.Context, click *ReferralClick) error
+	GetStatistic(ctx context.Context, filter ReferralClickFilter) ([]ReferralClickStatistic, error)
+}
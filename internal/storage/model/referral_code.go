@@ -5,16 +5,40 @@ import (
 	"time"
 )
 
+const (
+	ReferralCodeStatusInactive = 0
+	ReferralCodeStatusActive   = 1
+	ReferralCodeStatusRetired  = 2
+)
+
 type ReferralCode struct {
-	ID          int64     `db:"id"`
-	Msisdn      string    `db:"msisdn"`
-	Code        string    `db:"code"`
-	CreatedDate time.Time `db:"created_date"`
-	Status      int       `db:"status"`
+	ID          int64      `db:"id"`
+	Msisdn      string     `db:"msisdn"`
+	Code        string     `db:"code"`
+	CreatedDate time.Time  `db:"created_date"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+	Status      int        `db:"status"`
+	// CooldownUntil is set when code receive burst of referral, referral is not allowed until it passed
+	CooldownUntil *time.Time `db:"cooldown_until"`
+}
+
+// InCooldown return true when code still in cooldown after burst of referral
+func (c ReferralCode) InCooldown(now time.Time) bool {
+	return c.CooldownUntil != nil && c.CooldownUntil.After(now)
+}
+
+// IsExpired return true when code has expiry time and it already passed
+func (c ReferralCode) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !c.ExpiresAt.After(now)
 }
 
 type ReferralCodeRepository interface {
 	FindByMsisdn(ctx context.Context, msisdn string) (ReferralCode, error)
 	FindByCode(ctx context.Context, code string) (ReferralCode, error)
+	FindAllByMsisdn(ctx context.Context, msisdn string) ([]ReferralCode, error)
 	Insert(ctx context.Context, code *ReferralCode) error
+	UpdateStatus(ctx context.Context, code string, status int) error
+	UpdateExpiry(ctx context.Context, code string, expiresAt *time.Time) error
+	Retire(ctx context.Context, code string, expiresAt time.Time) error
+	UpdateCooldown(ctx context.Context, code string, cooldownUntil time.Time) error
 }
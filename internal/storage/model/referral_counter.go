@@ -0,0 +1,16 @@
+package model
+
+import "context"
+
+// ReferralCounter keep total of referral counted for reward of each referrer per month, it is a fast copy of
+// ReferralHistoryRepository.GetTotalByMsisdnAndMonth and may drift from it
+type ReferralCounter interface {
+	// Get return total of msisdn in month, found is false when msisdn is not counted yet
+	Get(ctx context.Context, msisdn, month string) (total int, found bool, err error)
+	// Set store total of msisdn in month, replacing the counted one
+	Set(ctx context.Context, msisdn, month string, total int) error
+	// Increment add delta to total of msisdn in month when it is already counted, otherwise it is left to be set
+	Increment(ctx context.Context, msisdn, month string, delta int) error
+	// GetAll return total of every counted msisdn in month
+	GetAll(ctx context.Context, month string) (map[string]int, error)
+}
@@ -5,19 +5,143 @@ import (
 	"time"
 )
 
+const (
+	ReferralStatusPending   = 0
+	ReferralStatusConfirmed = 1
+	ReferralStatusReversed  = 2
+	ReferralStatusRewarded  = 3
+	ReferralStatusExpired   = 4
+)
+
+// referralStatusTransition is next status allowed from each status, reversed and expired are final
+var referralStatusTransition = map[int][]int{
+	ReferralStatusPending:   {ReferralStatusConfirmed, ReferralStatusReversed, ReferralStatusExpired},
+	ReferralStatusConfirmed: {ReferralStatusRewarded, ReferralStatusReversed},
+	ReferralStatusRewarded:  {ReferralStatusReversed},
+}
+
 type ReferralHistory struct {
+	ID             int64      `db:"id"`
+	Msisdn         string     `db:"msisdn"`
+	Code           string     `db:"code"`
+	ReferralDate   string     `db:"referral_date"`
+	MsisdnReferee  string     `db:"msisdn_referee"`
+	CampaignID     int64      `db:"campaign_id"`
+	IPAddress      string     `db:"ip_address"`
+	DeviceID       string     `db:"device_id"`
+	Flagged        bool       `db:"flagged"`
+	FlagReason     string     `db:"flag_reason"`
+	Status         int        `db:"status"`
+	ConfirmedDate  *time.Time `db:"confirmed_date"`
+	ReversalReason string     `db:"reversal_reason"`
+	ReversedDate   *time.Time `db:"reversed_date"`
+	CreatedDate    time.Time  `db:"created_date"`
+}
+
+// CanTransitTo check whether referral status is allowed to change to given status
+func (h ReferralHistory) CanTransitTo(status int) bool {
+	for _, v := range referralStatusTransition[h.Status] {
+		if v == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferralVelocityFilter filter referral created since given time, empty field is not filtered
+type ReferralVelocityFilter struct {
+	Code      string
+	IPAddress string
+	DeviceID  string
+	Since     time.Time
+}
+
+// ReferralLevelTotal is total referral made at given level of downline, level 1 is direct referral
+type ReferralLevelTotal struct {
+	Level int `db:"level"`
+	Total int `db:"total"`
+}
+
+// ReferralDownline is referral made within downline of a msisdn, Msisdn is the referrer of MsisdnReferee
+type ReferralDownline struct {
+	Msisdn        string `db:"msisdn"`
+	MsisdnReferee string `db:"msisdn_referee"`
+	ReferralDate  string `db:"referral_date"`
+	Level         int    `db:"level"`
+}
+
+// ReferralExportFilter filter exported referral, empty field is not filtered. From and To use format yyyy-MM-dd
+type ReferralExportFilter struct {
+	Msisdn string
+	Code   string
+	From   string
+	To     string
+}
+
+// ReferralExport is referral enriched with status of its code and reward tier reached by referrer at the time,
+// MonthTotal is total counted referral of referrer in the month up to this referral
+type ReferralExport struct {
 	ID            int64     `db:"id"`
 	Msisdn        string    `db:"msisdn"`
 	Code          string    `db:"code"`
-	ReferralDate  string    `db:"referral_date"`
+	CodeStatus    int       `db:"code_status"`
 	MsisdnReferee string    `db:"msisdn_referee"`
+	ReferralDate  string    `db:"referral_date"`
+	CampaignID    int64     `db:"campaign_id"`
+	Status        int       `db:"status"`
+	Flagged       bool      `db:"flagged"`
+	MonthTotal    int       `db:"month_total"`
+	Reward        string    `db:"reward"`
 	CreatedDate   time.Time `db:"created_date"`
 }
 
+// ReferralRank is position of referrer in monthly leaderboard
+type ReferralRank struct {
+	Rank   int    `db:"rank"`
+	Msisdn string `db:"msisdn"`
+	Total  int    `db:"total"`
+}
+
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+// ReferralStatistic is counted referral within a period, Period is first date of week for week interval
+type ReferralStatistic struct {
+	Period         string `db:"period"`
+	TotalReferral  int    `db:"total_referral"`
+	ActiveReferrer int    `db:"active_referrer"`
+}
+
+// ReferralTierDistribution is total referrer reaching reward tier in a month, RewardID is 0 for referrer not
+// reaching any tier
+type ReferralTierDistribution struct {
+	RewardID      int64  `db:"reward_id"`
+	TotalReferral int    `db:"total_referral"`
+	Reward        string `db:"reward"`
+	TotalReferrer int    `db:"total_referrer"`
+}
+
 type ReferralHistoryRepository interface {
 	FindByMsisdn(ctx context.Context, msisdn string, offset, limit int) (result []ReferralHistory, err error)
 	CountByMsisdn(ctx context.Context, msisdn string) (total int, err error)
 	FindByMsisdnReferee(ctx context.Context, msisdnReferee string) (result ReferralHistory, err error)
 	GetTotalByMsisdnAndMonth(ctx context.Context, msisdn, month string) (total int, err error)
+	GetTotalByMsisdnAndCampaign(ctx context.Context, msisdn string, campaignID int64) (total int, err error)
+	GetTotalPerLevelByMsisdnAndMonth(ctx context.Context, msisdn, month string, maxLevel int) (result []ReferralLevelTotal, err error)
+	FindDownline(ctx context.Context, msisdn string, maxLevel int) (result []ReferralDownline, err error)
+	CountVelocity(ctx context.Context, filter ReferralVelocityFilter) (total int, err error)
+	FindTopReferrer(ctx context.Context, month string, limit int) (result []ReferralRank, err error)
+	GetRankByMsisdnAndMonth(ctx context.Context, msisdn, month string) (result ReferralRank, err error)
+	GetStatistic(ctx context.Context, from, to, interval string) (result []ReferralStatistic, err error)
+	GetTierDistribution(ctx context.Context, from, to string) (result []ReferralTierDistribution, err error)
+	StreamExport(ctx context.Context, filter ReferralExportFilter, fn func(ReferralExport) error) error
 	Insert(ctx context.Context, referral *ReferralHistory) error
+	InsertBatch(ctx context.Context, referrals []ReferralHistory) error
+	Confirm(ctx context.Context, ID int64) error
+	Reverse(ctx context.Context, ID int64, reason string) error
+	MarkRewarded(ctx context.Context, msisdn, month string) (total int64, err error)
+	ExpirePending(ctx context.Context, before time.Time) (total int64, err error)
 }
@@ -9,13 +9,19 @@ type Reward struct {
 	ID            int64     `db:"id"`
 	TotalReferral int       `db:"total_referral"`
 	Description   string    `db:"reward_description"`
+	CampaignID    int64     `db:"campaign_id"`
+	Level         int       `db:"level"`
 	CreatedDate   time.Time `db:"created_date"`
 	UpdatedDate   time.Time `db:"updated_date"`
 	Status        int       `db:"status"`
 }
 
 type RewardRepository interface {
+	FindAll(ctx context.Context) ([]Reward, error)
+	FindByID(ctx context.Context, ID int64) (Reward, error)
 	FindByTotalReferral(ctx context.Context, totalReferral int) (Reward, error)
+	FindByLevelAndTotalReferral(ctx context.Context, level, totalReferral int) (Reward, error)
+	FindByCampaignAndTotalReferral(ctx context.Context, campaignID int64, totalReferral int) (Reward, error)
 	Insert(ctx context.Context, model *Reward) (err error)
 	Update(ctx context.Context, model Reward) (err error)
 	Delete(ctx context.Context, ID int64) (err error)
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	RewardClaimStatusClaimed = 1
+	// RewardClaimStatusClawback is claim whose referral reversed, total referral no longer reach the tier
+	RewardClaimStatusClawback = 2
+)
+
+type RewardClaim struct {
+	ID            int64     `db:"id"`
+	Msisdn        string    `db:"msisdn"`
+	RewardID      int64     `db:"reward_id"`
+	TotalReferral int       `db:"total_referral"`
+	Description   string    `db:"reward_description"`
+	Period        string    `db:"period"`
+	Level         int       `db:"level"`
+	Status        int       `db:"status"`
+	CreatedDate   time.Time `db:"created_date"`
+}
+
+type RewardClaimRepository interface {
+	FindByMsisdn(ctx context.Context, msisdn string) (result []RewardClaim, err error)
+	FindByMsisdnRewardAndPeriod(ctx context.Context, msisdn string, rewardID int64, period string) (result RewardClaim, err error)
+	Insert(ctx context.Context, claim *RewardClaim) error
+	UpdateStatus(ctx context.Context, ID int64, status int) error
+}
@@ -0,0 +1,8 @@
+package model
+
+import "context"
+
+// Transactor run fn in a transaction, repository called with ctx given to fn write within the same transaction
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
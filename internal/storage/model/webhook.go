@@ -0,0 +1,70 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	WebhookSubscriptionStatusInactive = 0
+	WebhookSubscriptionStatusActive   = 1
+)
+
+const (
+	WebhookDeliveryStatusPending   = 0
+	WebhookDeliveryStatusDelivered = 1
+	// WebhookDeliveryStatusDead is delivery failed after max attempt, it is only retried when replayed
+	WebhookDeliveryStatusDead = 2
+)
+
+type WebhookSubscription struct {
+	ID     int64  `db:"id"`
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+	// Events is comma separated event the subscription receive
+	Events      string    `db:"events"`
+	Status      int       `db:"status"`
+	CreatedDate time.Time `db:"created_date"`
+	UpdatedDate time.Time `db:"updated_date"`
+}
+
+// EventList return events of subscription
+func (s WebhookSubscription) EventList() []string {
+	return strings.Split(s.Events, ",")
+}
+
+type WebhookDelivery struct {
+	ID              int64     `db:"id"`
+	SubscriptionID  int64     `db:"subscription_id"`
+	EventID         string    `db:"event_id"`
+	Event           string    `db:"event"`
+	Payload         string    `db:"payload"`
+	Status          int       `db:"status"`
+	Attempt         int       `db:"attempt"`
+	NextAttemptDate time.Time `db:"next_attempt_date"`
+	ResponseCode    int       `db:"response_code"`
+	LastError       string    `db:"last_error"`
+	CreatedDate     time.Time `db:"created_date"`
+	UpdatedDate     time.Time `db:"updated_date"`
+}
+
+type WebhookSubscriptionRepository interface {
+	FindAll(ctx context.Context) ([]WebhookSubscription, error)
+	FindByID(ctx context.Context, ID int64) (WebhookSubscription, error)
+	FindActiveByEvent(ctx context.Context, event string) ([]WebhookSubscription, error)
+	Insert(ctx context.Context, subscription *WebhookSubscription) error
+	UpdateStatus(ctx context.Context, ID int64, status int) error
+}
+
+type WebhookDeliveryRepository interface {
+	Insert(ctx context.Context, delivery *WebhookDelivery) error
+	// ClaimDue return pending delivery whose next attempt already passed and postpone it by lease,
+	// so the same delivery is not sent by other instance in the meantime
+	ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	UpdateResult(ctx context.Context, delivery WebhookDelivery) error
+	FindByStatus(ctx context.Context, status, offset, limit int) ([]WebhookDelivery, error)
+	CountByStatus(ctx context.Context, status int) (int, error)
+	Replay(ctx context.Context, ID int64) error
+	ReplayDead(ctx context.Context, subscriptionID int64) (int64, error)
+}
@@ -0,0 +1,193 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupCampaignRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCampaignRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupCampaignRepository(db)
+	})
+}
+
+func Test_campaignRepository_FindAll(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)campaign*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupCampaignRepository(db)
+		_, err := r.FindAll(context.Background())
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)campaign*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start_date", "end_date", "status"}).
+				AddRow(2, "year end", "2021-12-01", "2021-12-31", 1).
+				AddRow(1, "ramadan", "2021-04-13", "2021-05-12", 0))
+
+		r := SetupCampaignRepository(db)
+		result, err := r.FindAll(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+	})
+}
+
+func Test_campaignRepository_FindByID(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)campaign*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupCampaignRepository(db)
+		_, err := r.FindByID(context.Background(), 1)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)campaign*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start_date", "end_date", "status"}).
+				AddRow(1, "ramadan", "2021-04-13", "2021-05-12", 1))
+
+		r := SetupCampaignRepository(db)
+		result, err := r.FindByID(context.Background(), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, model.Campaign{
+			ID:        1,
+			Name:      "ramadan",
+			StartDate: "2021-04-13",
+			EndDate:   "2021-05-12",
+			Status:    1,
+		}, result)
+	})
+}
+
+func Test_campaignRepository_FindActiveByDate(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)campaign*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupCampaignRepository(db)
+		_, err := r.FindActiveByDate(context.Background(), "2021-04-20")
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)campaign*").
+			WithArgs("2021-04-20").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start_date", "end_date", "status"}).
+				AddRow(1, "ramadan", "2021-04-13", "2021-05-12", 1))
+
+		r := SetupCampaignRepository(db)
+		result, err := r.FindActiveByDate(context.Background(), "2021-04-20")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result))
+	})
+}
+
+func Test_campaignRepository_Insert(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)campaign*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupCampaignRepository(db)
+		err := r.Insert(context.Background(), &model.Campaign{Name: "ramadan", StartDate: "2021-04-13", EndDate: "2021-05-12"})
+		assert.NotNil(t, err)
+	})
+	t.Run("failed insert, id is 0", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)campaign*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(0))
+
+		r := SetupCampaignRepository(db)
+		err := r.Insert(context.Background(), &model.Campaign{Name: "ramadan", StartDate: "2021-04-13", EndDate: "2021-05-12"})
+		assert.NotNil(t, err)
+	})
+	t.Run("success insert data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)campaign*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(3))
+
+		r := SetupCampaignRepository(db)
+		data := &model.Campaign{Name: "ramadan", StartDate: "2021-04-13", EndDate: "2021-05-12"}
+		err := r.Insert(context.Background(), data)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), data.ID)
+	})
+}
+
+func Test_campaignRepository_Update(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)campaign*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupCampaignRepository(db)
+		err := r.Update(context.Background(), model.Campaign{ID: 2, Name: "year end"})
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)campaign*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupCampaignRepository(db)
+		err := r.Update(context.Background(), model.Campaign{ID: 2, EndDate: "2022-01-05"})
+		assert.NotNil(t, err)
+	})
+	t.Run("success update data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)campaign*").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupCampaignRepository(db)
+		err := r.Update(context.Background(), model.Campaign{ID: 2, StartDate: "2021-12-01", EndDate: "2022-01-05"})
+		assert.Nil(t, err)
+	})
+}
+
+func Test_campaignRepository_Delete(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)campaign*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupCampaignRepository(db)
+		err := r.Delete(context.Background(), 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)campaign*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupCampaignRepository(db)
+		err := r.Delete(context.Background(), 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("success delete data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)campaign*").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupCampaignRepository(db)
+		err := r.Delete(context.Background(), 2)
+		assert.Nil(t, err)
+	})
+}
@@ -1,6 +1,8 @@
 package postgresql
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
@@ -49,3 +51,46 @@ func NewDatabase(cfg *config.DatabaseConfig) *Database {
 func (d *Database) SchemaName() string {
 	return d.schema
 }
+
+type txKey struct{}
+
+// executor run query on database or on transaction
+type executor interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// WithinTransaction run fn in a transaction, repository called with ctx given to fn join the transaction.
+// Transaction is committed when fn return nil, otherwise it is rolled back. Nested call join the outer transaction.
+func (d *Database) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := d.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conn return transaction carried by ctx, otherwise the database
+func (d *Database) conn(ctx context.Context) executor {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return d.DB
+}
@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestDatabase_WithinTransaction(t *testing.T) {
+	t.Run("error begin transaction", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectBegin().WillReturnError(context.DeadlineExceeded)
+
+		err := db.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+	t.Run("rollback when fn return error", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery("^INSERT INTO (.+)referral_history*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery("^INSERT INTO (.+)outbox*").
+			WillReturnError(context.DeadlineExceeded)
+		mock.ExpectRollback()
+
+		historyRepository := SetupReferralHistoryRepository(db)
+		outboxRepository := SetupOutboxRepository(db)
+		err := db.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			if err := historyRepository.Insert(ctx, &model.ReferralHistory{Msisdn: "628000001111"}); err != nil {
+				return err
+			}
+			return outboxRepository.Insert(ctx, &model.OutboxEvent{EventID: "a1b2"})
+		})
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("nested call join outer transaction", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery("^INSERT INTO (.+)outbox*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+		mock.ExpectCommit()
+
+		outboxRepository := SetupOutboxRepository(db)
+		err := db.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return db.WithinTransaction(ctx, func(ctx context.Context) error {
+				return outboxRepository.Insert(ctx, &model.OutboxEvent{EventID: "a1b2"})
+			})
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
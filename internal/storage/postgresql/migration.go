@@ -0,0 +1,398 @@
+package postgresql
+
+// Migration is a numbered change of the schema, %s in the sql is replaced with schema of the database.
+// Migration already released must not be changed, add a new one with the next version instead
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// migrations is every migration of the schema ordered by version. Database created by migration/init.sql of any
+// release is adopted, so every migration skips table, column and index which already exists
+var migrations = []Migration{
+	{Version: 1, Name: "init", Up: migrationInitUp, Down: migrationInitDown},
+	{Version: 2, Name: "reward_claim", Up: migrationRewardClaimUp, Down: migrationRewardClaimDown},
+	{Version: 3, Name: "campaign", Up: migrationCampaignUp, Down: migrationCampaignDown},
+	{Version: 4, Name: "referral_code_expiry", Up: migrationCodeExpiryUp, Down: migrationCodeExpiryDown},
+	{Version: 5, Name: "referral_code_retire", Up: migrationCodeRetireUp, Down: migrationCodeRetireDown},
+	{Version: 6, Name: "referral_code_case_insensitive", Up: migrationCodeCaseUp, Down: migrationCodeCaseDown},
+	{Version: 7, Name: "reward_level", Up: migrationRewardLevelUp, Down: migrationRewardLevelDown},
+	{Version: 8, Name: "velocity_rule", Up: migrationVelocityUp, Down: migrationVelocityDown},
+	{Version: 9, Name: "referral_reversal", Up: migrationReversalUp, Down: migrationReversalDown},
+	{Version: 10, Name: "referral_status", Up: migrationStatusUp, Down: migrationStatusDown},
+	{Version: 11, Name: "report_index", Up: migrationReportUp, Down: migrationReportDown},
+	{Version: 12, Name: "referral_click", Up: migrationClickUp, Down: migrationClickDown},
+	{Version: 13, Name: "webhook", Up: migrationWebhookUp, Down: migrationWebhookDown},
+	{Version: 14, Name: "outbox", Up: migrationOutboxUp, Down: migrationOutboxDown},
+	{Version: 15, Name: "notification", Up: migrationNotificationUp, Down: migrationNotificationDown},
+}
+
+// migrationInitUp is the first schema created by migration/init.sql on container creation
+const migrationInitUp = `
+CREATE TABLE IF NOT EXISTS %s.referral_code
+(
+    id SERIAL,
+    msisdn character varying(20) NOT NULL,
+    code character varying(20) NOT NULL,
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    status integer NOT NULL DEFAULT 1,
+    CONSTRAINT referral_code_pkey PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS referral_code_msisdn_idx ON %s.referral_code(msisdn);
+CREATE UNIQUE INDEX IF NOT EXISTS referral_code_idx ON %s.referral_code(code);
+
+
+CREATE TABLE IF NOT EXISTS %s.reward
+(
+    id SERIAL,
+    total_referral integer NOT NULL,
+    reward_description character varying(100) NOT NULL,
+    status integer NOT NULL DEFAULT 1,
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    updated_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT reward_pkey PRIMARY KEY (id)
+);
+-- default reward is only created with the table
+INSERT INTO %s.reward (total_referral, reward_description)
+SELECT v.total_referral, v.reward_description FROM (VALUES
+(1, 'bonus 2 GB'),
+(5, 'bonus 12 GB'),
+(6, 'bonus 20 GB')
+) AS v (total_referral, reward_description) WHERE NOT EXISTS (SELECT 1 FROM %s.reward);
+
+
+CREATE TABLE IF NOT EXISTS %s.referral_history
+(
+    id SERIAL,
+    msisdn character varying(20) NOT NULL,
+    code character varying(20) NOT NULL,
+    msisdn_referee character varying(20) NOT NULL,
+    referral_date character varying(10) NOT NULL,
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT referral_history_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS referral_history_msisdn_idx ON %s.referral_history(msisdn);
+CREATE INDEX IF NOT EXISTS referral_history_code_idx ON %s.referral_history(code);
+CREATE INDEX IF NOT EXISTS referral_history_msisdn_referee_idx ON %s.referral_history(msisdn_referee);
+`
+
+const migrationInitDown = `
+DROP TABLE IF EXISTS %s.referral_history;
+DROP TABLE IF EXISTS %s.reward;
+DROP TABLE IF EXISTS %s.referral_code;
+`
+
+const migrationRewardClaimUp = `
+CREATE TABLE IF NOT EXISTS %s.reward_claim
+(
+    id SERIAL,
+    msisdn character varying(20) NOT NULL,
+    reward_id integer NOT NULL,
+    total_referral integer NOT NULL,
+    reward_description character varying(100) NOT NULL,
+    period character varying(7) NOT NULL,
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT reward_claim_pkey PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS reward_claim_msisdn_reward_period_idx ON %s.reward_claim(msisdn, reward_id, period);
+`
+
+const migrationRewardClaimDown = `
+DROP TABLE IF EXISTS %s.reward_claim;
+`
+
+const migrationCampaignUp = `
+CREATE TABLE IF NOT EXISTS %s.campaign
+(
+    id SERIAL,
+    name character varying(100) NOT NULL,
+    start_date character varying(10) NOT NULL,
+    end_date character varying(10) NOT NULL,
+    status integer NOT NULL DEFAULT 1,
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    updated_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT campaign_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS campaign_date_idx ON %s.campaign(start_date, end_date);
+
+ALTER TABLE %s.reward ADD COLUMN IF NOT EXISTS campaign_id integer;
+ALTER TABLE %s.reward DROP CONSTRAINT IF EXISTS reward_campaign_fkey,
+    ADD CONSTRAINT reward_campaign_fkey FOREIGN KEY (campaign_id) REFERENCES %s.campaign(id);
+
+ALTER TABLE %s.referral_history ADD COLUMN IF NOT EXISTS campaign_id integer;
+CREATE INDEX IF NOT EXISTS referral_history_msisdn_campaign_idx ON %s.referral_history(msisdn, campaign_id);
+`
+
+// migrationCampaignDown removes campaign reward, otherwise it becomes reward of the regular program
+const migrationCampaignDown = `
+DROP INDEX IF EXISTS %s.referral_history_msisdn_campaign_idx;
+ALTER TABLE %s.referral_history DROP COLUMN IF EXISTS campaign_id;
+
+DELETE FROM %s.reward WHERE campaign_id IS NOT NULL;
+ALTER TABLE %s.reward DROP CONSTRAINT IF EXISTS reward_campaign_fkey;
+ALTER TABLE %s.reward DROP COLUMN IF EXISTS campaign_id;
+
+DROP TABLE IF EXISTS %s.campaign;
+`
+
+const migrationCodeExpiryUp = `
+ALTER TABLE %s.referral_code ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone;
+`
+
+const migrationCodeExpiryDown = `
+ALTER TABLE %s.referral_code DROP COLUMN IF EXISTS expires_at;
+`
+
+// migrationCodeRetireUp keeps retired code as history, only one non retired code allowed per msisdn
+const migrationCodeRetireUp = `
+DROP INDEX IF EXISTS %s.referral_code_msisdn_idx;
+CREATE UNIQUE INDEX referral_code_msisdn_idx ON %s.referral_code(msisdn) WHERE status <> 2;
+`
+
+// migrationCodeRetireDown fails while msisdn still has a retired code
+const migrationCodeRetireDown = `
+DROP INDEX IF EXISTS %s.referral_code_msisdn_idx;
+CREATE UNIQUE INDEX referral_code_msisdn_idx ON %s.referral_code(msisdn);
+`
+
+// migrationCodeCaseUp makes code unique regardless of its case, it fails while codes only differ in case
+const migrationCodeCaseUp = `
+DROP INDEX IF EXISTS %s.referral_code_idx;
+CREATE UNIQUE INDEX referral_code_idx ON %s.referral_code(UPPER(code));
+`
+
+const migrationCodeCaseDown = `
+DROP INDEX IF EXISTS %s.referral_code_idx;
+CREATE UNIQUE INDEX referral_code_idx ON %s.referral_code(code);
+`
+
+// migrationRewardLevelUp adds level of downline earning the reward, existing reward is for direct referral
+const migrationRewardLevelUp = `
+ALTER TABLE %s.reward ADD COLUMN IF NOT EXISTS level integer NOT NULL DEFAULT 1;
+-- default level 2 reward is only created when there is none
+INSERT INTO %s.reward (total_referral, reward_description, level)
+SELECT v.total_referral, v.reward_description, v.level FROM (VALUES
+(1, 'bonus 500 MB', 2),
+(5, 'bonus 3 GB', 2)
+) AS v (total_referral, reward_description, level) WHERE NOT EXISTS (SELECT 1 FROM %s.reward WHERE level = 2);
+`
+
+// migrationRewardLevelDown removes reward of indirect downline, otherwise it becomes direct referral reward
+const migrationRewardLevelDown = `
+DELETE FROM %s.reward WHERE level <> 1;
+ALTER TABLE %s.reward DROP COLUMN IF EXISTS level;
+`
+
+const migrationVelocityUp = `
+ALTER TABLE %s.referral_code ADD COLUMN IF NOT EXISTS cooldown_until timestamp with time zone;
+
+ALTER TABLE %s.referral_history
+    ADD COLUMN IF NOT EXISTS ip_address character varying(45),
+    ADD COLUMN IF NOT EXISTS device_id character varying(100),
+    -- flagged by velocity rule, not counted for reward until reviewed
+    ADD COLUMN IF NOT EXISTS flagged boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS flag_reason character varying(50);
+DROP INDEX IF EXISTS %s.referral_history_code_idx;
+CREATE INDEX referral_history_code_idx ON %s.referral_history(code, created_date);
+CREATE INDEX IF NOT EXISTS referral_history_ip_address_idx ON %s.referral_history(ip_address, created_date);
+CREATE INDEX IF NOT EXISTS referral_history_device_id_idx ON %s.referral_history(device_id, created_date);
+`
+
+const migrationVelocityDown = `
+DROP INDEX IF EXISTS %s.referral_history_device_id_idx;
+DROP INDEX IF EXISTS %s.referral_history_ip_address_idx;
+DROP INDEX IF EXISTS %s.referral_history_code_idx;
+CREATE INDEX referral_history_code_idx ON %s.referral_history(code);
+ALTER TABLE %s.referral_history
+    DROP COLUMN IF EXISTS flag_reason,
+    DROP COLUMN IF EXISTS flagged,
+    DROP COLUMN IF EXISTS device_id,
+    DROP COLUMN IF EXISTS ip_address;
+
+ALTER TABLE %s.referral_code DROP COLUMN IF EXISTS cooldown_until;
+`
+
+// migrationReversalUp adds status of referral, existing referral is confirmed
+const migrationReversalUp = `
+ALTER TABLE %s.referral_history
+    -- 1 confirmed, 2 reversed
+    ADD COLUMN IF NOT EXISTS status integer NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS reversal_reason character varying(30),
+    ADD COLUMN IF NOT EXISTS reversed_date timestamp with time zone;
+
+ALTER TABLE %s.reward_claim
+    ADD COLUMN IF NOT EXISTS level integer NOT NULL DEFAULT 1,
+    -- 1 claimed, 2 clawback, referral reversed and tier is no longer reached
+    ADD COLUMN IF NOT EXISTS status integer NOT NULL DEFAULT 1;
+`
+
+const migrationReversalDown = `
+ALTER TABLE %s.reward_claim
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS level;
+
+ALTER TABLE %s.referral_history
+    DROP COLUMN IF EXISTS reversed_date,
+    DROP COLUMN IF EXISTS reversal_reason,
+    DROP COLUMN IF EXISTS status;
+`
+
+// migrationStatusUp makes new referral pending until confirmed,
+// status is 0 pending, 1 confirmed, 2 reversed, 3 rewarded, 4 expired
+const migrationStatusUp = `
+ALTER TABLE %s.referral_history ALTER COLUMN status SET DEFAULT 0;
+ALTER TABLE %s.referral_history ADD COLUMN IF NOT EXISTS confirmed_date timestamp with time zone;
+CREATE INDEX IF NOT EXISTS referral_history_pending_idx ON %s.referral_history(created_date) WHERE status = 0;
+`
+
+const migrationStatusDown = `
+DROP INDEX IF EXISTS %s.referral_history_pending_idx;
+ALTER TABLE %s.referral_history DROP COLUMN IF EXISTS confirmed_date;
+ALTER TABLE %s.referral_history ALTER COLUMN status SET DEFAULT 1;
+`
+
+// migrationReportUp indexes counted referral only, used by report aggregate
+const migrationReportUp = `
+CREATE INDEX IF NOT EXISTS referral_history_report_idx ON %s.referral_history(referral_date, msisdn)
+    WHERE flagged = false AND status IN (1, 3);
+`
+
+const migrationReportDown = `
+DROP INDEX IF EXISTS %s.referral_history_report_idx;
+`
+
+const migrationClickUp = `
+CREATE TABLE IF NOT EXISTS %s.referral_click
+(
+    id SERIAL,
+    code character varying(20) NOT NULL,
+    channel character varying(20),
+    user_agent character varying(255),
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT referral_click_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS referral_click_code_idx ON %s.referral_click(code, created_date);
+CREATE INDEX IF NOT EXISTS referral_click_created_date_idx ON %s.referral_click(created_date);
+`
+
+const migrationClickDown = `
+DROP TABLE IF EXISTS %s.referral_click;
+`
+
+const migrationWebhookUp = `
+CREATE TABLE IF NOT EXISTS %s.webhook_subscription
+(
+    id SERIAL,
+    url character varying(255) NOT NULL,
+    secret character varying(100) NOT NULL,
+    -- comma separated event e.g. referral.created,referral.reversed
+    events character varying(200) NOT NULL,
+    status integer NOT NULL DEFAULT 1,
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    updated_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT webhook_subscription_pkey PRIMARY KEY (id)
+);
+
+
+CREATE TABLE IF NOT EXISTS %s.webhook_delivery
+(
+    id SERIAL,
+    subscription_id integer NOT NULL,
+    -- same event delivered to many subscription share event id, receiver use it to drop duplicate
+    event_id character varying(32) NOT NULL,
+    event character varying(30) NOT NULL,
+    payload text NOT NULL,
+    -- 0 pending, 1 delivered, 2 dead
+    status integer NOT NULL DEFAULT 0,
+    attempt integer NOT NULL DEFAULT 0,
+    next_attempt_date timestamp with time zone DEFAULT now() NOT NULL,
+    response_code integer,
+    last_error character varying(255),
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    updated_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT webhook_delivery_pkey PRIMARY KEY (id),
+    CONSTRAINT webhook_delivery_subscription_fkey FOREIGN KEY (subscription_id) REFERENCES %s.webhook_subscription(id)
+);
+CREATE INDEX IF NOT EXISTS webhook_delivery_pending_idx ON %s.webhook_delivery(next_attempt_date) WHERE status = 0;
+CREATE INDEX IF NOT EXISTS webhook_delivery_status_idx ON %s.webhook_delivery(status, id);
+`
+
+const migrationWebhookDown = `
+DROP TABLE IF EXISTS %s.webhook_delivery;
+DROP TABLE IF EXISTS %s.webhook_subscription;
+`
+
+const migrationOutboxUp = `
+-- relay publish event at least once, same event is queued once per subscription
+ALTER TABLE %s.webhook_delivery DROP CONSTRAINT IF EXISTS webhook_delivery_event_key,
+    ADD CONSTRAINT webhook_delivery_event_key UNIQUE (subscription_id, event_id);
+
+
+CREATE TABLE IF NOT EXISTS %s.outbox
+(
+    id BIGSERIAL,
+    -- dedup id of event, it does not change when event is published again
+    event_id character varying(32) NOT NULL,
+    event character varying(30) NOT NULL,
+    payload text NOT NULL,
+    -- 0 pending, 1 published
+    status integer NOT NULL DEFAULT 0,
+    attempt integer NOT NULL DEFAULT 0,
+    next_attempt_date timestamp with time zone DEFAULT now() NOT NULL,
+    last_error character varying(255),
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    published_date timestamp with time zone,
+    CONSTRAINT outbox_pkey PRIMARY KEY (id),
+    CONSTRAINT outbox_event_key UNIQUE (event_id)
+);
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON %s.outbox(next_attempt_date) WHERE status = 0;
+CREATE INDEX IF NOT EXISTS outbox_published_idx ON %s.outbox(published_date) WHERE status = 1;
+`
+
+const migrationOutboxDown = `
+DROP TABLE IF EXISTS %s.outbox;
+ALTER TABLE %s.webhook_delivery DROP CONSTRAINT IF EXISTS webhook_delivery_event_key;
+`
+
+const migrationNotificationUp = `
+CREATE TABLE IF NOT EXISTS %s.notification_preference
+(
+    msisdn character varying(20) NOT NULL,
+    -- id or en
+    language character varying(2) NOT NULL DEFAULT 'id',
+    opt_out boolean NOT NULL DEFAULT false,
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    updated_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT notification_preference_pkey PRIMARY KEY (msisdn)
+);
+
+
+CREATE TABLE IF NOT EXISTS %s.notification
+(
+    id BIGSERIAL,
+    -- outbox event producing the notification
+    event_id character varying(32) NOT NULL,
+    msisdn character varying(20) NOT NULL,
+    template character varying(30) NOT NULL,
+    message character varying(500) NOT NULL,
+    -- 0 pending, 1 sent, 2 failed, 3 cancelled
+    status integer NOT NULL DEFAULT 0,
+    attempt integer NOT NULL DEFAULT 0,
+    -- pushed to the end of quiet hours
+    next_attempt_date timestamp with time zone DEFAULT now() NOT NULL,
+    last_error character varying(255),
+    created_date timestamp with time zone DEFAULT now() NOT NULL,
+    updated_date timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT notification_pkey PRIMARY KEY (id),
+    -- relay publish event at least once, recipient is notified once per event
+    CONSTRAINT notification_event_key UNIQUE (event_id, msisdn)
+);
+CREATE INDEX IF NOT EXISTS notification_pending_idx ON %s.notification(next_attempt_date) WHERE status = 0;
+`
+
+const migrationNotificationDown = `
+DROP TABLE IF EXISTS %s.notification;
+DROP TABLE IF EXISTS %s.notification_preference;
+`
@@ -0,0 +1,163 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type migrator struct {
+	db         *Database
+	migrations []Migration
+}
+
+func SetupMigrator(db *Database) *migrator {
+	if db == nil {
+		panic("postgresql db is nil")
+	}
+	return &migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// MigrationStatus is migration known by the binary, AppliedDate is nil when it is not applied yet
+type MigrationStatus struct {
+	Version     int        `db:"version"`
+	Name        string     `db:"name"`
+	AppliedDate *time.Time `db:"applied_date"`
+}
+
+const (
+	queryMigrationCreateTable = `CREATE SCHEMA IF NOT EXISTS %s;
+								 CREATE TABLE IF NOT EXISTS %s.schema_migrations (
+									version integer NOT NULL, 
+									name character varying(100) NOT NULL, 
+									applied_date timestamp with time zone DEFAULT now() NOT NULL,
+									CONSTRAINT schema_migrations_pkey PRIMARY KEY (version)
+								 )`
+	// lock is released when transaction ends, other instance migrating at the same time wait for it
+	queryMigrationLock     = "LOCK TABLE %s.schema_migrations IN SHARE ROW EXCLUSIVE MODE"
+	queryMigrationFindAll  = "SELECT version, name, applied_date FROM %s.schema_migrations ORDER BY version"
+	queryMigrationIsExist  = "SELECT EXISTS (SELECT 1 FROM %s.schema_migrations WHERE version = $1)"
+	queryMigrationFindLast = "SELECT version, name, applied_date FROM %s.schema_migrations ORDER BY version DESC LIMIT 1"
+	queryMigrationInsert   = "INSERT INTO %s.schema_migrations (version, name) VALUES ($1, $2)"
+	queryMigrationDelete   = "DELETE FROM %s.schema_migrations WHERE version = $1"
+)
+
+// query replace schema placeholder of q with schema of the database
+func (m migrator) query(q string) string {
+	return strings.ReplaceAll(q, "%s", m.db.SchemaName())
+}
+
+func (m migrator) createTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, m.query(queryMigrationCreateTable))
+	return err
+}
+
+// Up apply every migration not applied yet ordered by version, each migration is applied in its own transaction.
+// Return migration applied before error stop it
+func (m migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	if err = m.createTable(ctx); err != nil {
+		return nil, err
+	}
+	for _, v := range m.migrations {
+		migration := v
+		var done bool
+		err = m.db.WithinTransaction(ctx, func(ctx context.Context) error {
+			if _, err := m.db.conn(ctx).ExecContext(ctx, m.query(queryMigrationLock)); err != nil {
+				return err
+			}
+			if err := m.db.conn(ctx).GetContext(ctx, &done, m.query(queryMigrationIsExist), migration.Version); err != nil || done {
+				return err
+			}
+			if _, err := m.db.conn(ctx).ExecContext(ctx, m.query(migration.Up)); err != nil {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			_, err := m.db.conn(ctx).ExecContext(ctx, m.query(queryMigrationInsert), migration.Version, migration.Name)
+			return err
+		})
+		if err != nil {
+			return applied, err
+		}
+		if !done {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+// Down revert the last applied migration, return util.ErrorDataNotFound when there is none
+func (m migrator) Down(ctx context.Context) (reverted Migration, err error) {
+	if err = m.createTable(ctx); err != nil {
+		return Migration{}, err
+	}
+	err = m.db.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := m.db.conn(ctx).ExecContext(ctx, m.query(queryMigrationLock)); err != nil {
+			return err
+		}
+		var last []MigrationStatus
+		if err := m.db.conn(ctx).SelectContext(ctx, &last, m.query(queryMigrationFindLast)); err != nil {
+			return err
+		}
+		if len(last) == 0 {
+			return util.ErrorDataNotFound
+		}
+		migration, ok := m.find(last[0].Version)
+		if !ok {
+			return fmt.Errorf("migration %d %s is not known by this version", last[0].Version, last[0].Name)
+		}
+		if _, err := m.db.conn(ctx).ExecContext(ctx, m.query(migration.Down)); err != nil {
+			return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := m.db.conn(ctx).ExecContext(ctx, m.query(queryMigrationDelete), migration.Version); err != nil {
+			return err
+		}
+		reverted = migration
+		return nil
+	})
+	if err != nil {
+		return Migration{}, err
+	}
+	return reverted, nil
+}
+
+// Status return every migration ordered by version, applied migration unknown by this version is included as well
+func (m migrator) Status(ctx context.Context) (result []MigrationStatus, err error) {
+	if err = m.createTable(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	var applied []MigrationStatus
+	if err = m.db.SelectContext(ctx, &applied, m.query(queryMigrationFindAll)); err != nil {
+		return nil, err
+	}
+
+	i := 0
+	for _, v := range m.migrations {
+		for i < len(applied) && applied[i].Version < v.Version {
+			result = append(result, applied[i])
+			i++
+		}
+		status := MigrationStatus{Version: v.Version, Name: v.Name}
+		if i < len(applied) && applied[i].Version == v.Version {
+			status.AppliedDate = applied[i].AppliedDate
+			i++
+		}
+		result = append(result, status)
+	}
+	return append(result, applied[i:]...), nil
+}
+
+func (m migrator) find(version int) (Migration, bool) {
+	for _, v := range m.migrations {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return Migration{}, false
+}
@@ -0,0 +1,192 @@
+package postgresql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+var migrationColumns = []string{"version", "name", "applied_date"}
+
+func setupMigratorStub(t *testing.T) (*migrator, sqlmock.Sqlmock) {
+	db, mock := setupStub(t)
+	db.schema = "referral"
+	mock.ExpectExec("^CREATE SCHEMA IF NOT EXISTS referral;(.+)referral.schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	return SetupMigrator(db), mock
+}
+
+func TestSetupMigrator(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupMigrator(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupMigrator(db)
+	})
+}
+
+func Test_migrator_Up(t *testing.T) {
+	t.Run("error create migration table", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^CREATE SCHEMA*").
+			WillReturnError(context.DeadlineExceeded)
+
+		applied, err := SetupMigrator(db).Up(context.Background())
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Empty(t, applied)
+	})
+	t.Run("apply pending migration", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		for _, v := range migrations {
+			mock.ExpectBegin()
+			mock.ExpectExec("^LOCK TABLE referral.schema_migrations*").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			// database created by migration/init.sql already has the first schema
+			mock.ExpectQuery("^SELECT EXISTS (.+)referral.schema_migrations*").
+				WithArgs(v.Version).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(v.Version == 1))
+			if v.Version == 1 {
+				mock.ExpectCommit()
+				continue
+			}
+			mock.ExpectExec(regexp.QuoteMeta(m.query(v.Up))).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("^INSERT INTO referral.schema_migrations*").
+				WithArgs(v.Version, v.Name).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+
+		applied, err := m.Up(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, applied, len(migrations)-1)
+		assert.Equal(t, 2, applied[0].Version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("skip applied migration", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		for _, v := range migrations {
+			mock.ExpectBegin()
+			mock.ExpectExec("^LOCK TABLE*").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("^SELECT EXISTS*").
+				WithArgs(v.Version).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mock.ExpectCommit()
+		}
+
+		applied, err := m.Up(context.Background())
+		assert.Nil(t, err)
+		assert.Empty(t, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("rollback failed migration", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("^LOCK TABLE*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("^SELECT EXISTS*").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS*").
+			WillReturnError(context.DeadlineExceeded)
+		mock.ExpectRollback()
+
+		applied, err := m.Up(context.Background())
+		assert.NotNil(t, err)
+		assert.Empty(t, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func Test_migrator_Down(t *testing.T) {
+	t.Run("nothing applied", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("^LOCK TABLE*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("^SELECT (.+)referral.schema_migrations ORDER BY version DESC LIMIT 1").
+			WillReturnRows(sqlmock.NewRows(migrationColumns))
+		mock.ExpectRollback()
+
+		_, err := m.Down(context.Background())
+		assert.Equal(t, util.ErrorDataNotFound, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("applied migration unknown by this version", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("^LOCK TABLE*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("^SELECT*").
+			WillReturnRows(sqlmock.NewRows(migrationColumns).AddRow(99, "future", time.Now()))
+		mock.ExpectRollback()
+
+		_, err := m.Down(context.Background())
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("revert last migration", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		mock.ExpectBegin()
+		mock.ExpectExec("^LOCK TABLE*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("^SELECT*").
+			WillReturnRows(sqlmock.NewRows(migrationColumns).AddRow(6, "referral_code_case_insensitive", time.Now()))
+		mock.ExpectExec("DROP INDEX IF EXISTS referral.referral_code_idx;(.+)referral.referral_code\\(code\\)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("^DELETE FROM referral.schema_migrations*").
+			WithArgs(6).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		reverted, err := m.Down(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, "referral_code_case_insensitive", reverted.Name)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func Test_migrator_Status(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		mock.ExpectQuery("^SELECT*").
+			WillReturnError(context.DeadlineExceeded)
+
+		_, err := m.Status(context.Background())
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+	t.Run("pending and unknown migration", func(t *testing.T) {
+		m, mock := setupMigratorStub(t)
+		m.migrations = []Migration{{Version: 1, Name: "init"}, {Version: 3, Name: "add_index"}}
+		mock.ExpectQuery("^SELECT (.+)referral.schema_migrations ORDER BY version$").
+			WillReturnRows(sqlmock.NewRows(migrationColumns).
+				AddRow(1, "init", time.Now()).
+				AddRow(2, "removed", time.Now()))
+
+		result, err := m.Status(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, result, 3)
+		assert.NotNil(t, result[0].AppliedDate)
+		assert.Equal(t, "removed", result[1].Name)
+		assert.Equal(t, "add_index", result[2].Name)
+		assert.Nil(t, result[2].AppliedDate)
+	})
+}
+
+func Test_migrations(t *testing.T) {
+	for i, v := range migrations {
+		assert.Equal(t, i+1, v.Version, "migration version must be sequential")
+		assert.NotEmpty(t, v.Name)
+		assert.NotEmpty(t, v.Up)
+		assert.NotEmpty(t, v.Down)
+	}
+}
@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+)
+
+type notificationPreferenceRepository struct {
+	db *Database
+}
+
+func SetupNotificationPreferenceRepository(db *Database) *notificationPreferenceRepository {
+	if db == nil {
+		panic("postgresql db is nil")
+	}
+	return &notificationPreferenceRepository{
+		db: db,
+	}
+}
+
+func (r notificationPreferenceRepository) FindByMsisdn(ctx context.Context, msisdn string) (result model.NotificationPreference, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.GetContext(ctx, &result, query.NotificationPreferenceFindByMsisdn, msisdn)
+	return result, err
+}
+
+func (r notificationPreferenceRepository) Upsert(ctx context.Context, preference model.NotificationPreference) error {
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(query.NotificationPreferenceUpsert, r.db.SchemaName()), preference.Msisdn,
+		preference.Language, preference.OptOut)
+	return err
+}
@@ -0,0 +1,71 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupNotificationPreferenceRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupNotificationPreferenceRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupNotificationPreferenceRepository(db)
+	})
+}
+
+func Test_notificationPreferenceRepository_FindByMsisdn(t *testing.T) {
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)notification_preference*").
+			WithArgs("6280000011").
+			WillReturnError(sql.ErrNoRows)
+
+		r := SetupNotificationPreferenceRepository(db)
+		_, err := r.FindByMsisdn(context.Background(), "6280000011")
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)notification_preference*").
+			WithArgs("6280000011").
+			WillReturnRows(sqlmock.NewRows([]string{"msisdn", "language", "opt_out"}).
+				AddRow("6280000011", "en", true))
+
+		r := SetupNotificationPreferenceRepository(db)
+		result, err := r.FindByMsisdn(context.Background(), "6280000011")
+		assert.Nil(t, err)
+		assert.Equal(t, "en", result.Language)
+		assert.True(t, result.OptOut)
+	})
+}
+
+func Test_notificationPreferenceRepository_Upsert(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^INSERT INTO (.+)notification_preference*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupNotificationPreferenceRepository(db)
+		err := r.Upsert(context.Background(), model.NotificationPreference{Msisdn: "6280000011"})
+		assert.NotNil(t, err)
+	})
+	t.Run("success upsert", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^INSERT INTO (.+)notification_preference (.+)ON CONFLICT (.+)DO UPDATE").
+			WithArgs("6280000011", "en", true).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupNotificationPreferenceRepository(db)
+		err := r.Upsert(context.Background(), model.NotificationPreference{Msisdn: "6280000011", Language: "en", OptOut: true})
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type notificationRepository struct {
+	db *Database
+}
+
+func SetupNotificationRepository(db *Database) *notificationRepository {
+	if db == nil {
+		panic("postgresql db is nil")
+	}
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+const (
+	// SKIP LOCKED let other instance claim the next batch instead of waiting
+	queryNotificationClaimDue = `UPDATE %s.notification SET next_attempt_date = NOW() + $1 * INTERVAL '1 second' 
+								 WHERE id IN (
+									SELECT id FROM %s.notification WHERE status = 0 AND next_attempt_date <= NOW() 
+									ORDER BY next_attempt_date LIMIT $2 FOR UPDATE SKIP LOCKED
+								 ) RETURNING id, event_id, msisdn, template, message, status, attempt, next_attempt_date, 
+								 COALESCE(last_error, '') AS last_error, created_date, updated_date`
+)
+
+func (r notificationRepository) Insert(ctx context.Context, notification *model.Notification) error {
+	err := r.db.GetContext(ctx, &notification.ID, fmt.Sprintf(query.NotificationInsert, r.db.SchemaName()),
+		notification.EventID, notification.Msisdn, notification.Template, notification.Message, notification.NextAttemptDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if notification.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+func (r notificationRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.Notification, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.SelectContext(ctx, &result, fmt.Sprintf(queryNotificationClaimDue, r.db.SchemaName(), r.db.SchemaName()),
+		lease.Seconds(), limit)
+	return result, err
+}
+
+func (r notificationRepository) UpdateResult(ctx context.Context, notification model.Notification) error {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.NotificationUpdateResult, r.db.SchemaName()), notification.Status,
+		notification.Attempt, notification.NextAttemptDate, notification.LastError, notification.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+var notificationColumns = []string{"id", "event_id", "msisdn", "template", "message", "status", "attempt",
+	"next_attempt_date", "last_error"}
+
+func TestSetupNotificationRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupNotificationRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupNotificationRepository(db)
+	})
+}
+
+func Test_notificationRepository_Insert(t *testing.T) {
+	next := time.Now()
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)notification*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupNotificationRepository(db)
+		err := r.Insert(context.Background(), &model.Notification{EventID: "a1b2"})
+		assert.NotNil(t, err)
+	})
+	t.Run("failed insert, id is 0", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)notification*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(0))
+
+		r := SetupNotificationRepository(db)
+		err := r.Insert(context.Background(), &model.Notification{EventID: "a1b2"})
+		assert.NotNil(t, err)
+	})
+	t.Run("msisdn already notified of event", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)notification (.+)ON CONFLICT(.+)DO NOTHING").
+			WithArgs("a1b2", "6280000011", "referral_used", "hello", next).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		r := SetupNotificationRepository(db)
+		err := r.Insert(context.Background(), &model.Notification{EventID: "a1b2", Msisdn: "6280000011",
+			Template: "referral_used", Message: "hello", NextAttemptDate: next})
+		assert.Nil(t, err)
+	})
+	t.Run("success insert data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)notification*").
+			WithArgs("a1b2", "6280000011", "referral_used", "hello", next).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(9))
+
+		r := SetupNotificationRepository(db)
+		data := &model.Notification{EventID: "a1b2", Msisdn: "6280000011", Template: "referral_used", Message: "hello",
+			NextAttemptDate: next}
+		err := r.Insert(context.Background(), data)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(9), data.ID)
+	})
+}
+
+func Test_notificationRepository_ClaimDue(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^UPDATE (.+)notification*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupNotificationRepository(db)
+		_, err := r.ClaimDue(context.Background(), time.Minute, 50)
+		assert.NotNil(t, err)
+	})
+	t.Run("claim due notification", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^UPDATE (.+)notification (.+)FOR UPDATE SKIP LOCKED(.+)").
+			WithArgs(float64(60), 50).
+			WillReturnRows(sqlmock.NewRows(notificationColumns).
+				AddRow(9, "a1b2", "6280000011", "referral_used", "hello", 0, 1, time.Now(), "unexpected status 500"))
+
+		r := SetupNotificationRepository(db)
+		result, err := r.ClaimDue(context.Background(), time.Minute, 50)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result))
+		assert.Equal(t, "6280000011", result[0].Msisdn)
+	})
+}
+
+func Test_notificationRepository_UpdateResult(t *testing.T) {
+	next := time.Now()
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)notification*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupNotificationRepository(db)
+		err := r.UpdateResult(context.Background(), model.Notification{ID: 9})
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)notification*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupNotificationRepository(db)
+		err := r.UpdateResult(context.Background(), model.Notification{ID: 9})
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success update", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)notification*").
+			WithArgs(model.NotificationStatusFailed, 5, next, "unexpected status 503", 9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupNotificationRepository(db)
+		err := r.UpdateResult(context.Background(), model.Notification{ID: 9, Status: model.NotificationStatusFailed,
+			Attempt: 5, NextAttemptDate: next, LastError: "unexpected status 503"})
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,98 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type outboxRepository struct {
+	db *Database
+}
+
+func SetupOutboxRepository(db *Database) *outboxRepository {
+	if db == nil {
+		panic("postgresql db is nil")
+	}
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+const (
+	// SKIP LOCKED let other instance claim the next batch instead of waiting
+	queryOutboxClaimDue = `UPDATE %s.outbox SET next_attempt_date = NOW() + $1 * INTERVAL '1 second' 
+						   WHERE id IN (
+						   	SELECT id FROM %s.outbox WHERE status = 0 AND next_attempt_date <= NOW() 
+						   	ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED
+						   ) RETURNING id, event_id, event, payload, status, attempt, next_attempt_date, 
+						   COALESCE(last_error, '') AS last_error, created_date`
+)
+
+// Insert store event, it joins transaction of ctx so event is stored only when the change producing it is committed
+func (r outboxRepository) Insert(ctx context.Context, event *model.OutboxEvent) error {
+	err := r.db.conn(ctx).GetContext(ctx, &event.ID, fmt.Sprintf(query.OutboxInsert, r.db.SchemaName()),
+		event.EventID, event.Event, event.Payload)
+	if err != nil {
+		return err
+	}
+	if event.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+func (r outboxRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.OutboxEvent, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.SelectContext(ctx, &result, fmt.Sprintf(queryOutboxClaimDue, r.db.SchemaName(), r.db.SchemaName()),
+		lease.Seconds(), limit)
+	return result, err
+}
+
+func (r outboxRepository) MarkPublished(ctx context.Context, ID int64) error {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.OutboxMarkPublished, r.db.SchemaName()), ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// UpdateAttempt store failed attempt of pending event and when it is published again
+func (r outboxRepository) UpdateAttempt(ctx context.Context, event model.OutboxEvent) error {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.OutboxUpdateAttempt, r.db.SchemaName()), event.Attempt,
+		event.NextAttemptDate, event.LastError, event.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// DeletePublished remove event published before the time, pending event is kept
+func (r outboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.OutboxDeletePublished, r.db.SchemaName()), before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
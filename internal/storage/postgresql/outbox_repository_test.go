@@ -0,0 +1,180 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupOutboxRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupOutboxRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupOutboxRepository(db)
+	})
+}
+
+func Test_outboxRepository_Insert(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)outbox*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupOutboxRepository(db)
+		err := r.Insert(context.Background(), &model.OutboxEvent{EventID: "a1b2"})
+		assert.NotNil(t, err)
+	})
+	t.Run("failed insert, id is 0", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)outbox*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(0))
+
+		r := SetupOutboxRepository(db)
+		err := r.Insert(context.Background(), &model.OutboxEvent{EventID: "a1b2"})
+		assert.Equal(t, util.ErrorDatabase, err)
+	})
+	t.Run("success insert data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)outbox*").
+			WithArgs("a1b2", "referral.created", "{}").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(9))
+
+		r := SetupOutboxRepository(db)
+		data := &model.OutboxEvent{EventID: "a1b2", Event: "referral.created", Payload: "{}"}
+		err := r.Insert(context.Background(), data)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(9), data.ID)
+	})
+	t.Run("insert within transaction", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery("^INSERT INTO (.+)outbox*").
+			WithArgs("a1b2", "referral.created", "{}").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(9))
+		mock.ExpectCommit()
+
+		r := SetupOutboxRepository(db)
+		err := db.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return r.Insert(ctx, &model.OutboxEvent{EventID: "a1b2", Event: "referral.created", Payload: "{}"})
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func Test_outboxRepository_ClaimDue(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^UPDATE (.+)outbox*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupOutboxRepository(db)
+		_, err := r.ClaimDue(context.Background(), time.Minute, 100)
+		assert.NotNil(t, err)
+	})
+	t.Run("claim due event", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^UPDATE (.+)outbox (.+)FOR UPDATE SKIP LOCKED(.+)").
+			WithArgs(float64(60), 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "event", "payload", "status", "attempt",
+				"next_attempt_date", "last_error", "created_date"}).
+				AddRow(9, "a1b2", "referral.created", "{}", 0, 1, time.Now(), "connection refused", time.Now()))
+
+		r := SetupOutboxRepository(db)
+		result, err := r.ClaimDue(context.Background(), time.Minute, 100)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result))
+		assert.Equal(t, "a1b2", result[0].EventID)
+	})
+}
+
+func Test_outboxRepository_MarkPublished(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)outbox*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupOutboxRepository(db)
+		err := r.MarkPublished(context.Background(), 9)
+		assert.NotNil(t, err)
+	})
+	t.Run("event already published", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)outbox*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupOutboxRepository(db)
+		err := r.MarkPublished(context.Background(), 9)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success update", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)outbox SET status = 1").
+			WithArgs(9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupOutboxRepository(db)
+		err := r.MarkPublished(context.Background(), 9)
+		assert.Nil(t, err)
+	})
+}
+
+func Test_outboxRepository_UpdateAttempt(t *testing.T) {
+	next := time.Now()
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)outbox*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupOutboxRepository(db)
+		err := r.UpdateAttempt(context.Background(), model.OutboxEvent{ID: 9})
+		assert.NotNil(t, err)
+	})
+	t.Run("success update", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)outbox*").
+			WithArgs(2, next, "connection refused", 9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupOutboxRepository(db)
+		err := r.UpdateAttempt(context.Background(), model.OutboxEvent{ID: 9, Attempt: 2, NextAttemptDate: next,
+			LastError: "connection refused"})
+		assert.Nil(t, err)
+	})
+}
+
+func Test_outboxRepository_DeletePublished(t *testing.T) {
+	before := time.Now()
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^DELETE FROM (.+)outbox*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupOutboxRepository(db)
+		_, err := r.DeletePublished(context.Background(), before)
+		assert.NotNil(t, err)
+	})
+	t.Run("success delete", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^DELETE FROM (.+)outbox WHERE status = 1*").
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		r := SetupOutboxRepository(db)
+		total, err := r.DeletePublished(context.Background(), before)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), total)
+	})
+}
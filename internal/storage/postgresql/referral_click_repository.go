@@ -0,0 +1,67 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type referralClickRepository struct {
+	db *Database
+}
+
+func SetupReferralClickRepository(db *Database) *referralClickRepository {
+	if db == nil {
+		panic("postgresql db is nil")
+	}
+	return &referralClickRepository{
+		db: db,
+	}
+}
+
+const (
+	// referral is counted the same way as reward, code has neither click nor referral in the range is not listed
+	queryClickStatistic = `WITH click AS (
+							   SELECT code, COUNT(id) AS total FROM referral_click 
+							   WHERE created_date >= $1::date AND created_date < $2::date + 1%s GROUP BY code
+						   ), referral AS (
+							   SELECT code, COUNT(id) AS total FROM referral_history 
+							   WHERE referral_date BETWEEN $3 AND $4 AND flagged = false AND status IN (1, 3)%s GROUP BY code
+						   )
+						   SELECT COALESCE(c.code, h.code) AS code, COALESCE(rc.msisdn, '') AS msisdn, 
+						   COALESCE(c.total, 0) AS total_click, COALESCE(h.total, 0) AS total_referral 
+						   FROM click c FULL JOIN referral h ON h.code = c.code
+						   LEFT JOIN referral_code rc ON UPPER(rc.code) = UPPER(COALESCE(c.code, h.code))
+						   ORDER BY 3 DESC, 4 DESC, 1 LIMIT $5`
+)
+
+func (r referralClickRepository) Insert(ctx context.Context, click *model.ReferralClick) error {
+	err := r.db.GetContext(ctx, &click.ID, fmt.Sprintf(query.ClickInsert, r.db.SchemaName()), click.Code, click.Channel,
+		click.UserAgent)
+	if err != nil {
+		return err
+	}
+	if click.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+// GetStatistic return click and counted referral of each code between filter.From and filter.To, most clicked first
+func (r referralClickRepository) GetStatistic(ctx context.Context, filter model.ReferralClickFilter) (result []model.ReferralClickStatistic, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var condition string
+	args := []interface{}{filter.From, filter.To, filter.From, filter.To, filter.Limit}
+	if filter.Code != "" {
+		args = append(args, filter.Code)
+		condition = fmt.Sprintf(" AND UPPER(code) = UPPER($%d)", len(args))
+	}
+	err = r.db.SelectContext(ctx, &result, fmt.Sprintf(queryClickStatistic, condition, condition), args...)
+	return result, err
+}
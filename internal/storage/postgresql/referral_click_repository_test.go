@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupReferralClickRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReferralClickRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupReferralClickRepository(db)
+	})
+}
+
+func Test_referralClickRepository_Insert(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)referral_click*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralClickRepository(db)
+		err := r.Insert(context.Background(), &model.ReferralClick{Code: "4C7AE71563", Channel: "whatsapp"})
+		assert.NotNil(t, err)
+	})
+	t.Run("failed insert, id is 0", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)referral_click*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(0))
+
+		r := SetupReferralClickRepository(db)
+		err := r.Insert(context.Background(), &model.ReferralClick{Code: "4C7AE71563", Channel: "whatsapp"})
+		assert.NotNil(t, err)
+	})
+	t.Run("success insert data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)referral_click*").
+			WithArgs("4C7AE71563", "whatsapp", "Mozilla/5.0").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(7))
+
+		r := SetupReferralClickRepository(db)
+		data := &model.ReferralClick{Code: "4C7AE71563", Channel: "whatsapp", UserAgent: "Mozilla/5.0"}
+		err := r.Insert(context.Background(), data)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(7), data.ID)
+	})
+}
+
+func Test_referralClickRepository_GetStatistic(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH click AS (.+)").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralClickRepository(db)
+		_, err := r.GetStatistic(context.Background(), model.ReferralClickFilter{From: "2021-08-01", To: "2021-08-31", Limit: 10})
+		assert.NotNil(t, err)
+	})
+	t.Run("filter by code", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery(`^WITH click AS (.+)UPPER\(code\) = UPPER\(\$6\)(.+)UPPER\(code\) = UPPER\(\$6\)(.+)`).
+			WithArgs("2021-08-01", "2021-08-31", "2021-08-01", "2021-08-31", 10, "4c7ae71563").
+			WillReturnRows(sqlmock.NewRows([]string{"code", "msisdn", "total_click", "total_referral"}).
+				AddRow("4C7AE71563", "6280000011", 40, 3))
+
+		r := SetupReferralClickRepository(db)
+		result, err := r.GetStatistic(context.Background(), model.ReferralClickFilter{Code: "4c7ae71563",
+			From: "2021-08-01", To: "2021-08-31", Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralClickStatistic{
+			{Code: "4C7AE71563", Msisdn: "6280000011", TotalClick: 40, TotalReferral: 3},
+		}, result)
+	})
+}
@@ -2,10 +2,14 @@ package postgresql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
 	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
@@ -23,15 +27,17 @@ func SetupReferralCodeRepository(db *Database) *referralCodeRepository {
 }
 
 const (
-	queryReferralCodeFindByMsisdn = "SELECT id, msisdn, code, status FROM referral_code WHERE msisdn = $1"
-	queryReferralCodeFindByCode   = "SELECT id, msisdn, code, status FROM referral_code WHERE code = $1"
-	queryReferralCodeInsert       = "INSERT INTO %s.referral_code (msisdn, code) VALUES ($1, $2) RETURNING id"
+	queryReferralCodeRetire = `UPDATE %s.referral_code SET status = 2, expires_at = LEAST(expires_at, $1) 
+									 WHERE UPPER(code) = UPPER($2) AND status <> 2`
+
+	// unique index of code, case insensitive
+	referralCodeUniqueIndex = "referral_code_idx"
 )
 
 func (r referralCodeRepository) FindByMsisdn(ctx context.Context, msisdn string) (result model.ReferralCode, err error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
-	err = r.db.GetContext(ctx, &result, queryReferralCodeFindByMsisdn, msisdn)
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.ReferralCodeFindByMsisdn, msisdn)
 	if err != nil {
 		return model.ReferralCode{}, err
 	}
@@ -44,7 +50,7 @@ func (r referralCodeRepository) FindByMsisdn(ctx context.Context, msisdn string)
 func (r referralCodeRepository) FindByCode(ctx context.Context, code string) (result model.ReferralCode, err error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
-	err = r.db.GetContext(ctx, &result, queryReferralCodeFindByCode, code)
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.ReferralCodeFindByCode, code)
 	if err != nil {
 		return model.ReferralCode{}, err
 	}
@@ -54,8 +60,19 @@ func (r referralCodeRepository) FindByCode(ctx context.Context, code string) (re
 	return result, nil
 }
 
+func (r referralCodeRepository) FindAllByMsisdn(ctx context.Context, msisdn string) (result []model.ReferralCode, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.ReferralCodeFindAll, msisdn)
+	return result, err
+}
+
 func (r referralCodeRepository) Insert(ctx context.Context, code *model.ReferralCode) error {
-	err := r.db.GetContext(ctx, &code.ID, fmt.Sprintf(queryReferralCodeInsert, r.db.SchemaName()), code.Msisdn, code.Code)
+	err := r.db.conn(ctx).GetContext(ctx, &code.ID, fmt.Sprintf(query.ReferralCodeInsert, r.db.SchemaName()), code.Msisdn, code.Code)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation && pqErr.Constraint == referralCodeUniqueIndex {
+		return util.ErrorReferralCodeUnavailable
+	}
 	if err != nil {
 		return err
 	}
@@ -64,3 +81,66 @@ func (r referralCodeRepository) Insert(ctx context.Context, code *model.Referral
 	}
 	return nil
 }
+
+// UpdateStatus change status of code, retired code is never changed
+func (r referralCodeRepository) UpdateStatus(ctx context.Context, code string, status int) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.ReferralCodeUpdateStatus, r.db.SchemaName()), status, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r referralCodeRepository) UpdateExpiry(ctx context.Context, code string, expiresAt *time.Time) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.ReferralCodeUpdateExpiry, r.db.SchemaName()), expiresAt, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// Retire mark code as replaced by newer code, code still resolvable until expiresAt
+// unless it already has earlier expiry
+func (r referralCodeRepository) Retire(ctx context.Context, code string, expiresAt time.Time) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(queryReferralCodeRetire, r.db.SchemaName()), expiresAt, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r referralCodeRepository) UpdateCooldown(ctx context.Context, code string, cooldownUntil time.Time) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.ReferralCodeUpdateCooldown, r.db.SchemaName()), cooldownUntil, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
@@ -3,11 +3,14 @@ package postgresql
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
 func TestSetupReferralCodeRepository(t *testing.T) {
@@ -97,6 +100,30 @@ func Test_referralCodeRepository_FindByMsisdn(t *testing.T) {
 	})
 }
 
+func Test_referralCodeRepository_FindAllByMsisdn(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)referral_code*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralCodeRepository(db)
+		_, err := r.FindAllByMsisdn(context.Background(), "082100000")
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)referral_code*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "msisdn", "code", "status"}).
+				AddRow(5, "082100000", "F00D12AB34", 1).
+				AddRow(3, "082100000", "ABS123AD12", 2))
+
+		r := SetupReferralCodeRepository(db)
+		result, err := r.FindAllByMsisdn(context.Background(), "082100000")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+	})
+}
+
 func Test_referralCodeRepository_Insert(t *testing.T) {
 	t.Run("error context deadline exceed", func(t *testing.T) {
 		db, mock := setupStub(t)
@@ -107,6 +134,15 @@ func Test_referralCodeRepository_Insert(t *testing.T) {
 		err := r.Insert(context.Background(), &model.ReferralCode{Msisdn: "082100000", Code: "ABS123AD12"})
 		assert.NotNil(t, err)
 	})
+	t.Run("code already used", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)referral_code*").
+			WillReturnError(&pq.Error{Code: pqUniqueViolation, Constraint: referralCodeUniqueIndex})
+
+		r := SetupReferralCodeRepository(db)
+		err := r.Insert(context.Background(), &model.ReferralCode{Msisdn: "082100000", Code: "BUDI2021"})
+		assert.Equal(t, util.ErrorReferralCodeUnavailable, err)
+	})
 	t.Run("failed insert, id is 0", func(t *testing.T) {
 		db, mock := setupStub(t)
 		mock.ExpectQuery("^INSERT INTO (.+)referral_code*").
@@ -130,3 +166,128 @@ func Test_referralCodeRepository_Insert(t *testing.T) {
 		assert.Equal(t, int64(11), data.ID)
 	})
 }
+
+func Test_referralCodeRepository_UpdateStatus(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateStatus(context.Background(), "ABS123AD12", model.ReferralCodeStatusInactive)
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateStatus(context.Background(), "ABS123AD12", model.ReferralCodeStatusInactive)
+		assert.NotNil(t, err)
+	})
+	t.Run("success update status", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code SET status = (.+) AND status <> 2").
+			WithArgs(model.ReferralCodeStatusInactive, "ABS123AD12").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateStatus(context.Background(), "ABS123AD12", model.ReferralCodeStatusInactive)
+		assert.Nil(t, err)
+	})
+}
+
+func Test_referralCodeRepository_UpdateExpiry(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateExpiry(context.Background(), "ABS123AD12", nil)
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateExpiry(context.Background(), "ABS123AD12", nil)
+		assert.NotNil(t, err)
+	})
+	t.Run("success update expiry", func(t *testing.T) {
+		db, mock := setupStub(t)
+		expiresAt := time.Date(2021, 8, 31, 0, 0, 0, 0, time.UTC)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WithArgs(&expiresAt, "ABS123AD12").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateExpiry(context.Background(), "ABS123AD12", &expiresAt)
+		assert.Nil(t, err)
+	})
+}
+
+func Test_referralCodeRepository_Retire(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralCodeRepository(db)
+		err := r.Retire(context.Background(), "ABS123AD12", time.Now())
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found or already retired", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.Retire(context.Background(), "ABS123AD12", time.Now())
+		assert.NotNil(t, err)
+	})
+	t.Run("success retire code", func(t *testing.T) {
+		db, mock := setupStub(t)
+		expiresAt := time.Date(2021, 8, 31, 0, 0, 0, 0, time.UTC)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WithArgs(expiresAt, "ABS123AD12").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.Retire(context.Background(), "ABS123AD12", expiresAt)
+		assert.Nil(t, err)
+	})
+}
+
+func Test_referralCodeRepository_UpdateCooldown(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateCooldown(context.Background(), "ABS123AD12", time.Now())
+		assert.NotNil(t, err)
+	})
+	t.Run("code not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateCooldown(context.Background(), "ABS123AD12", time.Now())
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success update cooldown", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_code*").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupReferralCodeRepository(db)
+		err := r.UpdateCooldown(context.Background(), "ABS123AD12", time.Now())
+		assert.Nil(t, err)
+	})
+}
@@ -2,12 +2,15 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
 func TestSetupReferralHistoryRepository(t *testing.T) {
@@ -120,6 +123,30 @@ func Test_referralHistoryRepository_GetTotalByMsisdnAndMonth(t *testing.T) {
 	})
 }
 
+func Test_referralHistoryRepository_GetTotalByMsisdnAndCampaign(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT COUNT\\(id\\) FROM referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.GetTotalByMsisdnAndCampaign(context.Background(), "082100000", 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT COUNT\\(id\\) FROM referral_history*").
+			WithArgs("082100000", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).
+				AddRow(4))
+
+		r := SetupReferralHistoryRepository(db)
+		result, err := r.GetTotalByMsisdnAndCampaign(context.Background(), "082100000", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, result)
+	})
+}
+
 func Test_referralHistoryRepository_Insert(t *testing.T) {
 	t.Run("error context deadline exceed", func(t *testing.T) {
 		db, mock := setupStub(t)
@@ -153,3 +180,410 @@ func Test_referralHistoryRepository_Insert(t *testing.T) {
 		assert.Equal(t, int64(11), data.ID)
 	})
 }
+
+func Test_referralHistoryRepository_GetTotalPerLevelByMsisdnAndMonth(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH RECURSIVE downline (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.GetTotalPerLevelByMsisdnAndMonth(context.Background(), "082100000", "2021-08", 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH RECURSIVE downline (.+)referral_history*").
+			WithArgs("082100000", 2, "2021-08%").
+			WillReturnRows(sqlmock.NewRows([]string{"level", "total"}).
+				AddRow(1, 4).AddRow(2, 9))
+
+		r := SetupReferralHistoryRepository(db)
+		result, err := r.GetTotalPerLevelByMsisdnAndMonth(context.Background(), "082100000", "2021-08", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralLevelTotal{{Level: 1, Total: 4}, {Level: 2, Total: 9}}, result)
+	})
+}
+
+func Test_referralHistoryRepository_FindDownline(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH RECURSIVE downline (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.FindDownline(context.Background(), "082100000", 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH RECURSIVE downline (.+)referral_history*").
+			WithArgs("082100000", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"msisdn", "msisdn_referee", "referral_date", "level"}).
+				AddRow("082100000", "082100001", "2021-08-01", 1).
+				AddRow("082100001", "082100002", "2021-08-03", 2))
+
+		r := SetupReferralHistoryRepository(db)
+		result, err := r.FindDownline(context.Background(), "082100000", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, 2, result[1].Level)
+	})
+}
+
+func Test_referralHistoryRepository_CountVelocity(t *testing.T) {
+	since := time.Now().Add(-time.Hour)
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT COUNT\\(id\\) FROM referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.CountVelocity(context.Background(), model.ReferralVelocityFilter{Code: "ABS123AD12", Since: since})
+		assert.NotNil(t, err)
+	})
+	t.Run("filter by code", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT COUNT\\(id\\) FROM referral_history WHERE created_date >= \\$1 AND code = \\$2$").
+			WithArgs(since, "ABS123AD12").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+		r := SetupReferralHistoryRepository(db)
+		total, err := r.CountVelocity(context.Background(), model.ReferralVelocityFilter{Code: "ABS123AD12", Since: since})
+		assert.Nil(t, err)
+		assert.Equal(t, 7, total)
+	})
+	t.Run("filter by ip address and device", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT COUNT\\(id\\) FROM referral_history WHERE created_date >= \\$1 AND ip_address = \\$2 AND device_id = \\$3$").
+			WithArgs(since, "10.1.2.3", "device-1").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		r := SetupReferralHistoryRepository(db)
+		total, err := r.CountVelocity(context.Background(), model.ReferralVelocityFilter{IPAddress: "10.1.2.3",
+			DeviceID: "device-1", Since: since})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, total)
+	})
+}
+
+func Test_referralHistoryRepository_Reverse(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.Reverse(context.Background(), 3, "churn")
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found or already reversed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.Reverse(context.Background(), 3, "churn")
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success reverse referral", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WithArgs("fraud", int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.Reverse(context.Background(), 3, "fraud")
+		assert.Nil(t, err)
+	})
+}
+
+func Test_referralHistoryRepository_Confirm(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.Confirm(context.Background(), 3)
+		assert.NotNil(t, err)
+	})
+	t.Run("referral is not pending", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.Confirm(context.Background(), 3)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success confirm referral", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WithArgs(int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.Confirm(context.Background(), 3)
+		assert.Nil(t, err)
+	})
+}
+
+func Test_referralHistoryRepository_MarkRewarded(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.MarkRewarded(context.Background(), "62821000000", "2021-08")
+		assert.NotNil(t, err)
+	})
+	t.Run("success mark rewarded", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WithArgs("62821000000", "2021-08%").
+			WillReturnResult(sqlmock.NewResult(0, 5))
+
+		r := SetupReferralHistoryRepository(db)
+		total, err := r.MarkRewarded(context.Background(), "62821000000", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), total)
+	})
+}
+
+func Test_referralHistoryRepository_ExpirePending(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.ExpirePending(context.Background(), time.Now())
+		assert.NotNil(t, err)
+	})
+	t.Run("success expire pending", func(t *testing.T) {
+		before := time.Now().Add(-72 * time.Hour)
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)referral_history*").
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		r := SetupReferralHistoryRepository(db)
+		total, err := r.ExpirePending(context.Background(), before)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), total)
+	})
+}
+
+func Test_referralHistoryRepository_InsertBatch(t *testing.T) {
+	confirmedDate := time.Date(2021, 8, 12, 0, 0, 0, 0, time.UTC)
+	referrals := []model.ReferralHistory{
+		{Msisdn: "62821000000", Code: "ABCABC123A", ReferralDate: "2021-08-12", MsisdnReferee: "62821000001",
+			Status: model.ReferralStatusConfirmed, ConfirmedDate: &confirmedDate, CreatedDate: confirmedDate},
+		{Msisdn: "62821000000", Code: "ABCABC123A", ReferralDate: "2021-08-12", MsisdnReferee: "62821000002",
+			CampaignID: 3, Status: model.ReferralStatusConfirmed, ConfirmedDate: &confirmedDate, CreatedDate: confirmedDate},
+	}
+	t.Run("error begin transaction", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectBegin().WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.InsertBatch(context.Background(), referrals)
+		assert.NotNil(t, err)
+	})
+	t.Run("error copy row, rollback", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectBegin()
+		mock.ExpectPrepare("^COPY (.+)referral_history*")
+		mock.ExpectExec("^COPY (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+		mock.ExpectRollback()
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.InsertBatch(context.Background(), referrals)
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("success insert batch", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectBegin()
+		mock.ExpectPrepare("^COPY (.+)referral_history*")
+		mock.ExpectExec("^COPY (.+)referral_history*").
+			WithArgs("62821000000", "ABCABC123A", "2021-08-12", "62821000001", nil, model.ReferralStatusConfirmed,
+				&confirmedDate, confirmedDate).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("^COPY (.+)referral_history*").
+			WithArgs("62821000000", "ABCABC123A", "2021-08-12", "62821000002", int64(3), model.ReferralStatusConfirmed,
+				&confirmedDate, confirmedDate).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("^COPY (.+)referral_history*").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.InsertBatch(context.Background(), referrals)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func Test_referralHistoryRepository_StreamExport(t *testing.T) {
+	columns := []string{"id", "msisdn", "code", "code_status", "msisdn_referee", "referral_date", "campaign_id",
+		"status", "flagged", "month_total", "reward", "created_date"}
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH history (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		err := r.StreamExport(context.Background(), model.ReferralExportFilter{}, func(model.ReferralExport) error {
+			return nil
+		})
+		assert.NotNil(t, err)
+	})
+	t.Run("error returned by fn stop the export", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH history (.+)referral_history*").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "62821000000", "ABCABC123A", 1, "62821000001", "2021-08-12", 0, 1, false, 1, "bonus 2 GB", time.Now()).
+				AddRow(2, "62821000000", "ABCABC123A", 1, "62821000002", "2021-08-13", 0, 1, false, 2, "bonus 2 GB", time.Now()))
+
+		count := 0
+		r := SetupReferralHistoryRepository(db)
+		err := r.StreamExport(context.Background(), model.ReferralExportFilter{}, func(model.ReferralExport) error {
+			count++
+			return context.Canceled
+		})
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 1, count)
+	})
+	t.Run("success stream filtered referral", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH history (.+)referral_history*").
+			WithArgs("2021-08", "2021-08-10", "62821000000", "2021-08-31", "abcabc123a").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "62821000000", "ABCABC123A", 1, "62821000001", "2021-08-12", 0, 1, false, 1, "bonus 2 GB", time.Now()).
+				AddRow(2, "62821000000", "ABCABC123A", 1, "62821000002", "2021-08-13", 3, 0, false, 1, "bonus 2 GB", time.Now()))
+
+		var result []model.ReferralExport
+		r := SetupReferralHistoryRepository(db)
+		err := r.StreamExport(context.Background(), model.ReferralExportFilter{Msisdn: "62821000000", Code: "abcabc123a",
+			From: "2021-08-10", To: "2021-08-31"}, func(row model.ReferralExport) error {
+			result = append(result, row)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, int64(3), result[1].CampaignID)
+		assert.Equal(t, "bonus 2 GB", result[0].Reward)
+	})
+}
+
+func Test_referralHistoryRepository_FindTopReferrer(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH ranking (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.FindTopReferrer(context.Background(), "2021-08", 10)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH ranking (.+)referral_history*").
+			WithArgs("2021-08%", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"rank", "msisdn", "total"}).
+				AddRow(1, "62821000000", 5).
+				AddRow(2, "62821000009", 5))
+
+		r := SetupReferralHistoryRepository(db)
+		result, err := r.FindTopReferrer(context.Background(), "2021-08", 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, "62821000009", result[1].Msisdn)
+	})
+}
+
+func Test_referralHistoryRepository_GetRankByMsisdnAndMonth(t *testing.T) {
+	t.Run("not ranked", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH ranking (.+)referral_history*").
+			WillReturnError(sql.ErrNoRows)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.GetRankByMsisdnAndMonth(context.Background(), "62821000000", "2021-08")
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH ranking (.+)referral_history*").
+			WithArgs("2021-08%", "62821000000").
+			WillReturnRows(sqlmock.NewRows([]string{"rank", "msisdn", "total"}).
+				AddRow(7, "62821000000", 2))
+
+		r := SetupReferralHistoryRepository(db)
+		result, err := r.GetRankByMsisdnAndMonth(context.Background(), "62821000000", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, 7, result.Rank)
+	})
+}
+
+func Test_referralHistoryRepository_GetStatistic(t *testing.T) {
+	t.Run("invalid interval", func(t *testing.T) {
+		db, _ := setupStub(t)
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.GetStatistic(context.Background(), "2021-08-01", "2021-08-31", "year")
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT period(.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.GetStatistic(context.Background(), "2021-08-01", "2021-08-31", model.ReportIntervalDay)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT period(.+)DATE_TRUNC\\('week'(.+)referral_history*").
+			WithArgs("2021-08-01", "2021-08-31").
+			WillReturnRows(sqlmock.NewRows([]string{"period", "total_referral", "active_referrer"}).
+				AddRow("2021-08-02", 10, 4).
+				AddRow("2021-08-09", 3, 3))
+
+		r := SetupReferralHistoryRepository(db)
+		result, err := r.GetStatistic(context.Background(), "2021-08-01", "2021-08-31", model.ReportIntervalWeek)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ReferralStatistic{{Period: "2021-08-02", TotalReferral: 10, ActiveReferrer: 4},
+			{Period: "2021-08-09", TotalReferral: 3, ActiveReferrer: 3}}, result)
+	})
+}
+
+func Test_referralHistoryRepository_GetTierDistribution(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH referrer (.+)referral_history*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupReferralHistoryRepository(db)
+		_, err := r.GetTierDistribution(context.Background(), "2021-08-01", "2021-08-31")
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^WITH referrer (.+)referral_history*").
+			WithArgs("2021-08-01", "2021-08-31").
+			WillReturnRows(sqlmock.NewRows([]string{"reward_id", "total_referral", "reward", "total_referrer"}).
+				AddRow(1, 1, "bonus 2 GB", 12).
+				AddRow(2, 5, "bonus 12 GB", 2))
+
+		r := SetupReferralHistoryRepository(db)
+		result, err := r.GetTierDistribution(context.Background(), "2021-08-01", "2021-08-31")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, 12, result[0].TotalReferrer)
+	})
+}
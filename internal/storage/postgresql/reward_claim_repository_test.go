@@ -0,0 +1,152 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupRewardClaimRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardClaimRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupRewardClaimRepository(db)
+	})
+}
+
+func Test_rewardClaimRepository_FindByMsisdn(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward_claim*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardClaimRepository(db)
+		_, err := r.FindByMsisdn(context.Background(), "62821000000")
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward_claim*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "msisdn", "reward_id", "total_referral", "reward_description", "period"}).
+				AddRow(4, "62821000000", 2, 5, "bonus 12 GB", "2021-08").
+				AddRow(3, "62821000000", 1, 1, "bonus 2 GB", "2021-08"))
+
+		r := SetupRewardClaimRepository(db)
+		result, err := r.FindByMsisdn(context.Background(), "62821000000")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+	})
+}
+
+func Test_rewardClaimRepository_FindByMsisdnRewardAndPeriod(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward_claim*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardClaimRepository(db)
+		_, err := r.FindByMsisdnRewardAndPeriod(context.Background(), "62821000000", 2, "2021-08")
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward_claim*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "msisdn", "reward_id", "total_referral", "reward_description", "period"}).
+				AddRow(4, "62821000000", 2, 5, "bonus 12 GB", "2021-08"))
+
+		r := SetupRewardClaimRepository(db)
+		result, err := r.FindByMsisdnRewardAndPeriod(context.Background(), "62821000000", 2, "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, model.RewardClaim{
+			ID:            4,
+			Msisdn:        "62821000000",
+			RewardID:      2,
+			TotalReferral: 5,
+			Description:   "bonus 12 GB",
+			Period:        "2021-08",
+		}, result)
+	})
+}
+
+func Test_rewardClaimRepository_Insert(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)reward_claim*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardClaimRepository(db)
+		err := r.Insert(context.Background(), &model.RewardClaim{Msisdn: "62821000000", RewardID: 2, Period: "2021-08"})
+		assert.NotNil(t, err)
+	})
+	t.Run("duplicate claim", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)reward_claim*").
+			WillReturnError(&pq.Error{Code: pqUniqueViolation})
+
+		r := SetupRewardClaimRepository(db)
+		err := r.Insert(context.Background(), &model.RewardClaim{Msisdn: "62821000000", RewardID: 2, Period: "2021-08"})
+		assert.Equal(t, util.ErrorRewardAlreadyClaimed, err)
+	})
+	t.Run("failed insert, id is 0", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)reward_claim*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(0))
+
+		r := SetupRewardClaimRepository(db)
+		err := r.Insert(context.Background(), &model.RewardClaim{Msisdn: "62821000000", RewardID: 2, Period: "2021-08"})
+		assert.NotNil(t, err)
+	})
+	t.Run("success insert data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)reward_claim*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(11))
+
+		r := SetupRewardClaimRepository(db)
+		data := &model.RewardClaim{Msisdn: "62821000000", RewardID: 2, Period: "2021-08"}
+		err := r.Insert(context.Background(), data)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(11), data.ID)
+	})
+}
+
+func Test_rewardClaimRepository_UpdateStatus(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)reward_claim*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardClaimRepository(db)
+		err := r.UpdateStatus(context.Background(), 5, model.RewardClaimStatusClawback)
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)reward_claim*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupRewardClaimRepository(db)
+		err := r.UpdateStatus(context.Background(), 5, model.RewardClaimStatusClawback)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success update status", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)reward_claim*").
+			WithArgs(model.RewardClaimStatusClawback, int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupRewardClaimRepository(db)
+		err := r.UpdateStatus(context.Background(), 5, model.RewardClaimStatusClawback)
+		assert.Nil(t, err)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
 	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
@@ -24,21 +25,48 @@ func SetupRewardRepository(db *Database) *rewardRepository {
 }
 
 const (
-	queryRewardFindByTotalReferral = `SELECT id, total_referral, reward_description, status FROM reward 
-									  WHERE total_referral >= $1 AND status = 1 ORDER BY total_referral ASC LIMIT 1 `
-	queryRewardInsert     = "INSERT INTO %s.reward(total_referral, reward_description) VALUES ($1, $2) RETURNING id"
-	queryRewardSoftDelete = "UPDATE %s.reward SET status = 0 WHERE id = $1"
+	queryRewardFindAll = `SELECT id, total_referral, reward_description, COALESCE(campaign_id, 0) AS campaign_id, level, status,
+						  created_date, updated_date FROM reward ORDER BY campaign_id ASC, level ASC, total_referral ASC, id ASC`
 )
 
+func (r rewardRepository) FindAll(ctx context.Context) (result []model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.SelectContext(ctx, &result, queryRewardFindAll)
+	return result, err
+}
+
+func (r rewardRepository) FindByID(ctx context.Context, ID int64) (result model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.GetContext(ctx, &result, query.RewardFindByID, ID)
+	return result, err
+}
+
 func (r rewardRepository) FindByTotalReferral(ctx context.Context, totalReferral int) (result model.Reward, err error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
-	err = r.db.GetContext(ctx, &result, queryRewardFindByTotalReferral, totalReferral)
+	err = r.db.GetContext(ctx, &result, query.RewardFindByTotalReferral, totalReferral)
+	return result, err
+}
+
+func (r rewardRepository) FindByLevelAndTotalReferral(ctx context.Context, level, totalReferral int) (result model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.GetContext(ctx, &result, query.RewardFindByLevelAndTotalReferral, totalReferral, level)
+	return result, err
+}
+
+func (r rewardRepository) FindByCampaignAndTotalReferral(ctx context.Context, campaignID int64, totalReferral int) (result model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.GetContext(ctx, &result, query.RewardFindByCampaignAndTotalReferral, totalReferral, campaignID)
 	return result, err
 }
 
 func (r rewardRepository) Insert(ctx context.Context, model *model.Reward) (err error) {
-	err = r.db.GetContext(ctx, &model.ID, fmt.Sprintf(queryRewardInsert, r.db.SchemaName()), model.TotalReferral, model.Description)
+	err = r.db.GetContext(ctx, &model.ID, fmt.Sprintf(query.RewardInsert, r.db.SchemaName()), model.TotalReferral, model.Description,
+		model.CampaignID, model.Level)
 	if err != nil {
 		return err
 	}
@@ -86,7 +114,7 @@ func (r rewardRepository) Update(ctx context.Context, model model.Reward) (err e
 }
 
 func (r rewardRepository) Delete(ctx context.Context, ID int64) (err error) {
-	result, err := r.db.ExecContext(ctx, fmt.Sprintf(queryRewardSoftDelete, r.db.SchemaName()), ID)
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.RewardSoftDelete, r.db.SchemaName()), ID)
 	if err != nil {
 		return err
 	}
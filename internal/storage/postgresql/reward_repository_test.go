@@ -34,6 +34,58 @@ func TestSetupRewardRepository(t *testing.T) {
 	})
 }
 
+func Test_rewardRepository_FindAll(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardRepository(db)
+		_, err := r.FindAll(context.Background())
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "total_referral", "reward_description", "status"}).
+				AddRow(1, 1, "bonus 2 GB", 1).
+				AddRow(2, 5, "bonus 12 GB", 0))
+
+		r := SetupRewardRepository(db)
+		result, err := r.FindAll(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+	})
+}
+
+func Test_rewardRepository_FindByID(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardRepository(db)
+		_, err := r.FindByID(context.Background(), 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "total_referral", "reward_description", "status"}).
+				AddRow(2, 5, "bonus 12 GB", 1))
+
+		r := SetupRewardRepository(db)
+		result, err := r.FindByID(context.Background(), 2)
+		assert.Nil(t, err)
+		assert.Equal(t, model.Reward{
+			ID:            2,
+			TotalReferral: 5,
+			Description:   "bonus 12 GB",
+			Status:        1,
+		}, result)
+	})
+}
+
 func Test_rewardRepository_FindByTotalReferral(t *testing.T) {
 	t.Run("return context deadline exceed", func(t *testing.T) {
 		db, mock := setupStub(t)
@@ -62,6 +114,66 @@ func Test_rewardRepository_FindByTotalReferral(t *testing.T) {
 	})
 }
 
+func Test_rewardRepository_FindByCampaignAndTotalReferral(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardRepository(db)
+		_, err := r.FindByCampaignAndTotalReferral(context.Background(), 2, 10)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WithArgs(10, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "total_referral", "reward_description", "campaign_id", "status"}).
+				AddRow(7, 3, "bonus ramadan 10 GB", 2, 1))
+
+		r := SetupRewardRepository(db)
+		result, err := r.FindByCampaignAndTotalReferral(context.Background(), 2, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, model.Reward{
+			ID:            7,
+			TotalReferral: 3,
+			Description:   "bonus ramadan 10 GB",
+			CampaignID:    2,
+			Status:        1,
+		}, result)
+	})
+}
+
+func Test_rewardRepository_FindByLevelAndTotalReferral(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupRewardRepository(db)
+		_, err := r.FindByLevelAndTotalReferral(context.Background(), 2, 10)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)reward*").
+			WithArgs(10, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "total_referral", "reward_description", "level", "status"}).
+				AddRow(5, 5, "bonus 3 GB", 2, 1))
+
+		r := SetupRewardRepository(db)
+		result, err := r.FindByLevelAndTotalReferral(context.Background(), 2, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, model.Reward{
+			ID:            5,
+			TotalReferral: 5,
+			Description:   "bonus 3 GB",
+			Level:         2,
+			Status:        1,
+		}, result)
+	})
+}
+
 func Test_rewardRepository_Delete(t *testing.T) {
 	t.Run("error context deadline exceed", func(t *testing.T) {
 		db, mock := setupStub(t)
@@ -0,0 +1,120 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type webhookDeliveryRepository struct {
+	db *Database
+}
+
+func SetupWebhookDeliveryRepository(db *Database) *webhookDeliveryRepository {
+	if db == nil {
+		panic("postgresql db is nil")
+	}
+	return &webhookDeliveryRepository{
+		db: db,
+	}
+}
+
+const (
+	// SKIP LOCKED let other instance claim the next batch instead of waiting
+	queryWebhookDeliveryClaimDue = `UPDATE %s.webhook_delivery SET next_attempt_date = NOW() + $1 * INTERVAL '1 second' 
+									WHERE id IN (
+										SELECT id FROM %s.webhook_delivery WHERE status = 0 AND next_attempt_date <= NOW() 
+										ORDER BY next_attempt_date LIMIT $2 FOR UPDATE SKIP LOCKED
+									) RETURNING id, subscription_id, event_id, event, payload, status, attempt, 
+									next_attempt_date, COALESCE(response_code, 0) AS response_code, 
+									COALESCE(last_error, '') AS last_error, created_date, updated_date`
+	queryWebhookDeliveryFindByStatus = `SELECT id, subscription_id, event_id, event, payload, status, attempt, next_attempt_date, 
+										COALESCE(response_code, 0) AS response_code, COALESCE(last_error, '') AS last_error, 
+										created_date, updated_date FROM webhook_delivery WHERE status = $1 
+										ORDER BY id DESC OFFSET $2 LIMIT $3`
+)
+
+// Insert queue delivery, event already queued for the subscription is ignored and keep its delivery
+func (r webhookDeliveryRepository) Insert(ctx context.Context, delivery *model.WebhookDelivery) error {
+	err := r.db.GetContext(ctx, &delivery.ID, fmt.Sprintf(query.WebhookDeliveryInsert, r.db.SchemaName()),
+		delivery.SubscriptionID, delivery.EventID, delivery.Event, delivery.Payload)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+func (r webhookDeliveryRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.WebhookDelivery, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.SelectContext(ctx, &result, fmt.Sprintf(queryWebhookDeliveryClaimDue, r.db.SchemaName(), r.db.SchemaName()),
+		lease.Seconds(), limit)
+	return result, err
+}
+
+func (r webhookDeliveryRepository) UpdateResult(ctx context.Context, delivery model.WebhookDelivery) error {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.WebhookDeliveryUpdateResult, r.db.SchemaName()), delivery.Status,
+		delivery.Attempt, delivery.NextAttemptDate, delivery.ResponseCode, delivery.LastError, delivery.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r webhookDeliveryRepository) FindByStatus(ctx context.Context, status, offset, limit int) (result []model.WebhookDelivery, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.SelectContext(ctx, &result, queryWebhookDeliveryFindByStatus, status, offset, limit)
+	return result, err
+}
+
+func (r webhookDeliveryRepository) CountByStatus(ctx context.Context, status int) (total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.GetContext(ctx, &total, query.WebhookDeliveryCountByStatus, status)
+	return total, err
+}
+
+// Replay set dead delivery back to pending so it is sent on next run, delivery in other status is not found
+func (r webhookDeliveryRepository) Replay(ctx context.Context, ID int64) error {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.WebhookDeliveryReplay, r.db.SchemaName()), ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// ReplayDead set every dead delivery of subscription back to pending, all subscription when subscriptionID is 0
+func (r webhookDeliveryRepository) ReplayDead(ctx context.Context, subscriptionID int64) (total int64, err error) {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(query.WebhookDeliveryReplayDead, r.db.SchemaName()), subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
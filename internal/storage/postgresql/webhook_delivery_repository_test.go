@@ -0,0 +1,221 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+var webhookDeliveryColumns = []string{"id", "subscription_id", "event_id", "event", "payload", "status", "attempt",
+	"next_attempt_date", "response_code", "last_error"}
+
+func TestSetupWebhookDeliveryRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupWebhookDeliveryRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupWebhookDeliveryRepository(db)
+	})
+}
+
+func Test_webhookDeliveryRepository_Insert(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)webhook_delivery*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.Insert(context.Background(), &model.WebhookDelivery{SubscriptionID: 1})
+		assert.NotNil(t, err)
+	})
+	t.Run("failed insert, id is 0", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)webhook_delivery*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(0))
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.Insert(context.Background(), &model.WebhookDelivery{SubscriptionID: 1})
+		assert.NotNil(t, err)
+	})
+	t.Run("event already queued for subscription", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)webhook_delivery (.+)ON CONFLICT(.+)DO NOTHING").
+			WithArgs(1, "a1b2", "referral.created", "{}").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.Insert(context.Background(), &model.WebhookDelivery{SubscriptionID: 1, EventID: "a1b2",
+			Event: "referral.created", Payload: "{}"})
+		assert.Nil(t, err)
+	})
+	t.Run("success insert data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)webhook_delivery*").
+			WithArgs(1, "a1b2", "referral.created", "{}").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(9))
+
+		r := SetupWebhookDeliveryRepository(db)
+		data := &model.WebhookDelivery{SubscriptionID: 1, EventID: "a1b2", Event: "referral.created", Payload: "{}"}
+		err := r.Insert(context.Background(), data)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(9), data.ID)
+	})
+}
+
+func Test_webhookDeliveryRepository_ClaimDue(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^UPDATE (.+)webhook_delivery*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookDeliveryRepository(db)
+		_, err := r.ClaimDue(context.Background(), time.Minute, 50)
+		assert.NotNil(t, err)
+	})
+	t.Run("claim due delivery", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^UPDATE (.+)webhook_delivery (.+)FOR UPDATE SKIP LOCKED(.+)").
+			WithArgs(float64(60), 50).
+			WillReturnRows(sqlmock.NewRows(webhookDeliveryColumns).
+				AddRow(9, 1, "a1b2", "referral.created", "{}", 0, 2, time.Now(), 500, "unexpected status 500"))
+
+		r := SetupWebhookDeliveryRepository(db)
+		result, err := r.ClaimDue(context.Background(), time.Minute, 50)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result))
+		assert.Equal(t, 2, result[0].Attempt)
+	})
+}
+
+func Test_webhookDeliveryRepository_UpdateResult(t *testing.T) {
+	next := time.Now()
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.UpdateResult(context.Background(), model.WebhookDelivery{ID: 9})
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.UpdateResult(context.Background(), model.WebhookDelivery{ID: 9})
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success update", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery*").
+			WithArgs(model.WebhookDeliveryStatusDead, 8, next, 503, "unexpected status 503", 9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.UpdateResult(context.Background(), model.WebhookDelivery{ID: 9, Status: model.WebhookDeliveryStatusDead,
+			Attempt: 8, NextAttemptDate: next, ResponseCode: 503, LastError: "unexpected status 503"})
+		assert.Nil(t, err)
+	})
+}
+
+func Test_webhookDeliveryRepository_FindByStatus(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_delivery*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookDeliveryRepository(db)
+		_, err := r.FindByStatus(context.Background(), model.WebhookDeliveryStatusDead, 0, 10)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_delivery*").
+			WithArgs(model.WebhookDeliveryStatusDead, 0, 10).
+			WillReturnRows(sqlmock.NewRows(webhookDeliveryColumns).
+				AddRow(9, 1, "a1b2", "referral.created", "{}", 2, 8, time.Now(), 0, "connection refused"))
+
+		r := SetupWebhookDeliveryRepository(db)
+		result, err := r.FindByStatus(context.Background(), model.WebhookDeliveryStatusDead, 0, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, "connection refused", result[0].LastError)
+	})
+}
+
+func Test_webhookDeliveryRepository_CountByStatus(t *testing.T) {
+	db, mock := setupStub(t)
+	mock.ExpectQuery("^SELECT COUNT(.+)webhook_delivery*").
+		WithArgs(model.WebhookDeliveryStatusDead).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	r := SetupWebhookDeliveryRepository(db)
+	total, err := r.CountByStatus(context.Background(), model.WebhookDeliveryStatusDead)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+}
+
+func Test_webhookDeliveryRepository_Replay(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.Replay(context.Background(), 9)
+		assert.NotNil(t, err)
+	})
+	t.Run("delivery is not dead", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.Replay(context.Background(), 9)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success replay", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery (.+)status = 2").
+			WithArgs(9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupWebhookDeliveryRepository(db)
+		err := r.Replay(context.Background(), 9)
+		assert.Nil(t, err)
+	})
+}
+
+func Test_webhookDeliveryRepository_ReplayDead(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookDeliveryRepository(db)
+		_, err := r.ReplayDead(context.Background(), 0)
+		assert.NotNil(t, err)
+	})
+	t.Run("success replay", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_delivery*").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+
+		r := SetupWebhookDeliveryRepository(db)
+		total, err := r.ReplayDead(context.Background(), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), total)
+	})
+}
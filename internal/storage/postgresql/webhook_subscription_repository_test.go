@@ -0,0 +1,161 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupWebhookSubscriptionRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupWebhookSubscriptionRepository(nil)
+	})
+
+	assert.NotPanics(t, func() {
+		db, _ := setupStub(t)
+		SetupWebhookSubscriptionRepository(db)
+	})
+}
+
+func Test_webhookSubscriptionRepository_FindAll(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_subscription*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookSubscriptionRepository(db)
+		_, err := r.FindAll(context.Background())
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_subscription*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "events", "status"}).
+				AddRow(2, "https://crm.local/hook", "s3cr3t", "referral.created", 1).
+				AddRow(1, "https://reward.local/hook", "s3cr3t", "reward.tier_reached", 0))
+
+		r := SetupWebhookSubscriptionRepository(db)
+		result, err := r.FindAll(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(result))
+	})
+}
+
+func Test_webhookSubscriptionRepository_FindByID(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_subscription*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookSubscriptionRepository(db)
+		_, err := r.FindByID(context.Background(), 1)
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_subscription*").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "events", "status"}).
+				AddRow(1, "https://crm.local/hook", "s3cr3t", "referral.created,referral.reversed", 1))
+
+		r := SetupWebhookSubscriptionRepository(db)
+		result, err := r.FindByID(context.Background(), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"referral.created", "referral.reversed"}, result.EventList())
+	})
+}
+
+func Test_webhookSubscriptionRepository_FindActiveByEvent(t *testing.T) {
+	t.Run("return context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_subscription*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookSubscriptionRepository(db)
+		_, err := r.FindActiveByEvent(context.Background(), "referral.created")
+		assert.NotNil(t, err)
+	})
+	t.Run("data found in db", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^SELECT (.+)webhook_subscription WHERE status = 1(.+)").
+			WithArgs("referral.created").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "events", "status"}).
+				AddRow(1, "https://crm.local/hook", "s3cr3t", "referral.created,referral.reversed", 1))
+
+		r := SetupWebhookSubscriptionRepository(db)
+		result, err := r.FindActiveByEvent(context.Background(), "referral.created")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result))
+	})
+}
+
+func Test_webhookSubscriptionRepository_Insert(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)webhook_subscription*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookSubscriptionRepository(db)
+		err := r.Insert(context.Background(), &model.WebhookSubscription{URL: "https://crm.local/hook"})
+		assert.NotNil(t, err)
+	})
+	t.Run("failed insert, id is 0", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)webhook_subscription*").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(0))
+
+		r := SetupWebhookSubscriptionRepository(db)
+		err := r.Insert(context.Background(), &model.WebhookSubscription{URL: "https://crm.local/hook"})
+		assert.NotNil(t, err)
+	})
+	t.Run("success insert data", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectQuery("^INSERT INTO (.+)webhook_subscription*").
+			WithArgs("https://crm.local/hook", "s3cr3t", "referral.created").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).
+				AddRow(3))
+
+		r := SetupWebhookSubscriptionRepository(db)
+		data := &model.WebhookSubscription{URL: "https://crm.local/hook", Secret: "s3cr3t", Events: "referral.created"}
+		err := r.Insert(context.Background(), data)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), data.ID)
+	})
+}
+
+func Test_webhookSubscriptionRepository_UpdateStatus(t *testing.T) {
+	t.Run("error context deadline exceed", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_subscription*").
+			WillReturnError(context.DeadlineExceeded)
+
+		r := SetupWebhookSubscriptionRepository(db)
+		err := r.UpdateStatus(context.Background(), 1, model.WebhookSubscriptionStatusInactive)
+		assert.NotNil(t, err)
+	})
+	t.Run("data not found", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_subscription*").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := SetupWebhookSubscriptionRepository(db)
+		err := r.UpdateStatus(context.Background(), 1, model.WebhookSubscriptionStatusInactive)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success update", func(t *testing.T) {
+		db, mock := setupStub(t)
+		mock.ExpectExec("^UPDATE (.+)webhook_subscription*").
+			WithArgs(model.WebhookSubscriptionStatusInactive, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := SetupWebhookSubscriptionRepository(db)
+		err := r.UpdateStatus(context.Background(), 1, model.WebhookSubscriptionStatusInactive)
+		assert.Nil(t, err)
+	})
+}
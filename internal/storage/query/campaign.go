@@ -0,0 +1,12 @@
+package query
+
+const (
+	CampaignFindAll = `SELECT id, name, start_date, end_date, status, created_date, updated_date FROM campaign
+							ORDER BY start_date DESC, id DESC`
+	CampaignFindByID = `SELECT id, name, start_date, end_date, status, created_date, updated_date FROM campaign
+							 WHERE id = $1`
+	CampaignFindActiveByDate = `SELECT id, name, start_date, end_date, status, created_date, updated_date FROM campaign
+									 WHERE status = 1 AND start_date <= $1 AND end_date >= $1 ORDER BY start_date DESC, id DESC`
+	CampaignInsert     = "INSERT INTO %s.campaign (name, start_date, end_date) VALUES ($1, $2, $3) RETURNING id"
+	CampaignSoftDelete = "UPDATE %s.campaign SET status = 0, updated_date = NOW() WHERE id = $1"
+)
@@ -0,0 +1,8 @@
+package query
+
+const (
+	NotificationInsert = `INSERT INTO %s.notification (event_id, msisdn, template, message, next_attempt_date) 
+							   VALUES ($1, $2, $3, $4, $5) ON CONFLICT (event_id, msisdn) DO NOTHING RETURNING id`
+	NotificationUpdateResult = `UPDATE %s.notification SET status = $1, attempt = $2, next_attempt_date = $3, 
+									 last_error = NULLIF($4, ''), updated_date = NOW() WHERE id = $5`
+)
@@ -0,0 +1,9 @@
+package query
+
+const (
+	NotificationPreferenceFindByMsisdn = `SELECT msisdn, language, opt_out, created_date, updated_date 
+											   FROM notification_preference WHERE msisdn = $1`
+	NotificationPreferenceUpsert = `INSERT INTO %s.notification_preference (msisdn, language, opt_out) VALUES ($1, $2, $3) 
+										 ON CONFLICT (msisdn) DO UPDATE SET language = EXCLUDED.language, 
+										 opt_out = EXCLUDED.opt_out, updated_date = NOW()`
+)
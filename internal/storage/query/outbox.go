@@ -0,0 +1,10 @@
+package query
+
+const (
+	OutboxInsert        = "INSERT INTO %s.outbox (event_id, event, payload) VALUES ($1, $2, $3) RETURNING id"
+	OutboxMarkPublished = `UPDATE %s.outbox SET status = 1, attempt = attempt + 1, last_error = NULL, 
+								published_date = NOW() WHERE id = $1 AND status = 0`
+	OutboxUpdateAttempt = `UPDATE %s.outbox SET attempt = $1, next_attempt_date = $2, last_error = NULLIF($3, '') 
+								WHERE id = $4 AND status = 0`
+	OutboxDeletePublished = "DELETE FROM %s.outbox WHERE status = 1 AND published_date < $1"
+)
@@ -0,0 +1,4 @@
+// Package query is sql shared by postgresql and sqlite storage driver. Query is written in postgresql dialect,
+// table written with %s. is prefixed with schema of the driver and argument is $N placeholder.
+// Query specific to a driver is kept in its own package.
+package query
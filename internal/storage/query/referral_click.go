@@ -0,0 +1,6 @@
+package query
+
+const (
+	ClickInsert = `INSERT INTO %s.referral_click (code, channel, user_agent) 
+						VALUES ($1, NULLIF($2, ''), NULLIF($3, '')) RETURNING id`
+)
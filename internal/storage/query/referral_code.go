@@ -0,0 +1,12 @@
+package query
+
+const (
+	ReferralCodeFindByMsisdn = "SELECT id, msisdn, code, expires_at, status, cooldown_until FROM referral_code WHERE msisdn = $1 AND status <> 2"
+	ReferralCodeFindByCode   = "SELECT id, msisdn, code, expires_at, status, cooldown_until FROM referral_code WHERE UPPER(code) = UPPER($1)"
+	ReferralCodeFindAll      = `SELECT id, msisdn, code, created_date, expires_at, status FROM referral_code 
+									 WHERE msisdn = $1 ORDER BY id DESC`
+	ReferralCodeInsert         = "INSERT INTO %s.referral_code (msisdn, code) VALUES ($1, $2) RETURNING id"
+	ReferralCodeUpdateStatus   = "UPDATE %s.referral_code SET status = $1 WHERE UPPER(code) = UPPER($2) AND status <> 2"
+	ReferralCodeUpdateExpiry   = "UPDATE %s.referral_code SET expires_at = $1 WHERE UPPER(code) = UPPER($2)"
+	ReferralCodeUpdateCooldown = "UPDATE %s.referral_code SET cooldown_until = $1 WHERE UPPER(code) = UPPER($2)"
+)
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+const (
+	// reversed referral is kept as history but excluded from list
+	HistoryFindByMsisdn = `SELECT id, msisdn, code, referral_date, msisdn_referee, flagged, status, created_date FROM referral_history 
+								WHERE msisdn = $1 AND status <> 2 ORDER BY id DESC LIMIT $2 OFFSET $3`
+	HistoryCountByMsisdn = "SELECT COUNT(id) FROM referral_history WHERE msisdn = $1 AND status <> 2"
+	HistoryFindByReferee = `SELECT id, msisdn, code, referral_date, msisdn_referee, COALESCE(campaign_id, 0) AS campaign_id, 
+								 flagged, status, created_date FROM referral_history WHERE msisdn_referee = $1`
+	// only confirmed or rewarded referral is counted for reward, flagged referral is waiting for review
+	HistoryTotalMonthByMsisdn = `SELECT COUNT(id) FROM referral_history 
+									  WHERE msisdn = $1 AND referral_date LIKE $2 AND flagged = false AND status IN (1, 3)`
+	HistoryTotalCampaignByMsisdn = `SELECT COUNT(id) FROM referral_history 
+										 WHERE msisdn = $1 AND campaign_id = $2 AND flagged = false AND status IN (1, 3)`
+	// walk down referral chain, each referee can only be referred once so the chain is a tree
+	HistoryDownline = `WITH RECURSIVE downline AS (
+								SELECT msisdn, msisdn_referee, referral_date, flagged, status, 1 AS level FROM referral_history 
+								WHERE msisdn = $1
+								UNION ALL
+								SELECT h.msisdn, h.msisdn_referee, h.referral_date, h.flagged, h.status, d.level + 1 FROM referral_history h
+								JOIN downline d ON h.msisdn = d.msisdn_referee WHERE d.level < $2
+							)`
+	HistoryTotalMonthPerLevel = HistoryDownline + ` SELECT level, COUNT(1) AS total FROM downline 
+							 WHERE referral_date LIKE $3 AND flagged = false AND status IN (1, 3) GROUP BY level ORDER BY level`
+	HistoryFindDownline = HistoryDownline + ` SELECT msisdn, msisdn_referee, referral_date, level FROM downline 
+							   WHERE status IN (0, 1, 3) ORDER BY level, referral_date, msisdn_referee`
+	HistoryConfirm = `UPDATE %s.referral_history SET status = 1, confirmed_date = NOW() WHERE id = $1 AND status = 0`
+	// referrer with same total is ranked by who reach the total first, then by msisdn
+	HistoryRank = `WITH ranking AS (
+							SELECT ROW_NUMBER() OVER (ORDER BY COUNT(id) DESC, MAX(id), msisdn) AS rank, msisdn, 
+							COUNT(id) AS total FROM referral_history 
+							WHERE referral_date LIKE $1 AND flagged = false AND status IN (1, 3) GROUP BY msisdn
+						)`
+	HistoryTopReferrer  = HistoryRank + ` SELECT rank, msisdn, total FROM ranking ORDER BY rank LIMIT $2`
+	HistoryRankByMsisdn = HistoryRank + ` SELECT rank, msisdn, total FROM ranking WHERE msisdn = $2`
+	// period is replaced with expression of report interval
+	HistoryStatistic = `SELECT period, COUNT(id) AS total_referral, COUNT(DISTINCT msisdn) AS active_referrer FROM (
+								 SELECT id, msisdn, %s AS period FROM referral_history 
+								 WHERE referral_date BETWEEN $1 AND $2 AND flagged = false AND status IN (1, 3)
+							 ) t GROUP BY period ORDER BY period`
+	HistoryReverse = `UPDATE %s.referral_history SET status = 2, reversal_reason = $1, reversed_date = NOW() 
+						   WHERE id = $2 AND status IN (0, 1, 3)`
+	HistoryMarkRewarded = `UPDATE %s.referral_history SET status = 3 
+								WHERE msisdn = $1 AND referral_date LIKE $2 AND flagged = false AND status = 1`
+	HistoryExpirePending = `UPDATE %s.referral_history SET status = 4 
+								 WHERE status = 0 AND created_date < $1`
+	HistoryInsert = `INSERT INTO %s.referral_history (msisdn, code, referral_date, msisdn_referee, campaign_id, 
+						  ip_address, device_id, flagged, flag_reason, status) 
+						  VALUES ($1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, ''), NULLIF($7, ''), $8, NULLIF($9, ''), $10) RETURNING id`
+)
+
+// HistoryCountVelocity build query counting referral matching filter, empty field of filter is not added to the query
+func HistoryCountVelocity(filter model.ReferralVelocityFilter) (string, []interface{}) {
+	qb := strings.Builder{}
+	qb.WriteString("SELECT COUNT(id) FROM referral_history WHERE created_date >= $1")
+
+	args := []interface{}{filter.Since}
+	if filter.Code != "" {
+		args = append(args, filter.Code)
+		qb.WriteString(fmt.Sprintf(" AND code = $%d", len(args)))
+	}
+	if filter.IPAddress != "" {
+		args = append(args, filter.IPAddress)
+		qb.WriteString(fmt.Sprintf(" AND ip_address = $%d", len(args)))
+	}
+	if filter.DeviceID != "" {
+		args = append(args, filter.DeviceID)
+		qb.WriteString(fmt.Sprintf(" AND device_id = $%d", len(args)))
+	}
+	return qb.String(), args
+}
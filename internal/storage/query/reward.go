@@ -0,0 +1,18 @@
+package query
+
+const (
+	RewardFindByID = `SELECT id, total_referral, reward_description, COALESCE(campaign_id, 0) AS campaign_id, level, status,
+						   created_date, updated_date FROM reward WHERE id = $1`
+	RewardFindByTotalReferral = `SELECT id, total_referral, reward_description, level, status FROM reward 
+									  WHERE total_referral <= $1 AND campaign_id IS NULL AND level = 1 AND status = 1 
+									  ORDER BY total_referral DESC LIMIT 1 `
+	RewardFindByLevelAndTotalReferral = `SELECT id, total_referral, reward_description, level, status FROM reward 
+											  WHERE total_referral <= $1 AND campaign_id IS NULL AND level = $2 AND status = 1 
+											  ORDER BY total_referral DESC LIMIT 1 `
+	RewardFindByCampaignAndTotalReferral = `SELECT id, total_referral, reward_description, campaign_id, status FROM reward 
+												 WHERE total_referral <= $1 AND campaign_id = $2 AND status = 1 
+												 ORDER BY total_referral DESC LIMIT 1 `
+	RewardInsert = `INSERT INTO %s.reward(total_referral, reward_description, campaign_id, level) 
+						 VALUES ($1, $2, NULLIF($3, 0), $4) RETURNING id`
+	RewardSoftDelete = "UPDATE %s.reward SET status = 0 WHERE id = $1"
+)
@@ -0,0 +1,11 @@
+package query
+
+const (
+	ClaimFindByMsisdn = `SELECT id, msisdn, reward_id, total_referral, reward_description, period, level, status, created_date 
+							  FROM reward_claim WHERE msisdn = $1 ORDER BY id DESC`
+	ClaimFindByMsisdnRewardAndPeriod = `SELECT id, msisdn, reward_id, total_referral, reward_description, period, level, status, 
+											 created_date FROM reward_claim WHERE msisdn = $1 AND reward_id = $2 AND period = $3`
+	ClaimInsert = `INSERT INTO %s.reward_claim (msisdn, reward_id, total_referral, reward_description, period, level)
+						VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	ClaimUpdateStatus = "UPDATE %s.reward_claim SET status = $1 WHERE id = $2"
+)
@@ -0,0 +1,14 @@
+package query
+
+const (
+	WebhookDeliveryInsert = `INSERT INTO %s.webhook_delivery (subscription_id, event_id, event, payload) 
+								  VALUES ($1, $2, $3, $4) ON CONFLICT (subscription_id, event_id) DO NOTHING RETURNING id`
+	WebhookDeliveryUpdateResult = `UPDATE %s.webhook_delivery SET status = $1, attempt = $2, next_attempt_date = $3, 
+										response_code = NULLIF($4, 0), last_error = NULLIF($5, ''), updated_date = NOW() 
+										WHERE id = $6`
+	WebhookDeliveryCountByStatus = "SELECT COUNT(id) FROM webhook_delivery WHERE status = $1"
+	WebhookDeliveryReplay        = `UPDATE %s.webhook_delivery SET status = 0, attempt = 0, next_attempt_date = NOW(), 
+										 updated_date = NOW() WHERE id = $1 AND status = 2`
+	WebhookDeliveryReplayDead = `UPDATE %s.webhook_delivery SET status = 0, attempt = 0, next_attempt_date = NOW(), 
+									  updated_date = NOW() WHERE status = 2 AND ($1 = 0 OR subscription_id = $1)`
+)
@@ -0,0 +1,14 @@
+package query
+
+const (
+	WebhookSubscriptionFindAll = `SELECT id, url, secret, events, status, created_date, updated_date 
+									   FROM webhook_subscription ORDER BY id DESC`
+	WebhookSubscriptionFindByID = `SELECT id, url, secret, events, status, created_date, updated_date 
+										FROM webhook_subscription WHERE id = $1`
+	WebhookSubscriptionFindActiveByEvent = `SELECT id, url, secret, events, status, created_date, updated_date 
+												 FROM webhook_subscription 
+												 WHERE status = 1 AND ',' || events || ',' LIKE '%,' || $1 || ',%' ORDER BY id`
+	WebhookSubscriptionInsert = `INSERT INTO %s.webhook_subscription (url, secret, events) VALUES ($1, $2, $3) 
+									  RETURNING id`
+	WebhookSubscriptionUpdateStatus = "UPDATE %s.webhook_subscription SET status = $1, updated_date = NOW() WHERE id = $2"
+)
@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+func NewClient(cfg *config.RedisConfig) *goredis.Client {
+	if cfg == nil {
+		panic("config is nil")
+	}
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		fmt.Println("failed to ping redis")
+		panic(err)
+	}
+	return client
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// counter of a month is hash of msisdn to total, e.g. referral:counter:2021-08
+	keyReferralCounter = "referral:counter:"
+	// counterTTL keep counter of a month until the next month is over
+	counterTTL = 62 * 24 * time.Hour
+)
+
+// scriptIncrement only increment msisdn which is already counted, so it is not started from 0
+var scriptIncrement = goredis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	return redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+end
+return nil`)
+
+type referralCounter struct {
+	client goredis.UniversalClient
+}
+
+func SetupReferralCounter(client goredis.UniversalClient) *referralCounter {
+	if client == nil {
+		panic("redis client is nil")
+	}
+	return &referralCounter{
+		client: client,
+	}
+}
+
+func (r referralCounter) Get(ctx context.Context, msisdn, month string) (int, bool, error) {
+	total, err := r.client.HGet(ctx, keyReferralCounter+month, msisdn).Int()
+	if err == goredis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return total, true, nil
+}
+
+func (r referralCounter) Set(ctx context.Context, msisdn, month string, total int) error {
+	key := keyReferralCounter + month
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, key, msisdn, total)
+		pipe.Expire(ctx, key, counterTTL)
+		return nil
+	})
+	return err
+}
+
+func (r referralCounter) Increment(ctx context.Context, msisdn, month string, delta int) error {
+	err := scriptIncrement.Run(ctx, r.client, []string{keyReferralCounter + month}, msisdn, delta).Err()
+	if err == goredis.Nil {
+		return nil
+	}
+	return err
+}
+
+func (r referralCounter) GetAll(ctx context.Context, month string) (map[string]int, error) {
+	values, err := r.client.HGetAll(ctx, keyReferralCounter+month).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int, len(values))
+	for msisdn, value := range values {
+		total, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		result[msisdn] = total
+	}
+	return result, nil
+}
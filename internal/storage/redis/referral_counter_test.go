@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+func setupCounter(t *testing.T) (*miniredis.Miniredis, *referralCounter) {
+	server := miniredis.RunT(t)
+	return server, SetupReferralCounter(goredis.NewClient(&goredis.Options{Addr: server.Addr()}))
+}
+
+func TestNewClient(t *testing.T) {
+	assert.Panics(t, func() {
+		NewClient(nil)
+	})
+	server := miniredis.RunT(t)
+	assert.NotPanics(t, func() {
+		NewClient(&config.RedisConfig{Address: server.Addr()})
+	})
+	server.Close()
+	assert.Panics(t, func() {
+		NewClient(&config.RedisConfig{Address: server.Addr()})
+	})
+}
+
+func TestSetupReferralCounter(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReferralCounter(nil)
+	})
+}
+
+func Test_referralCounter_Get(t *testing.T) {
+	server, r := setupCounter(t)
+
+	_, found, err := r.Get(context.Background(), "62821000000", "2021-08")
+	assert.Nil(t, err)
+	assert.False(t, found)
+
+	server.HSet("referral:counter:2021-08", "62821000000", "4")
+	total, found, err := r.Get(context.Background(), "62821000000", "2021-08")
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 4, total)
+
+	server.HSet("referral:counter:2021-08", "62821000000", "x")
+	_, _, err = r.Get(context.Background(), "62821000000", "2021-08")
+	assert.NotNil(t, err)
+}
+
+func Test_referralCounter_Set(t *testing.T) {
+	server, r := setupCounter(t)
+
+	assert.Nil(t, r.Set(context.Background(), "62821000000", "2021-08", 3))
+	assert.Equal(t, "3", server.HGet("referral:counter:2021-08", "62821000000"))
+	assert.Equal(t, counterTTL, server.TTL("referral:counter:2021-08"))
+
+	server.SetError("unavailable")
+	assert.NotNil(t, r.Set(context.Background(), "62821000000", "2021-08", 3))
+}
+
+func Test_referralCounter_Increment(t *testing.T) {
+	server, r := setupCounter(t)
+
+	//counter is not started by increment
+	assert.Nil(t, r.Increment(context.Background(), "62821000000", "2021-08", 1))
+	assert.False(t, server.Exists("referral:counter:2021-08"))
+
+	server.HSet("referral:counter:2021-08", "62821000000", "3")
+	assert.Nil(t, r.Increment(context.Background(), "62821000000", "2021-08", 1))
+	assert.Nil(t, r.Increment(context.Background(), "62821000000", "2021-08", -2))
+	assert.Equal(t, "2", server.HGet("referral:counter:2021-08", "62821000000"))
+
+	server.SetError("unavailable")
+	assert.NotNil(t, r.Increment(context.Background(), "62821000000", "2021-08", 1))
+}
+
+func Test_referralCounter_GetAll(t *testing.T) {
+	server, r := setupCounter(t)
+
+	all, err := r.GetAll(context.Background(), "2021-08")
+	assert.Nil(t, err)
+	assert.Empty(t, all)
+
+	server.HSet("referral:counter:2021-08", "62821000000", "3", "62821000001", "1")
+	all, err = r.GetAll(context.Background(), "2021-08")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"62821000000": 3, "62821000001": 1}, all)
+
+	server.HSet("referral:counter:2021-08", "62821000002", "x")
+	_, err = r.GetAll(context.Background(), "2021-08")
+	assert.NotNil(t, err)
+}
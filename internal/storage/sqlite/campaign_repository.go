@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type campaignRepository struct {
+	db *Database
+}
+
+func SetupCampaignRepository(db *Database) *campaignRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &campaignRepository{
+		db: db,
+	}
+}
+
+func (r campaignRepository) FindAll(ctx context.Context) (result []model.Campaign, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.CampaignFindAll)
+	return result, err
+}
+
+func (r campaignRepository) FindByID(ctx context.Context, ID int64) (result model.Campaign, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.CampaignFindByID, ID)
+	return result, err
+}
+
+func (r campaignRepository) FindActiveByDate(ctx context.Context, date string) (result []model.Campaign, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.CampaignFindActiveByDate, date)
+	return result, err
+}
+
+func (r campaignRepository) Insert(ctx context.Context, campaign *model.Campaign) (err error) {
+	campaign.ID, err = r.db.insert(ctx, fmt.Sprintf(query.CampaignInsert, r.db.SchemaName()), campaign.Name,
+		campaign.StartDate, campaign.EndDate)
+	if err != nil {
+		return err
+	}
+	if campaign.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+func (r campaignRepository) Update(ctx context.Context, campaign model.Campaign) error {
+	qb := strings.Builder{}
+	qb.WriteString("UPDATE ")
+	qb.WriteString(r.db.SchemaName())
+	qb.WriteString(".campaign SET ")
+
+	var cols []string
+	var args []interface{}
+	if campaign.Name != "" {
+		args = append(args, campaign.Name)
+		cols = append(cols, fmt.Sprintf("name=$%d", len(args)))
+	}
+	if campaign.StartDate != "" {
+		args = append(args, campaign.StartDate)
+		cols = append(cols, fmt.Sprintf("start_date=$%d", len(args)))
+	}
+	if campaign.EndDate != "" {
+		args = append(args, campaign.EndDate)
+		cols = append(cols, fmt.Sprintf("end_date=$%d", len(args)))
+	}
+	cols = append(cols, "updated_date=NOW()")
+
+	qb.WriteString(strings.Join(cols, ","))
+
+	args = append(args, campaign.ID)
+	qb.WriteString(fmt.Sprintf(" WHERE id=$%d", len(args)))
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, qb.String(), args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r campaignRepository) Delete(ctx context.Context, ID int64) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.CampaignSoftDelete, r.db.SchemaName()), ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+// schemaName is name sqlite gives to the opened database file, shared query prefix written table with it
+const schemaName = "main"
+
+// timestamp is current time in the same format the driver bind time.Time, so stored time is comparable as text
+const timestamp = "STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')"
+
+type Database struct {
+	*sqlx.DB
+}
+
+// NewDatabase open database file and create the schema when it does not exist. Transaction take the write lock
+// when it begins, so concurrent transaction wait for each other instead of failing on upgrade of its lock
+func NewDatabase(cfg *config.SQLiteConfig) *Database {
+	if cfg == nil {
+		panic("config is nil")
+	}
+	db, err := sqlx.Open("sqlite3", cfg.Path+"?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"+
+		"&_foreign_keys=1&_case_sensitive_like=1")
+	if err != nil {
+		fmt.Println("failed to open database")
+		panic(err)
+	}
+	if _, err = db.Exec(schema); err != nil {
+		fmt.Println("failed to create schema")
+		panic(err)
+	}
+
+	return &Database{
+		db,
+	}
+}
+
+func (d *Database) SchemaName() string {
+	return schemaName
+}
+
+type txKey struct{}
+
+// executor run query on database or on transaction
+type executor interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// WithinTransaction run fn in a transaction, repository called with ctx given to fn join the transaction.
+// Transaction is committed when fn return nil, otherwise it is rolled back. Nested call join the outer transaction.
+func (d *Database) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := d.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conn return transaction carried by ctx, otherwise the database. Query run on it is translated to sqlite
+func (d *Database) conn(ctx context.Context) executor {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return dialect{tx}
+	}
+	return dialect{d.DB}
+}
+
+// insert run insert query and return id of inserted row, 0 when nothing is inserted because of ON CONFLICT DO
+// NOTHING. RETURNING is only supported since sqlite 3.35, so it is removed and id is read from the result instead
+func (d *Database) insert(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if i := strings.LastIndex(query, "RETURNING"); i >= 0 {
+		query = query[:i]
+	}
+	result, err := d.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// postpone set next attempt of row in table to until, it is used to lease due row to the caller
+func (d *Database) postpone(ctx context.Context, table string, IDs []int64, until time.Time) error {
+	query, args, err := sqlx.In(fmt.Sprintf("UPDATE %s.%s SET next_attempt_date = ? WHERE id IN (?)", d.SchemaName(), table),
+		until, IDs)
+	if err != nil {
+		return err
+	}
+	_, err = d.conn(ctx).ExecContext(ctx, query, args...)
+	return err
+}
+
+// isUniqueViolation report whether err is caused by breaking unique constraint, constraint is name of the index
+// or table.column of the constraint as written in the error, empty constraint match any
+func isUniqueViolation(err error, constraint string) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		return false
+	}
+	return strings.Contains(sqliteErr.Error(), constraint)
+}
+
+// placeholder is $N argument of postgresql, sqlite write it as ?N
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// dialect translate query written in postgresql dialect before it is run
+type dialect struct {
+	executor
+}
+
+func (d dialect) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return d.executor.GetContext(ctx, dest, rebind(query), bindArgs(args)...)
+}
+
+func (d dialect) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return d.executor.SelectContext(ctx, dest, rebind(query), bindArgs(args)...)
+}
+
+func (d dialect) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.executor.ExecContext(ctx, rebind(query), bindArgs(args)...)
+}
+
+func (d dialect) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return d.executor.QueryxContext(ctx, rebind(query), bindArgs(args)...)
+}
+
+func rebind(query string) string {
+	return placeholder.ReplaceAllString(strings.ReplaceAll(query, "NOW()", timestamp), "?$1")
+}
+
+// bindArgs convert time to UTC, time is stored as text so it is only comparable in the same time zone
+func bindArgs(args []interface{}) []interface{} {
+	for i, v := range args {
+		switch t := v.(type) {
+		case time.Time:
+			args[i] = t.UTC()
+		case *time.Time:
+			if t != nil {
+				args[i] = t.UTC()
+			}
+		}
+	}
+	return args
+}
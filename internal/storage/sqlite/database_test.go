@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+// setupDatabase open new database file removed when test finished
+func setupDatabase(t *testing.T) *Database {
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := NewDatabase(&config.SQLiteConfig{Path: filepath.Join(dir, "referral.db")})
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	})
+	return db
+}
+
+func TestNewDatabase(t *testing.T) {
+	assert.Panics(t, func() {
+		NewDatabase(nil)
+	})
+	assert.Panics(t, func() {
+		NewDatabase(&config.SQLiteConfig{Path: "/not/exist/referral.db"})
+	})
+
+	t.Run("open existing database", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "sqlite")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		cfg := &config.SQLiteConfig{Path: filepath.Join(dir, "referral.db")}
+
+		db := NewDatabase(cfg)
+		assert.Nil(t, SetupRewardRepository(db).Delete(context.Background(), 1))
+		assert.Nil(t, db.Close())
+
+		db = NewDatabase(cfg)
+		defer db.Close()
+		result, err := SetupRewardRepository(db).FindAll(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, result, 5)
+		assert.Equal(t, 0, result[0].Status)
+	})
+}
+
+func TestDatabase_WithinTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commit", func(t *testing.T) {
+		db := setupDatabase(t)
+		repo := SetupReferralCodeRepository(db)
+		err := db.WithinTransaction(ctx, func(ctx context.Context) error {
+			return repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+		})
+		assert.Nil(t, err)
+
+		result, err := repo.FindByCode(ctx, "ABCABC123A")
+		assert.Nil(t, err)
+		assert.Equal(t, "6280000011", result.Msisdn)
+	})
+	t.Run("rollback on error", func(t *testing.T) {
+		db := setupDatabase(t)
+		repo := SetupReferralCodeRepository(db)
+		err := db.WithinTransaction(ctx, func(ctx context.Context) error {
+			assert.Nil(t, repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"}))
+			return context.DeadlineExceeded
+		})
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		_, err = repo.FindByCode(ctx, "ABCABC123A")
+		assert.NotNil(t, err)
+	})
+	t.Run("rollback on panic", func(t *testing.T) {
+		db := setupDatabase(t)
+		repo := SetupReferralCodeRepository(db)
+		assert.Panics(t, func() {
+			_ = db.WithinTransaction(ctx, func(ctx context.Context) error {
+				assert.Nil(t, repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"}))
+				panic("failed")
+			})
+		})
+
+		_, err := repo.FindByCode(ctx, "ABCABC123A")
+		assert.NotNil(t, err)
+	})
+	t.Run("nested transaction join the outer", func(t *testing.T) {
+		db := setupDatabase(t)
+		repo := SetupReferralCodeRepository(db)
+		err := db.WithinTransaction(ctx, func(ctx context.Context) error {
+			err := db.WithinTransaction(ctx, func(ctx context.Context) error {
+				return repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+			})
+			assert.Nil(t, err)
+			return errors.New("failed")
+		})
+		assert.NotNil(t, err)
+
+		_, err = repo.FindByCode(ctx, "ABCABC123A")
+		assert.NotNil(t, err)
+	})
+	t.Run("concurrent transaction wait for each other", func(t *testing.T) {
+		db := setupDatabase(t)
+		repo := SetupReferralCodeRepository(db)
+		started := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- db.WithinTransaction(ctx, func(ctx context.Context) error {
+				close(started)
+				time.Sleep(100 * time.Millisecond)
+				return repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+			})
+		}()
+		<-started
+		err := db.WithinTransaction(ctx, func(ctx context.Context) error {
+			return repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000012", Code: "XYZXYZ123A"})
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, <-done)
+	})
+}
+
+func Test_rebind(t *testing.T) {
+	assert.Equal(t, "UPDATE main.outbox SET published_date = "+timestamp+" WHERE id = ?1 AND status = ?12",
+		rebind("UPDATE main.outbox SET published_date = NOW() WHERE id = $1 AND status = $12"))
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupNotificationPreferenceRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupNotificationPreferenceRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupNotificationPreferenceRepository(setupDatabase(t))
+	})
+}
+
+func Test_notificationPreferenceRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupNotificationPreferenceRepository(setupDatabase(t))
+
+	_, err := repo.FindByMsisdn(ctx, "6280000011")
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	assert.Nil(t, repo.Upsert(ctx, model.NotificationPreference{Msisdn: "6280000011", Language: "id"}))
+	first, err := repo.FindByMsisdn(ctx, "6280000011")
+	assert.Nil(t, err)
+
+	assert.Nil(t, repo.Upsert(ctx, model.NotificationPreference{Msisdn: "6280000011", Language: "en", OptOut: true}))
+	result, err := repo.FindByMsisdn(ctx, "6280000011")
+	assert.Nil(t, err)
+	assert.Equal(t, "en", result.Language)
+	assert.True(t, result.OptOut)
+	assert.Equal(t, first.CreatedDate, result.CreatedDate)
+}
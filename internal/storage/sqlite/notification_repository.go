@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type notificationRepository struct {
+	db *Database
+}
+
+func SetupNotificationRepository(db *Database) *notificationRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+const (
+	queryNotificationFindDue = `SELECT id, event_id, msisdn, template, message, status, attempt, next_attempt_date, 
+								COALESCE(last_error, '') AS last_error, created_date, updated_date FROM notification 
+								WHERE status = 0 AND next_attempt_date <= NOW() ORDER BY next_attempt_date, id LIMIT $1`
+)
+
+// Insert queue notification, notification of the same event and msisdn is ignored
+func (r notificationRepository) Insert(ctx context.Context, notification *model.Notification) error {
+	ID, err := r.db.insert(ctx, fmt.Sprintf(query.NotificationInsert, r.db.SchemaName()),
+		notification.EventID, notification.Msisdn, notification.Template, notification.Message, notification.NextAttemptDate)
+	if err != nil {
+		return err
+	}
+	if ID > 0 {
+		notification.ID = ID
+	}
+	return nil
+}
+
+// ClaimDue return pending notification whose next attempt already passed, earliest first, and postpone it by lease.
+// Transaction take the write lock when it begins, so other instance wait instead of claiming the same notification
+func (r notificationRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.Notification, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.db.conn(ctx).SelectContext(ctx, &result, queryNotificationFindDue, limit); err != nil || len(result) == 0 {
+			return err
+		}
+		IDs := make([]int64, len(result))
+		until := time.Now().Add(lease)
+		for i := range result {
+			IDs[i] = result[i].ID
+			result[i].NextAttemptDate = until
+		}
+		return r.db.postpone(ctx, "notification", IDs, until)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r notificationRepository) UpdateResult(ctx context.Context, notification model.Notification) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.NotificationUpdateResult, r.db.SchemaName()), notification.Status,
+		notification.Attempt, notification.NextAttemptDate, notification.LastError, notification.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
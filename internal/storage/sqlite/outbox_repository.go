@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type outboxRepository struct {
+	db *Database
+}
+
+func SetupOutboxRepository(db *Database) *outboxRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+const (
+	queryOutboxFindDue = `SELECT id, event_id, event, payload, status, attempt, next_attempt_date, 
+						  COALESCE(last_error, '') AS last_error, created_date FROM outbox 
+						  WHERE status = 0 AND next_attempt_date <= NOW() ORDER BY id LIMIT $1`
+)
+
+// Insert store event, it joins transaction of ctx so event is stored only when the change producing it is committed
+func (r outboxRepository) Insert(ctx context.Context, event *model.OutboxEvent) (err error) {
+	event.ID, err = r.db.insert(ctx, fmt.Sprintf(query.OutboxInsert, r.db.SchemaName()),
+		event.EventID, event.Event, event.Payload)
+	if err != nil {
+		return err
+	}
+	if event.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+// ClaimDue return pending event whose next attempt already passed in the order they are written,
+// and postpone it by lease. Transaction take the write lock when it begins, so other instance wait instead of
+// claiming the same event
+func (r outboxRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.OutboxEvent, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.db.conn(ctx).SelectContext(ctx, &result, queryOutboxFindDue, limit); err != nil || len(result) == 0 {
+			return err
+		}
+		IDs := make([]int64, len(result))
+		until := time.Now().Add(lease)
+		for i := range result {
+			IDs[i] = result[i].ID
+			result[i].NextAttemptDate = until
+		}
+		return r.db.postpone(ctx, "outbox", IDs, until)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r outboxRepository) MarkPublished(ctx context.Context, ID int64) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.OutboxMarkPublished, r.db.SchemaName()), ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// UpdateAttempt store failed attempt of pending event and when it is published again
+func (r outboxRepository) UpdateAttempt(ctx context.Context, event model.OutboxEvent) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.OutboxUpdateAttempt, r.db.SchemaName()), event.Attempt,
+		event.NextAttemptDate, event.LastError, event.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// DeletePublished remove event published before the time, pending event is kept
+func (r outboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.OutboxDeletePublished, r.db.SchemaName()), before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type referralClickRepository struct {
+	db *Database
+}
+
+func SetupReferralClickRepository(db *Database) *referralClickRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &referralClickRepository{
+		db: db,
+	}
+}
+
+const (
+	// referral is counted the same way as reward, code has neither click nor referral in the range is not listed.
+	// sqlite has no FULL JOIN so code of both is collected first
+	queryClickStatistic = `WITH click AS (
+							   SELECT code, COUNT(id) AS total FROM referral_click 
+							   WHERE created_date >= $1 AND created_date < $2%s GROUP BY code
+						   ), referral AS (
+							   SELECT code, COUNT(id) AS total FROM referral_history 
+							   WHERE referral_date BETWEEN $3 AND $4 AND flagged = false AND status IN (1, 3)%s GROUP BY code
+						   ), codes AS (
+							   SELECT code FROM click UNION SELECT code FROM referral
+						   )
+						   SELECT k.code, COALESCE(rc.msisdn, '') AS msisdn, 
+						   COALESCE(c.total, 0) AS total_click, COALESCE(h.total, 0) AS total_referral 
+						   FROM codes k LEFT JOIN click c ON c.code = k.code LEFT JOIN referral h ON h.code = k.code
+						   LEFT JOIN referral_code rc ON UPPER(rc.code) = UPPER(k.code)
+						   ORDER BY 3 DESC, 4 DESC, 1 LIMIT $5`
+)
+
+func (r referralClickRepository) Insert(ctx context.Context, click *model.ReferralClick) (err error) {
+	click.ID, err = r.db.insert(ctx, fmt.Sprintf(query.ClickInsert, r.db.SchemaName()), click.Code, click.Channel,
+		click.UserAgent)
+	if err != nil {
+		return err
+	}
+	if click.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+// GetStatistic return click and counted referral of each code between filter.From and filter.To, most clicked first
+func (r referralClickRepository) GetStatistic(ctx context.Context, filter model.ReferralClickFilter) (result []model.ReferralClickStatistic, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// click time is stored in UTC, date of the range is start of the day in local time
+	from, err := time.ParseInLocation("2006-01-02", filter.From, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.ParseInLocation("2006-01-02", filter.To, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	var condition string
+	args := []interface{}{from, to.AddDate(0, 0, 1), filter.From, filter.To, filter.Limit}
+	if filter.Code != "" {
+		args = append(args, filter.Code)
+		condition = fmt.Sprintf(" AND UPPER(code) = UPPER($%d)", len(args))
+	}
+	err = r.db.conn(ctx).SelectContext(ctx, &result, fmt.Sprintf(queryClickStatistic, condition, condition), args...)
+	return result, err
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type referralCodeRepository struct {
+	db *Database
+}
+
+func SetupReferralCodeRepository(db *Database) *referralCodeRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &referralCodeRepository{
+		db: db,
+	}
+}
+
+const (
+	// MIN of sqlite is NULL when any argument is NULL, unlike LEAST of postgresql
+	queryReferralCodeRetire = `UPDATE %s.referral_code SET status = 2, expires_at = MIN(COALESCE(expires_at, $1), $1) 
+									 WHERE UPPER(code) = UPPER($2) AND status <> 2`
+
+	// unique index of code, case insensitive
+	referralCodeUniqueIndex = "referral_code_idx"
+)
+
+func (r referralCodeRepository) FindByMsisdn(ctx context.Context, msisdn string) (result model.ReferralCode, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.ReferralCodeFindByMsisdn, msisdn)
+	if err != nil {
+		return model.ReferralCode{}, err
+	}
+	if result.Status == 0 {
+		return model.ReferralCode{}, util.ErrorDataNotFound
+	}
+	return result, nil
+}
+
+func (r referralCodeRepository) FindByCode(ctx context.Context, code string) (result model.ReferralCode, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.ReferralCodeFindByCode, code)
+	if err != nil {
+		return model.ReferralCode{}, err
+	}
+	if result.Status == 0 {
+		return model.ReferralCode{}, util.ErrorDataNotFound
+	}
+	return result, nil
+}
+
+func (r referralCodeRepository) FindAllByMsisdn(ctx context.Context, msisdn string) (result []model.ReferralCode, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.ReferralCodeFindAll, msisdn)
+	return result, err
+}
+
+func (r referralCodeRepository) Insert(ctx context.Context, code *model.ReferralCode) (err error) {
+	code.ID, err = r.db.insert(ctx, fmt.Sprintf(query.ReferralCodeInsert, r.db.SchemaName()), code.Msisdn, code.Code)
+	if isUniqueViolation(err, referralCodeUniqueIndex) {
+		return util.ErrorReferralCodeUnavailable
+	}
+	if err != nil {
+		return err
+	}
+	if code.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+// UpdateStatus change status of code, retired code is never changed
+func (r referralCodeRepository) UpdateStatus(ctx context.Context, code string, status int) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.ReferralCodeUpdateStatus, r.db.SchemaName()), status, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r referralCodeRepository) UpdateExpiry(ctx context.Context, code string, expiresAt *time.Time) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.ReferralCodeUpdateExpiry, r.db.SchemaName()), expiresAt, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// Retire mark code as replaced by newer code, code still resolvable until expiresAt
+// unless it already has earlier expiry
+func (r referralCodeRepository) Retire(ctx context.Context, code string, expiresAt time.Time) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(queryReferralCodeRetire, r.db.SchemaName()), expiresAt, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r referralCodeRepository) UpdateCooldown(ctx context.Context, code string, cooldownUntil time.Time) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.ReferralCodeUpdateCooldown, r.db.SchemaName()), cooldownUntil, code)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupReferralCodeRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReferralCodeRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupReferralCodeRepository(setupDatabase(t))
+	})
+}
+
+func Test_referralCodeRepository_Insert(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupReferralCodeRepository(setupDatabase(t))
+
+	code := &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A", Status: model.ReferralCodeStatusInactive}
+	assert.Nil(t, repo.Insert(ctx, code))
+	assert.Equal(t, int64(1), code.ID)
+
+	t.Run("duplicate code regardless of case", func(t *testing.T) {
+		err := repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000012", Code: "abcabc123a"})
+		assert.Equal(t, util.ErrorReferralCodeUnavailable, err)
+	})
+	t.Run("msisdn already has code", func(t *testing.T) {
+		err := repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "XYZXYZ123A"})
+		assert.NotNil(t, err)
+	})
+	t.Run("msisdn with retired code", func(t *testing.T) {
+		assert.Nil(t, repo.Retire(ctx, "ABCABC123A", time.Now()))
+		assert.Nil(t, repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "XYZXYZ123A"}))
+	})
+}
+
+func Test_referralCodeRepository_Find(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupReferralCodeRepository(setupDatabase(t))
+	_ = repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+	_ = repo.Retire(ctx, "ABCABC123A", time.Now().Add(time.Hour))
+	_ = repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "XYZXYZ123A"})
+	_ = repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000012", Code: "DEFDEF123A"})
+	_ = repo.UpdateStatus(ctx, "DEFDEF123A", model.ReferralCodeStatusInactive)
+
+	t.Run("FindByMsisdn skip retired code", func(t *testing.T) {
+		result, err := repo.FindByMsisdn(ctx, "6280000011")
+		assert.Nil(t, err)
+		assert.Equal(t, "XYZXYZ123A", result.Code)
+	})
+	t.Run("FindByMsisdn inactive code", func(t *testing.T) {
+		_, err := repo.FindByMsisdn(ctx, "6280000012")
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("FindByMsisdn not found", func(t *testing.T) {
+		_, err := repo.FindByMsisdn(ctx, "6280000013")
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("FindByCode regardless of case", func(t *testing.T) {
+		result, err := repo.FindByCode(ctx, "abcabc123a")
+		assert.Nil(t, err)
+		assert.Equal(t, model.ReferralCodeStatusRetired, result.Status)
+		assert.NotNil(t, result.ExpiresAt)
+	})
+	t.Run("FindByCode inactive code", func(t *testing.T) {
+		_, err := repo.FindByCode(ctx, "DEFDEF123A")
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("FindAllByMsisdn newest first", func(t *testing.T) {
+		result, err := repo.FindAllByMsisdn(ctx, "6280000011")
+		assert.Nil(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "XYZXYZ123A", result[0].Code)
+		assert.Equal(t, "ABCABC123A", result[1].Code)
+	})
+}
+
+func Test_referralCodeRepository_Update(t *testing.T) {
+	ctx := context.Background()
+	repo := SetupReferralCodeRepository(setupDatabase(t))
+	_ = repo.Insert(ctx, &model.ReferralCode{Msisdn: "6280000011", Code: "ABCABC123A"})
+
+	t.Run("code not found", func(t *testing.T) {
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateStatus(ctx, "XYZXYZ123A", model.ReferralCodeStatusActive))
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateCooldown(ctx, "XYZXYZ123A", time.Now()))
+	})
+	t.Run("update expiry and cooldown", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		assert.Nil(t, repo.UpdateExpiry(ctx, "ABCABC123A", &expiresAt))
+		assert.Nil(t, repo.UpdateCooldown(ctx, "ABCABC123A", expiresAt))
+
+		result, _ := repo.FindByCode(ctx, "ABCABC123A")
+		assert.True(t, expiresAt.Equal(*result.ExpiresAt))
+		assert.True(t, result.InCooldown(time.Now()))
+	})
+	t.Run("update code regardless of its case", func(t *testing.T) {
+		assert.Nil(t, repo.UpdateStatus(ctx, "abcabc123a", model.ReferralCodeStatusInactive))
+		_, err := repo.FindByCode(ctx, "ABCABC123A")
+		assert.Equal(t, util.ErrorDataNotFound, err)
+
+		assert.Nil(t, repo.UpdateStatus(ctx, "AbcAbc123a", model.ReferralCodeStatusActive))
+		_, err = repo.FindByCode(ctx, "ABCABC123A")
+		assert.Nil(t, err)
+	})
+	t.Run("retire keep earlier expiry", func(t *testing.T) {
+		assert.Nil(t, repo.Retire(ctx, "ABCABC123A", time.Now().Add(2*time.Hour)))
+
+		result, _ := repo.FindByCode(ctx, "ABCABC123A")
+		assert.True(t, result.ExpiresAt.Before(time.Now().Add(time.Hour+time.Minute)))
+	})
+	t.Run("retire retired code", func(t *testing.T) {
+		assert.Equal(t, util.ErrorDataNotFound, repo.Retire(ctx, "ABCABC123A", time.Now()))
+	})
+	t.Run("update status of retired code", func(t *testing.T) {
+		assert.Equal(t, util.ErrorDataNotFound, repo.UpdateStatus(ctx, "ABCABC123A", model.ReferralCodeStatusActive))
+
+		result, _ := repo.FindByCode(ctx, "ABCABC123A")
+		assert.Equal(t, model.ReferralCodeStatusRetired, result.Status)
+	})
+}
@@ -0,0 +1,283 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type referralHistoryRepository struct {
+	db *Database
+}
+
+func SetupReferralHistoryRepository(db *Database) *referralHistoryRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &referralHistoryRepository{
+		db: db,
+	}
+}
+
+const (
+	// total of each referrer is counted per month, referrer active in two months is counted in both.
+	// sqlite has no LATERAL join so reward of each referrer is looked up by subquery
+	queryHistoryTierDistribution = `WITH referrer AS (
+										SELECT SUBSTR(referral_date, 1, 7) AS month, msisdn, COUNT(id) AS total 
+										FROM referral_history WHERE referral_date BETWEEN $1 AND $2 AND flagged = false 
+										AND status IN (1, 3) GROUP BY SUBSTR(referral_date, 1, 7), msisdn
+									), tier AS (
+										SELECT (
+											SELECT id FROM reward WHERE total_referral <= f.total AND campaign_id IS NULL 
+											AND level = 1 AND status = 1 ORDER BY total_referral DESC LIMIT 1
+										) AS reward_id FROM referrer f
+									)
+									SELECT COALESCE(r.id, 0) AS reward_id, COALESCE(r.total_referral, 0) AS total_referral, 
+									COALESCE(r.reward_description, '') AS reward, COUNT(1) AS total_referrer FROM tier t
+									LEFT JOIN reward r ON r.id = t.reward_id 
+									GROUP BY r.id, r.total_referral, r.reward_description ORDER BY 2`
+	// running total is counted from the start of month so tier at the time is correct when range start mid month
+	queryHistoryExport = `WITH history AS (
+							  SELECT id, msisdn, code, msisdn_referee, referral_date, campaign_id, status, flagged, created_date,
+							  COUNT(id) FILTER (WHERE flagged = false AND status IN (1, 3)) 
+							  OVER (PARTITION BY msisdn, SUBSTR(referral_date, 1, 7) ORDER BY id) AS month_total
+							  FROM referral_history WHERE referral_date >= $1%s
+						  )
+						  SELECT h.id, h.msisdn, h.code, COALESCE(c.status, 0) AS code_status, h.msisdn_referee, h.referral_date,
+						  COALESCE(h.campaign_id, 0) AS campaign_id, h.status, h.flagged, h.month_total, 
+						  COALESCE((
+							  SELECT reward_description FROM reward WHERE total_referral <= h.month_total 
+							  AND campaign_id IS NULL AND level = 1 AND status = 1 ORDER BY total_referral DESC LIMIT 1
+						  ), '') AS reward, h.created_date FROM history h
+						  LEFT JOIN referral_code c ON UPPER(c.code) = UPPER(h.code)
+						  WHERE h.referral_date >= $2%s ORDER BY h.id`
+	queryHistoryInsertBatch = `INSERT INTO %s.referral_history (msisdn, code, referral_date, msisdn_referee, campaign_id, 
+								status, confirmed_date, created_date) VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, $7, $8)`
+)
+
+func (r referralHistoryRepository) FindByMsisdn(ctx context.Context, msisdn string, offset, limit int) (result []model.ReferralHistory, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.HistoryFindByMsisdn, msisdn, limit, offset)
+	return result, err
+}
+
+func (r referralHistoryRepository) CountByMsisdn(ctx context.Context, msisdn string) (total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &total, query.HistoryCountByMsisdn, msisdn)
+	return total, err
+}
+
+func (r referralHistoryRepository) FindByMsisdnReferee(ctx context.Context, msisdnReferee string) (result model.ReferralHistory, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.HistoryFindByReferee, msisdnReferee)
+	return result, err
+}
+
+func (r referralHistoryRepository) GetTotalByMsisdnAndMonth(ctx context.Context, msisdn, month string) (total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &total, query.HistoryTotalMonthByMsisdn, msisdn, month+"%")
+	return total, err
+}
+
+func (r referralHistoryRepository) GetTotalByMsisdnAndCampaign(ctx context.Context, msisdn string, campaignID int64) (total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &total, query.HistoryTotalCampaignByMsisdn, msisdn, campaignID)
+	return total, err
+}
+
+// GetTotalPerLevelByMsisdnAndMonth count referral made in month by each level of msisdn downline,
+// level without referral is not returned
+func (r referralHistoryRepository) GetTotalPerLevelByMsisdnAndMonth(ctx context.Context, msisdn, month string, maxLevel int) (result []model.ReferralLevelTotal, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.HistoryTotalMonthPerLevel, msisdn, maxLevel, month+"%")
+	return result, err
+}
+
+func (r referralHistoryRepository) FindDownline(ctx context.Context, msisdn string, maxLevel int) (result []model.ReferralDownline, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.HistoryFindDownline, msisdn, maxLevel)
+	return result, err
+}
+
+// CountVelocity count referral matching filter, flagged referral is counted as well
+func (r referralHistoryRepository) CountVelocity(ctx context.Context, filter model.ReferralVelocityFilter) (total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	q, args := query.HistoryCountVelocity(filter)
+	err = r.db.conn(ctx).GetContext(ctx, &total, q, args...)
+	return total, err
+}
+
+// FindTopReferrer return referrer with most counted referral in month
+func (r referralHistoryRepository) FindTopReferrer(ctx context.Context, month string, limit int) (result []model.ReferralRank, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.HistoryTopReferrer, month+"%", limit)
+	return result, err
+}
+
+func (r referralHistoryRepository) GetRankByMsisdnAndMonth(ctx context.Context, msisdn, month string) (result model.ReferralRank, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.HistoryRankByMsisdn, month+"%", msisdn)
+	return result, err
+}
+
+// statisticPeriod is period expression of each report interval
+var statisticPeriod = map[string]string{
+	model.ReportIntervalDay:   "referral_date",
+	model.ReportIntervalWeek:  "DATE(referral_date, 'weekday 0', '-6 days')",
+	model.ReportIntervalMonth: "SUBSTR(referral_date, 1, 7)",
+}
+
+// GetStatistic count referral and distinct referrer of each period between from and to (inclusive),
+// period without referral is not returned
+func (r referralHistoryRepository) GetStatistic(ctx context.Context, from, to, interval string) (result []model.ReferralStatistic, err error) {
+	period, ok := statisticPeriod[interval]
+	if !ok {
+		return nil, util.ErrorInvalidRequest
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, fmt.Sprintf(query.HistoryStatistic, period), from, to)
+	return result, err
+}
+
+func (r referralHistoryRepository) GetTierDistribution(ctx context.Context, from, to string) (result []model.ReferralTierDistribution, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, queryHistoryTierDistribution, from, to)
+	return result, err
+}
+
+// StreamExport call fn for each referral matching filter ordered by id, rows are read one by one so the result
+// is never loaded at once. Error returned by fn stop the export.
+func (r referralHistoryRepository) StreamExport(ctx context.Context, filter model.ReferralExportFilter, fn func(model.ReferralExport) error) error {
+	var monthStart string
+	if len(filter.From) >= 7 {
+		monthStart = filter.From[:7]
+	}
+
+	inner := strings.Builder{}
+	outer := strings.Builder{}
+	args := []interface{}{monthStart, filter.From}
+	if filter.Msisdn != "" {
+		args = append(args, filter.Msisdn)
+		inner.WriteString(fmt.Sprintf(" AND msisdn = $%d", len(args)))
+	}
+	if filter.To != "" {
+		args = append(args, filter.To)
+		outer.WriteString(fmt.Sprintf(" AND h.referral_date <= $%d", len(args)))
+	}
+	if filter.Code != "" {
+		args = append(args, filter.Code)
+		outer.WriteString(fmt.Sprintf(" AND UPPER(h.code) = UPPER($%d)", len(args)))
+	}
+
+	rows, err := r.db.conn(ctx).QueryxContext(ctx, fmt.Sprintf(queryHistoryExport, inner.String(), outer.String()), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row model.ReferralExport
+		if err = rows.StructScan(&row); err != nil {
+			return err
+		}
+		if err = fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r referralHistoryRepository) Insert(ctx context.Context, referral *model.ReferralHistory) (err error) {
+	referral.ID, err = r.db.insert(ctx, fmt.Sprintf(query.HistoryInsert, r.db.SchemaName()), referral.Msisdn,
+		referral.Code, referral.ReferralDate, referral.MsisdnReferee, referral.CampaignID, referral.IPAddress,
+		referral.DeviceID, referral.Flagged, referral.FlagReason, referral.Status)
+	if err != nil {
+		return err
+	}
+	if referral.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+// Confirm change pending referral to confirmed, referral in other status is not found
+func (r referralHistoryRepository) Confirm(ctx context.Context, ID int64) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.HistoryConfirm, r.db.SchemaName()), ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// InsertBatch insert referrals in single transaction, used to import referral in bulk
+func (r referralHistoryRepository) InsertBatch(ctx context.Context, referrals []model.ReferralHistory) error {
+	return r.db.WithinTransaction(ctx, func(ctx context.Context) error {
+		q := fmt.Sprintf(queryHistoryInsertBatch, r.db.SchemaName())
+		for _, v := range referrals {
+			if _, err := r.db.conn(ctx).ExecContext(ctx, q, v.Msisdn, v.Code, v.ReferralDate, v.MsisdnReferee, v.CampaignID,
+				v.Status, v.ConfirmedDate, v.CreatedDate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Reverse mark referral as reversed, reversed referral is not counted anymore
+func (r referralHistoryRepository) Reverse(ctx context.Context, ID int64, reason string) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.HistoryReverse, r.db.SchemaName()), reason, ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// MarkRewarded change confirmed referral of msisdn in month to rewarded, return total referral changed
+func (r referralHistoryRepository) MarkRewarded(ctx context.Context, msisdn, month string) (total int64, err error) {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.HistoryMarkRewarded, r.db.SchemaName()), msisdn, month+"%")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ExpirePending change pending referral created before given time to expired, return total referral expired
+func (r referralHistoryRepository) ExpirePending(ctx context.Context, before time.Time) (total int64, err error) {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.HistoryExpirePending, r.db.SchemaName()), before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type rewardClaimRepository struct {
+	db *Database
+}
+
+func SetupRewardClaimRepository(db *Database) *rewardClaimRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &rewardClaimRepository{
+		db: db,
+	}
+}
+
+func (r rewardClaimRepository) FindByMsisdn(ctx context.Context, msisdn string) (result []model.RewardClaim, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.ClaimFindByMsisdn, msisdn)
+	return result, err
+}
+
+func (r rewardClaimRepository) FindByMsisdnRewardAndPeriod(ctx context.Context, msisdn string, rewardID int64, period string) (result model.RewardClaim, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.ClaimFindByMsisdnRewardAndPeriod, msisdn, rewardID, period)
+	return result, err
+}
+
+func (r rewardClaimRepository) Insert(ctx context.Context, claim *model.RewardClaim) (err error) {
+	claim.ID, err = r.db.insert(ctx, fmt.Sprintf(query.ClaimInsert, r.db.SchemaName()), claim.Msisdn,
+		claim.RewardID, claim.TotalReferral, claim.Description, claim.Period, claim.Level)
+	if isUniqueViolation(err, "") {
+		return util.ErrorRewardAlreadyClaimed
+	}
+	if err != nil {
+		return err
+	}
+	if claim.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+func (r rewardClaimRepository) UpdateStatus(ctx context.Context, ID int64, status int) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.ClaimUpdateStatus, r.db.SchemaName()), status, ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
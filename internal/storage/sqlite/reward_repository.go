@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type rewardRepository struct {
+	db *Database
+}
+
+func SetupRewardRepository(db *Database) *rewardRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &rewardRepository{
+		db: db,
+	}
+}
+
+const (
+	// NULL is sorted first by sqlite, reward without campaign is put last the same as postgresql
+	queryRewardFindAll = `SELECT id, total_referral, reward_description, COALESCE(campaign_id, 0) AS campaign_id, level, status,
+						  created_date, updated_date FROM reward 
+						  ORDER BY campaign_id IS NULL, campaign_id ASC, level ASC, total_referral ASC, id ASC`
+)
+
+func (r rewardRepository) FindAll(ctx context.Context) (result []model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, queryRewardFindAll)
+	return result, err
+}
+
+func (r rewardRepository) FindByID(ctx context.Context, ID int64) (result model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.RewardFindByID, ID)
+	return result, err
+}
+
+func (r rewardRepository) FindByTotalReferral(ctx context.Context, totalReferral int) (result model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.RewardFindByTotalReferral, totalReferral)
+	return result, err
+}
+
+func (r rewardRepository) FindByLevelAndTotalReferral(ctx context.Context, level, totalReferral int) (result model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.RewardFindByLevelAndTotalReferral, totalReferral, level)
+	return result, err
+}
+
+func (r rewardRepository) FindByCampaignAndTotalReferral(ctx context.Context, campaignID int64, totalReferral int) (result model.Reward, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.RewardFindByCampaignAndTotalReferral, totalReferral, campaignID)
+	return result, err
+}
+
+func (r rewardRepository) Insert(ctx context.Context, model *model.Reward) (err error) {
+	model.ID, err = r.db.insert(ctx, fmt.Sprintf(query.RewardInsert, r.db.SchemaName()), model.TotalReferral, model.Description,
+		model.CampaignID, model.Level)
+	if err != nil {
+		return err
+	}
+	if model.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+func (r rewardRepository) Update(ctx context.Context, model model.Reward) (err error) {
+	qb := strings.Builder{}
+	qb.WriteString("UPDATE ")
+	qb.WriteString(r.db.SchemaName())
+	qb.WriteString(".reward SET ")
+
+	var cols []string
+	var args []interface{}
+	if model.TotalReferral > 0 {
+		args = append(args, model.TotalReferral)
+		cols = append(cols, fmt.Sprintf("total_referral=$%d", len(args)))
+	}
+	if model.Description != "" {
+		args = append(args, model.Description)
+		cols = append(cols, fmt.Sprintf("reward_description=$%d", len(args)))
+	}
+	cols = append(cols, "updated_date=NOW()")
+
+	qb.WriteString(strings.Join(cols, ","))
+
+	args = append(args, model.ID)
+	qb.WriteString(fmt.Sprintf(" WHERE id=$%d", len(args)))
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, qb.String(), args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r rewardRepository) Delete(ctx context.Context, ID int64) (err error) {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.RewardSoftDelete, r.db.SchemaName()), ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupRewardRepository(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardRepository(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRewardRepository(setupDatabase(t))
+	})
+}
+
+func Test_rewardRepository(t *testing.T) {
+	ctx := context.Background()
+	db := setupDatabase(t)
+	repo := SetupRewardRepository(db)
+	// default reward 1 to 5 is created with the schema
+	assert.Nil(t, SetupCampaignRepository(db).Insert(ctx, &model.Campaign{Name: "august", StartDate: "2021-08-01",
+		EndDate: "2021-08-31"}))
+	for _, v := range []model.Reward{
+		{TotalReferral: 3, Description: "bonus campaign", CampaignID: 1, Level: 1},
+		{TotalReferral: 2, Description: "bonus 5 GB", Level: 1},
+	} {
+		reward := v
+		assert.Nil(t, repo.Insert(ctx, &reward))
+	}
+	assert.Nil(t, repo.Delete(ctx, 7))
+
+	t.Run("FindAll list reward without campaign last", func(t *testing.T) {
+		result, err := repo.FindAll(ctx)
+		assert.Nil(t, err)
+		var ids []int64
+		for _, v := range result {
+			ids = append(ids, v.ID)
+		}
+		assert.Equal(t, []int64{6, 1, 7, 2, 3, 4, 5}, ids)
+	})
+	t.Run("FindByLevelAndTotalReferral highest tier reached, deleted is skipped", func(t *testing.T) {
+		result, err := repo.FindByTotalReferral(ctx, 4)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), result.ID)
+
+		result, err = repo.FindByLevelAndTotalReferral(ctx, 2, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, "bonus 3 GB", result.Description)
+
+		_, err = repo.FindByTotalReferral(ctx, 0)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("FindByCampaignAndTotalReferral", func(t *testing.T) {
+		result, err := repo.FindByCampaignAndTotalReferral(ctx, 1, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(6), result.ID)
+
+		_, err = repo.FindByCampaignAndTotalReferral(ctx, 1, 2)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+	t.Run("Update keep empty value", func(t *testing.T) {
+		assert.Nil(t, repo.Update(ctx, model.Reward{ID: 2, Description: "bonus 15 GB"}))
+		result, err := repo.FindByID(ctx, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, result.TotalReferral)
+		assert.Equal(t, "bonus 15 GB", result.Description)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.FindByID(ctx, 9)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Equal(t, util.ErrorDataNotFound, repo.Update(ctx, model.Reward{ID: 9}))
+		assert.Equal(t, util.ErrorDataNotFound, repo.Delete(ctx, 9))
+	})
+}
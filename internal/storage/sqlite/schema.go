@@ -0,0 +1,205 @@
+package sqlite
+
+// schema is postgresql migration written for sqlite, it is run every time database is opened so every statement
+// must be safe to run again. Time is stored as text in UTC so it is ordered the same way as the time.
+const schema = `
+CREATE TABLE IF NOT EXISTS referral_code
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    msisdn VARCHAR(20) NOT NULL,
+    code VARCHAR(20) NOT NULL,
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    expires_at TIMESTAMP,
+    status INTEGER NOT NULL DEFAULT 1,
+    cooldown_until TIMESTAMP
+);
+-- retired code is kept as history, only one non retired code allowed per msisdn
+CREATE UNIQUE INDEX IF NOT EXISTS referral_code_msisdn_idx ON referral_code(msisdn) WHERE status <> 2;
+-- code is unique regardless of its case
+CREATE UNIQUE INDEX IF NOT EXISTS referral_code_idx ON referral_code(UPPER(code));
+
+
+CREATE TABLE IF NOT EXISTS campaign
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    start_date VARCHAR(10) NOT NULL,
+    end_date VARCHAR(10) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 1,
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+CREATE INDEX IF NOT EXISTS campaign_date_idx ON campaign(start_date, end_date);
+
+
+CREATE TABLE IF NOT EXISTS reward
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    total_referral INTEGER NOT NULL,
+    reward_description VARCHAR(100) NOT NULL,
+    campaign_id INTEGER REFERENCES campaign(id),
+    -- level of downline earning the reward, 1 is direct referral
+    level INTEGER NOT NULL DEFAULT 1,
+    status INTEGER NOT NULL DEFAULT 1,
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+-- default reward is only created with the table
+INSERT INTO reward (total_referral, reward_description, level)
+SELECT total_referral, reward_description, level FROM (
+    SELECT 1 AS total_referral, 'bonus 2 GB' AS reward_description, 1 AS level
+    UNION ALL SELECT 5, 'bonus 12 GB', 1
+    UNION ALL SELECT 6, 'bonus 20 GB', 1
+    UNION ALL SELECT 1, 'bonus 500 MB', 2
+    UNION ALL SELECT 5, 'bonus 3 GB', 2
+) WHERE NOT EXISTS (SELECT 1 FROM reward);
+
+
+CREATE TABLE IF NOT EXISTS referral_history
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    msisdn VARCHAR(20) NOT NULL,
+    code VARCHAR(20) NOT NULL,
+    msisdn_referee VARCHAR(20) NOT NULL,
+    referral_date VARCHAR(10) NOT NULL,
+    campaign_id INTEGER,
+    ip_address VARCHAR(45),
+    device_id VARCHAR(100),
+    -- flagged by velocity rule, not counted for reward until reviewed
+    flagged BOOLEAN NOT NULL DEFAULT false,
+    flag_reason VARCHAR(50),
+    -- 0 pending, 1 confirmed, 2 reversed, 3 rewarded, 4 expired
+    status INTEGER NOT NULL DEFAULT 0,
+    confirmed_date TIMESTAMP,
+    reversal_reason VARCHAR(30),
+    reversed_date TIMESTAMP,
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+CREATE INDEX IF NOT EXISTS referral_history_msisdn_idx ON referral_history(msisdn);
+CREATE INDEX IF NOT EXISTS referral_history_code_idx ON referral_history(code, created_date);
+CREATE INDEX IF NOT EXISTS referral_history_ip_address_idx ON referral_history(ip_address, created_date);
+CREATE INDEX IF NOT EXISTS referral_history_device_id_idx ON referral_history(device_id, created_date);
+CREATE INDEX IF NOT EXISTS referral_history_msisdn_referee_idx ON referral_history(msisdn_referee);
+CREATE INDEX IF NOT EXISTS referral_history_msisdn_campaign_idx ON referral_history(msisdn, campaign_id);
+CREATE INDEX IF NOT EXISTS referral_history_pending_idx ON referral_history(created_date) WHERE status = 0;
+-- counted referral only, used by report aggregate
+CREATE INDEX IF NOT EXISTS referral_history_report_idx ON referral_history(referral_date, msisdn)
+    WHERE flagged = false AND status IN (1, 3);
+
+
+CREATE TABLE IF NOT EXISTS referral_click
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(20) NOT NULL,
+    channel VARCHAR(20),
+    user_agent VARCHAR(255),
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+CREATE INDEX IF NOT EXISTS referral_click_code_idx ON referral_click(code, created_date);
+CREATE INDEX IF NOT EXISTS referral_click_created_date_idx ON referral_click(created_date);
+
+
+CREATE TABLE IF NOT EXISTS reward_claim
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    msisdn VARCHAR(20) NOT NULL,
+    reward_id INTEGER NOT NULL,
+    total_referral INTEGER NOT NULL,
+    reward_description VARCHAR(100) NOT NULL,
+    period VARCHAR(7) NOT NULL,
+    level INTEGER NOT NULL DEFAULT 1,
+    -- 1 claimed, 2 clawback, referral reversed and tier is no longer reached
+    status INTEGER NOT NULL DEFAULT 1,
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS reward_claim_msisdn_reward_period_idx ON reward_claim(msisdn, reward_id, period);
+
+
+CREATE TABLE IF NOT EXISTS webhook_subscription
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url VARCHAR(255) NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    -- comma separated event e.g. referral.created,referral.reversed
+    events VARCHAR(200) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 1,
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+
+CREATE TABLE IF NOT EXISTS webhook_delivery
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscription(id),
+    -- same event delivered to many subscription share event id, receiver use it to drop duplicate
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    payload TEXT NOT NULL,
+    -- 0 pending, 1 delivered, 2 dead
+    status INTEGER NOT NULL DEFAULT 0,
+    attempt INTEGER NOT NULL DEFAULT 0,
+    next_attempt_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    response_code INTEGER,
+    last_error VARCHAR(255),
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    -- relay publish event at least once, same event is queued once per subscription
+    CONSTRAINT webhook_delivery_event_key UNIQUE (subscription_id, event_id)
+);
+CREATE INDEX IF NOT EXISTS webhook_delivery_pending_idx ON webhook_delivery(next_attempt_date) WHERE status = 0;
+CREATE INDEX IF NOT EXISTS webhook_delivery_status_idx ON webhook_delivery(status, id);
+
+
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    -- dedup id of event, it does not change when event is published again
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    payload TEXT NOT NULL,
+    -- 0 pending, 1 published
+    status INTEGER NOT NULL DEFAULT 0,
+    attempt INTEGER NOT NULL DEFAULT 0,
+    next_attempt_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    last_error VARCHAR(255),
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    published_date TIMESTAMP,
+    CONSTRAINT outbox_event_key UNIQUE (event_id)
+);
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox(next_attempt_date) WHERE status = 0;
+CREATE INDEX IF NOT EXISTS outbox_published_idx ON outbox(published_date) WHERE status = 1;
+
+
+CREATE TABLE IF NOT EXISTS notification_preference
+(
+    msisdn VARCHAR(20) NOT NULL PRIMARY KEY,
+    -- id or en
+    language VARCHAR(2) NOT NULL DEFAULT 'id',
+    opt_out BOOLEAN NOT NULL DEFAULT false,
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+
+CREATE TABLE IF NOT EXISTS notification
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    -- outbox event producing the notification
+    event_id VARCHAR(32) NOT NULL,
+    msisdn VARCHAR(20) NOT NULL,
+    template VARCHAR(30) NOT NULL,
+    message VARCHAR(500) NOT NULL,
+    -- 0 pending, 1 sent, 2 failed, 3 cancelled
+    status INTEGER NOT NULL DEFAULT 0,
+    attempt INTEGER NOT NULL DEFAULT 0,
+    -- pushed to the end of quiet hours
+    next_attempt_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    last_error VARCHAR(255),
+    created_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_date TIMESTAMP NOT NULL DEFAULT (STRFTIME('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    -- relay publish event at least once, recipient is notified once per event
+    CONSTRAINT notification_event_key UNIQUE (event_id, msisdn)
+);
+CREATE INDEX IF NOT EXISTS notification_pending_idx ON notification(next_attempt_date) WHERE status = 0;
+`
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type webhookDeliveryRepository struct {
+	db *Database
+}
+
+func SetupWebhookDeliveryRepository(db *Database) *webhookDeliveryRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &webhookDeliveryRepository{
+		db: db,
+	}
+}
+
+const (
+	queryWebhookDeliveryFindDue = `SELECT id, subscription_id, event_id, event, payload, status, attempt, next_attempt_date, 
+								   COALESCE(response_code, 0) AS response_code, COALESCE(last_error, '') AS last_error, 
+								   created_date, updated_date FROM webhook_delivery 
+								   WHERE status = 0 AND next_attempt_date <= NOW() ORDER BY next_attempt_date, id LIMIT $1`
+	// OFFSET is only allowed after LIMIT by sqlite
+	queryWebhookDeliveryFindByStatus = `SELECT id, subscription_id, event_id, event, payload, status, attempt, next_attempt_date, 
+										COALESCE(response_code, 0) AS response_code, COALESCE(last_error, '') AS last_error, 
+										created_date, updated_date FROM webhook_delivery WHERE status = $1 
+										ORDER BY id DESC LIMIT $3 OFFSET $2`
+)
+
+// Insert queue delivery, event already queued for the subscription is ignored and keep its delivery
+func (r webhookDeliveryRepository) Insert(ctx context.Context, delivery *model.WebhookDelivery) error {
+	ID, err := r.db.insert(ctx, fmt.Sprintf(query.WebhookDeliveryInsert, r.db.SchemaName()),
+		delivery.SubscriptionID, delivery.EventID, delivery.Event, delivery.Payload)
+	if err != nil {
+		return err
+	}
+	if ID > 0 {
+		delivery.ID = ID
+	}
+	return nil
+}
+
+// ClaimDue return pending delivery whose next attempt already passed, earliest first, and postpone it by lease.
+// Transaction take the write lock when it begins, so other instance wait instead of claiming the same delivery
+func (r webhookDeliveryRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) (result []model.WebhookDelivery, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.db.conn(ctx).SelectContext(ctx, &result, queryWebhookDeliveryFindDue, limit); err != nil || len(result) == 0 {
+			return err
+		}
+		IDs := make([]int64, len(result))
+		until := time.Now().Add(lease)
+		for i := range result {
+			IDs[i] = result[i].ID
+			result[i].NextAttemptDate = until
+		}
+		return r.db.postpone(ctx, "webhook_delivery", IDs, until)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r webhookDeliveryRepository) UpdateResult(ctx context.Context, delivery model.WebhookDelivery) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.WebhookDeliveryUpdateResult, r.db.SchemaName()), delivery.Status,
+		delivery.Attempt, delivery.NextAttemptDate, delivery.ResponseCode, delivery.LastError, delivery.ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+func (r webhookDeliveryRepository) FindByStatus(ctx context.Context, status, offset, limit int) (result []model.WebhookDelivery, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, queryWebhookDeliveryFindByStatus, status, offset, limit)
+	return result, err
+}
+
+func (r webhookDeliveryRepository) CountByStatus(ctx context.Context, status int) (total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &total, query.WebhookDeliveryCountByStatus, status)
+	return total, err
+}
+
+// Replay set dead delivery back to pending so it is sent on next run, delivery in other status is not found
+func (r webhookDeliveryRepository) Replay(ctx context.Context, ID int64) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.WebhookDeliveryReplay, r.db.SchemaName()), ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
+
+// ReplayDead set every dead delivery of subscription back to pending, all subscription when subscriptionID is 0
+func (r webhookDeliveryRepository) ReplayDead(ctx context.Context, subscriptionID int64) (total int64, err error) {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.WebhookDeliveryReplayDead, r.db.SchemaName()), subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
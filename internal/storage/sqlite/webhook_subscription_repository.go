@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/query"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type webhookSubscriptionRepository struct {
+	db *Database
+}
+
+func SetupWebhookSubscriptionRepository(db *Database) *webhookSubscriptionRepository {
+	if db == nil {
+		panic("sqlite db is nil")
+	}
+	return &webhookSubscriptionRepository{
+		db: db,
+	}
+}
+
+func (r webhookSubscriptionRepository) FindAll(ctx context.Context) (result []model.WebhookSubscription, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.WebhookSubscriptionFindAll)
+	return result, err
+}
+
+func (r webhookSubscriptionRepository) FindByID(ctx context.Context, ID int64) (result model.WebhookSubscription, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).GetContext(ctx, &result, query.WebhookSubscriptionFindByID, ID)
+	return result, err
+}
+
+func (r webhookSubscriptionRepository) FindActiveByEvent(ctx context.Context, event string) (result []model.WebhookSubscription, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err = r.db.conn(ctx).SelectContext(ctx, &result, query.WebhookSubscriptionFindActiveByEvent, event)
+	return result, err
+}
+
+func (r webhookSubscriptionRepository) Insert(ctx context.Context, subscription *model.WebhookSubscription) (err error) {
+	subscription.ID, err = r.db.insert(ctx, fmt.Sprintf(query.WebhookSubscriptionInsert, r.db.SchemaName()),
+		subscription.URL, subscription.Secret, subscription.Events)
+	if err != nil {
+		return err
+	}
+	if subscription.ID == 0 {
+		return util.ErrorDatabase
+	}
+	return nil
+}
+
+func (r webhookSubscriptionRepository) UpdateStatus(ctx context.Context, ID int64, status int) error {
+	result, err := r.db.conn(ctx).ExecContext(ctx, fmt.Sprintf(query.WebhookSubscriptionUpdateStatus, r.db.SchemaName()), status, ID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return util.ErrorDataNotFound
+	}
+	return nil
+}
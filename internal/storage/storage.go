@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage/memory"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/postgresql"
+	"github.com/candraalim/be_tsel_candra/internal/storage/sqlite"
+)
+
+const (
+	DriverPostgreSQL = "postgresql"
+	DriverSQLite     = "sqlite"
+	DriverMemory     = "memory"
+)
+
+// Repositories is every repository of a storage driver, repository called within Transactor joins its transaction
+type Repositories struct {
+	Transactor             model.Transactor
+	ReferralCode           model.ReferralCodeRepository
+	ReferralHistory        model.ReferralHistoryRepository
+	Reward                 model.RewardRepository
+	RewardClaim            model.RewardClaimRepository
+	Campaign               model.CampaignRepository
+	ReferralClick          model.ReferralClickRepository
+	WebhookSubscription    model.WebhookSubscriptionRepository
+	WebhookDelivery        model.WebhookDeliveryRepository
+	Outbox                 model.OutboxRepository
+	Notification           model.NotificationRepository
+	NotificationPreference model.NotificationPreferenceRepository
+}
+
+// defaultRewards is reward memory store start with, the same as created by postgresql migration
+var defaultRewards = []model.Reward{
+	{TotalReferral: 1, Description: "bonus 2 GB", Level: 1},
+	{TotalReferral: 5, Description: "bonus 12 GB", Level: 1},
+	{TotalReferral: 6, Description: "bonus 20 GB", Level: 1},
+	{TotalReferral: 1, Description: "bonus 500 MB", Level: 2},
+	{TotalReferral: 5, Description: "bonus 3 GB", Level: 2},
+}
+
+// Setup return repositories of configured driver, default to postgresql
+func Setup(cfg *config.AppConfig) Repositories {
+	if cfg == nil {
+		panic("config is nil")
+	}
+	driver := DriverPostgreSQL
+	if cfg.Storage != nil && cfg.Storage.Driver != "" {
+		driver = cfg.Storage.Driver
+	}
+
+	switch driver {
+	case DriverPostgreSQL:
+		return setupPostgreSQL(cfg.Database)
+	case DriverSQLite:
+		return setupSQLite(cfg.Storage.SQLite)
+	case DriverMemory:
+		return setupMemory()
+	default:
+		panic("unknown storage driver: " + driver)
+	}
+}
+
+func setupPostgreSQL(cfg *config.DatabaseConfig) Repositories {
+	db := postgresql.NewDatabase(cfg)
+	if cfg.MigrateOnStart {
+		if _, err := postgresql.SetupMigrator(db).Up(context.Background()); err != nil {
+			fmt.Println("failed to migrate database")
+			panic(err)
+		}
+	}
+	return Repositories{
+		Transactor:             db,
+		ReferralCode:           postgresql.SetupReferralCodeRepository(db),
+		ReferralHistory:        postgresql.SetupReferralHistoryRepository(db),
+		Reward:                 postgresql.SetupRewardRepository(db),
+		RewardClaim:            postgresql.SetupRewardClaimRepository(db),
+		Campaign:               postgresql.SetupCampaignRepository(db),
+		ReferralClick:          postgresql.SetupReferralClickRepository(db),
+		WebhookSubscription:    postgresql.SetupWebhookSubscriptionRepository(db),
+		WebhookDelivery:        postgresql.SetupWebhookDeliveryRepository(db),
+		Outbox:                 postgresql.SetupOutboxRepository(db),
+		Notification:           postgresql.SetupNotificationRepository(db),
+		NotificationPreference: postgresql.SetupNotificationPreferenceRepository(db),
+	}
+}
+
+// setupSQLite open database file, default reward is created with its schema
+func setupSQLite(cfg *config.SQLiteConfig) Repositories {
+	db := sqlite.NewDatabase(cfg)
+	return Repositories{
+		Transactor:             db,
+		ReferralCode:           sqlite.SetupReferralCodeRepository(db),
+		ReferralHistory:        sqlite.SetupReferralHistoryRepository(db),
+		Reward:                 sqlite.SetupRewardRepository(db),
+		RewardClaim:            sqlite.SetupRewardClaimRepository(db),
+		Campaign:               sqlite.SetupCampaignRepository(db),
+		ReferralClick:          sqlite.SetupReferralClickRepository(db),
+		WebhookSubscription:    sqlite.SetupWebhookSubscriptionRepository(db),
+		WebhookDelivery:        sqlite.SetupWebhookDeliveryRepository(db),
+		Outbox:                 sqlite.SetupOutboxRepository(db),
+		Notification:           sqlite.SetupNotificationRepository(db),
+		NotificationPreference: sqlite.SetupNotificationPreferenceRepository(db),
+	}
+}
+
+func setupMemory() Repositories {
+	store := memory.NewStore()
+	rewardRepository := memory.SetupRewardRepository(store)
+	for _, v := range defaultRewards {
+		reward := v
+		_ = rewardRepository.Insert(context.Background(), &reward)
+	}
+
+	return Repositories{
+		Transactor:             store,
+		ReferralCode:           memory.SetupReferralCodeRepository(store),
+		ReferralHistory:        memory.SetupReferralHistoryRepository(store),
+		Reward:                 rewardRepository,
+		RewardClaim:            memory.SetupRewardClaimRepository(store),
+		Campaign:               memory.SetupCampaignRepository(store),
+		ReferralClick:          memory.SetupReferralClickRepository(store),
+		WebhookSubscription:    memory.SetupWebhookSubscriptionRepository(store),
+		WebhookDelivery:        memory.SetupWebhookDeliveryRepository(store),
+		Outbox:                 memory.SetupOutboxRepository(store),
+		Notification:           memory.SetupNotificationRepository(store),
+		NotificationPreference: memory.SetupNotificationPreferenceRepository(store),
+	}
+}
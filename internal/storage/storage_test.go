@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/config"
+)
+
+func TestSetup(t *testing.T) {
+	assert.Panics(t, func() {
+		Setup(nil)
+	})
+	assert.Panics(t, func() {
+		Setup(&config.AppConfig{Storage: &config.StorageConfig{Driver: "mysql"}})
+	})
+	assert.Panics(t, func() {
+		Setup(&config.AppConfig{Storage: &config.StorageConfig{Driver: DriverSQLite}})
+	})
+
+	t.Run("memory start with default reward", func(t *testing.T) {
+		repositories := Setup(&config.AppConfig{Storage: &config.StorageConfig{Driver: DriverMemory}})
+
+		rewards, err := repositories.Reward.FindAll(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, rewards, len(defaultRewards))
+
+		reward, err := repositories.Reward.FindByLevelAndTotalReferral(context.Background(), 1, 5)
+		assert.Nil(t, err)
+		assert.Equal(t, "bonus 12 GB", reward.Description)
+	})
+	t.Run("sqlite start with default reward", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "storage")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		repositories := Setup(&config.AppConfig{Storage: &config.StorageConfig{Driver: DriverSQLite,
+			SQLite: &config.SQLiteConfig{Path: filepath.Join(dir, "referral.db")}}})
+
+		rewards, err := repositories.Reward.FindAll(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, rewards, len(defaultRewards))
+	})
+}
@@ -8,34 +8,117 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 
 	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/admin"
 	"github.com/candraalim/be_tsel_candra/internal/usecase/inquiry"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/leaderboard"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/link"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/preference"
 	"github.com/candraalim/be_tsel_candra/internal/usecase/referral"
+	"github.com/candraalim/be_tsel_candra/internal/usecase/reward"
 )
 
-func setupRouter(server *echo.Echo, auth *config.AuthConfig, inquiring *inquiry.InquiringHandler, referral *referral.ReferHandler) {
+type Handlers struct {
+	Inquiring     *inquiry.InquiringHandler
+	Referral      *referral.ReferHandler
+	Reward        *reward.RewardHandler
+	Leaderboard   *leaderboard.LeaderboardHandler
+	Link          *link.LinkHandler
+	Preference    *preference.PreferenceHandler
+	AdminReward   *admin.RewardHandler
+	AdminCampaign *admin.CampaignHandler
+	AdminCode     *admin.CodeHandler
+	AdminReferral *admin.ReferralHandler
+	AdminExport   *admin.ExportHandler
+	AdminReport   *admin.ReportHandler
+	AdminClick    *admin.ClickHandler
+	AdminWebhook  *admin.WebhookHandler
+}
+
+func setupRouter(server *echo.Echo, auth, adminAuth *config.AuthConfig, handlers Handlers) {
 
 	// health check
 	server.GET("/ping", func(c echo.Context) error {
 		return c.String(http.StatusOK, "services up and running... "+time.Now().Format(time.RFC3339))
 	})
 
-	var basicAuthFunc middleware.BasicAuthValidator = func(username, password string, context echo.Context) (b bool, e error) {
-		if username == auth.Username && password == auth.Password {
-			b = true
-			return
-		}
-		return
-	}
+	// shared referral link is opened by anyone, no credential
+	server.GET("/r/:code", handlers.Link.Redirect)
 
-	basicAuth := middleware.BasicAuth(basicAuthFunc)
+	basicAuth := middleware.BasicAuth(basicAuthValidator(auth))
 
 	group := server.Group("/1.0/referral", basicAuth)
 	{
-		group.GET("/:msisdn/code", inquiring.GetReferralCode)
-		group.GET("/:msisdn", inquiring.GetListReferral)
-		group.GET("/:msisdn/reward", inquiring.GetCurrentReferralReward)
+		group.GET("/:msisdn/code", handlers.Inquiring.GetReferralCode)
+		group.POST("/:msisdn/code", handlers.Inquiring.ClaimReferralCode)
+		group.GET("/:msisdn/code/qr", handlers.Inquiring.GetReferralCodeQR)
+		group.POST("/:msisdn/code/regenerate", handlers.Inquiring.RegenerateReferralCode)
+		group.GET("/:msisdn/code/history", handlers.Inquiring.GetReferralCodeHistory)
+		group.GET("/:msisdn/downline", handlers.Inquiring.GetDownline)
+		group.GET("/:msisdn", handlers.Inquiring.GetListReferral)
+		group.GET("/:msisdn/reward", handlers.Inquiring.GetCurrentReferralReward)
+	}
+	{
+		group.POST("", handlers.Referral.ProcessReferral)
+		group.POST("/:msisdn/confirm", handlers.Referral.ConfirmReferral)
+	}
+	{
+		group.POST("/:msisdn/reward/claim", handlers.Reward.ClaimReward)
+		group.GET("/:msisdn/reward/claim", handlers.Reward.GetListClaim)
+	}
+	{
+		group.GET("/leaderboard", handlers.Leaderboard.GetLeaderboard)
+		group.GET("/:msisdn/rank", handlers.Leaderboard.GetRank)
+	}
+	{
+		group.GET("/:msisdn/notification", handlers.Preference.GetPreference)
+		group.PUT("/:msisdn/notification", handlers.Preference.UpdatePreference)
+	}
+
+	// admin use different credential from partner
+	adminBasicAuth := middleware.BasicAuth(basicAuthValidator(adminAuth))
+
+	adminGroup := server.Group("/1.0/admin", adminBasicAuth)
+	{
+		adminGroup.GET("/reward", handlers.AdminReward.GetListReward)
+		adminGroup.GET("/reward/:id", handlers.AdminReward.GetReward)
+		adminGroup.POST("/reward", handlers.AdminReward.CreateReward)
+		adminGroup.PUT("/reward/:id", handlers.AdminReward.UpdateReward)
+		adminGroup.DELETE("/reward/:id", handlers.AdminReward.DeactivateReward)
+	}
+	{
+		adminGroup.GET("/campaign", handlers.AdminCampaign.GetListCampaign)
+		adminGroup.GET("/campaign/:id", handlers.AdminCampaign.GetCampaign)
+		adminGroup.POST("/campaign", handlers.AdminCampaign.CreateCampaign)
+		adminGroup.PUT("/campaign/:id", handlers.AdminCampaign.UpdateCampaign)
+		adminGroup.DELETE("/campaign/:id", handlers.AdminCampaign.DeactivateCampaign)
+	}
+	{
+		adminGroup.POST("/code/:code/deactivate", handlers.AdminCode.DeactivateCode)
+		adminGroup.POST("/code/:code/reactivate", handlers.AdminCode.ReactivateCode)
+		adminGroup.POST("/code/:code/expire", handlers.AdminCode.ExpireCode)
+		adminGroup.GET("/code/stats", handlers.AdminClick.GetClickStatistic)
+	}
+	{
+		adminGroup.POST("/referral/:msisdn/reverse", handlers.AdminReferral.ReverseReferral)
+		adminGroup.GET("/referral/export", handlers.AdminExport.ExportReferral)
+		adminGroup.GET("/referral/report", handlers.AdminReport.GetReferralReport)
 	}
 	{
-		group.POST("", referral.ProcessReferral)
+		adminGroup.GET("/webhook", handlers.AdminWebhook.GetListWebhook)
+		adminGroup.POST("/webhook", handlers.AdminWebhook.CreateWebhook)
+		adminGroup.DELETE("/webhook/:id", handlers.AdminWebhook.DeactivateWebhook)
+		adminGroup.GET("/webhook/delivery", handlers.AdminWebhook.GetListDelivery)
+		adminGroup.POST("/webhook/delivery/replay", handlers.AdminWebhook.ReplayDeadDelivery)
+		adminGroup.POST("/webhook/delivery/:id/replay", handlers.AdminWebhook.ReplayDelivery)
+	}
+}
+
+func basicAuthValidator(auth *config.AuthConfig) middleware.BasicAuthValidator {
+	return func(username, password string, context echo.Context) (b bool, e error) {
+		if auth != nil && username == auth.Username && password == auth.Password {
+			b = true
+			return
+		}
+		return
 	}
 }
@@ -12,17 +12,15 @@ import (
 	"gopkg.in/go-playground/validator.v9"
 
 	"github.com/candraalim/be_tsel_candra/config"
-	"github.com/candraalim/be_tsel_candra/internal/usecase/inquiry"
-	"github.com/candraalim/be_tsel_candra/internal/usecase/referral"
 	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
-func StartHttpService(config *config.ServerConfig, auth *config.AuthConfig, inquiring *inquiry.InquiringHandler, referral *referral.ReferHandler) {
+func StartHttpService(config *config.ServerConfig, auth, adminAuth *config.AuthConfig, handlers Handlers) {
 	server := echo.New()
 	server.HideBanner = true
 
 	setupMiddleware(server)
-	setupRouter(server, auth, inquiring, referral)
+	setupRouter(server, auth, adminAuth, handlers)
 
 	// start server
 	go func() {
@@ -44,9 +42,11 @@ func StartHttpService(config *config.ServerConfig, auth *config.AuthConfig, inqu
 }
 
 func setupMiddleware(server *echo.Echo) {
+	//panic of a request is responded as error instead of stopping the service
+	server.Use(middleware.Recover())
 	server.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.POST, echo.OPTIONS},
+		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderAccept, echo.HeaderAccessControlAllowOrigin,
 			echo.HeaderContentType, echo.HeaderAuthorization, echo.HeaderContentLength, echo.HeaderContentEncoding,
 			echo.HeaderAcceptEncoding, echo.HeaderXCSRFToken},
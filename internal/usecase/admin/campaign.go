@@ -0,0 +1,165 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type CampaignUseCase interface {
+	GetListCampaign(ctx context.Context) (ListCampaignResponse, error)
+	GetCampaign(ctx context.Context, ID int64) (CampaignResponse, error)
+	CreateCampaign(ctx context.Context, request CreateCampaignRequest) (CampaignResponse, error)
+	UpdateCampaign(ctx context.Context, request UpdateCampaignRequest) (CampaignResponse, error)
+	DeactivateCampaign(ctx context.Context, ID int64) (CampaignResponse, error)
+}
+
+type campaignUseCase struct {
+	campaignRepository model.CampaignRepository
+}
+
+func SetupCampaignUseCase(campaignRepository model.CampaignRepository) CampaignUseCase {
+	if campaignRepository == nil {
+		panic("CampaignRepository is nil")
+	}
+	return &campaignUseCase{
+		campaignRepository: campaignRepository,
+	}
+}
+
+func (c campaignUseCase) GetListCampaign(ctx context.Context) (ListCampaignResponse, error) {
+	entities, err := c.campaignRepository.FindAll(ctx)
+	if err != nil {
+		return ListCampaignResponse{}, err
+	}
+
+	list := make([]Campaign, len(entities))
+	for i, v := range entities {
+		list[i] = assemblerCampaign(v)
+	}
+
+	resp := ListCampaignResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.List = list
+	return resp, nil
+}
+
+func (c campaignUseCase) GetCampaign(ctx context.Context, ID int64) (CampaignResponse, error) {
+	campaign, err := c.findCampaign(ctx, ID)
+	if err != nil {
+		return CampaignResponse{}, err
+	}
+	return CampaignResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+		Data:    assemblerCampaign(campaign),
+	}, nil
+}
+
+func (c campaignUseCase) CreateCampaign(ctx context.Context, request CreateCampaignRequest) (CampaignResponse, error) {
+	if err := validateCampaignWindow(request.StartDate, request.EndDate); err != nil {
+		return CampaignResponse{}, err
+	}
+
+	campaign := model.Campaign{
+		Name:      request.Name,
+		StartDate: request.StartDate,
+		EndDate:   request.EndDate,
+	}
+	if err := c.campaignRepository.Insert(ctx, &campaign); err != nil {
+		return CampaignResponse{}, err
+	}
+	return c.GetCampaign(ctx, campaign.ID)
+}
+
+func (c campaignUseCase) UpdateCampaign(ctx context.Context, request UpdateCampaignRequest) (CampaignResponse, error) {
+	if request.Name == "" && request.StartDate == "" && request.EndDate == "" {
+		log.Println("nothing to update on campaign: ", request.ID)
+		return CampaignResponse{}, util.ErrorInvalidRequest
+	}
+	campaign, err := c.findCampaign(ctx, request.ID)
+	if err != nil {
+		return CampaignResponse{}, err
+	}
+
+	//validate the window after update applied
+	startDate, endDate := campaign.StartDate, campaign.EndDate
+	if request.StartDate != "" {
+		startDate = request.StartDate
+	}
+	if request.EndDate != "" {
+		endDate = request.EndDate
+	}
+	if err := validateCampaignWindow(startDate, endDate); err != nil {
+		return CampaignResponse{}, err
+	}
+
+	err = c.campaignRepository.Update(ctx, model.Campaign{
+		ID:        request.ID,
+		Name:      request.Name,
+		StartDate: request.StartDate,
+		EndDate:   request.EndDate,
+	})
+	if err != nil {
+		return CampaignResponse{}, err
+	}
+	return c.GetCampaign(ctx, request.ID)
+}
+
+func (c campaignUseCase) DeactivateCampaign(ctx context.Context, ID int64) (CampaignResponse, error) {
+	if _, err := c.findCampaign(ctx, ID); err != nil {
+		return CampaignResponse{}, err
+	}
+	if err := c.campaignRepository.Delete(ctx, ID); err != nil {
+		return CampaignResponse{}, err
+	}
+	return c.GetCampaign(ctx, ID)
+}
+
+func (c campaignUseCase) findCampaign(ctx context.Context, ID int64) (model.Campaign, error) {
+	campaign, err := c.campaignRepository.FindByID(ctx, ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Println("campaign not found: ", ID)
+		return model.Campaign{}, util.ErrorDataNotFound
+	}
+	return campaign, err
+}
+
+// validateCampaignWindow make sure both date use format yyyy-MM-dd and end date is not before start date,
+// referral date is compared as string against this window
+func validateCampaignWindow(startDate, endDate string) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		log.Println("invalid campaign start date: ", startDate)
+		return util.ErrorInvalidRequest
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		log.Println("invalid campaign end date: ", endDate)
+		return util.ErrorInvalidRequest
+	}
+	if end.Before(start) {
+		log.Println("campaign end date before start date: ", startDate, endDate)
+		return util.ErrorInvalidRequest
+	}
+	return nil
+}
+
+func assemblerCampaign(campaign model.Campaign) Campaign {
+	return Campaign{
+		ID:          campaign.ID,
+		Name:        campaign.Name,
+		StartDate:   campaign.StartDate,
+		EndDate:     campaign.EndDate,
+		Status:      campaign.Status,
+		CreatedDate: campaign.CreatedDate.UnixNano() / 1000000,
+		UpdatedDate: campaign.UpdatedDate.UnixNano() / 1000000,
+	}
+}
@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CampaignHandler struct {
+	useCase CampaignUseCase
+}
+
+func SetupCampaignHandler(useCase CampaignUseCase) *CampaignHandler {
+	if useCase == nil {
+		panic("campaign use case is nil")
+	}
+	return &CampaignHandler{
+		useCase: useCase,
+	}
+}
+
+func (h CampaignHandler) GetListCampaign(e echo.Context) error {
+	res, err := h.useCase.GetListCampaign(e.Request().Context())
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h CampaignHandler) GetCampaign(e echo.Context) error {
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.useCase.GetCampaign(e.Request().Context(), ID)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h CampaignHandler) CreateCampaign(e echo.Context) error {
+	var request CreateCampaignRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+
+	if err := validate(e, &request); err != nil {
+		return err
+	}
+
+	res, err := h.useCase.CreateCampaign(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h CampaignHandler) UpdateCampaign(e echo.Context) error {
+	var request UpdateCampaignRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+
+	if err := validate(e, &request); err != nil {
+		return err
+	}
+
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+	request.ID = ID
+
+	res, err := h.useCase.UpdateCampaign(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h CampaignHandler) DeactivateCampaign(e echo.Context) error {
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.useCase.DeactivateCampaign(e.Request().Context(), ID)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupCampaignHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCampaignHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupCampaignHandler(&campaignUseCase{})
+	})
+}
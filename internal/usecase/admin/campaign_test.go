@@ -0,0 +1,147 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupCampaignUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCampaignUseCase(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupCampaignUseCase(&mocks.CampaignRepository{})
+	})
+}
+
+func Test_campaignUseCase_GetListCampaign(t *testing.T) {
+	t.Run("error get list", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindAll", mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		_, err := c.GetListCampaign(context.Background())
+		assert.NotNil(t, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindAll", mock.Anything).Return([]model.Campaign{
+			{ID: 2, Name: "year end", StartDate: "2021-12-01", EndDate: "2021-12-31", Status: 1},
+		}, nil)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		resp, err := c.GetListCampaign(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(resp.Data.List))
+		assert.Equal(t, "year end", resp.Data.List[0].Name)
+	})
+}
+
+func Test_campaignUseCase_GetCampaign(t *testing.T) {
+	t.Run("campaign not found", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(2)).Return(model.Campaign{}, sql.ErrNoRows)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		_, err := c.GetCampaign(context.Background(), 2)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(2)).Return(model.Campaign{ID: 2, Name: "year end"}, nil)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		resp, err := c.GetCampaign(context.Background(), 2)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), resp.Data.ID)
+	})
+}
+
+func Test_campaignUseCase_CreateCampaign(t *testing.T) {
+	t.Run("invalid date format", func(t *testing.T) {
+		c := campaignUseCase{}
+		_, err := c.CreateCampaign(context.Background(), CreateCampaignRequest{Name: "ramadan", StartDate: "13-04-2021", EndDate: "2021-05-12"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("end date before start date", func(t *testing.T) {
+		c := campaignUseCase{}
+		_, err := c.CreateCampaign(context.Background(), CreateCampaignRequest{Name: "ramadan", StartDate: "2021-05-12", EndDate: "2021-04-13"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error insert", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		_, err := c.CreateCampaign(context.Background(), CreateCampaignRequest{Name: "ramadan", StartDate: "2021-04-13", EndDate: "2021-05-12"})
+		assert.NotNil(t, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*model.Campaign).ID = 3
+		}).Return(nil)
+		campaignMock.On("FindByID", mock.Anything, int64(3)).Return(model.Campaign{ID: 3, Name: "ramadan",
+			StartDate: "2021-04-13", EndDate: "2021-05-12", Status: 1}, nil)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		resp, err := c.CreateCampaign(context.Background(), CreateCampaignRequest{Name: "ramadan", StartDate: "2021-04-13", EndDate: "2021-05-12"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), resp.Data.ID)
+	})
+}
+
+func Test_campaignUseCase_UpdateCampaign(t *testing.T) {
+	t.Run("nothing to update", func(t *testing.T) {
+		c := campaignUseCase{}
+		_, err := c.UpdateCampaign(context.Background(), UpdateCampaignRequest{ID: 3})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("end date before existing start date", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(3)).Return(model.Campaign{ID: 3, StartDate: "2021-04-13", EndDate: "2021-05-12"}, nil)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		_, err := c.UpdateCampaign(context.Background(), UpdateCampaignRequest{ID: 3, EndDate: "2021-04-01"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(3)).Return(model.Campaign{ID: 3, StartDate: "2021-04-13", EndDate: "2021-05-12"}, nil)
+		campaignMock.On("Update", mock.Anything, model.Campaign{ID: 3, EndDate: "2021-05-20"}).Return(nil)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		_, err := c.UpdateCampaign(context.Background(), UpdateCampaignRequest{ID: 3, EndDate: "2021-05-20"})
+		assert.Nil(t, err)
+		campaignMock.AssertExpectations(t)
+	})
+}
+
+func Test_campaignUseCase_DeactivateCampaign(t *testing.T) {
+	t.Run("campaign not found", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(3)).Return(model.Campaign{}, sql.ErrNoRows)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		_, err := c.DeactivateCampaign(context.Background(), 3)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(3)).Return(model.Campaign{ID: 3}, nil)
+		campaignMock.On("Delete", mock.Anything, int64(3)).Return(nil)
+
+		c := campaignUseCase{campaignRepository: campaignMock}
+		_, err := c.DeactivateCampaign(context.Background(), 3)
+		assert.Nil(t, err)
+		campaignMock.AssertExpectations(t)
+	})
+}
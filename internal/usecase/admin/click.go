@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const (
+	defaultClickStatisticLimit = 10
+	maxClickStatisticLimit     = 100
+)
+
+type ClickUseCase interface {
+	GetClickStatistic(ctx context.Context, request ClickStatisticRequest) (ClickStatisticResponse, error)
+}
+
+type clickUseCase struct {
+	clickRepository model.ReferralClickRepository
+}
+
+func SetupClickUseCase(clickRepository model.ReferralClickRepository) ClickUseCase {
+	if clickRepository == nil {
+		panic("ReferralClickRepository is nil")
+	}
+	return &clickUseCase{
+		clickRepository: clickRepository,
+	}
+}
+
+// GetClickStatistic return click of referral link and counted referral of most clicked code between from and to,
+// default to last 30 days
+func (c clickUseCase) GetClickStatistic(ctx context.Context, request ClickStatisticRequest) (resp ClickStatisticResponse, err error) {
+	from, to, err := reportRange(request.From, request.To)
+	if err != nil {
+		return ClickStatisticResponse{}, err
+	}
+	limit := request.Limit
+	if limit < 1 {
+		limit = defaultClickStatisticLimit
+	}
+	if limit > maxClickStatisticLimit {
+		limit = maxClickStatisticLimit
+	}
+
+	statistics, err := c.clickRepository.GetStatistic(ctx, model.ReferralClickFilter{
+		Code:  strings.TrimSpace(request.Code),
+		From:  from.Format("2006-01-02"),
+		To:    to.Format("2006-01-02"),
+		Limit: limit,
+	})
+	if err != nil {
+		return ClickStatisticResponse{}, err
+	}
+
+	list := make([]ClickStatistic, len(statistics))
+	for i, v := range statistics {
+		var rate float64
+		if v.TotalClick > 0 {
+			rate = math.Round(float64(v.TotalReferral)/float64(v.TotalClick)*10000) / 100
+		}
+		list[i] = ClickStatistic{
+			ReferralCode:   v.Code,
+			Msisdn:         v.Msisdn,
+			TotalClick:     v.TotalClick,
+			TotalReferral:  v.TotalReferral,
+			ConversionRate: rate,
+		}
+	}
+
+	resp = ClickStatisticResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.From = from.Format("2006-01-02")
+	resp.Data.To = to.Format("2006-01-02")
+	resp.Data.List = list
+	return resp, nil
+}
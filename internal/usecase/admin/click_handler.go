@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ClickHandler struct {
+	useCase ClickUseCase
+}
+
+func SetupClickHandler(useCase ClickUseCase) *ClickHandler {
+	if useCase == nil {
+		panic("click use case is nil")
+	}
+	return &ClickHandler{
+		useCase: useCase,
+	}
+}
+
+func (h ClickHandler) GetClickStatistic(e echo.Context) error {
+	var limit int
+	if lim, err := strconv.Atoi(e.QueryParam("limit")); err == nil {
+		limit = lim
+	}
+
+	res, err := h.useCase.GetClickStatistic(e.Request().Context(), ClickStatisticRequest{
+		Code:  e.QueryParam("code"),
+		From:  e.QueryParam("from"),
+		To:    e.QueryParam("to"),
+		Limit: limit,
+	})
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
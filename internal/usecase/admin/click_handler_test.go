@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupClickHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupClickHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupClickHandler(&clickUseCase{})
+	})
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupClickUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupClickUseCase(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupClickUseCase(&mocks.ReferralClickRepository{})
+	})
+}
+
+func Test_clickUseCase_GetClickStatistic(t *testing.T) {
+	t.Run("invalid range", func(t *testing.T) {
+		c := clickUseCase{}
+		_, err := c.GetClickStatistic(context.Background(), ClickStatisticRequest{From: "2021-08-31", To: "2021-08-01"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error get statistic", func(t *testing.T) {
+		clickMock := &mocks.ReferralClickRepository{}
+		clickMock.On("GetStatistic", mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		c := clickUseCase{clickRepository: clickMock}
+		_, err := c.GetClickStatistic(context.Background(), ClickStatisticRequest{})
+		assert.NotNil(t, err)
+	})
+	t.Run("limit capped and conversion rate calculated", func(t *testing.T) {
+		clickMock := &mocks.ReferralClickRepository{}
+		clickMock.On("GetStatistic", mock.Anything, model.ReferralClickFilter{Code: "4C7AE71563", From: "2021-08-01",
+			To: "2021-08-31", Limit: maxClickStatisticLimit}).Return([]model.ReferralClickStatistic{
+			{Code: "4C7AE71563", Msisdn: "6280000011", TotalClick: 3, TotalReferral: 1},
+			{Code: "BAKUSEDA", Msisdn: "6280000012", TotalClick: 0, TotalReferral: 2},
+		}, nil)
+
+		c := clickUseCase{clickRepository: clickMock}
+		resp, err := c.GetClickStatistic(context.Background(), ClickStatisticRequest{Code: " 4C7AE71563 ",
+			From: "2021-08-01", To: "2021-08-31", Limit: 1000})
+		assert.Nil(t, err)
+		assert.Equal(t, "2021-08-01", resp.Data.From)
+		assert.Equal(t, []ClickStatistic{
+			{ReferralCode: "4C7AE71563", Msisdn: "6280000011", TotalClick: 3, TotalReferral: 1, ConversionRate: 33.33},
+			{ReferralCode: "BAKUSEDA", Msisdn: "6280000012", TotalReferral: 2},
+		}, resp.Data.List)
+	})
+}
@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type CodeUseCase interface {
+	DeactivateCode(ctx context.Context, code string) (CodeResponse, error)
+	ReactivateCode(ctx context.Context, code string) (CodeResponse, error)
+	ExpireCode(ctx context.Context, request ExpireCodeRequest) (CodeResponse, error)
+}
+
+type codeUseCase struct {
+	codeRepository model.ReferralCodeRepository
+	transactor     model.Transactor
+}
+
+func SetupCodeUseCase(referralCodeRepository model.ReferralCodeRepository, transactor model.Transactor) CodeUseCase {
+	if referralCodeRepository == nil {
+		panic("ReferralCodeRepository is nil")
+	}
+	if transactor == nil {
+		panic("Transactor is nil")
+	}
+	return &codeUseCase{
+		codeRepository: referralCodeRepository,
+		transactor:     transactor,
+	}
+}
+
+// DeactivateCode set code to inactive, retired code is not found
+func (c codeUseCase) DeactivateCode(ctx context.Context, code string) (CodeResponse, error) {
+	code = strings.TrimSpace(code)
+	if err := c.codeRepository.UpdateStatus(ctx, code, model.ReferralCodeStatusInactive); err != nil {
+		return CodeResponse{}, err
+	}
+	resp := CodeResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.ReferralCode = code
+	resp.Data.Status = model.ReferralCodeStatusInactive
+	return resp, nil
+}
+
+// ReactivateCode set code back to active and remove its expiry. Retired code is not found,
+// so its msisdn keeps a single active code and grace period of the retired code is kept
+func (c codeUseCase) ReactivateCode(ctx context.Context, code string) (CodeResponse, error) {
+	code = strings.TrimSpace(code)
+	//status and expiry are changed together, code is not left active with its old expiry
+	err := c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.codeRepository.UpdateStatus(ctx, code, model.ReferralCodeStatusActive); err != nil {
+			return err
+		}
+		return c.codeRepository.UpdateExpiry(ctx, code, nil)
+	})
+	if err != nil {
+		return CodeResponse{}, err
+	}
+	resp := CodeResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.ReferralCode = code
+	resp.Data.Status = model.ReferralCodeStatusActive
+	return resp, nil
+}
+
+// ExpireCode set expiry time of code, code is expired immediately when expiry time is not given
+func (c codeUseCase) ExpireCode(ctx context.Context, request ExpireCodeRequest) (CodeResponse, error) {
+	code := strings.TrimSpace(request.ReferralCode)
+	expiresAt := time.Now()
+	if request.ExpiresAt != "" {
+		var err error
+		expiresAt, err = time.Parse(time.RFC3339, request.ExpiresAt)
+		if err != nil {
+			log.Println("invalid expiry time: ", request.ExpiresAt)
+			return CodeResponse{}, util.ErrorInvalidRequest
+		}
+	}
+	if err := c.codeRepository.UpdateExpiry(ctx, code, &expiresAt); err != nil {
+		return CodeResponse{}, err
+	}
+	resp := CodeResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.ReferralCode = code
+	resp.Data.ExpiresAt = expiresAt.UnixNano() / 1000000
+	return resp, nil
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CodeHandler struct {
+	useCase CodeUseCase
+}
+
+func SetupCodeHandler(useCase CodeUseCase) *CodeHandler {
+	if useCase == nil {
+		panic("code use case is nil")
+	}
+	return &CodeHandler{
+		useCase: useCase,
+	}
+}
+
+func (h CodeHandler) DeactivateCode(e echo.Context) error {
+	res, err := h.useCase.DeactivateCode(e.Request().Context(), e.Param("code"))
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h CodeHandler) ReactivateCode(e echo.Context) error {
+	res, err := h.useCase.ReactivateCode(e.Request().Context(), e.Param("code"))
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h CodeHandler) ExpireCode(e echo.Context) error {
+	var request ExpireCodeRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+	request.ReferralCode = e.Param("code")
+
+	res, err := h.useCase.ExpireCode(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupCodeHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCodeHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupCodeHandler(&codeUseCase{})
+	})
+}
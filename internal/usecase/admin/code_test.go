@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupCodeUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupCodeUseCase(nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupCodeUseCase(&mocks.ReferralCodeRepository{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupCodeUseCase(&mocks.ReferralCodeRepository{}, &mocks.Transactor{})
+	})
+}
+
+func Test_codeUseCase_DeactivateCode(t *testing.T) {
+	t.Run("code not found", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateStatus", mock.Anything, "ABCABC123A", model.ReferralCodeStatusInactive).Return(util.ErrorDataNotFound)
+
+		c := codeUseCase{codeRepository: codeMock}
+		_, err := c.DeactivateCode(context.Background(), "ABCABC123A")
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateStatus", mock.Anything, "ABCABC123A", model.ReferralCodeStatusInactive).Return(nil)
+
+		c := codeUseCase{codeRepository: codeMock}
+		resp, err := c.DeactivateCode(context.Background(), "ABCABC123A")
+		assert.Nil(t, err)
+		assert.Equal(t, "ABCABC123A", resp.Data.ReferralCode)
+	})
+}
+
+func Test_codeUseCase_ReactivateCode(t *testing.T) {
+	t.Run("code not found", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateStatus", mock.Anything, "ABCABC123A", model.ReferralCodeStatusActive).Return(util.ErrorDataNotFound)
+
+		c := codeUseCase{codeRepository: codeMock, transactor: transactorMock()}
+		_, err := c.ReactivateCode(context.Background(), "ABCABC123A")
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("error remove expiry", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateStatus", mock.Anything, "ABCABC123A", model.ReferralCodeStatusActive).Return(nil)
+		codeMock.On("UpdateExpiry", mock.Anything, "ABCABC123A", (*time.Time)(nil)).Return(context.DeadlineExceeded)
+
+		//error of the expiry update is returned to the transactor, so the status update is rolled back
+		var txErr error
+		transactor := &mocks.Transactor{}
+		transactor.On("WithinTransaction", mock.Anything, mock.Anything).Return(
+			func(ctx context.Context, fn func(context.Context) error) error {
+				txErr = fn(ctx)
+				return txErr
+			})
+
+		c := codeUseCase{codeRepository: codeMock, transactor: transactor}
+		_, err := c.ReactivateCode(context.Background(), "ABCABC123A")
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, context.DeadlineExceeded, txErr)
+		codeMock.AssertExpectations(t)
+	})
+	t.Run("return success", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateStatus", mock.Anything, "ABCABC123A", model.ReferralCodeStatusActive).Return(nil)
+		codeMock.On("UpdateExpiry", mock.Anything, "ABCABC123A", (*time.Time)(nil)).Return(nil)
+
+		c := codeUseCase{codeRepository: codeMock, transactor: transactorMock()}
+		resp, err := c.ReactivateCode(context.Background(), "ABCABC123A")
+		assert.Nil(t, err)
+		assert.Equal(t, model.ReferralCodeStatusActive, resp.Data.Status)
+	})
+}
+
+func Test_codeUseCase_ExpireCode(t *testing.T) {
+	t.Run("invalid expiry time", func(t *testing.T) {
+		c := codeUseCase{}
+		_, err := c.ExpireCode(context.Background(), ExpireCodeRequest{ReferralCode: "ABCABC123A", ExpiresAt: "2021-08-31"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("expire immediately", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateExpiry", mock.Anything, "ABCABC123A", mock.MatchedBy(func(t *time.Time) bool {
+			return t != nil && !t.After(time.Now())
+		})).Return(nil)
+
+		c := codeUseCase{codeRepository: codeMock}
+		_, err := c.ExpireCode(context.Background(), ExpireCodeRequest{ReferralCode: "ABCABC123A"})
+		assert.Nil(t, err)
+		codeMock.AssertExpectations(t)
+	})
+	t.Run("expire at given time", func(t *testing.T) {
+		expiresAt := time.Date(2021, 8, 31, 23, 59, 59, 0, time.UTC)
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("UpdateExpiry", mock.Anything, "ABCABC123A", mock.MatchedBy(func(t *time.Time) bool {
+			return t != nil && t.Equal(expiresAt)
+		})).Return(nil)
+
+		c := codeUseCase{codeRepository: codeMock}
+		resp, err := c.ExpireCode(context.Background(), ExpireCodeRequest{ReferralCode: "ABCABC123A", ExpiresAt: "2021-08-31T23:59:59Z"})
+		assert.Nil(t, err)
+		assert.Equal(t, expiresAt.UnixNano()/1000000, resp.Data.ExpiresAt)
+	})
+}
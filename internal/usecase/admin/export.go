@@ -0,0 +1,143 @@
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+var exportCSVHeader = []string{"id", "msisdn", "code", "codeStatus", "msisdnReferee", "referralDate", "campaignId",
+	"status", "flagged", "monthTotal", "reward", "dateTime"}
+
+type ExportUseCase interface {
+	ExportReferral(ctx context.Context, request ExportReferralRequest, w io.Writer) error
+}
+
+type exportUseCase struct {
+	historyRepository model.ReferralHistoryRepository
+}
+
+func SetupExportUseCase(historyRepository model.ReferralHistoryRepository) ExportUseCase {
+	if historyRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	return &exportUseCase{
+		historyRepository: historyRepository,
+	}
+}
+
+// ExportReferral write referral matching request to w as csv or ndjson, row is written as soon as it is read from
+// db. Invalid request is returned before anything written.
+func (e exportUseCase) ExportReferral(ctx context.Context, request ExportReferralRequest, w io.Writer) (err error) {
+	filter, err := exportFilter(request)
+	if err != nil {
+		return err
+	}
+
+	var write func(ExportReferral) error
+	var flush func() error
+	switch request.Format {
+	case "", ExportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err = writer.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		write = func(row ExportReferral) error {
+			return writer.Write(exportCSVRecord(row))
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case ExportFormatNDJSON:
+		encoder := json.NewEncoder(w)
+		write = func(row ExportReferral) error {
+			return encoder.Encode(row)
+		}
+		flush = func() error {
+			return nil
+		}
+	default:
+		log.Println("invalid export format: ", request.Format)
+		return util.ErrorInvalidRequest
+	}
+
+	err = e.historyRepository.StreamExport(ctx, filter, func(v model.ReferralExport) error {
+		return write(ExportReferral{
+			ID:            v.ID,
+			Msisdn:        v.Msisdn,
+			Code:          v.Code,
+			CodeStatus:    v.CodeStatus,
+			MsisdnReferee: v.MsisdnReferee,
+			ReferralDate:  v.ReferralDate,
+			CampaignID:    v.CampaignID,
+			Status:        v.Status,
+			Flagged:       v.Flagged,
+			MonthTotal:    v.MonthTotal,
+			Reward:        v.Reward,
+			DateTime:      v.CreatedDate.UnixNano() / 1000000,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+func exportFilter(request ExportReferralRequest) (model.ReferralExportFilter, error) {
+	filter := model.ReferralExportFilter{
+		Code: request.Code,
+		From: request.From,
+		To:   request.To,
+	}
+	if request.Msisdn != "" {
+		msisdn, err := util.ValidateAndSanitizeMsisdn(request.Msisdn)
+		if err != nil {
+			return model.ReferralExportFilter{}, err
+		}
+		filter.Msisdn = msisdn
+	}
+	for _, v := range []string{request.From, request.To} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			log.Println("invalid export date: ", v)
+			return model.ReferralExportFilter{}, util.ErrorInvalidRequest
+		}
+	}
+	if request.From != "" && request.To != "" && request.To < request.From {
+		log.Println("export end date before start date: ", request.From, request.To)
+		return model.ReferralExportFilter{}, util.ErrorInvalidRequest
+	}
+	return filter, nil
+}
+
+func exportCSVRecord(row ExportReferral) []string {
+	return []string{
+		strconv.FormatInt(row.ID, 10),
+		row.Msisdn,
+		row.Code,
+		strconv.Itoa(row.CodeStatus),
+		row.MsisdnReferee,
+		row.ReferralDate,
+		strconv.FormatInt(row.CampaignID, 10),
+		strconv.Itoa(row.Status),
+		strconv.FormatBool(row.Flagged),
+		strconv.Itoa(row.MonthTotal),
+		row.Reward,
+		strconv.FormatInt(row.DateTime, 10),
+	}
+}
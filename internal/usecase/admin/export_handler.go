@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ExportHandler struct {
+	useCase ExportUseCase
+}
+
+func SetupExportHandler(useCase ExportUseCase) *ExportHandler {
+	if useCase == nil {
+		panic("export use case is nil")
+	}
+	return &ExportHandler{
+		useCase: useCase,
+	}
+}
+
+func (h ExportHandler) ExportReferral(e echo.Context) error {
+	request := ExportReferralRequest{
+		Format: e.QueryParam("format"),
+		Msisdn: e.QueryParam("msisdn"),
+		Code:   e.QueryParam("code"),
+		From:   e.QueryParam("from"),
+		To:     e.QueryParam("to"),
+	}
+
+	contentType, extension := "text/csv", ExportFormatCSV
+	if request.Format == ExportFormatNDJSON {
+		contentType, extension = "application/x-ndjson", ExportFormatNDJSON
+	}
+	header := e.Response().Header()
+	header.Set(echo.HeaderContentType, contentType)
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=referral_%s.%s",
+		time.Now().Format("20060102150405"), extension))
+
+	err := h.useCase.ExportReferral(e.Request().Context(), request, e.Response())
+	//handle error response
+	if err != nil {
+		//error after rows streamed can not be sent to client anymore
+		if e.Response().Committed {
+			e.Logger().Error(err)
+			return nil
+		}
+		header.Del(echo.HeaderContentDisposition)
+		return err
+	}
+	if !e.Response().Committed {
+		e.Response().WriteHeader(http.StatusOK)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupExportHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupExportHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupExportHandler(&exportUseCase{})
+	})
+}
+
+func TestExportHandler_ExportReferral(t *testing.T) {
+	t.Run("invalid request is returned before streaming", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/1.0/admin/referral/export?from=2021-13-01", nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		h := SetupExportHandler(&exportUseCase{historyRepository: &mocks.ReferralHistoryRepository{}})
+		err := h.ExportReferral(c)
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+	})
+	t.Run("stream ndjson", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("StreamExport", mock.Anything, mock.Anything, mock.Anything).
+			Run(streamExport(model.ReferralExport{ID: 1}, model.ReferralExport{ID: 2})).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/1.0/admin/referral/export?format=ndjson", nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		h := SetupExportHandler(&exportUseCase{historyRepository: historyMock})
+		err := h.ExportReferral(c)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), ".ndjson")
+	})
+}
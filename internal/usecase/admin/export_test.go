@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupExportUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupExportUseCase(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupExportUseCase(&mocks.ReferralHistoryRepository{})
+	})
+}
+
+func streamExport(rows ...model.ReferralExport) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func(model.ReferralExport) error)
+		for _, v := range rows {
+			_ = fn(v)
+		}
+	}
+}
+
+func Test_exportUseCase_ExportReferral(t *testing.T) {
+	createdDate := time.Date(2021, 8, 12, 7, 0, 0, 0, time.UTC)
+	rows := []model.ReferralExport{
+		{ID: 1, Msisdn: "62821000000", Code: "ABCABC123A", CodeStatus: 1, MsisdnReferee: "62821000001",
+			ReferralDate: "2021-08-12", Status: 1, MonthTotal: 1, Reward: "bonus 2 GB", CreatedDate: createdDate},
+		{ID: 2, Msisdn: "62821000000", Code: "ABCABC123A", CodeStatus: 1, MsisdnReferee: "62821000002",
+			ReferralDate: "2021-08-12", CampaignID: 3, Status: 0, MonthTotal: 1, Reward: "bonus 2 GB", CreatedDate: createdDate},
+	}
+	t.Run("invalid date", func(t *testing.T) {
+		e := exportUseCase{}
+		err := e.ExportReferral(context.Background(), ExportReferralRequest{From: "12-08-2021"}, &bytes.Buffer{})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("end date before start date", func(t *testing.T) {
+		e := exportUseCase{}
+		err := e.ExportReferral(context.Background(), ExportReferralRequest{From: "2021-08-12", To: "2021-08-01"}, &bytes.Buffer{})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("invalid msisdn", func(t *testing.T) {
+		e := exportUseCase{}
+		err := e.ExportReferral(context.Background(), ExportReferralRequest{Msisdn: "080000acbcd"}, &bytes.Buffer{})
+		assert.NotNil(t, err)
+	})
+	t.Run("invalid format, nothing written", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		e := exportUseCase{}
+		err := e.ExportReferral(context.Background(), ExportReferralRequest{Format: "xml"}, out)
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+		assert.Equal(t, 0, out.Len())
+	})
+	t.Run("error stream", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("StreamExport", mock.Anything, mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		e := exportUseCase{historyRepository: historyMock}
+		err := e.ExportReferral(context.Background(), ExportReferralRequest{}, &bytes.Buffer{})
+		assert.NotNil(t, err)
+	})
+	t.Run("export csv", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("StreamExport", mock.Anything, model.ReferralExportFilter{Msisdn: "62821000000", From: "2021-08-01",
+			To: "2021-08-31"}, mock.Anything).Run(streamExport(rows...)).Return(nil)
+
+		out := &bytes.Buffer{}
+		e := exportUseCase{historyRepository: historyMock}
+		err := e.ExportReferral(context.Background(), ExportReferralRequest{Msisdn: "62821000000", From: "2021-08-01",
+			To: "2021-08-31"}, out)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"id,msisdn,code,codeStatus,msisdnReferee,referralDate,campaignId,status,flagged,monthTotal,reward,dateTime",
+			"1,62821000000,ABCABC123A,1,62821000001,2021-08-12,0,1,false,1,bonus 2 GB,1628751600000",
+			"2,62821000000,ABCABC123A,1,62821000002,2021-08-12,3,0,false,1,bonus 2 GB,1628751600000",
+		}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+	})
+	t.Run("export ndjson", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("StreamExport", mock.Anything, model.ReferralExportFilter{Code: "ABCABC123A"}, mock.Anything).
+			Run(streamExport(rows...)).Return(nil)
+
+		out := &bytes.Buffer{}
+		e := exportUseCase{historyRepository: historyMock}
+		err := e.ExportReferral(context.Background(), ExportReferralRequest{Format: ExportFormatNDJSON, Code: "ABCABC123A"}, out)
+		assert.Nil(t, err)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, 2, len(lines))
+		assert.Equal(t, `{"id":2,"msisdn":"62821000000","code":"ABCABC123A","codeStatus":1,"msisdnReferee":"62821000002",`+
+			`"referralDate":"2021-08-12","campaignId":3,"status":0,"flagged":false,"monthTotal":1,"reward":"bonus 2 GB",`+
+			`"dateTime":1628751600000}`, lines[1])
+	})
+}
@@ -0,0 +1,269 @@
+package admin
+
+type CreateRewardRequest struct {
+	TotalReferral int    `json:"totalReferral" validate:"required,min=1"`
+	Reward        string `json:"reward" validate:"required,max=100"`
+	CampaignID    int64  `json:"campaignId" validate:"omitempty,min=1"`
+	Level         int    `json:"level" validate:"omitempty,min=1"`
+}
+
+type UpdateRewardRequest struct {
+	ID            int64  `json:"-"`
+	TotalReferral int    `json:"totalReferral" validate:"omitempty,min=1"`
+	Reward        string `json:"reward" validate:"omitempty,max=100"`
+}
+
+type RewardResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    Reward `json:"data"`
+}
+
+type ListRewardResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []Reward `json:"list"`
+	} `json:"data"`
+}
+
+type Reward struct {
+	ID            int64  `json:"id"`
+	TotalReferral int    `json:"totalReferral"`
+	Reward        string `json:"reward"`
+	CampaignID    int64  `json:"campaignId,omitempty"`
+	Level         int    `json:"level"`
+	Status        int    `json:"status"`
+	CreatedDate   int64  `json:"createdDate"`
+	UpdatedDate   int64  `json:"updatedDate"`
+}
+
+type CreateCampaignRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	StartDate string `json:"startDate" validate:"required,len=10"`
+	EndDate   string `json:"endDate" validate:"required,len=10"`
+}
+
+type UpdateCampaignRequest struct {
+	ID        int64  `json:"-"`
+	Name      string `json:"name" validate:"omitempty,max=100"`
+	StartDate string `json:"startDate" validate:"omitempty,len=10"`
+	EndDate   string `json:"endDate" validate:"omitempty,len=10"`
+}
+
+type CampaignResponse struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	Data    Campaign `json:"data"`
+}
+
+type ListCampaignResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []Campaign `json:"list"`
+	} `json:"data"`
+}
+
+type Campaign struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	Status      int    `json:"status"`
+	CreatedDate int64  `json:"createdDate"`
+	UpdatedDate int64  `json:"updatedDate"`
+}
+
+type ExpireCodeRequest struct {
+	ReferralCode string `json:"-"`
+	ExpiresAt    string `json:"expiresAt"`
+}
+
+type CodeResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		ReferralCode string `json:"referralCode"`
+		Status       int    `json:"status,omitempty"`
+		ExpiresAt    int64  `json:"expiresAt,omitempty"`
+	} `json:"data"`
+}
+
+type ReverseReferralRequest struct {
+	MsisdnReferee string `json:"-"`
+	Reason        string `json:"reason" validate:"required,oneof=churn fraud duplicate other"`
+}
+
+type ReverseReferralResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Msisdn        string          `json:"msisdn"`
+		MsisdnReferee string          `json:"msisdnReferee"`
+		ReferralDate  string          `json:"referralDate"`
+		Reason        string          `json:"reason"`
+		Clawback      []ClawbackClaim `json:"clawback"`
+	} `json:"data"`
+}
+
+type ClawbackClaim struct {
+	Msisdn        string `json:"msisdn"`
+	Period        string `json:"period"`
+	Level         int    `json:"level"`
+	TotalReferral int    `json:"totalReferral"`
+	Reward        string `json:"reward"`
+}
+
+type ExportReferralRequest struct {
+	// Format is csv (default) or ndjson
+	Format string
+	Msisdn string
+	Code   string
+	From   string
+	To     string
+}
+
+type ExportReferral struct {
+	ID            int64  `json:"id"`
+	Msisdn        string `json:"msisdn"`
+	Code          string `json:"code"`
+	CodeStatus    int    `json:"codeStatus"`
+	MsisdnReferee string `json:"msisdnReferee"`
+	ReferralDate  string `json:"referralDate"`
+	CampaignID    int64  `json:"campaignId,omitempty"`
+	Status        int    `json:"status"`
+	Flagged       bool   `json:"flagged"`
+	MonthTotal    int    `json:"monthTotal"`
+	Reward        string `json:"reward"`
+	DateTime      int64  `json:"dateTime"`
+}
+
+type ReportRequest struct {
+	From     string
+	To       string
+	Interval string
+}
+
+type ReportResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		From     string             `json:"from"`
+		To       string             `json:"to"`
+		Interval string             `json:"interval"`
+		Series   []ReportStatistic  `json:"series"`
+		Tiers    []TierDistribution `json:"tiers"`
+	} `json:"data"`
+}
+
+type ReportStatistic struct {
+	Period          string  `json:"period"`
+	TotalReferral   int     `json:"totalReferral"`
+	ActiveReferrer  int     `json:"activeReferrer"`
+	AverageReferral float64 `json:"averageReferral"`
+}
+
+type TierDistribution struct {
+	RewardID      int64  `json:"rewardId"`
+	TotalReferral int    `json:"totalReferral"`
+	Reward        string `json:"reward"`
+	TotalReferrer int    `json:"totalReferrer"`
+}
+
+type ClickStatisticRequest struct {
+	Code  string
+	From  string
+	To    string
+	Limit int
+}
+
+type ClickStatisticResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		From string           `json:"from"`
+		To   string           `json:"to"`
+		List []ClickStatistic `json:"list"`
+	} `json:"data"`
+}
+
+type ClickStatistic struct {
+	ReferralCode  string `json:"referralCode"`
+	Msisdn        string `json:"msisdn"`
+	TotalClick    int    `json:"totalClick"`
+	TotalReferral int    `json:"totalReferral"`
+	// ConversionRate is percentage of referral per click, 0 when code has no click
+	ConversionRate float64 `json:"conversionRate"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,max=255"`
+	Events []string `json:"events" validate:"required,min=1"`
+	// Secret is generated when empty, it is only returned on create
+	Secret string `json:"secret" validate:"omitempty,min=16,max=100"`
+}
+
+type WebhookResponse struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Data    Webhook `json:"data"`
+}
+
+type ListWebhookResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []Webhook `json:"list"`
+	} `json:"data"`
+}
+
+type Webhook struct {
+	ID          int64    `json:"id"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret"`
+	Events      []string `json:"events"`
+	Status      int      `json:"status"`
+	CreatedDate int64    `json:"createdDate"`
+	UpdatedDate int64    `json:"updatedDate"`
+}
+
+type ListWebhookDeliveryRequest struct {
+	// Status default to dead
+	Status string
+	Page   int
+	Limit  int
+}
+
+type ListWebhookDeliveryResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List  []WebhookDelivery `json:"list"`
+		Total int               `json:"total"`
+		Page  int               `json:"page"`
+		Limit int               `json:"limit"`
+	} `json:"data"`
+}
+
+type WebhookDelivery struct {
+	ID              int64  `json:"id"`
+	SubscriptionID  int64  `json:"subscriptionId"`
+	EventID         string `json:"eventId"`
+	Event           string `json:"event"`
+	Status          int    `json:"status"`
+	Attempt         int    `json:"attempt"`
+	NextAttemptDate int64  `json:"nextAttemptDate"`
+	ResponseCode    int    `json:"responseCode,omitempty"`
+	LastError       string `json:"lastError,omitempty"`
+	CreatedDate     int64  `json:"createdDate"`
+	UpdatedDate     int64  `json:"updatedDate"`
+}
+
+type ReplayWebhookResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Total int64 `json:"total"`
+	} `json:"data"`
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/counter"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type ReferralUseCase interface {
+	ReverseReferral(ctx context.Context, request ReverseReferralRequest) (ReverseReferralResponse, error)
+}
+
+type referralUseCase struct {
+	historyRepository model.ReferralHistoryRepository
+	claimRepository   model.RewardClaimRepository
+	config            config.ReferralConfig
+	transactor        model.Transactor
+	outboxRepository  model.OutboxRepository
+	tracker           counter.Tracker
+}
+
+func SetupReferralUseCase(historyRepository model.ReferralHistoryRepository, claimRepository model.RewardClaimRepository,
+	referralConfig *config.ReferralConfig, transactor model.Transactor, outboxRepository model.OutboxRepository,
+	tracker counter.Tracker) ReferralUseCase {
+	if historyRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	if claimRepository == nil {
+		panic("RewardClaimRepository is nil")
+	}
+	if referralConfig == nil {
+		panic("ReferralConfig is nil")
+	}
+	if transactor == nil {
+		panic("Transactor is nil")
+	}
+	if outboxRepository == nil {
+		panic("OutboxRepository is nil")
+	}
+	if tracker == nil {
+		panic("Tracker is nil")
+	}
+	return &referralUseCase{
+		historyRepository: historyRepository,
+		claimRepository:   claimRepository,
+		config:            *referralConfig,
+		transactor:        transactor,
+		outboxRepository:  outboxRepository,
+		tracker:           tracker,
+	}
+}
+
+// ReverseReferral mark referral of referee as reversed and claw back claim of every upline which tier is no longer
+// reached in the period of the referral, both are stored in the same transaction
+func (r referralUseCase) ReverseReferral(ctx context.Context, request ReverseReferralRequest) (ReverseReferralResponse, error) {
+	msisdnReferee, err := util.ValidateAndSanitizeMsisdn(request.MsisdnReferee)
+	if err != nil {
+		return ReverseReferralResponse{}, err
+	}
+
+	history, err := r.historyRepository.FindByMsisdnReferee(ctx, msisdnReferee)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ReverseReferralResponse{}, util.ErrorDataNotFound
+	}
+	if err != nil {
+		return ReverseReferralResponse{}, err
+	}
+	if !history.CanTransitTo(model.ReferralStatusReversed) {
+		log.Println("referral can not be reversed: ", msisdnReferee, history.Status)
+		return ReverseReferralResponse{}, util.ErrorReferralStatusInvalid
+	}
+
+	//store reversal together with its clawback and event
+	var clawback []ClawbackClaim
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		if err = r.historyRepository.Reverse(ctx, history.ID, request.Reason); err != nil {
+			return err
+		}
+		if clawback, err = r.clawbackClaim(ctx, history); err != nil {
+			return err
+		}
+		return outbox.Write(ctx, r.outboxRepository, outbox.EventReferralReversed, outbox.ReferralEvent{
+			Msisdn:        history.Msisdn,
+			MsisdnReferee: history.MsisdnReferee,
+			ReferralCode:  history.Code,
+			ReferralDate:  history.ReferralDate,
+			CampaignID:    history.CampaignID,
+			Status:        model.ReferralStatusReversed,
+			Flagged:       history.Flagged,
+			Reason:        request.Reason,
+		})
+	})
+	if err != nil {
+		return ReverseReferralResponse{}, err
+	}
+	//only confirmed or rewarded referral which is not flagged is counted, counter is a cache updated once committed
+	if !history.Flagged && history.Status != model.ReferralStatusPending {
+		r.tracker.Add(ctx, history.Msisdn, history.ReferralDate[:7], -1)
+	}
+
+	resp := ReverseReferralResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Msisdn = history.Msisdn
+	resp.Data.MsisdnReferee = history.MsisdnReferee
+	resp.Data.ReferralDate = history.ReferralDate
+	resp.Data.Reason = request.Reason
+	resp.Data.Clawback = clawback
+	return resp, nil
+}
+
+// clawbackClaim walk the upline of reversed referral, referrer is level 1 and its referrer is level 2 and so on
+func (r referralUseCase) clawbackClaim(ctx context.Context, history model.ReferralHistory) ([]ClawbackClaim, error) {
+	period := history.ReferralDate[:7]
+	result := make([]ClawbackClaim, 0)
+
+	msisdn := history.Msisdn
+	for level := 1; level <= r.config.Depth(); level++ {
+		total, err := r.getTotalReferral(ctx, msisdn, period, level)
+		if err != nil {
+			return nil, err
+		}
+
+		claims, err := r.claimRepository.FindByMsisdn(ctx, msisdn)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range claims {
+			if v.Period != period || v.Level != level || v.Status != model.RewardClaimStatusClaimed {
+				continue
+			}
+			//tier still reached after reversal
+			if v.TotalReferral <= total {
+				continue
+			}
+			if err = r.claimRepository.UpdateStatus(ctx, v.ID, model.RewardClaimStatusClawback); err != nil {
+				return nil, err
+			}
+			log.Println("reward claim clawed back: ", v.Msisdn, v.RewardID, period)
+			result = append(result, ClawbackClaim{
+				Msisdn:        v.Msisdn,
+				Period:        v.Period,
+				Level:         v.Level,
+				TotalReferral: v.TotalReferral,
+				Reward:        v.Description,
+			})
+		}
+		if level == r.config.Depth() {
+			break
+		}
+
+		upline, err := r.historyRepository.FindByMsisdnReferee(ctx, msisdn)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		msisdn = upline.Msisdn
+	}
+	return result, nil
+}
+
+func (r referralUseCase) getTotalReferral(ctx context.Context, msisdn, period string, level int) (int, error) {
+	if level == 1 {
+		return r.historyRepository.GetTotalByMsisdnAndMonth(ctx, msisdn, period)
+	}
+	totals, err := r.historyRepository.GetTotalPerLevelByMsisdnAndMonth(ctx, msisdn, period, level)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range totals {
+		if v.Level == level {
+			return v.Total, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReferralHandler struct {
+	useCase ReferralUseCase
+}
+
+func SetupReferralHandler(useCase ReferralUseCase) *ReferralHandler {
+	if useCase == nil {
+		panic("referral use case is nil")
+	}
+	return &ReferralHandler{
+		useCase: useCase,
+	}
+}
+
+func (h ReferralHandler) ReverseReferral(e echo.Context) error {
+	var request ReverseReferralRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+
+	if err := validate(e, &request); err != nil {
+		return err
+	}
+	request.MsisdnReferee = e.Param("msisdn")
+
+	res, err := h.useCase.ReverseReferral(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
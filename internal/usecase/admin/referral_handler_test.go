@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupReferralHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReferralHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupReferralHandler(&referralUseCase{})
+	})
+}
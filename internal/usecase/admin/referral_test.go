@@ -0,0 +1,307 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	counterMocks "github.com/candraalim/be_tsel_candra/internal/mock/counter"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/storage/sqlite"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupReferralUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReferralUseCase(nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferralUseCase(&mocks.ReferralHistoryRepository{}, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferralUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardClaimRepository{}, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferralUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardClaimRepository{}, &config.ReferralConfig{},
+			nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferralUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardClaimRepository{}, &config.ReferralConfig{},
+			&mocks.Transactor{}, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferralUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardClaimRepository{}, &config.ReferralConfig{},
+			&mocks.Transactor{}, &mocks.OutboxRepository{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupReferralUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardClaimRepository{}, &config.ReferralConfig{},
+			&mocks.Transactor{}, &mocks.OutboxRepository{}, &counterMocks.Tracker{})
+	})
+}
+
+// transactorMock run fn directly as if it were in a transaction
+func transactorMock() *mocks.Transactor {
+	transactor := &mocks.Transactor{}
+	transactor.On("WithinTransaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	return transactor
+}
+
+func Test_referralUseCase_ReverseReferral(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		r := referralUseCase{}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "080000acbcd", Reason: "churn"})
+		assert.NotNil(t, err)
+	})
+	t.Run("referral not found", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{}, sql.ErrNoRows)
+
+		r := referralUseCase{historyRepository: historyMock}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("referral already reversed", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Status: model.ReferralStatusReversed}, nil)
+
+		r := referralUseCase{historyRepository: historyMock}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Equal(t, util.ErrorReferralStatusInvalid, err)
+	})
+	t.Run("expired referral can not be reversed", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Status: model.ReferralStatusExpired}, nil)
+
+		r := referralUseCase{historyRepository: historyMock}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Equal(t, util.ErrorReferralStatusInvalid, err)
+	})
+	t.Run("error reverse", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Status: model.ReferralStatusConfirmed}, nil)
+		historyMock.On("Reverse", mock.Anything, int64(3), "churn").Return(context.DeadlineExceeded)
+
+		r := referralUseCase{historyRepository: historyMock, transactor: transactorMock()}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.NotNil(t, err)
+	})
+	t.Run("error write event", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Msisdn: "62821000000", MsisdnReferee: "62821000001", ReferralDate: "2021-08-12", Status: model.ReferralStatusConfirmed}, nil)
+		historyMock.On("Reverse", mock.Anything, int64(3), "churn").Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(5, nil)
+
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("Insert", mock.Anything, mock.Anything).Return(sql.ErrConnDone)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdn", mock.Anything, "62821000000").Return([]model.RewardClaim{}, nil)
+
+		trackerMock := &counterMocks.Tracker{}
+
+		r := referralUseCase{historyRepository: historyMock, claimRepository: claimMock, transactor: transactorMock(),
+			outboxRepository: outboxMock, tracker: trackerMock}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Equal(t, sql.ErrConnDone, err)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("error clawback fail the reversal", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Msisdn: "62821000000", MsisdnReferee: "62821000001", ReferralDate: "2021-08-12", Status: model.ReferralStatusConfirmed}, nil)
+		historyMock.On("Reverse", mock.Anything, int64(3), "churn").Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(4, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdn", mock.Anything, "62821000000").Return([]model.RewardClaim{
+			{ID: 7, Msisdn: "62821000000", TotalReferral: 5, Period: "2021-08", Level: 1, Status: model.RewardClaimStatusClaimed},
+		}, nil)
+		claimMock.On("UpdateStatus", mock.Anything, int64(7), model.RewardClaimStatusClawback).Return(sql.ErrConnDone)
+
+		outboxMock := &mocks.OutboxRepository{}
+		trackerMock := &counterMocks.Tracker{}
+
+		r := referralUseCase{historyRepository: historyMock, claimRepository: claimMock, transactor: transactorMock(),
+			outboxRepository: outboxMock, tracker: trackerMock}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Equal(t, sql.ErrConnDone, err)
+		outboxMock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("tier still reached, nothing clawed back", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Msisdn: "62821000000", MsisdnReferee: "62821000001", ReferralDate: "2021-08-12", Status: model.ReferralStatusConfirmed}, nil)
+		historyMock.On("Reverse", mock.Anything, int64(3), "churn").Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(5, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdn", mock.Anything, "62821000000").Return([]model.RewardClaim{
+			{ID: 7, Msisdn: "62821000000", TotalReferral: 5, Period: "2021-08", Level: 1, Status: model.RewardClaimStatusClaimed},
+		}, nil)
+
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Add", mock.Anything, "62821000000", "2021-08", -1).Return()
+
+		r := referralUseCase{historyRepository: historyMock, claimRepository: claimMock, transactor: transactorMock(),
+			outboxRepository: outboxMock, tracker: trackerMock}
+		resp, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Nil(t, err)
+		assert.Equal(t, "62821000000", resp.Data.Msisdn)
+		assert.Equal(t, 0, len(resp.Data.Clawback))
+		claimMock.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+		trackerMock.AssertExpectations(t)
+	})
+	t.Run("pending referral is not counted", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Msisdn: "62821000000", MsisdnReferee: "62821000001", ReferralDate: "2021-08-12", Status: model.ReferralStatusPending}, nil)
+		historyMock.On("Reverse", mock.Anything, int64(3), "churn").Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(5, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdn", mock.Anything, "62821000000").Return([]model.RewardClaim{}, nil)
+
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+		trackerMock := &counterMocks.Tracker{}
+
+		r := referralUseCase{historyRepository: historyMock, claimRepository: claimMock, transactor: transactorMock(),
+			outboxRepository: outboxMock, tracker: trackerMock}
+		_, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Nil(t, err)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("clawback claim of referrer and upline", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000001").Return(model.ReferralHistory{ID: 3,
+			Msisdn: "62821000000", MsisdnReferee: "62821000001", ReferralDate: "2021-08-12", Status: model.ReferralStatusConfirmed}, nil)
+		historyMock.On("Reverse", mock.Anything, int64(3), "fraud").Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(4, nil)
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "62821000000").Return(model.ReferralHistory{Msisdn: "62821000009"}, nil)
+		historyMock.On("GetTotalPerLevelByMsisdnAndMonth", mock.Anything, "62821000009", "2021-08", 2).
+			Return([]model.ReferralLevelTotal{{Level: 1, Total: 1}, {Level: 2, Total: 2}}, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdn", mock.Anything, "62821000000").Return([]model.RewardClaim{
+			{ID: 7, Msisdn: "62821000000", TotalReferral: 5, Description: "bonus 12 GB", Period: "2021-08", Level: 1,
+				Status: model.RewardClaimStatusClaimed},
+			{ID: 6, Msisdn: "62821000000", TotalReferral: 1, Period: "2021-08", Level: 1, Status: model.RewardClaimStatusClaimed},
+			{ID: 2, Msisdn: "62821000000", TotalReferral: 5, Period: "2021-07", Level: 1, Status: model.RewardClaimStatusClaimed},
+		}, nil)
+		claimMock.On("FindByMsisdn", mock.Anything, "62821000009").Return([]model.RewardClaim{
+			{ID: 8, Msisdn: "62821000009", TotalReferral: 3, Description: "bonus 500 MB", Period: "2021-08", Level: 2,
+				Status: model.RewardClaimStatusClaimed},
+		}, nil)
+		claimMock.On("UpdateStatus", mock.Anything, int64(7), model.RewardClaimStatusClawback).Return(nil)
+		claimMock.On("UpdateStatus", mock.Anything, int64(8), model.RewardClaimStatusClawback).Return(nil)
+
+		outboxMock := &mocks.OutboxRepository{}
+		outboxMock.On("Insert", mock.Anything, mock.MatchedBy(func(e *model.OutboxEvent) bool {
+			var data outbox.ReferralEvent
+			return e.Event == outbox.EventReferralReversed && json.Unmarshal([]byte(e.Payload), &data) == nil &&
+				data.MsisdnReferee == "62821000001" && data.Status == model.ReferralStatusReversed && data.Reason == "fraud"
+		})).Return(nil)
+
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Add", mock.Anything, "62821000000", "2021-08", -1).Return()
+
+		r := referralUseCase{historyRepository: historyMock, claimRepository: claimMock, config: config.ReferralConfig{MaxLevel: 2},
+			transactor: transactorMock(), outboxRepository: outboxMock, tracker: trackerMock}
+		resp, err := r.ReverseReferral(context.Background(), ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "fraud"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resp.Data.Clawback))
+		assert.Equal(t, "bonus 12 GB", resp.Data.Clawback[0].Reward)
+		assert.Equal(t, 2, resp.Data.Clawback[1].Level)
+		claimMock.AssertExpectations(t)
+		outboxMock.AssertExpectations(t)
+	})
+}
+
+// setupSQLite return database file removed once the test finished
+func setupSQLite(t *testing.T) *sqlite.Database {
+	dir, err := ioutil.TempDir("", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sqlite.NewDatabase(&config.SQLiteConfig{Path: filepath.Join(dir, "referral.db")})
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	})
+	return db
+}
+
+func Test_referralUseCase_ReverseReferral_SQLite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("flagged referral is not counted", func(t *testing.T) {
+		db := setupSQLite(t)
+		historyRepository := sqlite.SetupReferralHistoryRepository(db)
+		assert.Nil(t, historyRepository.Insert(ctx, &model.ReferralHistory{Msisdn: "62821000000", Code: "ABCABC123A",
+			MsisdnReferee: "62821000001", ReferralDate: "2021-08-12", CampaignID: 5, Flagged: true,
+			FlagReason: "velocity_code", Status: model.ReferralStatusConfirmed}))
+		outboxRepository := sqlite.SetupOutboxRepository(db)
+		trackerMock := &counterMocks.Tracker{}
+
+		r := SetupReferralUseCase(historyRepository, sqlite.SetupRewardClaimRepository(db), &config.ReferralConfig{},
+			db, outboxRepository, trackerMock)
+		resp, err := r.ReverseReferral(ctx, ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "fraud"})
+		assert.Nil(t, err)
+		assert.Equal(t, "62821000000", resp.Data.Msisdn)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		events, err := outboxRepository.ClaimDue(ctx, time.Minute, 10)
+		assert.Nil(t, err)
+		assert.Len(t, events, 1)
+		var data outbox.ReferralEvent
+		assert.Nil(t, json.Unmarshal([]byte(events[0].Payload), &data))
+		assert.True(t, data.Flagged)
+		assert.Equal(t, int64(5), data.CampaignID)
+		assert.Equal(t, model.ReferralStatusReversed, data.Status)
+	})
+	t.Run("counted referral is clawed back", func(t *testing.T) {
+		db := setupSQLite(t)
+		historyRepository := sqlite.SetupReferralHistoryRepository(db)
+		assert.Nil(t, historyRepository.Insert(ctx, &model.ReferralHistory{Msisdn: "62821000000", Code: "ABCABC123A",
+			MsisdnReferee: "62821000001", ReferralDate: "2021-08-12", Status: model.ReferralStatusConfirmed}))
+		claimRepository := sqlite.SetupRewardClaimRepository(db)
+		assert.Nil(t, claimRepository.Insert(ctx, &model.RewardClaim{Msisdn: "62821000000", RewardID: 1, TotalReferral: 1,
+			Description: "bonus 2 GB", Period: "2021-08", Level: 1}))
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Add", mock.Anything, "62821000000", "2021-08", -1).Return()
+
+		r := SetupReferralUseCase(historyRepository, claimRepository, &config.ReferralConfig{}, db,
+			sqlite.SetupOutboxRepository(db), trackerMock)
+		resp, err := r.ReverseReferral(ctx, ReverseReferralRequest{MsisdnReferee: "62821000001", Reason: "churn"})
+		assert.Nil(t, err)
+		assert.Len(t, resp.Data.Clawback, 1)
+		trackerMock.AssertExpectations(t)
+
+		claims, err := claimRepository.FindByMsisdn(ctx, "62821000000")
+		assert.Nil(t, err)
+		assert.Equal(t, model.RewardClaimStatusClawback, claims[0].Status)
+	})
+}
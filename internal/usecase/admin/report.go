@@ -0,0 +1,162 @@
+package admin
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const (
+	defaultReportDays = 30
+	maxReportDays     = 366
+)
+
+type ReportUseCase interface {
+	GetReferralReport(ctx context.Context, request ReportRequest) (ReportResponse, error)
+}
+
+type reportUseCase struct {
+	historyRepository model.ReferralHistoryRepository
+}
+
+func SetupReportUseCase(historyRepository model.ReferralHistoryRepository) ReportUseCase {
+	if historyRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	return &reportUseCase{
+		historyRepository: historyRepository,
+	}
+}
+
+// GetReferralReport return counted referral, active referrer and average referral per referrer of each period
+// between from and to, with distribution of referrer across reward tier. Default to last 30 days per day.
+func (r reportUseCase) GetReferralReport(ctx context.Context, request ReportRequest) (resp ReportResponse, err error) {
+	from, to, err := reportRange(request.From, request.To)
+	if err != nil {
+		return ReportResponse{}, err
+	}
+	interval := request.Interval
+	if interval == "" {
+		interval = model.ReportIntervalDay
+	}
+	if interval != model.ReportIntervalDay && interval != model.ReportIntervalWeek && interval != model.ReportIntervalMonth {
+		log.Println("invalid report interval: ", interval)
+		return ReportResponse{}, util.ErrorInvalidRequest
+	}
+
+	var statistics []model.ReferralStatistic
+	var tiers []model.ReferralTierDistribution
+
+	eg := errgroup.Group{}
+	eg.Go(func() error {
+		var er error
+		statistics, er = r.historyRepository.GetStatistic(ctx, from.Format("2006-01-02"), to.Format("2006-01-02"), interval)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		tiers, er = r.historyRepository.GetTierDistribution(ctx, from.Format("2006-01-02"), to.Format("2006-01-02"))
+		return er
+	})
+	if err = eg.Wait(); err != nil {
+		return ReportResponse{}, err
+	}
+
+	resp = ReportResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.From = from.Format("2006-01-02")
+	resp.Data.To = to.Format("2006-01-02")
+	resp.Data.Interval = interval
+	resp.Data.Series = reportSeries(statistics, from, to, interval)
+	resp.Data.Tiers = make([]TierDistribution, len(tiers))
+	for i, v := range tiers {
+		resp.Data.Tiers[i] = TierDistribution{
+			RewardID:      v.RewardID,
+			TotalReferral: v.TotalReferral,
+			Reward:        v.Reward,
+			TotalReferrer: v.TotalReferrer,
+		}
+	}
+	return resp, nil
+}
+
+func reportRange(fromDate, toDate string) (from, to time.Time, err error) {
+	to = time.Now()
+	if toDate != "" {
+		if to, err = time.Parse("2006-01-02", toDate); err != nil {
+			log.Println("invalid report end date: ", toDate)
+			return from, to, util.ErrorInvalidRequest
+		}
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	from = to.AddDate(0, 0, 1-defaultReportDays)
+	if fromDate != "" {
+		if from, err = time.Parse("2006-01-02", fromDate); err != nil {
+			log.Println("invalid report start date: ", fromDate)
+			return from, to, util.ErrorInvalidRequest
+		}
+	}
+	if to.Before(from) || to.Sub(from) >= maxReportDays*24*time.Hour {
+		log.Println("invalid report range: ", fromDate, toDate)
+		return from, to, util.ErrorInvalidRequest
+	}
+	return from, to, nil
+}
+
+// reportSeries fill period without referral so the series is continuous
+func reportSeries(statistics []model.ReferralStatistic, from, to time.Time, interval string) []ReportStatistic {
+	byPeriod := make(map[string]model.ReferralStatistic, len(statistics))
+	for _, v := range statistics {
+		byPeriod[v.Period] = v
+	}
+
+	result := make([]ReportStatistic, 0)
+	for current := periodStart(from, interval); !current.After(to); current = nextPeriod(current, interval) {
+		period := current.Format("2006-01-02")
+		if interval == model.ReportIntervalMonth {
+			period = current.Format("2006-01")
+		}
+		v := byPeriod[period]
+		var average float64
+		if v.ActiveReferrer > 0 {
+			average = math.Round(float64(v.TotalReferral)/float64(v.ActiveReferrer)*100) / 100
+		}
+		result = append(result, ReportStatistic{
+			Period:          period,
+			TotalReferral:   v.TotalReferral,
+			ActiveReferrer:  v.ActiveReferrer,
+			AverageReferral: average,
+		})
+	}
+	return result
+}
+
+// periodStart return first date of period, week start on monday
+func periodStart(date time.Time, interval string) time.Time {
+	switch interval {
+	case model.ReportIntervalWeek:
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case model.ReportIntervalMonth:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return date
+}
+
+func nextPeriod(date time.Time, interval string) time.Time {
+	switch interval {
+	case model.ReportIntervalWeek:
+		return date.AddDate(0, 0, 7)
+	case model.ReportIntervalMonth:
+		return date.AddDate(0, 1, 0)
+	}
+	return date.AddDate(0, 0, 1)
+}
@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReportHandler struct {
+	useCase ReportUseCase
+}
+
+func SetupReportHandler(useCase ReportUseCase) *ReportHandler {
+	if useCase == nil {
+		panic("report use case is nil")
+	}
+	return &ReportHandler{
+		useCase: useCase,
+	}
+}
+
+func (h ReportHandler) GetReferralReport(e echo.Context) error {
+	res, err := h.useCase.GetReferralReport(e.Request().Context(), ReportRequest{
+		From:     e.QueryParam("from"),
+		To:       e.QueryParam("to"),
+		Interval: e.QueryParam("interval"),
+	})
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupReportHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReportHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupReportHandler(&reportUseCase{})
+	})
+}
@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupReportUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupReportUseCase(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupReportUseCase(&mocks.ReferralHistoryRepository{})
+	})
+}
+
+func Test_reportUseCase_GetReferralReport(t *testing.T) {
+	t.Run("invalid date", func(t *testing.T) {
+		r := reportUseCase{}
+		_, err := r.GetReferralReport(context.Background(), ReportRequest{From: "01-08-2021"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("range too long", func(t *testing.T) {
+		r := reportUseCase{}
+		_, err := r.GetReferralReport(context.Background(), ReportRequest{From: "2020-01-01", To: "2021-08-31"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("invalid interval", func(t *testing.T) {
+		r := reportUseCase{}
+		_, err := r.GetReferralReport(context.Background(), ReportRequest{Interval: "year"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error get statistic", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetStatistic", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+		historyMock.On("GetTierDistribution", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+		r := reportUseCase{historyRepository: historyMock}
+		_, err := r.GetReferralReport(context.Background(), ReportRequest{})
+		assert.NotNil(t, err)
+	})
+	t.Run("default to last 30 days per day", func(t *testing.T) {
+		to := time.Now().Format("2006-01-02")
+		from := time.Now().AddDate(0, 0, -29).Format("2006-01-02")
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetStatistic", mock.Anything, from, to, model.ReportIntervalDay).Return([]model.ReferralStatistic{}, nil)
+		historyMock.On("GetTierDistribution", mock.Anything, from, to).Return([]model.ReferralTierDistribution{}, nil)
+
+		r := reportUseCase{historyRepository: historyMock}
+		resp, err := r.GetReferralReport(context.Background(), ReportRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 30, len(resp.Data.Series))
+		assert.Equal(t, to, resp.Data.Series[29].Period)
+	})
+	t.Run("weekly series with empty week", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetStatistic", mock.Anything, "2021-08-04", "2021-08-20", model.ReportIntervalWeek).Return([]model.ReferralStatistic{
+			{Period: "2021-08-02", TotalReferral: 10, ActiveReferrer: 3},
+			{Period: "2021-08-16", TotalReferral: 2, ActiveReferrer: 2},
+		}, nil)
+		historyMock.On("GetTierDistribution", mock.Anything, "2021-08-04", "2021-08-20").Return([]model.ReferralTierDistribution{
+			{TotalReferrer: 1},
+			{RewardID: 1, TotalReferral: 1, Reward: "bonus 2 GB", TotalReferrer: 3},
+		}, nil)
+
+		r := reportUseCase{historyRepository: historyMock}
+		resp, err := r.GetReferralReport(context.Background(), ReportRequest{From: "2021-08-04", To: "2021-08-20",
+			Interval: model.ReportIntervalWeek})
+		assert.Nil(t, err)
+		assert.Equal(t, []ReportStatistic{
+			{Period: "2021-08-02", TotalReferral: 10, ActiveReferrer: 3, AverageReferral: 3.33},
+			{Period: "2021-08-09"},
+			{Period: "2021-08-16", TotalReferral: 2, ActiveReferrer: 2, AverageReferral: 1},
+		}, resp.Data.Series)
+		assert.Equal(t, 2, len(resp.Data.Tiers))
+		assert.Equal(t, "bonus 2 GB", resp.Data.Tiers[1].Reward)
+	})
+	t.Run("monthly series", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetStatistic", mock.Anything, "2021-07-15", "2021-09-10", model.ReportIntervalMonth).Return([]model.ReferralStatistic{
+			{Period: "2021-08", TotalReferral: 4, ActiveReferrer: 4},
+		}, nil)
+		historyMock.On("GetTierDistribution", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+		r := reportUseCase{historyRepository: historyMock}
+		resp, err := r.GetReferralReport(context.Background(), ReportRequest{From: "2021-07-15", To: "2021-09-10",
+			Interval: model.ReportIntervalMonth})
+		assert.Nil(t, err)
+		assert.Equal(t, []ReportStatistic{
+			{Period: "2021-07"},
+			{Period: "2021-08", TotalReferral: 4, ActiveReferrer: 4, AverageReferral: 1},
+			{Period: "2021-09"},
+		}, resp.Data.Series)
+	})
+}
@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type RewardUseCase interface {
+	GetListReward(ctx context.Context) (ListRewardResponse, error)
+	GetReward(ctx context.Context, ID int64) (RewardResponse, error)
+	CreateReward(ctx context.Context, request CreateRewardRequest) (RewardResponse, error)
+	UpdateReward(ctx context.Context, request UpdateRewardRequest) (RewardResponse, error)
+	DeactivateReward(ctx context.Context, ID int64) (RewardResponse, error)
+}
+
+type rewardUseCase struct {
+	rewardRepository   model.RewardRepository
+	campaignRepository model.CampaignRepository
+}
+
+func SetupRewardUseCase(rewardRepository model.RewardRepository, campaignRepository model.CampaignRepository) RewardUseCase {
+	if rewardRepository == nil {
+		panic("RewardRepository is nil")
+	}
+	if campaignRepository == nil {
+		panic("CampaignRepository is nil")
+	}
+	return &rewardUseCase{
+		rewardRepository:   rewardRepository,
+		campaignRepository: campaignRepository,
+	}
+}
+
+func (r rewardUseCase) GetListReward(ctx context.Context) (ListRewardResponse, error) {
+	entities, err := r.rewardRepository.FindAll(ctx)
+	if err != nil {
+		return ListRewardResponse{}, err
+	}
+
+	list := make([]Reward, len(entities))
+	for i, v := range entities {
+		list[i] = assemblerReward(v)
+	}
+
+	resp := ListRewardResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.List = list
+	return resp, nil
+}
+
+func (r rewardUseCase) GetReward(ctx context.Context, ID int64) (RewardResponse, error) {
+	reward, err := r.findReward(ctx, ID)
+	if err != nil {
+		return RewardResponse{}, err
+	}
+	return RewardResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+		Data:    assemblerReward(reward),
+	}, nil
+}
+
+func (r rewardUseCase) CreateReward(ctx context.Context, request CreateRewardRequest) (RewardResponse, error) {
+	if request.Level == 0 {
+		request.Level = 1
+	}
+	//reward tier of campaign, make sure campaign exist
+	if request.CampaignID > 0 {
+		//campaign only reward direct referral
+		if request.Level > 1 {
+			log.Println("campaign reward tier only for level 1: ", request.CampaignID, request.Level)
+			return RewardResponse{}, util.ErrorInvalidRequest
+		}
+		_, err := r.campaignRepository.FindByID(ctx, request.CampaignID)
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Println("campaign not found: ", request.CampaignID)
+			return RewardResponse{}, util.ErrorInvalidRequest
+		}
+		if err != nil {
+			return RewardResponse{}, err
+		}
+	}
+
+	reward := model.Reward{
+		TotalReferral: request.TotalReferral,
+		Description:   request.Reward,
+		CampaignID:    request.CampaignID,
+		Level:         request.Level,
+	}
+	if err := r.validateTotalReferral(ctx, reward); err != nil {
+		return RewardResponse{}, err
+	}
+
+	if err := r.rewardRepository.Insert(ctx, &reward); err != nil {
+		return RewardResponse{}, err
+	}
+	return r.GetReward(ctx, reward.ID)
+}
+
+func (r rewardUseCase) UpdateReward(ctx context.Context, request UpdateRewardRequest) (RewardResponse, error) {
+	if request.TotalReferral == 0 && request.Reward == "" {
+		log.Println("nothing to update on reward: ", request.ID)
+		return RewardResponse{}, util.ErrorInvalidRequest
+	}
+	reward, err := r.findReward(ctx, request.ID)
+	if err != nil {
+		return RewardResponse{}, err
+	}
+	if request.TotalReferral > 0 {
+		reward.TotalReferral = request.TotalReferral
+		if err := r.validateTotalReferral(ctx, reward); err != nil {
+			return RewardResponse{}, err
+		}
+	}
+
+	err = r.rewardRepository.Update(ctx, model.Reward{
+		ID:            request.ID,
+		TotalReferral: request.TotalReferral,
+		Description:   request.Reward,
+	})
+	if err != nil {
+		return RewardResponse{}, err
+	}
+	return r.GetReward(ctx, request.ID)
+}
+
+func (r rewardUseCase) DeactivateReward(ctx context.Context, ID int64) (RewardResponse, error) {
+	if _, err := r.findReward(ctx, ID); err != nil {
+		return RewardResponse{}, err
+	}
+	if err := r.rewardRepository.Delete(ctx, ID); err != nil {
+		return RewardResponse{}, err
+	}
+	return r.GetReward(ctx, ID)
+}
+
+func (r rewardUseCase) findReward(ctx context.Context, ID int64) (model.Reward, error) {
+	reward, err := r.rewardRepository.FindByID(ctx, ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Println("reward not found: ", ID)
+		return model.Reward{}, util.ErrorDataNotFound
+	}
+	return reward, err
+}
+
+// validateTotalReferral make sure there is only one active tier for each total referral in the same campaign and level,
+// otherwise reward lookup by total referral become ambiguous
+func (r rewardUseCase) validateTotalReferral(ctx context.Context, reward model.Reward) error {
+	rewards, err := r.rewardRepository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range rewards {
+		if v.ID != reward.ID && v.Status == 1 && v.CampaignID == reward.CampaignID && v.Level == reward.Level &&
+			v.TotalReferral == reward.TotalReferral {
+			log.Println("active reward tier already exist: ", reward.CampaignID, reward.Level, reward.TotalReferral)
+			return util.ErrorInvalidRequest
+		}
+	}
+	return nil
+}
+
+func assemblerReward(reward model.Reward) Reward {
+	return Reward{
+		ID:            reward.ID,
+		TotalReferral: reward.TotalReferral,
+		Reward:        reward.Description,
+		CampaignID:    reward.CampaignID,
+		Level:         reward.Level,
+		Status:        reward.Status,
+		CreatedDate:   reward.CreatedDate.UnixNano() / 1000000,
+		UpdatedDate:   reward.UpdatedDate.UnixNano() / 1000000,
+	}
+}
@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type RewardHandler struct {
+	useCase RewardUseCase
+}
+
+func SetupRewardHandler(useCase RewardUseCase) *RewardHandler {
+	if useCase == nil {
+		panic("reward use case is nil")
+	}
+	return &RewardHandler{
+		useCase: useCase,
+	}
+}
+
+func (h RewardHandler) GetListReward(e echo.Context) error {
+	res, err := h.useCase.GetListReward(e.Request().Context())
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h RewardHandler) GetReward(e echo.Context) error {
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.useCase.GetReward(e.Request().Context(), ID)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h RewardHandler) CreateReward(e echo.Context) error {
+	var request CreateRewardRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+
+	if err := validate(e, &request); err != nil {
+		return err
+	}
+
+	res, err := h.useCase.CreateReward(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h RewardHandler) UpdateReward(e echo.Context) error {
+	var request UpdateRewardRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+
+	if err := validate(e, &request); err != nil {
+		return err
+	}
+
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+	request.ID = ID
+
+	res, err := h.useCase.UpdateReward(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h RewardHandler) DeactivateReward(e echo.Context) error {
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.useCase.DeactivateReward(e.Request().Context(), ID)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func paramID(e echo.Context) (int64, error) {
+	ID, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil || ID < 1 {
+		return 0, util.ErrorInvalidRequest
+	}
+	return ID, nil
+}
+
+// validate request of admin API, validation failure is responded as invalid request
+func validate(e echo.Context, request interface{}) error {
+	if err := e.Validate(request); err != nil {
+		log.Println("invalid request: ", err)
+		return util.ErrorInvalidRequest
+	}
+	return nil
+}
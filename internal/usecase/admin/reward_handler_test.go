@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupRewardHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRewardHandler(&rewardUseCase{})
+	})
+}
+
+type stubValidator struct {
+	err error
+}
+
+func (v stubValidator) Validate(i interface{}) error {
+	return v.err
+}
+
+func TestRewardHandler_CreateReward(t *testing.T) {
+	t.Run("validation failure is invalid request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/1.0/admin/reward", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		server := echo.New()
+		server.Validator = stubValidator{err: errors.New("totalReferral is required")}
+
+		h := SetupRewardHandler(&rewardUseCase{})
+		err := h.CreateReward(server.NewContext(req, rec))
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+}
@@ -0,0 +1,217 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupRewardUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardUseCase(nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRewardUseCase(&mocks.RewardRepository{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRewardUseCase(&mocks.RewardRepository{}, &mocks.CampaignRepository{})
+	})
+}
+
+func Test_rewardUseCase_GetListReward(t *testing.T) {
+	t.Run("error get list", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindAll", mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.GetListReward(context.Background())
+		assert.NotNil(t, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindAll", mock.Anything).Return([]model.Reward{
+			{ID: 1, TotalReferral: 1, Description: "bonus 2 GB", Status: 1},
+			{ID: 2, TotalReferral: 5, Description: "bonus 12 GB", Status: 0},
+		}, nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		resp, err := r.GetListReward(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resp.Data.List))
+		assert.Equal(t, "bonus 12 GB", resp.Data.List[1].Reward)
+	})
+}
+
+func Test_rewardUseCase_GetReward(t *testing.T) {
+	t.Run("reward not found", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(9)).Return(model.Reward{}, sql.ErrNoRows)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.GetReward(context.Background(), 9)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(2)).Return(model.Reward{ID: 2, TotalReferral: 5, Description: "bonus 12 GB", Status: 1}, nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		resp, err := r.GetReward(context.Background(), 2)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), resp.Data.ID)
+		assert.Equal(t, 5, resp.Data.TotalReferral)
+	})
+}
+
+func Test_rewardUseCase_CreateReward(t *testing.T) {
+	t.Run("campaign not found", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(3)).Return(model.Campaign{}, sql.ErrNoRows)
+
+		r := rewardUseCase{campaignRepository: campaignMock}
+		_, err := r.CreateReward(context.Background(), CreateRewardRequest{TotalReferral: 5, Reward: "bonus 10 GB", CampaignID: 3})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("same total referral in other campaign", func(t *testing.T) {
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindByID", mock.Anything, int64(3)).Return(model.Campaign{ID: 3}, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindAll", mock.Anything).Return([]model.Reward{{ID: 1, TotalReferral: 5, Status: 1}}, nil)
+		rewardMock.On("Insert", mock.Anything, mock.MatchedBy(func(m *model.Reward) bool {
+			return m.CampaignID == 3
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*model.Reward).ID = 8
+		}).Return(nil)
+		rewardMock.On("FindByID", mock.Anything, int64(8)).Return(model.Reward{ID: 8, TotalReferral: 5, CampaignID: 3, Status: 1}, nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock, campaignRepository: campaignMock}
+		resp, err := r.CreateReward(context.Background(), CreateRewardRequest{TotalReferral: 5, Reward: "bonus 10 GB", CampaignID: 3})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), resp.Data.CampaignID)
+	})
+	t.Run("active tier already exist", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindAll", mock.Anything).Return([]model.Reward{{ID: 1, TotalReferral: 5, Level: 1, Status: 1}}, nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.CreateReward(context.Background(), CreateRewardRequest{TotalReferral: 5, Reward: "bonus 10 GB"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("level of campaign tier", func(t *testing.T) {
+		r := rewardUseCase{}
+		_, err := r.CreateReward(context.Background(), CreateRewardRequest{TotalReferral: 5, Reward: "bonus 1 GB", CampaignID: 3, Level: 2})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("same total referral in other level", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindAll", mock.Anything).Return([]model.Reward{{ID: 1, TotalReferral: 5, Level: 1, Status: 1}}, nil)
+		rewardMock.On("Insert", mock.Anything, mock.MatchedBy(func(reward *model.Reward) bool {
+			return reward.Level == 2
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*model.Reward).ID = 6
+		}).Return(nil)
+		rewardMock.On("FindByID", mock.Anything, int64(6)).Return(model.Reward{ID: 6, TotalReferral: 5, Level: 2, Status: 1}, nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		resp, err := r.CreateReward(context.Background(), CreateRewardRequest{TotalReferral: 5, Reward: "bonus 3 GB", Level: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.Data.Level)
+	})
+	t.Run("error insert", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindAll", mock.Anything).Return([]model.Reward{{ID: 1, TotalReferral: 5, Status: 0}}, nil)
+		rewardMock.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.CreateReward(context.Background(), CreateRewardRequest{TotalReferral: 5, Reward: "bonus 10 GB"})
+		assert.NotNil(t, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindAll", mock.Anything).Return([]model.Reward{}, nil)
+		rewardMock.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*model.Reward).ID = 4
+		}).Return(nil)
+		rewardMock.On("FindByID", mock.Anything, int64(4)).Return(model.Reward{ID: 4, TotalReferral: 10, Description: "bonus 30 GB", Status: 1}, nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		resp, err := r.CreateReward(context.Background(), CreateRewardRequest{TotalReferral: 10, Reward: "bonus 30 GB"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), resp.Data.ID)
+		assert.Equal(t, "bonus 30 GB", resp.Data.Reward)
+	})
+}
+
+func Test_rewardUseCase_UpdateReward(t *testing.T) {
+	t.Run("nothing to update", func(t *testing.T) {
+		r := rewardUseCase{}
+		_, err := r.UpdateReward(context.Background(), UpdateRewardRequest{ID: 2})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("reward not found", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(2)).Return(model.Reward{}, sql.ErrNoRows)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.UpdateReward(context.Background(), UpdateRewardRequest{ID: 2, Reward: "bonus 15 GB"})
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("active tier already exist", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(2)).Return(model.Reward{ID: 2, TotalReferral: 5, Status: 1}, nil)
+		rewardMock.On("FindAll", mock.Anything).Return([]model.Reward{{ID: 2, TotalReferral: 5, Status: 1}, {ID: 3, TotalReferral: 6, Status: 1}}, nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.UpdateReward(context.Background(), UpdateRewardRequest{ID: 2, TotalReferral: 6})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(2)).Return(model.Reward{ID: 2, TotalReferral: 5, Description: "bonus 15 GB", Status: 1}, nil)
+		rewardMock.On("Update", mock.Anything, model.Reward{ID: 2, Description: "bonus 15 GB"}).Return(nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		resp, err := r.UpdateReward(context.Background(), UpdateRewardRequest{ID: 2, Reward: "bonus 15 GB"})
+		assert.Nil(t, err)
+		assert.Equal(t, "bonus 15 GB", resp.Data.Reward)
+		rewardMock.AssertExpectations(t)
+	})
+}
+
+func Test_rewardUseCase_DeactivateReward(t *testing.T) {
+	t.Run("reward not found", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(2)).Return(model.Reward{}, sql.ErrNoRows)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.DeactivateReward(context.Background(), 2)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("error delete", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(2)).Return(model.Reward{ID: 2, Status: 1}, nil)
+		rewardMock.On("Delete", mock.Anything, int64(2)).Return(context.DeadlineExceeded)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		_, err := r.DeactivateReward(context.Background(), 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByID", mock.Anything, int64(2)).Return(model.Reward{ID: 2, Status: 0}, nil)
+		rewardMock.On("Delete", mock.Anything, int64(2)).Return(nil)
+
+		r := rewardUseCase{rewardRepository: rewardMock}
+		resp, err := r.DeactivateReward(context.Background(), 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, resp.Data.Status)
+	})
+}
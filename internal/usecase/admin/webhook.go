@@ -0,0 +1,283 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+	"github.com/candraalim/be_tsel_candra/internal/webhook"
+)
+
+const (
+	defaultDeliveryLimit = 20
+	maxDeliveryLimit     = 100
+)
+
+// deliveryStatus map status query of delivery list to stored status
+var deliveryStatus = map[string]int{
+	"pending":   model.WebhookDeliveryStatusPending,
+	"delivered": model.WebhookDeliveryStatusDelivered,
+	"dead":      model.WebhookDeliveryStatusDead,
+}
+
+type WebhookUseCase interface {
+	GetListWebhook(ctx context.Context) (ListWebhookResponse, error)
+	CreateWebhook(ctx context.Context, request CreateWebhookRequest) (WebhookResponse, error)
+	DeactivateWebhook(ctx context.Context, ID int64) (WebhookResponse, error)
+	GetListDelivery(ctx context.Context, request ListWebhookDeliveryRequest) (ListWebhookDeliveryResponse, error)
+	ReplayDelivery(ctx context.Context, ID int64) (ReplayWebhookResponse, error)
+	ReplayDeadDelivery(ctx context.Context, subscriptionID int64) (ReplayWebhookResponse, error)
+}
+
+type webhookUseCase struct {
+	subscriptionRepository model.WebhookSubscriptionRepository
+	deliveryRepository     model.WebhookDeliveryRepository
+}
+
+func SetupWebhookUseCase(subscriptionRepository model.WebhookSubscriptionRepository,
+	deliveryRepository model.WebhookDeliveryRepository) WebhookUseCase {
+	if subscriptionRepository == nil {
+		panic("WebhookSubscriptionRepository is nil")
+	}
+	if deliveryRepository == nil {
+		panic("WebhookDeliveryRepository is nil")
+	}
+	return &webhookUseCase{
+		subscriptionRepository: subscriptionRepository,
+		deliveryRepository:     deliveryRepository,
+	}
+}
+
+// GetListWebhook return every subscription, secret is masked
+func (w webhookUseCase) GetListWebhook(ctx context.Context) (ListWebhookResponse, error) {
+	entities, err := w.subscriptionRepository.FindAll(ctx)
+	if err != nil {
+		return ListWebhookResponse{}, err
+	}
+
+	list := make([]Webhook, len(entities))
+	for i, v := range entities {
+		list[i] = assemblerWebhook(v)
+		list[i].Secret = maskSecret(v.Secret)
+	}
+
+	resp := ListWebhookResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.List = list
+	return resp, nil
+}
+
+// CreateWebhook register subscription of events, the only response containing the secret
+func (w webhookUseCase) CreateWebhook(ctx context.Context, request CreateWebhookRequest) (WebhookResponse, error) {
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		log.Println("invalid webhook url: ", request.URL)
+		return WebhookResponse{}, util.ErrorInvalidRequest
+	}
+
+	events := make([]string, 0, len(request.Events))
+	for _, v := range request.Events {
+		if !outbox.ValidEvent(v) {
+			log.Println("invalid webhook event: ", v)
+			return WebhookResponse{}, util.ErrorInvalidRequest
+		}
+		if !contains(events, v) {
+			events = append(events, v)
+		}
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			return WebhookResponse{}, err
+		}
+	}
+
+	subscription := model.WebhookSubscription{
+		URL:    request.URL,
+		Secret: secret,
+		Events: strings.Join(events, ","),
+	}
+	if err = w.subscriptionRepository.Insert(ctx, &subscription); err != nil {
+		return WebhookResponse{}, err
+	}
+
+	subscription, err = w.findWebhook(ctx, subscription.ID)
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+	return WebhookResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+		Data:    assemblerWebhook(subscription),
+	}, nil
+}
+
+// DeactivateWebhook stop subscription receiving new event, its pending delivery become dead on next attempt
+func (w webhookUseCase) DeactivateWebhook(ctx context.Context, ID int64) (WebhookResponse, error) {
+	if _, err := w.findWebhook(ctx, ID); err != nil {
+		return WebhookResponse{}, err
+	}
+	if err := w.subscriptionRepository.UpdateStatus(ctx, ID, model.WebhookSubscriptionStatusInactive); err != nil {
+		return WebhookResponse{}, err
+	}
+
+	subscription, err := w.findWebhook(ctx, ID)
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+	resp := WebhookResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+		Data:    assemblerWebhook(subscription),
+	}
+	resp.Data.Secret = maskSecret(subscription.Secret)
+	return resp, nil
+}
+
+// GetListDelivery return delivery of status, latest first. Default to dead delivery
+func (w webhookUseCase) GetListDelivery(ctx context.Context, request ListWebhookDeliveryRequest) (resp ListWebhookDeliveryResponse, err error) {
+	if request.Status == "" {
+		request.Status = "dead"
+	}
+	status, ok := deliveryStatus[request.Status]
+	if !ok {
+		log.Println("invalid webhook delivery status: ", request.Status)
+		return ListWebhookDeliveryResponse{}, util.ErrorInvalidRequest
+	}
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = defaultDeliveryLimit
+	}
+	if request.Limit > maxDeliveryLimit {
+		request.Limit = maxDeliveryLimit
+	}
+
+	var (
+		entities []model.WebhookDelivery
+		total    int
+	)
+	eg := errgroup.Group{}
+	eg.Go(func() error {
+		var er error
+		entities, er = w.deliveryRepository.FindByStatus(ctx, status, (request.Page-1)*request.Limit, request.Limit)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		total, er = w.deliveryRepository.CountByStatus(ctx, status)
+		return er
+	})
+	if err = eg.Wait(); err != nil {
+		return ListWebhookDeliveryResponse{}, err
+	}
+
+	resp = ListWebhookDeliveryResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.List = make([]WebhookDelivery, len(entities))
+	for i, v := range entities {
+		resp.Data.List[i] = WebhookDelivery{
+			ID:              v.ID,
+			SubscriptionID:  v.SubscriptionID,
+			EventID:         v.EventID,
+			Event:           v.Event,
+			Status:          v.Status,
+			Attempt:         v.Attempt,
+			NextAttemptDate: v.NextAttemptDate.UnixNano() / 1000000,
+			ResponseCode:    v.ResponseCode,
+			LastError:       v.LastError,
+			CreatedDate:     v.CreatedDate.UnixNano() / 1000000,
+			UpdatedDate:     v.UpdatedDate.UnixNano() / 1000000,
+		}
+	}
+	resp.Data.Total = total
+	resp.Data.Page = request.Page
+	resp.Data.Limit = request.Limit
+	return resp, nil
+}
+
+// ReplayDelivery put dead delivery back to pending with fresh attempt
+func (w webhookUseCase) ReplayDelivery(ctx context.Context, ID int64) (ReplayWebhookResponse, error) {
+	if err := w.deliveryRepository.Replay(ctx, ID); err != nil {
+		return ReplayWebhookResponse{}, err
+	}
+	resp := ReplayWebhookResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Total = 1
+	return resp, nil
+}
+
+// ReplayDeadDelivery put every dead delivery of subscription back to pending, all subscription when ID is 0
+func (w webhookUseCase) ReplayDeadDelivery(ctx context.Context, subscriptionID int64) (ReplayWebhookResponse, error) {
+	if subscriptionID > 0 {
+		if _, err := w.findWebhook(ctx, subscriptionID); err != nil {
+			return ReplayWebhookResponse{}, err
+		}
+	}
+	total, err := w.deliveryRepository.ReplayDead(ctx, subscriptionID)
+	if err != nil {
+		return ReplayWebhookResponse{}, err
+	}
+	log.Println("dead webhook delivery replayed: ", subscriptionID, total)
+
+	resp := ReplayWebhookResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Total = total
+	return resp, nil
+}
+
+func (w webhookUseCase) findWebhook(ctx context.Context, ID int64) (model.WebhookSubscription, error) {
+	subscription, err := w.subscriptionRepository.FindByID(ctx, ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Println("webhook subscription not found: ", ID)
+		return model.WebhookSubscription{}, util.ErrorDataNotFound
+	}
+	return subscription, err
+}
+
+func assemblerWebhook(subscription model.WebhookSubscription) Webhook {
+	return Webhook{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		Secret:      subscription.Secret,
+		Events:      subscription.EventList(),
+		Status:      subscription.Status,
+		CreatedDate: subscription.CreatedDate.UnixNano() / 1000000,
+		UpdatedDate: subscription.UpdatedDate.UnixNano() / 1000000,
+	}
+}
+
+// maskSecret keep last 4 character so admin can tell which secret is configured
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type WebhookHandler struct {
+	useCase WebhookUseCase
+}
+
+func SetupWebhookHandler(useCase WebhookUseCase) *WebhookHandler {
+	if useCase == nil {
+		panic("webhook use case is nil")
+	}
+	return &WebhookHandler{
+		useCase: useCase,
+	}
+}
+
+func (h WebhookHandler) GetListWebhook(e echo.Context) error {
+	res, err := h.useCase.GetListWebhook(e.Request().Context())
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h WebhookHandler) CreateWebhook(e echo.Context) error {
+	var request CreateWebhookRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+
+	if err := validate(e, &request); err != nil {
+		return err
+	}
+
+	res, err := h.useCase.CreateWebhook(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h WebhookHandler) DeactivateWebhook(e echo.Context) error {
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.useCase.DeactivateWebhook(e.Request().Context(), ID)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h WebhookHandler) GetListDelivery(e echo.Context) error {
+	request := ListWebhookDeliveryRequest{
+		Status: e.QueryParam("status"),
+	}
+	if pg, err := strconv.Atoi(e.QueryParam("page")); err == nil {
+		request.Page = pg
+	}
+	if lim, err := strconv.Atoi(e.QueryParam("limit")); err == nil {
+		request.Limit = lim
+	}
+
+	res, err := h.useCase.GetListDelivery(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h WebhookHandler) ReplayDelivery(e echo.Context) error {
+	ID, err := paramID(e)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.useCase.ReplayDelivery(e.Request().Context(), ID)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h WebhookHandler) ReplayDeadDelivery(e echo.Context) error {
+	var subscriptionID int64
+	if value := e.QueryParam("subscriptionId"); value != "" {
+		ID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || ID < 1 {
+			return util.ErrorInvalidRequest
+		}
+		subscriptionID = ID
+	}
+
+	res, err := h.useCase.ReplayDeadDelivery(e.Request().Context(), subscriptionID)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
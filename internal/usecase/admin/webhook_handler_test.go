@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupWebhookHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupWebhookHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupWebhookHandler(&webhookUseCase{})
+	})
+}
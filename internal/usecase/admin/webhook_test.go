@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupWebhookUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupWebhookUseCase(nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupWebhookUseCase(&mocks.WebhookSubscriptionRepository{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupWebhookUseCase(&mocks.WebhookSubscriptionRepository{}, &mocks.WebhookDeliveryRepository{})
+	})
+}
+
+func Test_webhookUseCase_GetListWebhook(t *testing.T) {
+	t.Run("error get list", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindAll", mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		w := webhookUseCase{subscriptionRepository: subscriptionMock}
+		_, err := w.GetListWebhook(context.Background())
+		assert.NotNil(t, err)
+	})
+	t.Run("secret is masked", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindAll", mock.Anything).Return([]model.WebhookSubscription{
+			{ID: 1, URL: "https://partner.example.com/hook", Secret: "s3cr3t-value-abcd", Events: "referral.created,code.generated",
+				Status: model.WebhookSubscriptionStatusActive},
+		}, nil)
+
+		w := webhookUseCase{subscriptionRepository: subscriptionMock}
+		resp, err := w.GetListWebhook(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(resp.Data.List))
+		assert.Equal(t, "*************abcd", resp.Data.List[0].Secret)
+		assert.Equal(t, []string{"referral.created", "code.generated"}, resp.Data.List[0].Events)
+	})
+}
+
+func Test_webhookUseCase_CreateWebhook(t *testing.T) {
+	t.Run("invalid url", func(t *testing.T) {
+		w := webhookUseCase{}
+		_, err := w.CreateWebhook(context.Background(), CreateWebhookRequest{URL: "ftp://partner.example.com",
+			Events: []string{"referral.created"}})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+
+		_, err = w.CreateWebhook(context.Background(), CreateWebhookRequest{URL: "/hook",
+			Events: []string{"referral.created"}})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("invalid event", func(t *testing.T) {
+		w := webhookUseCase{}
+		_, err := w.CreateWebhook(context.Background(), CreateWebhookRequest{URL: "https://partner.example.com/hook",
+			Events: []string{"referral.created", "referral.deleted"}})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error insert", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("Insert", mock.Anything, mock.Anything).Return(util.ErrorDatabase)
+
+		w := webhookUseCase{subscriptionRepository: subscriptionMock}
+		_, err := w.CreateWebhook(context.Background(), CreateWebhookRequest{URL: "https://partner.example.com/hook",
+			Events: []string{"referral.created"}})
+		assert.Equal(t, util.ErrorDatabase, err)
+	})
+	t.Run("success generate secret", func(t *testing.T) {
+		var secret string
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("Insert", mock.Anything, mock.MatchedBy(func(s *model.WebhookSubscription) bool {
+			secret = s.Secret
+			return len(s.Secret) == 48 && s.Events == "referral.created,reward.tier_reached"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*model.WebhookSubscription).ID = 3
+		}).Return(nil)
+		subscriptionMock.On("FindByID", mock.Anything, int64(3)).Return(func(context.Context, int64) model.WebhookSubscription {
+			return model.WebhookSubscription{ID: 3, URL: "https://partner.example.com/hook", Secret: secret,
+				Events: "referral.created,reward.tier_reached", Status: model.WebhookSubscriptionStatusActive}
+		}, nil)
+
+		w := webhookUseCase{subscriptionRepository: subscriptionMock}
+		resp, err := w.CreateWebhook(context.Background(), CreateWebhookRequest{URL: "https://partner.example.com/hook",
+			Events: []string{"referral.created", "reward.tier_reached", "referral.created"}})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), resp.Data.ID)
+		assert.Equal(t, secret, resp.Data.Secret)
+		subscriptionMock.AssertExpectations(t)
+	})
+}
+
+func Test_webhookUseCase_DeactivateWebhook(t *testing.T) {
+	t.Run("subscription not found", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindByID", mock.Anything, int64(9)).Return(model.WebhookSubscription{}, sql.ErrNoRows)
+
+		w := webhookUseCase{subscriptionRepository: subscriptionMock}
+		_, err := w.DeactivateWebhook(context.Background(), 9)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindByID", mock.Anything, int64(3)).Return(model.WebhookSubscription{ID: 3,
+			Secret: "s3cr3t-value-abcd", Events: "referral.created"}, nil)
+		subscriptionMock.On("UpdateStatus", mock.Anything, int64(3), model.WebhookSubscriptionStatusInactive).Return(nil)
+
+		w := webhookUseCase{subscriptionRepository: subscriptionMock}
+		resp, err := w.DeactivateWebhook(context.Background(), 3)
+		assert.Nil(t, err)
+		assert.Equal(t, "*************abcd", resp.Data.Secret)
+		subscriptionMock.AssertExpectations(t)
+	})
+}
+
+func Test_webhookUseCase_GetListDelivery(t *testing.T) {
+	t.Run("invalid status", func(t *testing.T) {
+		w := webhookUseCase{}
+		_, err := w.GetListDelivery(context.Background(), ListWebhookDeliveryRequest{Status: "failed"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error count", func(t *testing.T) {
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("FindByStatus", mock.Anything, model.WebhookDeliveryStatusDead, 0, 20).Return([]model.WebhookDelivery{}, nil)
+		deliveryMock.On("CountByStatus", mock.Anything, model.WebhookDeliveryStatusDead).Return(0, context.DeadlineExceeded)
+
+		w := webhookUseCase{deliveryRepository: deliveryMock}
+		_, err := w.GetListDelivery(context.Background(), ListWebhookDeliveryRequest{})
+		assert.NotNil(t, err)
+	})
+	t.Run("success default to dead delivery", func(t *testing.T) {
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("FindByStatus", mock.Anything, model.WebhookDeliveryStatusDead, 100, 100).Return([]model.WebhookDelivery{
+			{ID: 4, SubscriptionID: 3, Event: "referral.created", Status: model.WebhookDeliveryStatusDead, Attempt: 8,
+				ResponseCode: 500, LastError: "unexpected status 500"},
+		}, nil)
+		deliveryMock.On("CountByStatus", mock.Anything, model.WebhookDeliveryStatusDead).Return(101, nil)
+
+		w := webhookUseCase{deliveryRepository: deliveryMock}
+		resp, err := w.GetListDelivery(context.Background(), ListWebhookDeliveryRequest{Page: 2, Limit: 500})
+		assert.Nil(t, err)
+		assert.Equal(t, 101, resp.Data.Total)
+		assert.Equal(t, 100, resp.Data.Limit)
+		assert.Equal(t, 500, resp.Data.List[0].ResponseCode)
+	})
+}
+
+func Test_webhookUseCase_ReplayDelivery(t *testing.T) {
+	t.Run("delivery is not dead", func(t *testing.T) {
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("Replay", mock.Anything, int64(4)).Return(util.ErrorDataNotFound)
+
+		w := webhookUseCase{deliveryRepository: deliveryMock}
+		_, err := w.ReplayDelivery(context.Background(), 4)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("Replay", mock.Anything, int64(4)).Return(nil)
+
+		w := webhookUseCase{deliveryRepository: deliveryMock}
+		resp, err := w.ReplayDelivery(context.Background(), 4)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), resp.Data.Total)
+	})
+}
+
+func Test_webhookUseCase_ReplayDeadDelivery(t *testing.T) {
+	t.Run("subscription not found", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindByID", mock.Anything, int64(9)).Return(model.WebhookSubscription{}, sql.ErrNoRows)
+
+		w := webhookUseCase{subscriptionRepository: subscriptionMock}
+		_, err := w.ReplayDeadDelivery(context.Background(), 9)
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("success all subscription", func(t *testing.T) {
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("ReplayDead", mock.Anything, int64(0)).Return(int64(12), nil)
+
+		w := webhookUseCase{deliveryRepository: deliveryMock}
+		resp, err := w.ReplayDeadDelivery(context.Background(), 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(12), resp.Data.Total)
+	})
+}
@@ -1,6 +1,7 @@
 package inquiry
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -31,6 +32,68 @@ func (h InquiringHandler) GetReferralCode(e echo.Context) error {
 	return e.JSON(http.StatusOK, res)
 }
 
+func (h InquiringHandler) GetReferralCodeQR(e echo.Context) error {
+	var size int
+	if sz, err := strconv.Atoi(e.QueryParam("size")); err == nil {
+		size = sz
+	}
+
+	res, err := h.useCase.GetReferralCodeQR(e.Request().Context(), ReferralCodeQRRequest{
+		Msisdn: e.Param("msisdn"),
+		Format: e.QueryParam("format"),
+		Size:   size,
+		Level:  e.QueryParam("level"),
+	})
+	//handle error response
+	if err != nil {
+		return err
+	}
+	extension := "png"
+	if res.ContentType == "image/svg+xml" {
+		extension = "svg"
+	}
+	e.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf("inline; filename=\"%s.%s\"", res.ReferralCode, extension))
+	return e.Blob(http.StatusOK, res.ContentType, res.Image)
+}
+
+func (h InquiringHandler) RegenerateReferralCode(e echo.Context) error {
+	msisdn := e.Param("msisdn")
+
+	res, err := h.useCase.RegenerateReferralCode(e.Request().Context(), msisdn)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h InquiringHandler) ClaimReferralCode(e echo.Context) error {
+	var request ClaimReferralCodeRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+	request.Msisdn = e.Param("msisdn")
+
+	res, err := h.useCase.ClaimReferralCode(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h InquiringHandler) GetReferralCodeHistory(e echo.Context) error {
+	msisdn := e.Param("msisdn")
+
+	res, err := h.useCase.GetReferralCodeHistory(e.Request().Context(), msisdn)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
 func (h InquiringHandler) GetListReferral(e echo.Context) error {
 	msisdn := e.Param("msisdn")
 
@@ -60,3 +123,19 @@ func (h InquiringHandler) GetCurrentReferralReward(e echo.Context) error {
 	}
 	return e.JSON(http.StatusOK, res)
 }
+
+func (h InquiringHandler) GetDownline(e echo.Context) error {
+	msisdn := e.Param("msisdn")
+
+	var depth int
+	if d, err := strconv.Atoi(e.QueryParam("depth")); err == nil {
+		depth = d
+	}
+
+	res, err := h.useCase.GetDownline(e.Request().Context(), msisdn, depth)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
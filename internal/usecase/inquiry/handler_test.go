@@ -1,8 +1,18 @@
 package inquiry
 
 import (
-	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
 )
 
 func TestSetupInquiringHandler(t *testing.T) {
@@ -13,3 +23,23 @@ func TestSetupInquiringHandler(t *testing.T) {
 		SetupInquiringHandler(&inquiryUseCase{})
 	})
 }
+
+func TestInquiringHandler_GetReferralCodeQR(t *testing.T) {
+	codeMock := &mocks.ReferralCodeRepository{}
+	codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "4C7AE71563"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/1.0/referral/080000123131/code/qr?format=svg&size=128", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("msisdn")
+	c.SetParamValues("080000123131")
+
+	h := SetupInquiringHandler(&inquiryUseCase{codeRepository: codeMock,
+		config: config.ReferralConfig{ShareURL: "https://ref.telkomsel.com"}})
+	err := h.GetReferralCodeQR(c)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/svg+xml", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `inline; filename="4C7AE71563.svg"`, rec.Header().Get(echo.HeaderContentDisposition))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "<svg"))
+}
@@ -2,9 +2,7 @@ package inquiry
 
 import (
 	"context"
-	"crypto/rand"
 	"database/sql"
-	"encoding/hex"
 	"errors"
 	"log"
 	"strings"
@@ -12,25 +10,49 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/counter"
+	"github.com/candraalim/be_tsel_candra/internal/generator"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
 	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
 type InquiringUseCase interface {
 	GetReferralCode(ctx context.Context, msisdn string) (ReferralCodeResponse, error)
+	GetReferralCodeQR(ctx context.Context, request ReferralCodeQRRequest) (ReferralCodeQRResponse, error)
+	RegenerateReferralCode(ctx context.Context, msisdn string) (ReferralCodeResponse, error)
+	ClaimReferralCode(ctx context.Context, request ClaimReferralCodeRequest) (ReferralCodeResponse, error)
+	GetReferralCodeHistory(ctx context.Context, msisdn string) (ReferralCodeHistoryResponse, error)
 	GetCurrentReferralReward(ctx context.Context, msisdn string) (ReferralRewardResponse, error)
 	GetListReferral(ctx context.Context, msisdn string, page, limit int) (ReferralHistoryResponse, error)
+	GetDownline(ctx context.Context, msisdn string, depth int) (DownlineResponse, error)
 }
 
+// maxDownlineDepth limit depth of downline tree returned in single request
+const maxDownlineDepth = 5
+
 type inquiryUseCase struct {
-	codeRepository    model.ReferralCodeRepository
-	historyRepository model.ReferralHistoryRepository
-	rewardRepository  model.RewardRepository
+	codeRepository     model.ReferralCodeRepository
+	historyRepository  model.ReferralHistoryRepository
+	rewardRepository   model.RewardRepository
+	campaignRepository model.CampaignRepository
+	config             config.ReferralConfig
+	codeGenerator      generator.CodeGenerator
+	transactor         model.Transactor
+	outboxRepository   model.OutboxRepository
+	tracker            counter.Tracker
 }
 
 func SetupInquiryUseCase(referralCodeRepository model.ReferralCodeRepository,
 	referralHistoryRepository model.ReferralHistoryRepository,
-	rewardRepository model.RewardRepository) InquiringUseCase {
+	rewardRepository model.RewardRepository,
+	campaignRepository model.CampaignRepository,
+	referralConfig *config.ReferralConfig,
+	codeGenerator generator.CodeGenerator,
+	transactor model.Transactor,
+	outboxRepository model.OutboxRepository,
+	tracker counter.Tracker) InquiringUseCase {
 	if referralCodeRepository == nil {
 		panic("ReferralCodeRepository is nil")
 	}
@@ -40,10 +62,34 @@ func SetupInquiryUseCase(referralCodeRepository model.ReferralCodeRepository,
 	if rewardRepository == nil {
 		panic("RewardRepository is nil")
 	}
+	if campaignRepository == nil {
+		panic("CampaignRepository is nil")
+	}
+	if referralConfig == nil {
+		panic("ReferralConfig is nil")
+	}
+	if codeGenerator == nil {
+		panic("CodeGenerator is nil")
+	}
+	if transactor == nil {
+		panic("Transactor is nil")
+	}
+	if outboxRepository == nil {
+		panic("OutboxRepository is nil")
+	}
+	if tracker == nil {
+		panic("Tracker is nil")
+	}
 	return &inquiryUseCase{
-		codeRepository:    referralCodeRepository,
-		historyRepository: referralHistoryRepository,
-		rewardRepository:  rewardRepository,
+		codeRepository:     referralCodeRepository,
+		historyRepository:  referralHistoryRepository,
+		rewardRepository:   rewardRepository,
+		campaignRepository: campaignRepository,
+		config:             *referralConfig,
+		codeGenerator:      codeGenerator,
+		transactor:         transactor,
+		outboxRepository:   outboxRepository,
+		tracker:            tracker,
 	}
 }
 
@@ -78,10 +124,16 @@ func (i inquiryUseCase) GetReferralCode(ctx context.Context, msisdn string) (res
 	if err != nil {
 		return ReferralCodeResponse{}, err
 	}
-	//store to db
-	err = i.codeRepository.Insert(context.Background(), &model.ReferralCode{
-		Msisdn: msisdn,
-		Code:   referralCode,
+	//store to db together with its event
+	err = i.transactor.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		err := i.codeRepository.Insert(ctx, &model.ReferralCode{
+			Msisdn: msisdn,
+			Code:   referralCode,
+		})
+		if err != nil {
+			return err
+		}
+		return i.writeCodeGenerated(ctx, msisdn, referralCode, "")
 	})
 	if err != nil {
 		return ReferralCodeResponse{}, err
@@ -97,13 +149,161 @@ func (i inquiryUseCase) GetReferralCode(ctx context.Context, msisdn string) (res
 	}, nil
 }
 
-//todo move length referral code to config
+// RegenerateReferralCode retire current referral code and issue a new one, retired code still can be used
+// during grace period and referral made with it stay attributed to the same msisdn
+func (i inquiryUseCase) RegenerateReferralCode(ctx context.Context, msisdn string) (response ReferralCodeResponse, err error) {
+	//validate msisdn
+	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
+	if err != nil {
+		return ReferralCodeResponse{}, err
+	}
+
+	current, err := i.findReplaceableCode(ctx, msisdn)
+	if err != nil {
+		return ReferralCodeResponse{}, err
+	}
+
+	referralCode, err := i.generateReferralCode(ctx)
+	if err != nil {
+		return ReferralCodeResponse{}, err
+	}
+	return i.replaceReferralCode(ctx, current, msisdn, referralCode)
+}
+
+// ClaimReferralCode replace current referral code with code chosen by user, random code is generated
+// when no code requested
+func (i inquiryUseCase) ClaimReferralCode(ctx context.Context, request ClaimReferralCodeRequest) (response ReferralCodeResponse, err error) {
+	if strings.TrimSpace(request.ReferralCode) == "" {
+		return i.RegenerateReferralCode(ctx, request.Msisdn)
+	}
+
+	//validate msisdn
+	msisdn, err := util.ValidateAndSanitizeMsisdn(request.Msisdn)
+	if err != nil {
+		return ReferralCodeResponse{}, err
+	}
+
+	referralCode, err := normalizeVanityCode(request.ReferralCode, i.config.CodeConfig().MaxCodeLength(), i.config.BlockedCodeWords)
+	if err != nil {
+		return ReferralCodeResponse{}, err
+	}
+
+	current, err := i.findReplaceableCode(ctx, msisdn)
+	if err != nil {
+		return ReferralCodeResponse{}, err
+	}
+	//already owned by the msisdn
+	if current.ID > 0 && strings.EqualFold(current.Code, referralCode) {
+		response = ReferralCodeResponse{
+			Code:    util.CodeSuccess,
+			Message: util.MessageSuccess,
+		}
+		response.Data.ReferralCode = current.Code
+		return response, nil
+	}
+
+	//code is unique regardless its status, retired and inactive code can not be claimed
+	_, err = i.codeRepository.FindByCode(ctx, referralCode)
+	if err == nil || errors.Is(err, util.ErrorDataNotFound) {
+		log.Println("referral code already used: ", referralCode)
+		return ReferralCodeResponse{}, util.ErrorReferralCodeUnavailable
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return ReferralCodeResponse{}, err
+	}
+	return i.replaceReferralCode(ctx, current, msisdn, referralCode)
+}
+
+// findReplaceableCode return current code of msisdn, empty when msisdn does not have code yet
+func (i inquiryUseCase) findReplaceableCode(ctx context.Context, msisdn string) (model.ReferralCode, error) {
+	current, err := i.codeRepository.FindByMsisdn(ctx, msisdn)
+	//deactivated code can not be replaced by user
+	if errors.Is(err, util.ErrorDataNotFound) {
+		log.Println("referral code inactive: ", msisdn)
+		return model.ReferralCode{}, util.ErrorReferralCodeInactive
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return model.ReferralCode{}, err
+	}
+	return current, nil
+}
+
+func (i inquiryUseCase) replaceReferralCode(ctx context.Context, current model.ReferralCode, msisdn, referralCode string) (response ReferralCodeResponse, err error) {
+	err = i.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		//retire current code first, only one non retired code allowed per msisdn
+		if current.ID > 0 {
+			err := i.codeRepository.Retire(ctx, current.Code, time.Now().Add(i.config.CodeGracePeriod()))
+			if err != nil {
+				return err
+			}
+		}
+		err := i.codeRepository.Insert(ctx, &model.ReferralCode{
+			Msisdn: msisdn,
+			Code:   referralCode,
+		})
+		if err != nil {
+			return err
+		}
+		return i.writeCodeGenerated(ctx, msisdn, referralCode, current.Code)
+	})
+	if err != nil {
+		return ReferralCodeResponse{}, err
+	}
+
+	response = ReferralCodeResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	response.Data.ReferralCode = referralCode
+	return response, nil
+}
+
+// writeCodeGenerated store event of new code in outbox, within transaction of ctx
+func (i inquiryUseCase) writeCodeGenerated(ctx context.Context, msisdn, referralCode, previousCode string) error {
+	return outbox.Write(ctx, i.outboxRepository, outbox.EventCodeGenerated, outbox.CodeEvent{
+		Msisdn:       msisdn,
+		ReferralCode: referralCode,
+		PreviousCode: previousCode,
+	})
+}
+
+func (i inquiryUseCase) GetReferralCodeHistory(ctx context.Context, msisdn string) (response ReferralCodeHistoryResponse, err error) {
+	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
+	if err != nil {
+		return ReferralCodeHistoryResponse{}, err
+	}
+
+	entities, err := i.codeRepository.FindAllByMsisdn(ctx, msisdn)
+	if err != nil {
+		return ReferralCodeHistoryResponse{}, err
+	}
+
+	list := make([]ReferralCodeHistory, len(entities))
+	for idx, v := range entities {
+		list[idx] = ReferralCodeHistory{
+			ReferralCode: v.Code,
+			Status:       v.Status,
+			DateTime:     v.CreatedDate.UnixNano() / 1000000,
+		}
+		if v.ExpiresAt != nil {
+			list[idx].ExpiresAt = v.ExpiresAt.UnixNano() / 1000000
+		}
+	}
+
+	response = ReferralCodeHistoryResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	response.Data.List = list
+	return response, nil
+}
+
 func (i inquiryUseCase) generateReferralCode(ctx context.Context) (referralCode string, err error) {
 	// generate unique referral code, make sure it unique by lookup to db
-	// if return duplicate then try to generate it 10 times, after that return error
+	// if return duplicate then retry it as configured, after that return error
 	// retry in next request
-	for c := 0; c < 10; c++ {
-		code, er := randomHex(5)
+	for c := 0; c < i.config.CodeConfig().Retry(); c++ {
+		code, er := i.codeGenerator.Generate()
 		if er != nil {
 			log.Println("failed to generate random", er.Error())
 			return "", util.ErrorGenerateReferralCode
@@ -111,7 +311,7 @@ func (i inquiryUseCase) generateReferralCode(ctx context.Context) (referralCode
 		if _, er := i.codeRepository.FindByCode(ctx, code); errors.Is(er, sql.ErrNoRows) {
 			referralCode = code
 			break
-		} else if er != nil {
+		} else if er != nil && !errors.Is(er, util.ErrorDataNotFound) {
 			break
 		}
 	}
@@ -122,14 +322,6 @@ func (i inquiryUseCase) generateReferralCode(ctx context.Context) (referralCode
 	return referralCode, nil
 }
 
-func randomHex(n int) (string, error) {
-	bytes := make([]byte, n)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	return strings.ToUpper(hex.EncodeToString(bytes)), nil
-}
-
 func (i inquiryUseCase) GetCurrentReferralReward(ctx context.Context, msisdn string) (resp ReferralRewardResponse, err error) {
 	//validate msisdn
 	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
@@ -137,37 +329,106 @@ func (i inquiryUseCase) GetCurrentReferralReward(ctx context.Context, msisdn str
 		return ReferralRewardResponse{}, err
 	}
 
-	//todo get total from redis
 	//get total referral
 	month := time.Now().Format("2006-01")
-	total, err := i.historyRepository.GetTotalByMsisdnAndMonth(ctx, msisdn, month)
+	total, err := i.tracker.Total(ctx, msisdn, month)
 	if err != nil {
 		return ReferralRewardResponse{}, err
 	}
 	resp = ReferralRewardResponse{
 		Code:    util.CodeSuccess,
 		Message: util.MessageSuccess,
-		Data: struct {
-			TotalReferral int    `json:"totalReferral"`
-			Reward        string `json:"reward"`
-		}{
+		Data: ReferralRewardData{
 			TotalReferral: total,
+			Campaigns:     []CampaignReward{},
 		},
 	}
-	//when no referral happen, return success but empty reward
-	if total == 0 {
-		return resp, nil
+
+	//when no referral happen, reward is empty
+	if total > 0 {
+		//lookup reward based on total referral, no rows means lowest tier is not reached yet
+		reward, err := i.rewardRepository.FindByTotalReferral(ctx, total)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return ReferralRewardResponse{}, err
+		}
+		resp.Data.Reward = reward.Description
+	}
+
+	//reward of each downline level, level 1 is direct referral counted above
+	resp.Data.Levels = []LevelReward{{Level: 1, TotalReferral: total, Reward: resp.Data.Reward}}
+	if depth := i.config.Depth(); depth > 1 {
+		levels, err := i.getLevelReward(ctx, msisdn, month, depth)
+		if err != nil {
+			return ReferralRewardResponse{}, err
+		}
+		resp.Data.Levels = append(resp.Data.Levels, levels...)
 	}
 
-	//lookup reward based on total referral
-	reward, err := i.rewardRepository.FindByTotalReferral(ctx, total)
+	//progress of each running campaign, counted within campaign window with its own reward tier
+	campaigns, err := i.campaignRepository.FindActiveByDate(ctx, time.Now().Format("2006-01-02"))
 	if err != nil {
 		return ReferralRewardResponse{}, err
 	}
-	resp.Data.Reward = reward.Description
+	for _, campaign := range campaigns {
+		progress, err := i.getCampaignReward(ctx, msisdn, campaign)
+		if err != nil {
+			return ReferralRewardResponse{}, err
+		}
+		resp.Data.Campaigns = append(resp.Data.Campaigns, progress)
+	}
 	return resp, nil
 }
 
+// getLevelReward return total referral and reward reached by downline level 2 until depth
+func (i inquiryUseCase) getLevelReward(ctx context.Context, msisdn, month string, depth int) ([]LevelReward, error) {
+	totals, err := i.historyRepository.GetTotalPerLevelByMsisdnAndMonth(ctx, msisdn, month, depth)
+	if err != nil {
+		return nil, err
+	}
+	totalByLevel := make(map[int]int, len(totals))
+	for _, v := range totals {
+		totalByLevel[v.Level] = v.Total
+	}
+
+	result := make([]LevelReward, 0, depth-1)
+	for level := 2; level <= depth; level++ {
+		levelReward := LevelReward{Level: level, TotalReferral: totalByLevel[level]}
+		if levelReward.TotalReferral > 0 {
+			reward, err := i.rewardRepository.FindByLevelAndTotalReferral(ctx, level, levelReward.TotalReferral)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			levelReward.Reward = reward.Description
+		}
+		result = append(result, levelReward)
+	}
+	return result, nil
+}
+
+func (i inquiryUseCase) getCampaignReward(ctx context.Context, msisdn string, campaign model.Campaign) (CampaignReward, error) {
+	result := CampaignReward{
+		ID:        campaign.ID,
+		Name:      campaign.Name,
+		StartDate: campaign.StartDate,
+		EndDate:   campaign.EndDate,
+	}
+	total, err := i.historyRepository.GetTotalByMsisdnAndCampaign(ctx, msisdn, campaign.ID)
+	if err != nil {
+		return CampaignReward{}, err
+	}
+	result.TotalReferral = total
+	if total == 0 {
+		return result, nil
+	}
+
+	reward, err := i.rewardRepository.FindByCampaignAndTotalReferral(ctx, campaign.ID, total)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return CampaignReward{}, err
+	}
+	result.Reward = reward.Description
+	return result, nil
+}
+
 func (i inquiryUseCase) GetListReferral(ctx context.Context, msisdn string, page, limit int) (resp ReferralHistoryResponse, err error) {
 	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
 	if err != nil {
@@ -220,6 +481,8 @@ func assemblerReferralHistory(entities []model.ReferralHistory, total, page, lim
 			Msisdn:       v.MsisdnReferee,
 			ReferralDate: v.ReferralDate,
 			DateTime:     v.CreatedDate.UnixNano() / 1000000,
+			Status:       v.Status,
+			Flagged:      v.Flagged,
 		}
 	}
 
@@ -240,3 +503,50 @@ func assemblerReferralHistory(entities []model.ReferralHistory, total, page, lim
 		},
 	}
 }
+
+// GetDownline return referral tree of msisdn, depth default to configured reward depth
+func (i inquiryUseCase) GetDownline(ctx context.Context, msisdn string, depth int) (resp DownlineResponse, err error) {
+	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
+	if err != nil {
+		return DownlineResponse{}, err
+	}
+	if depth < 1 {
+		depth = i.config.Depth()
+	}
+	if depth > maxDownlineDepth {
+		depth = maxDownlineDepth
+	}
+
+	entities, err := i.historyRepository.FindDownline(ctx, msisdn, depth)
+	if err != nil {
+		return DownlineResponse{}, err
+	}
+
+	children := make(map[string][]model.ReferralDownline)
+	for _, v := range entities {
+		children[v.Msisdn] = append(children[v.Msisdn], v)
+	}
+
+	resp = DownlineResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Msisdn = msisdn
+	resp.Data.Depth = depth
+	resp.Data.Total = len(entities)
+	resp.Data.Downline = assemblerDownline(children, msisdn)
+	return resp, nil
+}
+
+func assemblerDownline(children map[string][]model.ReferralDownline, msisdn string) []Downline {
+	list := make([]Downline, len(children[msisdn]))
+	for i, v := range children[msisdn] {
+		list[i] = Downline{
+			Msisdn:       v.MsisdnReferee,
+			ReferralDate: v.ReferralDate,
+			Level:        v.Level,
+			Downline:     assemblerDownline(children, v.MsisdnReferee),
+		}
+	}
+	return list
+}
@@ -3,29 +3,93 @@ package inquiry
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/generator"
+	counterMocks "github.com/candraalim/be_tsel_candra/internal/mock/counter"
 	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
 	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
+var hexGenerator = generator.SetupCodeGenerator(config.CodeConfig{})
+
+type staticGenerator string
+
+func (g staticGenerator) Generate() (string, error) {
+	return string(g), nil
+}
+
 func TestSetupInquiryUseCase(t *testing.T) {
 	assert.Panics(t, func() {
-		SetupInquiryUseCase(nil, nil, nil)
+		SetupInquiryUseCase(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, nil, nil, nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, nil, nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{}, nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{},
+			&mocks.CampaignRepository{}, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{},
+			&mocks.CampaignRepository{}, &config.ReferralConfig{}, nil, nil, nil, nil)
 	})
 	assert.Panics(t, func() {
-		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, nil, nil)
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{},
+			&mocks.CampaignRepository{}, &config.ReferralConfig{}, hexGenerator, nil, nil, nil)
 	})
 	assert.Panics(t, func() {
-		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, nil)
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{},
+			&mocks.CampaignRepository{}, &config.ReferralConfig{}, hexGenerator, &mocks.Transactor{}, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{},
+			&mocks.CampaignRepository{}, &config.ReferralConfig{}, hexGenerator, &mocks.Transactor{}, &mocks.OutboxRepository{}, nil)
 	})
 	assert.NotPanics(t, func() {
-		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{})
+		SetupInquiryUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{},
+			&mocks.CampaignRepository{}, &config.ReferralConfig{}, hexGenerator, &mocks.Transactor{}, &mocks.OutboxRepository{},
+			&counterMocks.Tracker{})
+	})
+}
+
+// transactorMock run fn directly as if it were in a transaction
+func transactorMock() *mocks.Transactor {
+	transactor := &mocks.Transactor{}
+	transactor.On("WithinTransaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	return transactor
+}
+
+// outboxMock accept any event
+func outboxMock() *mocks.OutboxRepository {
+	outboxRepository := &mocks.OutboxRepository{}
+	outboxRepository.On("Insert", mock.Anything, mock.Anything).Return(nil)
+	return outboxRepository
+}
+
+// codeEvent match outbox event of code generated
+func codeEvent(match func(e outbox.CodeEvent) bool) interface{} {
+	return mock.MatchedBy(func(e *model.OutboxEvent) bool {
+		var data outbox.CodeEvent
+		return e.Event == outbox.EventCodeGenerated && len(e.EventID) == 32 &&
+			json.Unmarshal([]byte(e.Payload), &data) == nil && match(data)
 	})
 }
 
@@ -36,21 +100,25 @@ func Test_inquiryUseCase_GetCurrentReferralReward(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 	t.Run("get total referral history return error", func(t *testing.T) {
-		historyMock := &mocks.ReferralHistoryRepository{}
-		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(0, context.DeadlineExceeded)
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(0, context.DeadlineExceeded)
 
 		i := inquiryUseCase{
-			historyRepository: historyMock,
+			tracker: trackerMock,
 		}
 		_, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
 		assert.NotNil(t, err)
 	})
 	t.Run("get total referral history 0", func(t *testing.T) {
-		historyMock := &mocks.ReferralHistoryRepository{}
-		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(0, nil)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{}, nil)
 
 		i := inquiryUseCase{
-			historyRepository: historyMock,
+			tracker:            trackerMock,
+			campaignRepository: campaignMock,
 		}
 		resp, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
 		assert.Nil(t, err)
@@ -59,35 +127,124 @@ func Test_inquiryUseCase_GetCurrentReferralReward(t *testing.T) {
 		assert.Empty(t, resp.Data.Reward)
 	})
 	t.Run("unable to get reward data", func(t *testing.T) {
-		historyMock := &mocks.ReferralHistoryRepository{}
-		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(3, nil)
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(3, nil)
 
 		rewardMock := &mocks.RewardRepository{}
 		rewardMock.On("FindByTotalReferral", mock.Anything, mock.Anything).Return(model.Reward{}, context.DeadlineExceeded)
 
 		i := inquiryUseCase{
-			historyRepository: historyMock,
-			rewardRepository:  rewardMock,
+			tracker:          trackerMock,
+			rewardRepository: rewardMock,
 		}
 		_, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
 		assert.NotNil(t, err)
 	})
+	t.Run("lowest reward tier not reached", func(t *testing.T) {
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(1, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByTotalReferral", mock.Anything, mock.Anything).Return(model.Reward{}, sql.ErrNoRows)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{}, nil)
+
+		i := inquiryUseCase{
+			tracker:            trackerMock,
+			rewardRepository:   rewardMock,
+			campaignRepository: campaignMock,
+		}
+		resp, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, resp.Data.TotalReferral)
+		assert.Empty(t, resp.Data.Reward)
+	})
 	t.Run("success get reward", func(t *testing.T) {
-		historyMock := &mocks.ReferralHistoryRepository{}
-		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(4, nil)
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(4, nil)
 
 		rewardMock := &mocks.RewardRepository{}
 		rewardMock.On("FindByTotalReferral", mock.Anything, mock.Anything).Return(model.Reward{Description: "bonus 20GB"}, nil)
 
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{}, nil)
+
 		i := inquiryUseCase{
-			historyRepository: historyMock,
-			rewardRepository:  rewardMock,
+			tracker:            trackerMock,
+			rewardRepository:   rewardMock,
+			campaignRepository: campaignMock,
 		}
 		resp, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
 		assert.Nil(t, err)
 		assert.Equal(t, util.CodeSuccess, resp.Code)
 		assert.Equal(t, 4, resp.Data.TotalReferral)
 		assert.Equal(t, "bonus 20GB", resp.Data.Reward)
+		assert.Empty(t, resp.Data.Campaigns)
+	})
+	t.Run("unable to get active campaign", func(t *testing.T) {
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(0, nil)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		i := inquiryUseCase{
+			tracker:            trackerMock,
+			campaignRepository: campaignMock,
+		}
+		_, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
+		assert.NotNil(t, err)
+	})
+	t.Run("unable to get campaign total referral", func(t *testing.T) {
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(0, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndCampaign", mock.Anything, mock.Anything, int64(2)).Return(0, context.DeadlineExceeded)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{{ID: 2}}, nil)
+
+		i := inquiryUseCase{
+			tracker:            trackerMock,
+			historyRepository:  historyMock,
+			campaignRepository: campaignMock,
+		}
+		_, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
+		assert.NotNil(t, err)
+	})
+	t.Run("success get reward per campaign", func(t *testing.T) {
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(4, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndCampaign", mock.Anything, mock.Anything, int64(2)).Return(3, nil)
+		historyMock.On("GetTotalByMsisdnAndCampaign", mock.Anything, mock.Anything, int64(1)).Return(0, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByTotalReferral", mock.Anything, 4).Return(model.Reward{Description: "bonus 2 GB"}, nil)
+		rewardMock.On("FindByCampaignAndTotalReferral", mock.Anything, int64(2), 3).Return(model.Reward{Description: "bonus ramadan 10 GB"}, nil)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{
+			{ID: 2, Name: "ramadan", StartDate: "2021-04-13", EndDate: "2021-05-12"},
+			{ID: 1, Name: "weekend", StartDate: "2021-04-10", EndDate: "2021-05-30"},
+		}, nil)
+
+		i := inquiryUseCase{
+			tracker:            trackerMock,
+			historyRepository:  historyMock,
+			rewardRepository:   rewardMock,
+			campaignRepository: campaignMock,
+		}
+		resp, err := i.GetCurrentReferralReward(context.Background(), "62821000000")
+		assert.Nil(t, err)
+		assert.Equal(t, "bonus 2 GB", resp.Data.Reward)
+		assert.Equal(t, []CampaignReward{
+			{ID: 2, Name: "ramadan", StartDate: "2021-04-13", EndDate: "2021-05-12", TotalReferral: 3, Reward: "bonus ramadan 10 GB"},
+			{ID: 1, Name: "weekend", StartDate: "2021-04-10", EndDate: "2021-05-30"},
+		}, resp.Data.Campaigns)
 	})
 }
 
@@ -164,6 +321,7 @@ func Test_inquiryUseCase_GetReferralCode(t *testing.T) {
 
 		i := inquiryUseCase{
 			codeRepository: codeMock,
+			codeGenerator:  hexGenerator,
 		}
 		_, err := i.GetReferralCode(context.Background(), "080000123131")
 		assert.NotNil(t, err)
@@ -174,6 +332,7 @@ func Test_inquiryUseCase_GetReferralCode(t *testing.T) {
 
 		i := inquiryUseCase{
 			codeRepository: codeMock,
+			codeGenerator:  hexGenerator,
 		}
 		resp, err := i.GetReferralCode(context.Background(), "080000123131")
 		assert.Nil(t, err)
@@ -185,14 +344,39 @@ func Test_inquiryUseCase_GetReferralCode(t *testing.T) {
 		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
 		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
 		codeMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+		outboxRepository := &mocks.OutboxRepository{}
+		outboxRepository.On("Insert", mock.Anything, codeEvent(func(e outbox.CodeEvent) bool {
+			return e.Msisdn == "6280000123131" && e.ReferralCode != "" && e.PreviousCode == ""
+		})).Return(nil)
 
 		i := inquiryUseCase{
-			codeRepository: codeMock,
+			codeRepository:   codeMock,
+			codeGenerator:    hexGenerator,
+			transactor:       transactorMock(),
+			outboxRepository: outboxRepository,
 		}
 		resp, err := i.GetReferralCode(context.Background(), "080000123131")
 		assert.Nil(t, err)
 		assert.Equal(t, util.CodeSuccess, resp.Code)
 		assert.NotEmpty(t, resp.Data.ReferralCode)
+		outboxRepository.AssertExpectations(t)
+	})
+	t.Run("failed write event fail generate referral code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+		outboxRepository := &mocks.OutboxRepository{}
+		outboxRepository.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		i := inquiryUseCase{
+			codeRepository:   codeMock,
+			codeGenerator:    hexGenerator,
+			transactor:       transactorMock(),
+			outboxRepository: outboxRepository,
+		}
+		_, err := i.GetReferralCode(context.Background(), "080000123131")
+		assert.Equal(t, context.DeadlineExceeded, err)
 	})
 	t.Run("unable to store referrel code", func(t *testing.T) {
 		codeMock := &mocks.ReferralCodeRepository{}
@@ -202,6 +386,8 @@ func Test_inquiryUseCase_GetReferralCode(t *testing.T) {
 
 		i := inquiryUseCase{
 			codeRepository: codeMock,
+			codeGenerator:  hexGenerator,
+			transactor:     transactorMock(),
 		}
 		_, err := i.GetReferralCode(context.Background(), "080000123131")
 		assert.NotNil(t, err)
@@ -214,10 +400,26 @@ func Test_inquiryUseCase_GetReferralCode(t *testing.T) {
 
 		i := inquiryUseCase{
 			codeRepository: codeMock,
+			codeGenerator:  hexGenerator,
+			transactor:     transactorMock(),
 		}
 		_, err := i.GetReferralCode(context.Background(), "080000123131")
 		assert.NotNil(t, err)
 	})
+	t.Run("generate not unique, retry as configured", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("FindByCode", mock.Anything, "TSELBAKU").Return(model.ReferralCode{}, nil)
+
+		i := inquiryUseCase{
+			codeRepository: codeMock,
+			codeGenerator:  staticGenerator("TSELBAKU"),
+			config:         config.ReferralConfig{Code: &config.CodeConfig{MaxRetry: 3}},
+		}
+		_, err := i.GetReferralCode(context.Background(), "080000123131")
+		assert.Equal(t, util.ErrorGenerateReferralCode, err)
+		codeMock.AssertNumberOfCalls(t, "FindByCode", 3)
+	})
 	t.Run("error in middle generate referral code", func(t *testing.T) {
 		codeMock := &mocks.ReferralCodeRepository{}
 		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
@@ -226,8 +428,285 @@ func Test_inquiryUseCase_GetReferralCode(t *testing.T) {
 
 		i := inquiryUseCase{
 			codeRepository: codeMock,
+			codeGenerator:  hexGenerator,
+			transactor:     transactorMock(),
 		}
 		_, err := i.GetReferralCode(context.Background(), "080000123131")
 		assert.NotNil(t, err)
 	})
 }
+
+func Test_inquiryUseCase_RegenerateReferralCode(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		i := inquiryUseCase{}
+		_, err := i.RegenerateReferralCode(context.Background(), "080000acbcd")
+		assert.NotNil(t, err)
+	})
+	t.Run("current code inactive", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, util.ErrorDataNotFound)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		_, err := i.RegenerateReferralCode(context.Background(), "080000123131")
+		assert.Equal(t, util.ErrorReferralCodeInactive, err)
+	})
+	t.Run("db timeout", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, context.DeadlineExceeded)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		_, err := i.RegenerateReferralCode(context.Background(), "080000123131")
+		assert.NotNil(t, err)
+	})
+	t.Run("unable to retire current code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{ID: 3, Code: "AA11BB22CC"}, nil)
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("Retire", mock.Anything, "AA11BB22CC", mock.Anything).Return(context.DeadlineExceeded)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator, transactor: transactorMock()}
+		_, err := i.RegenerateReferralCode(context.Background(), "080000123131")
+		assert.NotNil(t, err)
+	})
+	t.Run("success retire current code with grace period", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{ID: 3, Code: "AA11BB22CC"}, nil)
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("Retire", mock.Anything, "AA11BB22CC", mock.MatchedBy(func(t time.Time) bool {
+			return t.After(time.Now().Add(47*time.Hour)) && t.Before(time.Now().Add(49*time.Hour))
+		})).Return(nil)
+		codeMock.On("Insert", mock.Anything, mock.MatchedBy(func(c *model.ReferralCode) bool {
+			return c.Msisdn == "6280000123131" && c.Code != "AA11BB22CC"
+		})).Return(nil)
+		outboxRepository := &mocks.OutboxRepository{}
+		outboxRepository.On("Insert", mock.Anything, codeEvent(func(e outbox.CodeEvent) bool {
+			return e.PreviousCode == "AA11BB22CC"
+		})).Return(nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator, transactor: transactorMock(),
+			outboxRepository: outboxRepository, config: config.ReferralConfig{CodeGracePeriodHours: 48}}
+		resp, err := i.RegenerateReferralCode(context.Background(), "080000123131")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, resp.Data.ReferralCode)
+		codeMock.AssertExpectations(t)
+		outboxRepository.AssertExpectations(t)
+	})
+	t.Run("success without current code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator, transactor: transactorMock(),
+			outboxRepository: outboxMock()}
+		resp, err := i.RegenerateReferralCode(context.Background(), "080000123131")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, resp.Data.ReferralCode)
+		codeMock.AssertNotCalled(t, "Retire", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func Test_inquiryUseCase_GetReferralCodeHistory(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		i := inquiryUseCase{}
+		_, err := i.GetReferralCodeHistory(context.Background(), "080000acbcd")
+		assert.NotNil(t, err)
+	})
+	t.Run("db timeout", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindAllByMsisdn", mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		_, err := i.GetReferralCodeHistory(context.Background(), "080000123131")
+		assert.NotNil(t, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindAllByMsisdn", mock.Anything, mock.Anything).Return([]model.ReferralCode{
+			{ID: 5, Code: "F00D12AB34", Status: model.ReferralCodeStatusActive, CreatedDate: time.Now()},
+			{ID: 3, Code: "AA11BB22CC", Status: model.ReferralCodeStatusRetired, CreatedDate: time.Now(), ExpiresAt: &expiresAt},
+		}, nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		resp, err := i.GetReferralCodeHistory(context.Background(), "080000123131")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resp.Data.List))
+		assert.Zero(t, resp.Data.List[0].ExpiresAt)
+		assert.Equal(t, expiresAt.UnixNano()/1000000, resp.Data.List[1].ExpiresAt)
+	})
+}
+
+func Test_inquiryUseCase_ClaimReferralCode(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		i := inquiryUseCase{}
+		_, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000acbcd", ReferralCode: "BUDI2021"})
+		assert.NotNil(t, err)
+	})
+	t.Run("code not allowed", func(t *testing.T) {
+		i := inquiryUseCase{config: config.ReferralConfig{BlockedCodeWords: []string{"jelek"}}}
+		for _, code := range []string{"BUD", "BUDI-2021", "BUDI 2021", "ADMIN01", "Jelek99", "ABCDEFGHIJ0123456789X"} {
+			_, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000123131", ReferralCode: code})
+			assert.Equal(t, util.ErrorReferralCodeNotAllowed, err, code)
+		}
+	})
+	t.Run("current code inactive", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, util.ErrorDataNotFound)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		_, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000123131", ReferralCode: "BUDI2021"})
+		assert.Equal(t, util.ErrorReferralCodeInactive, err)
+	})
+	t.Run("code already owned", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{ID: 3, Code: "BUDI2021"}, nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		resp, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000123131", ReferralCode: "budi2021"})
+		assert.Nil(t, err)
+		assert.Equal(t, "BUDI2021", resp.Data.ReferralCode)
+		codeMock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	})
+	t.Run("code used by other msisdn", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("FindByCode", mock.Anything, "BUDI2021").Return(model.ReferralCode{}, util.ErrorDataNotFound)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		_, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000123131", ReferralCode: "Budi2021"})
+		assert.Equal(t, util.ErrorReferralCodeUnavailable, err)
+	})
+	t.Run("db timeout", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, context.DeadlineExceeded)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator}
+		_, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000123131", ReferralCode: "BUDI2021"})
+		assert.NotNil(t, err)
+	})
+	t.Run("success replace current code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{ID: 3, Code: "AA11BB22CC"}, nil)
+		codeMock.On("FindByCode", mock.Anything, "BUDI2021").Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("Retire", mock.Anything, "AA11BB22CC", mock.Anything).Return(nil)
+		codeMock.On("Insert", mock.Anything, mock.MatchedBy(func(c *model.ReferralCode) bool {
+			return c.Msisdn == "6280000123131" && c.Code == "BUDI2021"
+		})).Return(nil)
+		outboxRepository := &mocks.OutboxRepository{}
+		outboxRepository.On("Insert", mock.Anything, codeEvent(func(e outbox.CodeEvent) bool {
+			return e == outbox.CodeEvent{Msisdn: "6280000123131", ReferralCode: "BUDI2021", PreviousCode: "AA11BB22CC"}
+		})).Return(nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator, transactor: transactorMock(),
+			outboxRepository: outboxRepository}
+		resp, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000123131", ReferralCode: " budi2021 "})
+		assert.Nil(t, err)
+		assert.Equal(t, "BUDI2021", resp.Data.ReferralCode)
+		codeMock.AssertExpectations(t)
+		outboxRepository.AssertExpectations(t)
+	})
+	t.Run("no code requested, generate random code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+		codeMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, codeGenerator: hexGenerator, transactor: transactorMock(),
+			outboxRepository: outboxMock()}
+		resp, err := i.ClaimReferralCode(context.Background(), ClaimReferralCodeRequest{Msisdn: "080000123131"})
+		assert.Nil(t, err)
+		assert.Equal(t, 10, len(resp.Data.ReferralCode))
+	})
+}
+
+func Test_inquiryUseCase_GetCurrentReferralReward_Level(t *testing.T) {
+	t.Run("error get total per level", func(t *testing.T) {
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62800001231321", mock.Anything).Return(0, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalPerLevelByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything, 2).
+			Return(nil, context.DeadlineExceeded)
+
+		i := inquiryUseCase{historyRepository: historyMock, tracker: trackerMock, config: config.ReferralConfig{MaxLevel: 2}}
+		_, err := i.GetCurrentReferralReward(context.Background(), "0800001231321")
+		assert.NotNil(t, err)
+	})
+	t.Run("return reward per level", func(t *testing.T) {
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Total", mock.Anything, "62800001231321", mock.Anything).Return(1, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalPerLevelByMsisdnAndMonth", mock.Anything, "62800001231321", mock.Anything, 3).
+			Return([]model.ReferralLevelTotal{{Level: 1, Total: 1}, {Level: 2, Total: 6}}, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByTotalReferral", mock.Anything, 1).Return(model.Reward{Description: "bonus 2 GB"}, nil)
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 2, 6).Return(model.Reward{Description: "bonus 3 GB"}, nil)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{}, nil)
+
+		i := inquiryUseCase{historyRepository: historyMock, rewardRepository: rewardMock, campaignRepository: campaignMock,
+			tracker: trackerMock, config: config.ReferralConfig{MaxLevel: 3}}
+		resp, err := i.GetCurrentReferralReward(context.Background(), "0800001231321")
+		assert.Nil(t, err)
+		assert.Equal(t, []LevelReward{
+			{Level: 1, TotalReferral: 1, Reward: "bonus 2 GB"},
+			{Level: 2, TotalReferral: 6, Reward: "bonus 3 GB"},
+			{Level: 3},
+		}, resp.Data.Levels)
+	})
+}
+
+func Test_inquiryUseCase_GetDownline(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		i := inquiryUseCase{}
+		_, err := i.GetDownline(context.Background(), "080000acbcd", 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("db timeout", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindDownline", mock.Anything, mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		i := inquiryUseCase{historyRepository: historyMock}
+		_, err := i.GetDownline(context.Background(), "0800001231321", 2)
+		assert.NotNil(t, err)
+	})
+	t.Run("depth default to configured level and capped", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindDownline", mock.Anything, mock.Anything, 2).Return(nil, nil)
+		historyMock.On("FindDownline", mock.Anything, mock.Anything, maxDownlineDepth).Return(nil, nil)
+
+		i := inquiryUseCase{historyRepository: historyMock, config: config.ReferralConfig{MaxLevel: 2}}
+		resp, err := i.GetDownline(context.Background(), "0800001231321", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.Data.Depth)
+		assert.Empty(t, resp.Data.Downline)
+
+		resp, err = i.GetDownline(context.Background(), "0800001231321", 100)
+		assert.Nil(t, err)
+		assert.Equal(t, maxDownlineDepth, resp.Data.Depth)
+	})
+	t.Run("return tree", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindDownline", mock.Anything, "62800001231321", 2).Return([]model.ReferralDownline{
+			{Msisdn: "62800001231321", MsisdnReferee: "6280001", ReferralDate: "2021-08-01", Level: 1},
+			{Msisdn: "62800001231321", MsisdnReferee: "6280002", ReferralDate: "2021-08-02", Level: 1},
+			{Msisdn: "6280001", MsisdnReferee: "6280003", ReferralDate: "2021-08-05", Level: 2},
+		}, nil)
+
+		i := inquiryUseCase{historyRepository: historyMock}
+		resp, err := i.GetDownline(context.Background(), "0800001231321", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, resp.Data.Total)
+		assert.Equal(t, []Downline{
+			{Msisdn: "6280001", ReferralDate: "2021-08-01", Level: 1, Downline: []Downline{
+				{Msisdn: "6280003", ReferralDate: "2021-08-05", Level: 2, Downline: []Downline{}},
+			}},
+			{Msisdn: "6280002", ReferralDate: "2021-08-02", Level: 1, Downline: []Downline{}},
+		}, resp.Data.Downline)
+	})
+}
@@ -0,0 +1,45 @@
+package inquiry
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/candraalim/be_tsel_candra/internal/qr"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+// GetReferralCodeQR return QR code image of referral link of msisdn, referral code is generated when msisdn
+// does not have one like GetReferralCode
+func (i inquiryUseCase) GetReferralCodeQR(ctx context.Context, request ReferralCodeQRRequest) (ReferralCodeQRResponse, error) {
+	option := qr.Option{
+		Format: strings.ToLower(request.Format),
+		Size:   request.Size,
+		Level:  strings.ToUpper(request.Level),
+	}
+	//validate before referral code is generated
+	if err := option.Validate(); err != nil {
+		log.Println(err, ": ", request.Format, request.Size, request.Level)
+		return ReferralCodeQRResponse{}, util.ErrorInvalidRequest
+	}
+	if i.config.ShareURL == "" {
+		log.Println("share url is not configured")
+		return ReferralCodeQRResponse{}, util.ErrorGeneral
+	}
+
+	code, err := i.GetReferralCode(ctx, request.Msisdn)
+	if err != nil {
+		return ReferralCodeQRResponse{}, err
+	}
+
+	img, err := qr.Encode(i.config.ShareLink(code.Data.ReferralCode), option)
+	if err != nil {
+		log.Println("failed to encode qr: ", err)
+		return ReferralCodeQRResponse{}, util.ErrorGeneral
+	}
+	return ReferralCodeQRResponse{
+		ReferralCode: code.Data.ReferralCode,
+		ContentType:  img.ContentType,
+		Image:        img.Data,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package inquiry
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func Test_inquiryUseCase_GetReferralCodeQR(t *testing.T) {
+	shareConfig := config.ReferralConfig{ShareURL: "https://ref.telkomsel.com/"}
+
+	t.Run("invalid option is rejected before code generated", func(t *testing.T) {
+		i := inquiryUseCase{config: shareConfig}
+		_, err := i.GetReferralCodeQR(context.Background(), ReferralCodeQRRequest{Msisdn: "080000123131", Format: "gif"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+		_, err = i.GetReferralCodeQR(context.Background(), ReferralCodeQRRequest{Msisdn: "080000123131", Size: 10})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+		_, err = i.GetReferralCodeQR(context.Background(), ReferralCodeQRRequest{Msisdn: "080000123131", Level: "X"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("share url not configured", func(t *testing.T) {
+		i := inquiryUseCase{}
+		_, err := i.GetReferralCodeQR(context.Background(), ReferralCodeQRRequest{Msisdn: "080000123131"})
+		assert.Equal(t, util.ErrorGeneral, err)
+	})
+	t.Run("error get referral code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, context.DeadlineExceeded)
+
+		i := inquiryUseCase{codeRepository: codeMock, config: shareConfig}
+		_, err := i.GetReferralCodeQR(context.Background(), ReferralCodeQRRequest{Msisdn: "080000123131"})
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+	t.Run("default png", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "4C7AE71563"}, nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, config: shareConfig}
+		resp, err := i.GetReferralCodeQR(context.Background(), ReferralCodeQRRequest{Msisdn: "080000123131"})
+		assert.Nil(t, err)
+		assert.Equal(t, "4C7AE71563", resp.ReferralCode)
+		assert.Equal(t, "image/png", resp.ContentType)
+		img, err := png.Decode(bytes.NewReader(resp.Image))
+		assert.Nil(t, err)
+		assert.Equal(t, 256, img.Bounds().Dx())
+	})
+	t.Run("svg with lower case option", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "4C7AE71563"}, nil)
+
+		i := inquiryUseCase{codeRepository: codeMock, config: shareConfig}
+		resp, err := i.GetReferralCodeQR(context.Background(), ReferralCodeQRRequest{Msisdn: "080000123131",
+			Format: "SVG", Size: 512, Level: "h"})
+		assert.Nil(t, err)
+		assert.Equal(t, "image/svg+xml", resp.ContentType)
+		assert.True(t, strings.Contains(string(resp.Image), `width="512"`))
+	})
+}
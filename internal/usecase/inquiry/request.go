@@ -0,0 +1,15 @@
+package inquiry
+
+type ClaimReferralCodeRequest struct {
+	Msisdn       string `json:"-"`
+	ReferralCode string `json:"referralCode"`
+}
+
+type ReferralCodeQRRequest struct {
+	Msisdn string
+	// Format is png (default) or svg
+	Format string
+	Size   int
+	// Level is error correction level L, M (default), Q or H
+	Level string
+}
@@ -8,15 +8,49 @@ type ReferralCodeResponse struct {
 	} `json:"data"`
 }
 
-type ReferralRewardResponse struct {
+type ReferralCodeHistoryResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Data    struct {
-		TotalReferral int    `json:"totalReferral"`
-		Reward        string `json:"reward"`
+		List []ReferralCodeHistory `json:"list"`
 	} `json:"data"`
 }
 
+type ReferralCodeHistory struct {
+	ReferralCode string `json:"referralCode"`
+	Status       int    `json:"status"`
+	DateTime     int64  `json:"dateTime"`
+	ExpiresAt    int64  `json:"expiresAt,omitempty"`
+}
+
+type ReferralRewardResponse struct {
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Data    ReferralRewardData `json:"data"`
+}
+
+type ReferralRewardData struct {
+	TotalReferral int              `json:"totalReferral"`
+	Reward        string           `json:"reward"`
+	Levels        []LevelReward    `json:"levels"`
+	Campaigns     []CampaignReward `json:"campaigns"`
+}
+
+type LevelReward struct {
+	Level         int    `json:"level"`
+	TotalReferral int    `json:"totalReferral"`
+	Reward        string `json:"reward"`
+}
+
+type CampaignReward struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	StartDate     string `json:"startDate"`
+	EndDate       string `json:"endDate"`
+	TotalReferral int    `json:"totalReferral"`
+	Reward        string `json:"reward"`
+}
+
 type ReferralHistoryResponse struct {
 	Code    string              `json:"code"`
 	Message string              `json:"message"`
@@ -32,6 +66,8 @@ type ReferralHistory struct {
 	Msisdn       string `json:"msisdn"`
 	ReferralDate string `json:"referralDate"`
 	DateTime     int64  `json:"dateTime"`
+	Status       int    `json:"status"`
+	Flagged      bool   `json:"flagged,omitempty"`
 }
 
 type Meta struct {
@@ -43,3 +79,28 @@ type Meta struct {
 	FirstPage   bool `json:"firstPage"`
 	LastPage    bool `json:"lastPage"`
 }
+
+type DownlineResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Msisdn   string     `json:"msisdn"`
+		Depth    int        `json:"depth"`
+		Total    int        `json:"total"`
+		Downline []Downline `json:"downline"`
+	} `json:"data"`
+}
+
+type Downline struct {
+	Msisdn       string     `json:"msisdn"`
+	ReferralDate string     `json:"referralDate"`
+	Level        int        `json:"level"`
+	Downline     []Downline `json:"downline"`
+}
+
+// ReferralCodeQRResponse is image of referral link, written as is instead of json
+type ReferralCodeQRResponse struct {
+	ReferralCode string
+	ContentType  string
+	Image        []byte
+}
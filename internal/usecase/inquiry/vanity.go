@@ -0,0 +1,39 @@
+package inquiry
+
+import (
+	"log"
+	"strings"
+
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const vanityCodeMinLength = 4
+
+// reservedCodeWords can not be part of vanity code, additional word (e.g. profanity) is configured
+// in referral.blockedCodeWords
+var reservedCodeWords = []string{"ADMIN", "TSEL", "TELKOMSEL", "OFFICIAL", "PROMO", "GRATIS", "FREE"}
+
+// normalizeVanityCode return upper case vanity code, code is stored in upper case so uniqueness
+// is case insensitive
+func normalizeVanityCode(code string, maxLength int, blockedWords []string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) < vanityCodeMinLength || len(code) > maxLength {
+		log.Println("invalid vanity code length: ", code)
+		return "", util.ErrorReferralCodeNotAllowed
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			log.Println("invalid vanity code character: ", code)
+			return "", util.ErrorReferralCodeNotAllowed
+		}
+	}
+	for _, words := range [][]string{reservedCodeWords, blockedWords} {
+		for _, word := range words {
+			if word != "" && strings.Contains(code, strings.ToUpper(word)) {
+				log.Println("vanity code contains blocked word: ", code)
+				return "", util.ErrorReferralCodeNotAllowed
+			}
+		}
+	}
+	return code, nil
+}
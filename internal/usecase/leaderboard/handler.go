@@ -0,0 +1,44 @@
+package leaderboard
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LeaderboardHandler struct {
+	useCase LeaderboardUseCase
+}
+
+func SetupLeaderboardHandler(useCase LeaderboardUseCase) *LeaderboardHandler {
+	if useCase == nil {
+		panic("leaderboard use case is nil")
+	}
+	return &LeaderboardHandler{
+		useCase: useCase,
+	}
+}
+
+func (h LeaderboardHandler) GetLeaderboard(e echo.Context) error {
+	var limit int
+	if lim, err := strconv.Atoi(e.QueryParam("limit")); err == nil {
+		limit = lim
+	}
+
+	res, err := h.useCase.GetLeaderboard(e.Request().Context(), e.QueryParam("month"), limit)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h LeaderboardHandler) GetRank(e echo.Context) error {
+	res, err := h.useCase.GetRank(e.Request().Context(), e.Param("msisdn"), e.QueryParam("month"))
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
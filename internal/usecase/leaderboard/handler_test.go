@@ -0,0 +1,16 @@
+package leaderboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupLeaderboardHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupLeaderboardHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupLeaderboardHandler(&leaderboardUseCase{})
+	})
+}
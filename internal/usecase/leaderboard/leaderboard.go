@@ -0,0 +1,159 @@
+package leaderboard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+type LeaderboardUseCase interface {
+	GetLeaderboard(ctx context.Context, month string, limit int) (LeaderboardResponse, error)
+	GetRank(ctx context.Context, msisdn, month string) (RankResponse, error)
+}
+
+type leaderboardUseCase struct {
+	historyRepository model.ReferralHistoryRepository
+	config            config.ReferralConfig
+
+	mu    *sync.Mutex
+	cache map[string]cachedLeaderboard
+}
+
+// cachedLeaderboard is top maxLeaderboardLimit referrer of a month, smaller limit is served from it
+type cachedLeaderboard struct {
+	ranks     []model.ReferralRank
+	expiredAt time.Time
+}
+
+func SetupLeaderboardUseCase(historyRepository model.ReferralHistoryRepository, referralConfig *config.ReferralConfig) LeaderboardUseCase {
+	if historyRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	if referralConfig == nil {
+		panic("ReferralConfig is nil")
+	}
+	return &leaderboardUseCase{
+		historyRepository: historyRepository,
+		config:            *referralConfig,
+		mu:                &sync.Mutex{},
+		cache:             make(map[string]cachedLeaderboard),
+	}
+}
+
+// GetLeaderboard return top referrer of month with masked msisdn, month default to current month
+func (l leaderboardUseCase) GetLeaderboard(ctx context.Context, month string, limit int) (resp LeaderboardResponse, err error) {
+	month, err = validateMonth(month)
+	if err != nil {
+		return LeaderboardResponse{}, err
+	}
+	if limit < 1 {
+		limit = defaultLeaderboardLimit
+	}
+	if limit > maxLeaderboardLimit {
+		limit = maxLeaderboardLimit
+	}
+
+	ranks, err := l.findTopReferrer(ctx, month)
+	if err != nil {
+		return LeaderboardResponse{}, err
+	}
+	if len(ranks) > limit {
+		ranks = ranks[:limit]
+	}
+
+	list := make([]Rank, len(ranks))
+	for i, v := range ranks {
+		list[i] = Rank{
+			Rank:          v.Rank,
+			Msisdn:        util.MaskMsisdn(v.Msisdn),
+			TotalReferral: v.Total,
+		}
+	}
+
+	resp = LeaderboardResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Month = month
+	resp.Data.List = list
+	return resp, nil
+}
+
+// GetRank return rank of msisdn in month, rank is 0 when msisdn has no counted referral in the month
+func (l leaderboardUseCase) GetRank(ctx context.Context, msisdn, month string) (resp RankResponse, err error) {
+	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
+	if err != nil {
+		return RankResponse{}, err
+	}
+	month, err = validateMonth(month)
+	if err != nil {
+		return RankResponse{}, err
+	}
+
+	rank, err := l.historyRepository.GetRankByMsisdnAndMonth(ctx, msisdn, month)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return RankResponse{}, err
+	}
+
+	resp = RankResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Month = month
+	resp.Data.Rank = rank.Rank
+	resp.Data.Msisdn = msisdn
+	resp.Data.TotalReferral = rank.Total
+	return resp, nil
+}
+
+func (l leaderboardUseCase) findTopReferrer(ctx context.Context, month string) ([]model.ReferralRank, error) {
+	now := time.Now()
+	l.mu.Lock()
+	cached, ok := l.cache[month]
+	l.mu.Unlock()
+	if ok && now.Before(cached.expiredAt) {
+		return cached.ranks, nil
+	}
+
+	ranks, err := l.historyRepository.FindTopReferrer(ctx, month, maxLeaderboardLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	//remove expired leaderboard so old month does not stay in memory
+	for k, v := range l.cache {
+		if !now.Before(v.expiredAt) {
+			delete(l.cache, k)
+		}
+	}
+	l.cache[month] = cachedLeaderboard{
+		ranks:     ranks,
+		expiredAt: now.Add(l.config.LeaderboardCacheTTL()),
+	}
+	return ranks, nil
+}
+
+func validateMonth(month string) (string, error) {
+	if month == "" {
+		return time.Now().Format("2006-01"), nil
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		log.Println("invalid month: ", month)
+		return "", util.ErrorInvalidRequest
+	}
+	return month, nil
+}
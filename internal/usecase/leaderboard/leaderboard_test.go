@@ -0,0 +1,124 @@
+package leaderboard
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupLeaderboardUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupLeaderboardUseCase(nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupLeaderboardUseCase(&mocks.ReferralHistoryRepository{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupLeaderboardUseCase(&mocks.ReferralHistoryRepository{}, &config.ReferralConfig{})
+	})
+}
+
+func newLeaderboardUseCase(historyRepository model.ReferralHistoryRepository) leaderboardUseCase {
+	return leaderboardUseCase{
+		historyRepository: historyRepository,
+		mu:                &sync.Mutex{},
+		cache:             make(map[string]cachedLeaderboard),
+	}
+}
+
+func Test_leaderboardUseCase_GetLeaderboard(t *testing.T) {
+	t.Run("invalid month", func(t *testing.T) {
+		l := newLeaderboardUseCase(nil)
+		_, err := l.GetLeaderboard(context.Background(), "08-2021", 10)
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error get top referrer", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindTopReferrer", mock.Anything, "2021-08", maxLeaderboardLimit).Return(nil, context.DeadlineExceeded)
+
+		l := newLeaderboardUseCase(historyMock)
+		_, err := l.GetLeaderboard(context.Background(), "2021-08", 10)
+		assert.NotNil(t, err)
+	})
+	t.Run("masked and limited, next request served from cache", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindTopReferrer", mock.Anything, "2021-08", maxLeaderboardLimit).Return([]model.ReferralRank{
+			{Rank: 1, Msisdn: "6281234567890", Total: 6},
+			{Rank: 2, Msisdn: "6281234567000", Total: 6},
+			{Rank: 3, Msisdn: "6281234560000", Total: 2},
+		}, nil).Once()
+
+		l := newLeaderboardUseCase(historyMock)
+		resp, err := l.GetLeaderboard(context.Background(), "2021-08", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, "2021-08", resp.Data.Month)
+		assert.Equal(t, []Rank{
+			{Rank: 1, Msisdn: "6281******890", TotalReferral: 6},
+			{Rank: 2, Msisdn: "6281******000", TotalReferral: 6},
+		}, resp.Data.List)
+
+		resp, err = l.GetLeaderboard(context.Background(), "2021-08", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(resp.Data.List))
+		historyMock.AssertNumberOfCalls(t, "FindTopReferrer", 1)
+	})
+	t.Run("expired cache is reloaded", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindTopReferrer", mock.Anything, "2021-08", maxLeaderboardLimit).Return([]model.ReferralRank{}, nil)
+
+		l := newLeaderboardUseCase(historyMock)
+		l.cache["2021-08"] = cachedLeaderboard{expiredAt: time.Now().Add(-time.Second)}
+		l.cache["2021-07"] = cachedLeaderboard{expiredAt: time.Now().Add(-time.Second)}
+		_, err := l.GetLeaderboard(context.Background(), "2021-08", 10)
+		assert.Nil(t, err)
+		historyMock.AssertNumberOfCalls(t, "FindTopReferrer", 1)
+		assert.Equal(t, 1, len(l.cache))
+	})
+}
+
+func Test_leaderboardUseCase_GetRank(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		l := newLeaderboardUseCase(nil)
+		_, err := l.GetRank(context.Background(), "080000acbcd", "2021-08")
+		assert.NotNil(t, err)
+	})
+	t.Run("error get rank", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetRankByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(model.ReferralRank{}, context.DeadlineExceeded)
+
+		l := newLeaderboardUseCase(historyMock)
+		_, err := l.GetRank(context.Background(), "62821000000", "2021-08")
+		assert.NotNil(t, err)
+	})
+	t.Run("not ranked", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetRankByMsisdnAndMonth", mock.Anything, "62821000000", "2021-08").Return(model.ReferralRank{}, sql.ErrNoRows)
+
+		l := newLeaderboardUseCase(historyMock)
+		resp, err := l.GetRank(context.Background(), "62821000000", "2021-08")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, resp.Data.Rank)
+	})
+	t.Run("return rank", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetRankByMsisdnAndMonth", mock.Anything, "62821000000", time.Now().Format("2006-01")).
+			Return(model.ReferralRank{Rank: 4, Msisdn: "62821000000", Total: 3}, nil)
+
+		l := newLeaderboardUseCase(historyMock)
+		resp, err := l.GetRank(context.Background(), "62821000000", "")
+		assert.Nil(t, err)
+		assert.Equal(t, 4, resp.Data.Rank)
+		assert.Equal(t, 3, resp.Data.TotalReferral)
+		assert.Equal(t, "62821000000", resp.Data.Msisdn)
+	})
+}
@@ -0,0 +1,23 @@
+package leaderboard
+
+type LeaderboardResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Month string `json:"month"`
+		List  []Rank `json:"list"`
+	} `json:"data"`
+}
+
+type RankResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    Rank   `json:"data"`
+}
+
+type Rank struct {
+	Month         string `json:"month,omitempty"`
+	Rank          int    `json:"rank"`
+	Msisdn        string `json:"msisdn"`
+	TotalReferral int    `json:"totalReferral"`
+}
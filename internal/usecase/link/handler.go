@@ -0,0 +1,31 @@
+package link
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LinkHandler struct {
+	useCase LinkUseCase
+}
+
+func SetupLinkHandler(useCase LinkUseCase) *LinkHandler {
+	if useCase == nil {
+		panic("link use case is nil")
+	}
+	return &LinkHandler{
+		useCase: useCase,
+	}
+}
+
+func (h LinkHandler) Redirect(e echo.Context) error {
+	location := h.useCase.Redirect(e.Request().Context(), RedirectRequest{
+		Code:      e.Param("code"),
+		Channel:   e.QueryParam("channel"),
+		UserAgent: e.Request().UserAgent(),
+	})
+	//every visit must reach the service to be counted
+	e.Response().Header().Set("Cache-Control", "no-store")
+	return e.Redirect(http.StatusFound, location)
+}
@@ -0,0 +1,47 @@
+package link
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupLinkHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupLinkHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupLinkHandler(&linkUseCase{})
+	})
+}
+
+func TestLinkHandler_Redirect(t *testing.T) {
+	codeMock := &mocks.ReferralCodeRepository{}
+	codeMock.On("FindByCode", mock.Anything, "4C7AE71563").Return(model.ReferralCode{ID: 1, Code: "4C7AE71563",
+		Status: model.ReferralCodeStatusActive}, nil)
+	clickMock := &mocks.ReferralClickRepository{}
+	clickMock.On("Insert", mock.Anything, &model.ReferralClick{Code: "4C7AE71563", Channel: "sms",
+		UserAgent: "Mozilla/5.0"}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/r/4C7AE71563?channel=sms", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("code")
+	c.SetParamValues("4C7AE71563")
+
+	h := SetupLinkHandler(newLinkUseCase(codeMock, clickMock))
+	err := h.Redirect(c)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://www.telkomsel.com/referral?channel=sms&code=4C7AE71563&utm_source=referral",
+		rec.Header().Get(echo.HeaderLocation))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,118 @@
+package link
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/generator"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+const (
+	maxChannelLength   = 20
+	maxUserAgentLength = 255
+)
+
+type LinkUseCase interface {
+	Redirect(ctx context.Context, request RedirectRequest) string
+}
+
+type RedirectRequest struct {
+	Code      string
+	Channel   string
+	UserAgent string
+}
+
+type linkUseCase struct {
+	codeRepository  model.ReferralCodeRepository
+	clickRepository model.ReferralClickRepository
+	config          config.ReferralConfig
+	landingURL      *url.URL
+}
+
+func SetupLinkUseCase(referralCodeRepository model.ReferralCodeRepository,
+	referralClickRepository model.ReferralClickRepository,
+	referralConfig *config.ReferralConfig) LinkUseCase {
+	if referralCodeRepository == nil {
+		panic("ReferralCodeRepository is nil")
+	}
+	if referralClickRepository == nil {
+		panic("ReferralClickRepository is nil")
+	}
+	if referralConfig == nil {
+		panic("ReferralConfig is nil")
+	}
+	landingURL, err := url.Parse(referralConfig.LandingURL)
+	if err != nil || !landingURL.IsAbs() {
+		panic("LandingURL is invalid")
+	}
+	return &linkUseCase{
+		codeRepository:  referralCodeRepository,
+		clickRepository: referralClickRepository,
+		config:          *referralConfig,
+		landingURL:      landingURL,
+	}
+}
+
+// Redirect log click of shared referral link and return landing url with the code. Unknown, inactive or expired code
+// is not logged and redirected to landing url without code, failure to log the click does not fail the redirect.
+func (l linkUseCase) Redirect(ctx context.Context, request RedirectRequest) string {
+	code := strings.TrimSpace(request.Code)
+	if code == "" || len(code) > l.config.CodeConfig().MaxCodeLength() || !generator.ValidCodeCharacter(code) ||
+		!generator.ValidCheckDigit(code) {
+		log.Println("invalid referral link code: ", code)
+		return l.landingURL.String()
+	}
+
+	referralCode, err := l.codeRepository.FindByCode(ctx, code)
+	if err != nil {
+		log.Println("referral link code not found: ", code, err)
+		return l.landingURL.String()
+	}
+	if referralCode.IsExpired(time.Now()) {
+		log.Println("referral link code expired: ", code)
+		return l.landingURL.String()
+	}
+
+	channel := sanitizeChannel(request.Channel)
+	err = l.clickRepository.Insert(ctx, &model.ReferralClick{
+		Code:      referralCode.Code,
+		Channel:   channel,
+		UserAgent: truncate(request.UserAgent, maxUserAgentLength),
+	})
+	if err != nil {
+		log.Println("failed to log referral link click: ", referralCode.Code, err)
+	}
+
+	landing := *l.landingURL
+	query := landing.Query()
+	query.Set("code", referralCode.Code)
+	if channel != "" {
+		query.Set("channel", channel)
+	}
+	landing.RawQuery = query.Encode()
+	return landing.String()
+}
+
+// sanitizeChannel keep lower case letter, digit, dash and underscore of channel
+func sanitizeChannel(channel string) string {
+	channel = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(channel))
+	return truncate(channel, maxChannelLength)
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) > length {
+		return string(runes[:length])
+	}
+	return value
+}
@@ -0,0 +1,108 @@
+package link
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+const landing = "https://www.telkomsel.com/referral?utm_source=referral"
+
+func TestSetupLinkUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupLinkUseCase(nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupLinkUseCase(&mocks.ReferralCodeRepository{}, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupLinkUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralClickRepository{}, nil)
+	})
+	assert.Panics(t, func() {
+		SetupLinkUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralClickRepository{}, &config.ReferralConfig{})
+	})
+	assert.NotPanics(t, func() {
+		SetupLinkUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralClickRepository{},
+			&config.ReferralConfig{LandingURL: landing})
+	})
+}
+
+func newLinkUseCase(codeRepository model.ReferralCodeRepository, clickRepository model.ReferralClickRepository) LinkUseCase {
+	return SetupLinkUseCase(codeRepository, clickRepository, &config.ReferralConfig{LandingURL: landing})
+}
+
+func Test_linkUseCase_Redirect(t *testing.T) {
+	t.Run("malformed code is not looked up", func(t *testing.T) {
+		l := newLinkUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralClickRepository{})
+		assert.Equal(t, landing, l.Redirect(context.Background(), RedirectRequest{Code: "4C7AE71563-X"}))
+	})
+	t.Run("non ascii code is not looked up", func(t *testing.T) {
+		l := newLinkUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralClickRepository{})
+		assert.Equal(t, landing, l.Redirect(context.Background(), RedirectRequest{Code: "ſſ-A"}))
+		assert.Equal(t, landing, l.Redirect(context.Background(), RedirectRequest{Code: "%E2%88%9E"}))
+	})
+	t.Run("too long code is not looked up", func(t *testing.T) {
+		l := newLinkUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralClickRepository{})
+		assert.Equal(t, landing, l.Redirect(context.Background(), RedirectRequest{Code: strings.Repeat("A", 21)}))
+	})
+	t.Run("code not found", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, "4C7AE71563").Return(model.ReferralCode{}, sql.ErrNoRows)
+
+		l := newLinkUseCase(codeMock, &mocks.ReferralClickRepository{})
+		assert.Equal(t, landing, l.Redirect(context.Background(), RedirectRequest{Code: "4C7AE71563"}))
+	})
+	t.Run("expired code is not logged", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, "4C7AE71563").Return(model.ReferralCode{ID: 1, Code: "4C7AE71563",
+			Status: model.ReferralCodeStatusActive, ExpiresAt: &expiresAt}, nil)
+
+		l := newLinkUseCase(codeMock, &mocks.ReferralClickRepository{})
+		assert.Equal(t, landing, l.Redirect(context.Background(), RedirectRequest{Code: "4C7AE71563"}))
+	})
+	t.Run("click logged with sanitized channel", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, "4c7ae71563").Return(model.ReferralCode{ID: 1, Code: "4C7AE71563",
+			Status: model.ReferralCodeStatusActive}, nil)
+		clickMock := &mocks.ReferralClickRepository{}
+		clickMock.On("Insert", mock.Anything, &model.ReferralClick{Code: "4C7AE71563", Channel: "whatsapp",
+			UserAgent: "Mozilla/5.0"}).Return(nil)
+
+		l := newLinkUseCase(codeMock, clickMock)
+		location := l.Redirect(context.Background(), RedirectRequest{Code: " 4c7ae71563 ", Channel: "WhatsApp!",
+			UserAgent: "Mozilla/5.0"})
+		u, err := url.Parse(location)
+		assert.Nil(t, err)
+		assert.Equal(t, "www.telkomsel.com", u.Host)
+		assert.Equal(t, url.Values{"code": {"4C7AE71563"}, "channel": {"whatsapp"}, "utm_source": {"referral"}}, u.Query())
+		clickMock.AssertExpectations(t)
+	})
+	t.Run("failed to log click still redirect with code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, "4C7AE71563").Return(model.ReferralCode{ID: 1, Code: "4C7AE71563",
+			Status: model.ReferralCodeStatusActive}, nil)
+		clickMock := &mocks.ReferralClickRepository{}
+		clickMock.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		l := newLinkUseCase(codeMock, clickMock)
+		location := l.Redirect(context.Background(), RedirectRequest{Code: "4C7AE71563"})
+		assert.Equal(t, "https://www.telkomsel.com/referral?code=4C7AE71563&utm_source=referral", location)
+	})
+}
+
+func Test_sanitizeChannel(t *testing.T) {
+	assert.Equal(t, "", sanitizeChannel(""))
+	assert.Equal(t, "ig_story-1", sanitizeChannel("IG_Story-1"))
+	assert.Equal(t, "abcdefghijklmnopqrst", sanitizeChannel("abcdefghijklmnopqrstuvwxyz"))
+}
@@ -0,0 +1,44 @@
+package preference
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PreferenceHandler struct {
+	useCase PreferenceUseCase
+}
+
+func SetupPreferenceHandler(useCase PreferenceUseCase) *PreferenceHandler {
+	if useCase == nil {
+		panic("preference use case is nil")
+	}
+	return &PreferenceHandler{
+		useCase: useCase,
+	}
+}
+
+func (h PreferenceHandler) GetPreference(e echo.Context) error {
+	res, err := h.useCase.GetPreference(e.Request().Context(), e.Param("msisdn"))
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h PreferenceHandler) UpdatePreference(e echo.Context) error {
+	var request UpdatePreferenceRequest
+	if err := e.Bind(&request); err != nil {
+		return err
+	}
+	request.Msisdn = e.Param("msisdn")
+
+	res, err := h.useCase.UpdatePreference(e.Request().Context(), request)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
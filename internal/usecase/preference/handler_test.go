@@ -0,0 +1,47 @@
+package preference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupPreferenceHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupPreferenceHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupPreferenceHandler(&preferenceUseCase{})
+	})
+}
+
+func TestPreferenceHandler_UpdatePreference(t *testing.T) {
+	preferenceMock := &mocks.NotificationPreferenceRepository{}
+	preferenceMock.On("FindByMsisdn", mock.Anything, "6280000123131").Return(model.NotificationPreference{
+		Msisdn: "6280000123131", Language: "id"}, nil)
+	preferenceMock.On("Upsert", mock.Anything, model.NotificationPreference{Msisdn: "6280000123131", Language: "en",
+		OptOut: true}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/1.0/referral/080000123131/notification",
+		strings.NewReader(`{"language":"en","optOut":true}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("msisdn")
+	c.SetParamValues("080000123131")
+
+	h := SetupPreferenceHandler(&preferenceUseCase{preferenceRepository: preferenceMock})
+	err := h.UpdatePreference(c)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"optOut":true`)
+	preferenceMock.AssertExpectations(t)
+}
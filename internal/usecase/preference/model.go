@@ -0,0 +1,20 @@
+package preference
+
+type UpdatePreferenceRequest struct {
+	Msisdn string `json:"-"`
+	// Language is id or en, current language is kept when empty
+	Language string `json:"language"`
+	OptOut   bool   `json:"optOut"`
+}
+
+type PreferenceResponse struct {
+	Code    string     `json:"code"`
+	Message string     `json:"message"`
+	Data    Preference `json:"data"`
+}
+
+type Preference struct {
+	Msisdn   string `json:"msisdn"`
+	Language string `json:"language"`
+	OptOut   bool   `json:"optOut"`
+}
@@ -0,0 +1,98 @@
+package preference
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/notification"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type PreferenceUseCase interface {
+	GetPreference(ctx context.Context, msisdn string) (PreferenceResponse, error)
+	UpdatePreference(ctx context.Context, request UpdatePreferenceRequest) (PreferenceResponse, error)
+}
+
+type preferenceUseCase struct {
+	preferenceRepository model.NotificationPreferenceRepository
+	config               config.NotificationConfig
+}
+
+func SetupPreferenceUseCase(preferenceRepository model.NotificationPreferenceRepository,
+	notificationConfig *config.NotificationConfig) PreferenceUseCase {
+	if preferenceRepository == nil {
+		panic("NotificationPreferenceRepository is nil")
+	}
+	if notificationConfig == nil {
+		notificationConfig = &config.NotificationConfig{}
+	}
+	return &preferenceUseCase{
+		preferenceRepository: preferenceRepository,
+		config:               *notificationConfig,
+	}
+}
+
+// GetPreference return notification preference of msisdn, default one when it is not set
+func (p preferenceUseCase) GetPreference(ctx context.Context, msisdn string) (PreferenceResponse, error) {
+	msisdn, err := util.ValidateAndSanitizeMsisdn(msisdn)
+	if err != nil {
+		return PreferenceResponse{}, err
+	}
+
+	preference, err := p.findPreference(ctx, msisdn)
+	if err != nil {
+		return PreferenceResponse{}, err
+	}
+	return assemblerResponse(preference), nil
+}
+
+// UpdatePreference set language and opt out of msisdn, opted out msisdn receive no notification
+// including the one already queued
+func (p preferenceUseCase) UpdatePreference(ctx context.Context, request UpdatePreferenceRequest) (PreferenceResponse, error) {
+	msisdn, err := util.ValidateAndSanitizeMsisdn(request.Msisdn)
+	if err != nil {
+		return PreferenceResponse{}, err
+	}
+	if request.Language != "" && !notification.ValidLanguage(request.Language) {
+		log.Println("invalid notification language: ", request.Language)
+		return PreferenceResponse{}, util.ErrorInvalidRequest
+	}
+
+	preference, err := p.findPreference(ctx, msisdn)
+	if err != nil {
+		return PreferenceResponse{}, err
+	}
+	if request.Language != "" {
+		preference.Language = request.Language
+	}
+	preference.OptOut = request.OptOut
+
+	if err = p.preferenceRepository.Upsert(ctx, preference); err != nil {
+		return PreferenceResponse{}, err
+	}
+	return assemblerResponse(preference), nil
+}
+
+func (p preferenceUseCase) findPreference(ctx context.Context, msisdn string) (model.NotificationPreference, error) {
+	preference, err := p.preferenceRepository.FindByMsisdn(ctx, msisdn)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.NotificationPreference{Msisdn: msisdn, Language: p.config.Language()}, nil
+	}
+	return preference, err
+}
+
+func assemblerResponse(preference model.NotificationPreference) PreferenceResponse {
+	return PreferenceResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+		Data: Preference{
+			Msisdn:   preference.Msisdn,
+			Language: preference.Language,
+			OptOut:   preference.OptOut,
+		},
+	}
+}
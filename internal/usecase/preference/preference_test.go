@@ -0,0 +1,105 @@
+package preference
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupPreferenceUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupPreferenceUseCase(nil, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupPreferenceUseCase(&mocks.NotificationPreferenceRepository{}, nil)
+	})
+}
+
+func Test_preferenceUseCase_GetPreference(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		p := preferenceUseCase{}
+		_, err := p.GetPreference(context.Background(), "080000acbcd")
+		assert.NotNil(t, err)
+	})
+	t.Run("error find preference", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{}, context.DeadlineExceeded)
+
+		p := preferenceUseCase{preferenceRepository: preferenceMock}
+		_, err := p.GetPreference(context.Background(), "6280000011")
+		assert.NotNil(t, err)
+	})
+	t.Run("default preference", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{}, sql.ErrNoRows)
+
+		p := preferenceUseCase{preferenceRepository: preferenceMock, config: config.NotificationConfig{DefaultLanguage: "en"}}
+		resp, err := p.GetPreference(context.Background(), "6280000011")
+		assert.Nil(t, err)
+		assert.Equal(t, Preference{Msisdn: "6280000011", Language: "en"}, resp.Data)
+	})
+	t.Run("success", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{
+			Msisdn: "6280000011", Language: "id", OptOut: true}, nil)
+
+		p := preferenceUseCase{preferenceRepository: preferenceMock}
+		resp, err := p.GetPreference(context.Background(), "6280000011")
+		assert.Nil(t, err)
+		assert.Equal(t, util.CodeSuccess, resp.Code)
+		assert.True(t, resp.Data.OptOut)
+	})
+}
+
+func Test_preferenceUseCase_UpdatePreference(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		p := preferenceUseCase{}
+		_, err := p.UpdatePreference(context.Background(), UpdatePreferenceRequest{Msisdn: "080000acbcd"})
+		assert.NotNil(t, err)
+	})
+	t.Run("invalid language", func(t *testing.T) {
+		p := preferenceUseCase{}
+		_, err := p.UpdatePreference(context.Background(), UpdatePreferenceRequest{Msisdn: "6280000011", Language: "jv"})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("error upsert", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{}, sql.ErrNoRows)
+		preferenceMock.On("Upsert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		p := preferenceUseCase{preferenceRepository: preferenceMock}
+		_, err := p.UpdatePreference(context.Background(), UpdatePreferenceRequest{Msisdn: "6280000011", OptOut: true})
+		assert.NotNil(t, err)
+	})
+	t.Run("opt out keep current language", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{
+			Msisdn: "6280000011", Language: "en"}, nil)
+		preferenceMock.On("Upsert", mock.Anything, model.NotificationPreference{Msisdn: "6280000011", Language: "en",
+			OptOut: true}).Return(nil)
+
+		p := preferenceUseCase{preferenceRepository: preferenceMock}
+		resp, err := p.UpdatePreference(context.Background(), UpdatePreferenceRequest{Msisdn: "6280000011", OptOut: true})
+		assert.Nil(t, err)
+		assert.Equal(t, Preference{Msisdn: "6280000011", Language: "en", OptOut: true}, resp.Data)
+		preferenceMock.AssertExpectations(t)
+	})
+	t.Run("new preference", func(t *testing.T) {
+		preferenceMock := &mocks.NotificationPreferenceRepository{}
+		preferenceMock.On("FindByMsisdn", mock.Anything, "6280000011").Return(model.NotificationPreference{}, sql.ErrNoRows)
+		preferenceMock.On("Upsert", mock.Anything, model.NotificationPreference{Msisdn: "6280000011", Language: "en"}).Return(nil)
+
+		p := preferenceUseCase{preferenceRepository: preferenceMock}
+		_, err := p.UpdatePreference(context.Background(), UpdatePreferenceRequest{Msisdn: "6280000011", Language: "en"})
+		assert.Nil(t, err)
+		preferenceMock.AssertExpectations(t)
+	})
+}
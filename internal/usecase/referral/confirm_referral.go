@@ -0,0 +1,113 @@
+package referral
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+// ConfirmReferral confirm pending referral of referee, called once referee activate the SIM
+func (r referUseCase) ConfirmReferral(ctx context.Context, msisdnReferee string) (resp ConfirmResponse, err error) {
+	msisdnReferee, err = util.ValidateAndSanitizeMsisdn(msisdnReferee)
+	if err != nil {
+		return ConfirmResponse{}, err
+	}
+
+	history, err := r.historyRepository.FindByMsisdnReferee(ctx, msisdnReferee)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ConfirmResponse{}, util.ErrorDataNotFound
+	}
+	if err != nil {
+		return ConfirmResponse{}, err
+	}
+	if history.Status == model.ReferralStatusExpired {
+		log.Println("referral expired: ", msisdnReferee)
+		return ConfirmResponse{}, util.ErrorReferralExpired
+	}
+	if !history.CanTransitTo(model.ReferralStatusConfirmed) {
+		log.Println("referral can not be confirmed: ", msisdnReferee, history.Status)
+		return ConfirmResponse{}, util.ErrorReferralStatusInvalid
+	}
+	//pending window elapsed but not yet expired by the job
+	if time.Since(history.CreatedDate) > r.config.PendingExpiry() {
+		log.Println("referral expired: ", msisdnReferee)
+		return ConfirmResponse{}, util.ErrorReferralExpired
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.historyRepository.Confirm(ctx, history.ID); err != nil {
+			return err
+		}
+		//flagged referral is not counted for reward
+		if history.Flagged {
+			return nil
+		}
+		return r.writeTierReached(ctx, history)
+	})
+	//status changed by other request
+	if errors.Is(err, util.ErrorDataNotFound) {
+		return ConfirmResponse{}, util.ErrorReferralStatusInvalid
+	}
+	if err != nil {
+		return ConfirmResponse{}, err
+	}
+	if !history.Flagged {
+		r.tracker.Add(ctx, history.Msisdn, history.ReferralDate[:7], 1)
+	}
+
+	resp = ConfirmResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Msisdn = history.Msisdn
+	resp.Data.MsisdnReferee = history.MsisdnReferee
+	resp.Data.ReferralDate = history.ReferralDate
+	resp.Data.Status = model.ReferralStatusConfirmed
+	return resp, nil
+}
+
+// writeTierReached store event in outbox when confirmed referral make referrer reach default reward tier of the month,
+// tier is reached only by the referral making the total equal to it. Total is counted within transaction of ctx
+// so it include the confirmed referral
+func (r referUseCase) writeTierReached(ctx context.Context, history model.ReferralHistory) error {
+	month := history.ReferralDate[:7]
+	total, err := r.historyRepository.GetTotalByMsisdnAndMonth(ctx, history.Msisdn, month)
+	if err != nil {
+		return err
+	}
+	reward, err := r.rewardRepository.FindByTotalReferral(ctx, total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if reward.TotalReferral != total {
+		return nil
+	}
+	return outbox.Write(ctx, r.outboxRepository, outbox.EventRewardTierReached, outbox.TierEvent{
+		Msisdn:        history.Msisdn,
+		Month:         month,
+		TotalReferral: total,
+		RewardID:      reward.ID,
+		Reward:        reward.Description,
+	})
+}
+
+// ExpirePendingReferral expire referral which is not confirmed within configured window
+func (r referUseCase) ExpirePendingReferral(ctx context.Context) error {
+	total, err := r.historyRepository.ExpirePending(ctx, time.Now().Add(-r.config.PendingExpiry()))
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		log.Println("pending referral expired: ", total)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package referral
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	counterMocks "github.com/candraalim/be_tsel_candra/internal/mock/counter"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func Test_referUseCase_ConfirmReferral(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		r := referUseCase{}
+		_, err := r.ConfirmReferral(context.Background(), "080000acbcd")
+		assert.NotNil(t, err)
+	})
+	t.Run("referral not found", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{}, sql.ErrNoRows)
+
+		r := referUseCase{historyRepository: historyMock}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Equal(t, util.ErrorDataNotFound, err)
+	})
+	t.Run("referral already expired", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Status: model.ReferralStatusExpired}, nil)
+
+		r := referUseCase{historyRepository: historyMock}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Equal(t, util.ErrorReferralExpired, err)
+	})
+	t.Run("referral already confirmed", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Status: model.ReferralStatusConfirmed}, nil)
+
+		r := referUseCase{historyRepository: historyMock}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Equal(t, util.ErrorReferralStatusInvalid, err)
+	})
+	t.Run("pending window elapsed", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Status: model.ReferralStatusPending, CreatedDate: time.Now().Add(-3 * time.Hour)}, nil)
+
+		r := referUseCase{historyRepository: historyMock, config: config.ReferralConfig{PendingExpiryHours: 2}}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Equal(t, util.ErrorReferralExpired, err)
+		historyMock.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything)
+	})
+	t.Run("status changed by other request", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Status: model.ReferralStatusPending, CreatedDate: time.Now()}, nil)
+		historyMock.On("Confirm", mock.Anything, int64(4)).Return(util.ErrorDataNotFound)
+
+		r := referUseCase{historyRepository: historyMock, transactor: transactorMock()}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Equal(t, util.ErrorReferralStatusInvalid, err)
+	})
+	t.Run("success", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Msisdn: "628000001111", MsisdnReferee: "6280001100001", ReferralDate: "2021-08-12",
+			Status: model.ReferralStatusPending, CreatedDate: time.Now()}, nil)
+		historyMock.On("Confirm", mock.Anything, int64(4)).Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "628000001111", "2021-08").Return(4, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByTotalReferral", mock.Anything, 4).Return(model.Reward{ID: 2, TotalReferral: 3}, nil)
+
+		outboxRepository := &mocks.OutboxRepository{}
+
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Add", mock.Anything, "628000001111", "2021-08", 1).Return()
+
+		r := referUseCase{historyRepository: historyMock, rewardRepository: rewardMock, transactor: transactorMock(),
+			outboxRepository: outboxRepository, tracker: trackerMock}
+		resp, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Nil(t, err)
+		assert.Equal(t, "628000001111", resp.Data.Msisdn)
+		assert.Equal(t, model.ReferralStatusConfirmed, resp.Data.Status)
+		outboxRepository.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+		trackerMock.AssertExpectations(t)
+	})
+	t.Run("failed write event rollback confirm", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Msisdn: "628000001111", MsisdnReferee: "6280001100001", ReferralDate: "2021-08-12",
+			Status: model.ReferralStatusPending, CreatedDate: time.Now()}, nil)
+		historyMock.On("Confirm", mock.Anything, int64(4)).Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "628000001111", "2021-08").Return(3, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByTotalReferral", mock.Anything, 3).Return(model.Reward{ID: 2, TotalReferral: 3}, nil)
+
+		outboxRepository := &mocks.OutboxRepository{}
+		outboxRepository.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		trackerMock := &counterMocks.Tracker{}
+
+		r := referUseCase{historyRepository: historyMock, rewardRepository: rewardMock, transactor: transactorMock(),
+			outboxRepository: outboxRepository, tracker: trackerMock}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Equal(t, context.DeadlineExceeded, err)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("success reach reward tier", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Msisdn: "628000001111", MsisdnReferee: "6280001100001", ReferralDate: "2021-08-12",
+			Status: model.ReferralStatusPending, CreatedDate: time.Now()}, nil)
+		historyMock.On("Confirm", mock.Anything, int64(4)).Return(nil)
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, "628000001111", "2021-08").Return(3, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByTotalReferral", mock.Anything, 3).Return(model.Reward{ID: 2, TotalReferral: 3,
+			Description: "Bonus Quota 3GB"}, nil)
+
+		outboxRepository := &mocks.OutboxRepository{}
+		outboxRepository.On("Insert", mock.Anything, mock.MatchedBy(func(e *model.OutboxEvent) bool {
+			var data outbox.TierEvent
+			return e.Event == outbox.EventRewardTierReached && json.Unmarshal([]byte(e.Payload), &data) == nil &&
+				data == outbox.TierEvent{Msisdn: "628000001111", Month: "2021-08", TotalReferral: 3, RewardID: 2,
+					Reward: "Bonus Quota 3GB"}
+		})).Return(nil)
+
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Add", mock.Anything, "628000001111", "2021-08", 1).Return()
+
+		r := referUseCase{historyRepository: historyMock, rewardRepository: rewardMock, transactor: transactorMock(),
+			outboxRepository: outboxRepository, tracker: trackerMock}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Nil(t, err)
+		outboxRepository.AssertExpectations(t)
+	})
+	t.Run("success flagged referral not counted for reward tier", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100001").Return(model.ReferralHistory{ID: 4,
+			Msisdn: "628000001111", MsisdnReferee: "6280001100001", ReferralDate: "2021-08-12", Flagged: true,
+			Status: model.ReferralStatusPending, CreatedDate: time.Now()}, nil)
+		historyMock.On("Confirm", mock.Anything, int64(4)).Return(nil)
+
+		trackerMock := &counterMocks.Tracker{}
+
+		r := referUseCase{historyRepository: historyMock, transactor: transactorMock(), tracker: trackerMock}
+		_, err := r.ConfirmReferral(context.Background(), "6280001100001")
+		assert.Nil(t, err)
+		historyMock.AssertNotCalled(t, "GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func Test_referUseCase_ExpirePendingReferral(t *testing.T) {
+	t.Run("error expire", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("ExpirePending", mock.Anything, mock.Anything).Return(int64(0), context.DeadlineExceeded)
+
+		r := referUseCase{historyRepository: historyMock}
+		assert.NotNil(t, r.ExpirePendingReferral(context.Background()))
+	})
+	t.Run("success", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("ExpirePending", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			return before.Before(time.Now().Add(-71 * time.Hour))
+		})).Return(int64(3), nil)
+
+		r := referUseCase{historyRepository: historyMock}
+		assert.Nil(t, r.ExpirePendingReferral(context.Background()))
+		historyMock.AssertExpectations(t)
+	})
+}
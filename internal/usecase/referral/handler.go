@@ -36,3 +36,12 @@ func (h ReferHandler) ProcessReferral(e echo.Context) error {
 	}
 	return e.JSON(http.StatusOK, res)
 }
+
+func (h ReferHandler) ConfirmReferral(e echo.Context) error {
+	res, err := h.useCase.ConfirmReferral(e.Request().Context(), e.Param("msisdn"))
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
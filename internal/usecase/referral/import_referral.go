@@ -0,0 +1,226 @@
+package referral
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/counter"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+const defaultImportBatchSize = 1000
+
+type ImportUseCase interface {
+	ImportReferral(ctx context.Context, source io.Reader, report io.Writer, option ImportOption) (ImportResult, error)
+}
+
+type importUseCase struct {
+	referUseCase
+}
+
+func SetupImportUseCase(referralCodeRepository model.ReferralCodeRepository,
+	referralHistoryRepository model.ReferralHistoryRepository,
+	campaignRepository model.CampaignRepository,
+	referralConfig *config.ReferralConfig,
+	tracker counter.Tracker) ImportUseCase {
+	if referralCodeRepository == nil {
+		panic("ReferralCodeRepository is nil")
+	}
+	if referralHistoryRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	if campaignRepository == nil {
+		panic("CampaignRepository is nil")
+	}
+	if referralConfig == nil {
+		panic("ReferralConfig is nil")
+	}
+	if tracker == nil {
+		panic("Tracker is nil")
+	}
+	return &importUseCase{
+		referUseCase: referUseCase{
+			codeRepository:     referralCodeRepository,
+			historyRepository:  referralHistoryRepository,
+			campaignRepository: campaignRepository,
+			config:             *referralConfig,
+			tracker:            tracker,
+		},
+	}
+}
+
+// ImportReferral import historical referral from csv with column code, msisdn and referral_date (yyyy-MM-dd).
+// Each row is checked with the same rule as processing referral, valid row is inserted as confirmed referral in
+// batch while invalid row is written to report, counter of referrer is added once its batch is inserted.
+// Database error stop the import, batch inserted before it is kept.
+func (i importUseCase) ImportReferral(ctx context.Context, source io.Reader, report io.Writer, option ImportOption) (result ImportResult, err error) {
+	batchSize := option.BatchSize
+	if batchSize < 1 {
+		batchSize = defaultImportBatchSize
+	}
+
+	reader := csv.NewReader(source)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	reportWriter := csv.NewWriter(report)
+	defer reportWriter.Flush()
+	if err = reportWriter.Write([]string{"line", "code", "msisdn", "error"}); err != nil {
+		return result, err
+	}
+
+	//referee within the file is not in db yet, keep them to check duplicate referee across rows
+	referees := make(map[string]int)
+	campaigns := make(map[string]int64)
+	batch := make([]model.ReferralHistory, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !option.DryRun {
+			if err := i.historyRepository.InsertBatch(ctx, batch); err != nil {
+				return err
+			}
+			i.addCounter(ctx, batch)
+		}
+		result.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	line := 0
+	for {
+		record, er := reader.Read()
+		if er == io.EOF {
+			break
+		}
+		line++
+		if er != nil {
+			return result, er
+		}
+		//header is optional
+		if line == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "code") {
+			continue
+		}
+		result.Total++
+
+		history, er := i.importRow(ctx, record, referees, campaigns)
+		if er != nil {
+			var rowErr importRowError
+			if !errors.As(er, &rowErr) {
+				return result, er
+			}
+			result.Failed++
+			row := paddedRecord(record)
+			if er = reportWriter.Write([]string{strconv.Itoa(line), row[0], row[1], er.Error()}); er != nil {
+				return result, er
+			}
+			continue
+		}
+		referees[history.MsisdnReferee] = line
+
+		batch = append(batch, history)
+		if len(batch) >= batchSize {
+			if err = flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err = flush(); err != nil {
+		return result, err
+	}
+	log.Println("referral imported: ", result.Imported, "failed: ", result.Failed, "dry run: ", option.DryRun)
+	return result, nil
+}
+
+// importRowError is rule violation of a row which is not from referral rule
+type importRowError string
+
+func (e importRowError) Error() string {
+	return string(e)
+}
+
+func (i importUseCase) importRow(ctx context.Context, record []string, referees map[string]int,
+	campaigns map[string]int64) (model.ReferralHistory, error) {
+	if len(record) < 3 {
+		return model.ReferralHistory{}, importRowError("missing column")
+	}
+	code := strings.TrimSpace(record[0])
+	referralDate := strings.TrimSpace(record[2])
+
+	msisdn, err := util.ValidateAndSanitizeMsisdn(strings.TrimSpace(record[1]))
+	if err != nil {
+		return model.ReferralHistory{}, importRowError("invalid msisdn")
+	}
+	date, err := time.ParseInLocation("2006-01-02", referralDate, time.Local)
+	if err != nil {
+		return model.ReferralHistory{}, importRowError("invalid referral date")
+	}
+	if line, ok := referees[msisdn]; ok {
+		return model.ReferralHistory{}, importRowError("duplicate referee of line " + strconv.Itoa(line))
+	}
+
+	referralCode, reason, err := i.validateReferral(ctx, code, msisdn, date)
+	if err != nil {
+		return model.ReferralHistory{}, wrapImportError(err, reason)
+	}
+
+	campaignID, ok := campaigns[referralDate]
+	if !ok {
+		result, err := i.campaignRepository.FindActiveByDate(ctx, referralDate)
+		if err != nil {
+			return model.ReferralHistory{}, err
+		}
+		if len(result) > 0 {
+			campaignID = result[0].ID
+		}
+		campaigns[referralDate] = campaignID
+	}
+
+	return model.ReferralHistory{
+		Msisdn:        referralCode.Msisdn,
+		Code:          referralCode.Code,
+		ReferralDate:  referralDate,
+		MsisdnReferee: msisdn,
+		CampaignID:    campaignID,
+		Status:        model.ReferralStatusConfirmed,
+		ConfirmedDate: &date,
+		CreatedDate:   date,
+	}, nil
+}
+
+// addCounter add imported confirmed referral to counter of its referrer and month
+func (i importUseCase) addCounter(ctx context.Context, batch []model.ReferralHistory) {
+	type key struct{ msisdn, month string }
+	totals := make(map[key]int)
+	for _, v := range batch {
+		totals[key{msisdn: v.Msisdn, month: v.ReferralDate[:7]}]++
+	}
+	for k, total := range totals {
+		i.tracker.Add(ctx, k.msisdn, k.month, total)
+	}
+}
+
+// wrapImportError replace rule violation with reason written to report, other error is returned as is
+func wrapImportError(err error, reason string) error {
+	var appErr *util.ApplicationError
+	if errors.As(err, &appErr) {
+		return importRowError(reason)
+	}
+	return err
+}
+
+func paddedRecord(record []string) []string {
+	result := []string{"", ""}
+	copy(result, record)
+	return result
+}
@@ -0,0 +1,151 @@
+package referral
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	counterMocks "github.com/candraalim/be_tsel_candra/internal/mock/counter"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupImportUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupImportUseCase(nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupImportUseCase(&mocks.ReferralCodeRepository{}, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupImportUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupImportUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{}, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupImportUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&config.ReferralConfig{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupImportUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&config.ReferralConfig{}, &counterMocks.Tracker{})
+	})
+}
+
+const importSource = `code,msisdn,referral_date
+ABCABC123A,6280001100001,2021-08-12
+ABCABC123A,080001100002,2021-08-13
+ABCABC123A,6280001100001,2021-08-14
+ABCABC123A,6280001100003,12-08-2021
+NOTFOUND12,6280001100004,2021-08-12
+ABCABC123A,628000001111,2021-08-12
+ABCABC123A,6280001100005,2021-08-12
+ABCABC123A,abc,2021-08-12
+`
+
+func setupImportMock() (*mocks.ReferralCodeRepository, *mocks.ReferralHistoryRepository, *mocks.CampaignRepository) {
+	codeMock := &mocks.ReferralCodeRepository{}
+	codeMock.On("FindByCode", mock.Anything, "ABCABC123A").Return(model.ReferralCode{Code: "ABCABC123A",
+		Msisdn: "628000001111"}, nil)
+	codeMock.On("FindByCode", mock.Anything, "NOTFOUND12").Return(model.ReferralCode{}, sql.ErrNoRows)
+	codeMock.On("FindByMsisdn", mock.Anything, "628000001111").Return(model.ReferralCode{ID: 1, Code: "ABCABC123A",
+		Msisdn: "628000001111"}, nil)
+	codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
+
+	historyMock := &mocks.ReferralHistoryRepository{}
+	historyMock.On("FindByMsisdnReferee", mock.Anything, "6280001100005").Return(model.ReferralHistory{ID: 9}, nil)
+	historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
+
+	campaignMock := &mocks.CampaignRepository{}
+	campaignMock.On("FindActiveByDate", mock.Anything, "2021-08-12").Return([]model.Campaign{{ID: 5}}, nil).Once()
+	campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return(nil, nil)
+	return codeMock, historyMock, campaignMock
+}
+
+func Test_importUseCase_ImportReferral(t *testing.T) {
+	t.Run("dry run does not insert", func(t *testing.T) {
+		codeMock, historyMock, campaignMock := setupImportMock()
+
+		trackerMock := &counterMocks.Tracker{}
+
+		i := importUseCase{referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			tracker: trackerMock}}
+		report := &bytes.Buffer{}
+		result, err := i.ImportReferral(context.Background(), strings.NewReader(importSource), report, ImportOption{DryRun: true})
+		assert.Nil(t, err)
+		assert.Equal(t, ImportResult{Total: 8, Imported: 2, Failed: 6}, result)
+		historyMock.AssertNotCalled(t, "InsertBatch", mock.Anything, mock.Anything)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+		assert.Equal(t, []string{
+			"line,code,msisdn,error",
+			"4,ABCABC123A,6280001100001,duplicate referee of line 2",
+			"5,ABCABC123A,6280001100003,invalid referral date",
+			"6,NOTFOUND12,6280001100004,invalid referral code: invalid request",
+			"7,ABCABC123A,628000001111,referee already has referral code",
+			"8,ABCABC123A,6280001100005,referee already referred",
+			"9,ABCABC123A,abc,invalid msisdn",
+		}, lines)
+	})
+	t.Run("insert valid row in batch", func(t *testing.T) {
+		codeMock, historyMock, campaignMock := setupImportMock()
+		historyMock.On("InsertBatch", mock.Anything, mock.MatchedBy(func(batch []model.ReferralHistory) bool {
+			return len(batch) == 1 && batch[0].MsisdnReferee == "6280001100001" && batch[0].CampaignID == 5 &&
+				batch[0].Status == model.ReferralStatusConfirmed && batch[0].CreatedDate.Format("2006-01-02") == "2021-08-12"
+		})).Return(nil).Once()
+		historyMock.On("InsertBatch", mock.Anything, mock.MatchedBy(func(batch []model.ReferralHistory) bool {
+			return len(batch) == 1 && batch[0].MsisdnReferee == "6280001100002" && batch[0].CampaignID == 0
+		})).Return(nil).Once()
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Add", mock.Anything, "628000001111", "2021-08", 1).Return().Twice()
+
+		i := importUseCase{referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			tracker: trackerMock}}
+		result, err := i.ImportReferral(context.Background(), strings.NewReader(importSource), &bytes.Buffer{},
+			ImportOption{BatchSize: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, result.Imported)
+		historyMock.AssertExpectations(t)
+		trackerMock.AssertExpectations(t)
+	})
+	t.Run("counter is added per referrer and month of batch", func(t *testing.T) {
+		codeMock, historyMock, campaignMock := setupImportMock()
+		historyMock.On("InsertBatch", mock.Anything, mock.Anything).Return(nil).Once()
+		trackerMock := &counterMocks.Tracker{}
+		trackerMock.On("Add", mock.Anything, "628000001111", "2021-08", 2).Return().Once()
+
+		i := importUseCase{referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			tracker: trackerMock}}
+		_, err := i.ImportReferral(context.Background(), strings.NewReader(importSource), &bytes.Buffer{}, ImportOption{})
+		assert.Nil(t, err)
+		trackerMock.AssertExpectations(t)
+	})
+	t.Run("database error stop the import", func(t *testing.T) {
+		codeMock, historyMock, campaignMock := setupImportMock()
+		historyMock.On("InsertBatch", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+		trackerMock := &counterMocks.Tracker{}
+
+		i := importUseCase{referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			tracker: trackerMock}}
+		result, err := i.ImportReferral(context.Background(), strings.NewReader(importSource), &bytes.Buffer{}, ImportOption{})
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, 0, result.Imported)
+		trackerMock.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("database error on lookup", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrConnDone)
+
+		i := importUseCase{referUseCase{codeRepository: codeMock}}
+		_, err := i.ImportReferral(context.Background(), strings.NewReader(importSource), &bytes.Buffer{}, ImportOption{})
+		assert.Equal(t, sql.ErrConnDone, err)
+	})
+}
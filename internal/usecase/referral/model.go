@@ -8,4 +8,30 @@ type ReferResponse struct {
 type ReferRequest struct {
 	Code   string `json:"code" validate:"required"`
 	Msisdn string `json:"msisdn" validate:"required"`
+	// IPAddress and DeviceID of referee, used by velocity rule
+	IPAddress string `json:"ipAddress" validate:"omitempty,max=45"`
+	DeviceID  string `json:"deviceId" validate:"omitempty,max=100"`
+}
+
+type ConfirmResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Msisdn        string `json:"msisdn"`
+		MsisdnReferee string `json:"msisdnReferee"`
+		ReferralDate  string `json:"referralDate"`
+		Status        int    `json:"status"`
+	} `json:"data"`
+}
+
+type ImportOption struct {
+	// DryRun check every row without inserting them
+	DryRun    bool
+	BatchSize int
+}
+
+type ImportResult struct {
+	Total    int
+	Imported int
+	Failed   int
 }
@@ -7,30 +7,79 @@ import (
 	"log"
 	"time"
 
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/counter"
+	"github.com/candraalim/be_tsel_candra/internal/fraud"
+	"github.com/candraalim/be_tsel_candra/internal/generator"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
 	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
 type ReferUseCase interface {
 	ProcessReferral(ctx context.Context, request ReferRequest) (ReferResponse, error)
+	ConfirmReferral(ctx context.Context, msisdnReferee string) (ConfirmResponse, error)
+	ExpirePendingReferral(ctx context.Context) error
 }
 
 type referUseCase struct {
-	codeRepository    model.ReferralCodeRepository
-	historyRepository model.ReferralHistoryRepository
+	codeRepository     model.ReferralCodeRepository
+	historyRepository  model.ReferralHistoryRepository
+	campaignRepository model.CampaignRepository
+	rewardRepository   model.RewardRepository
+	config             config.ReferralConfig
+	ruleEngine         fraud.RuleEngine
+	transactor         model.Transactor
+	outboxRepository   model.OutboxRepository
+	tracker            counter.Tracker
 }
 
 func SetupReferUseCase(referralCodeRepository model.ReferralCodeRepository,
-	referralHistoryRepository model.ReferralHistoryRepository) ReferUseCase {
+	referralHistoryRepository model.ReferralHistoryRepository,
+	campaignRepository model.CampaignRepository,
+	rewardRepository model.RewardRepository,
+	referralConfig *config.ReferralConfig,
+	ruleEngine fraud.RuleEngine,
+	transactor model.Transactor,
+	outboxRepository model.OutboxRepository,
+	tracker counter.Tracker) ReferUseCase {
 	if referralCodeRepository == nil {
 		panic("ReferralCodeRepository is nil")
 	}
 	if referralHistoryRepository == nil {
 		panic("ReferralHistoryRepository is nil")
 	}
+	if campaignRepository == nil {
+		panic("CampaignRepository is nil")
+	}
+	if rewardRepository == nil {
+		panic("RewardRepository is nil")
+	}
+	if referralConfig == nil {
+		panic("ReferralConfig is nil")
+	}
+	if ruleEngine == nil {
+		panic("RuleEngine is nil")
+	}
+	if transactor == nil {
+		panic("Transactor is nil")
+	}
+	if outboxRepository == nil {
+		panic("OutboxRepository is nil")
+	}
+	if tracker == nil {
+		panic("Tracker is nil")
+	}
 	return &referUseCase{
-		codeRepository:    referralCodeRepository,
-		historyRepository: referralHistoryRepository,
+		codeRepository:     referralCodeRepository,
+		historyRepository:  referralHistoryRepository,
+		campaignRepository: campaignRepository,
+		rewardRepository:   rewardRepository,
+		config:             *referralConfig,
+		ruleEngine:         ruleEngine,
+		transactor:         transactor,
+		outboxRepository:   outboxRepository,
+		tracker:            tracker,
 	}
 }
 
@@ -41,45 +90,130 @@ func (r referUseCase) ProcessReferral(ctx context.Context, request ReferRequest)
 		return ReferResponse{}, err
 	}
 
-	//todo change to config
-	if len(request.Code) > 20 {
-		log.Println("invalid code length")
-		return ReferResponse{}, util.ErrorInvalidRequest
-	}
-
-	referralCode, err := r.codeRepository.FindByCode(ctx, request.Code)
-	if errors.Is(err, sql.ErrNoRows) {
-		log.Println("referral code not found: ", request.Code)
-		return ReferResponse{}, util.ErrorInvalidRequest
-	}
+	referralCode, _, err := r.validateReferral(ctx, request.Code, request.Msisdn, time.Now())
 	if err != nil {
 		return ReferResponse{}, err
 	}
 
-	history, err := r.historyRepository.FindByMsisdnReferee(ctx, request.Msisdn)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	//velocity rules, offending referral is rejected or stored as flagged as configured
+	verdict, err := r.ruleEngine.Evaluate(ctx, fraud.Referral{
+		Code:      referralCode,
+		IPAddress: request.IPAddress,
+		DeviceID:  request.DeviceID,
+	})
+	if err != nil {
 		return ReferResponse{}, err
 	}
-	if history.ID > 0 {
-		log.Println("msisdn already register with other referral: ", request.Msisdn)
-		return ReferResponse{}, util.ErrorInvalidRequest
+	if verdict.Violation != "" && verdict.Reject {
+		log.Println("referral rejected: ", referralCode.Code, request.Msisdn, verdict.Violation)
+		return ReferResponse{}, util.ErrorReferralRejected
 	}
 
-	if code, _ := r.codeRepository.FindByMsisdn(ctx, request.Msisdn); code.ID > 0 {
-		log.Println("msisdn already register: ", request.Msisdn)
-		return ReferResponse{}, util.ErrorInvalidRequest
+	referralDate := time.Now().Format("2006-01-02")
+
+	//attribute referral to active campaign, latest started campaign win when campaigns overlap
+	campaigns, err := r.campaignRepository.FindActiveByDate(ctx, referralDate)
+	if err != nil {
+		return ReferResponse{}, err
+	}
+	var campaignID int64
+	if len(campaigns) > 0 {
+		campaignID = campaigns[0].ID
 	}
 
-	history = model.ReferralHistory{
+	history := model.ReferralHistory{
 		Msisdn:        referralCode.Msisdn,
-		Code:          request.Code,
-		ReferralDate:  time.Now().Format("2006-01-02"),
+		Code:          referralCode.Code,
+		ReferralDate:  referralDate,
 		MsisdnReferee: request.Msisdn,
+		CampaignID:    campaignID,
+		IPAddress:     request.IPAddress,
+		DeviceID:      request.DeviceID,
+		Flagged:       verdict.Violation != "",
+		FlagReason:    verdict.Violation,
+		//referral is counted once referee is confirmed
+		Status: model.ReferralStatusPending,
 	}
-	err = r.historyRepository.Insert(ctx, &history)
+	//store referral together with its event
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.historyRepository.Insert(ctx, &history); err != nil {
+			return err
+		}
+		return outbox.Write(ctx, r.outboxRepository, outbox.EventReferralCreated, outbox.ReferralEvent{
+			Msisdn:        history.Msisdn,
+			MsisdnReferee: history.MsisdnReferee,
+			ReferralCode:  history.Code,
+			ReferralDate:  history.ReferralDate,
+			CampaignID:    history.CampaignID,
+			Status:        history.Status,
+			Flagged:       history.Flagged,
+		})
+	})
 	if err != nil {
 		return ReferResponse{}, err
 	}
-	//TODO store counter to redis
+	//pending referral is not counted, counter is incremented once it is confirmed
 	return ReferResponse{Code: util.CodeSuccess, Message: util.MessageSuccess}, nil
 }
+
+// validateReferral lookup code and check referee can be referred with it at given time, it is shared by processing
+// and importing referral. Reason describe rule violation written to import report, it is empty on other error
+func (r referUseCase) validateReferral(ctx context.Context, code, msisdnReferee string, at time.Time) (model.ReferralCode, string, error) {
+	referralCode, err := r.findReferralCode(ctx, code)
+	if err != nil {
+		return model.ReferralCode{}, "invalid referral code: " + err.Error(), err
+	}
+	if referralCode.IsExpired(at) {
+		log.Println("referral code expired: ", code)
+		return model.ReferralCode{}, "referral code expired", util.ErrorReferralCodeExpired
+	}
+
+	if err = r.checkReferee(ctx, msisdnReferee); err != nil {
+		return model.ReferralCode{}, "referee already referred", err
+	}
+
+	//owner of referral code is an existing user, it also reject referring itself
+	if owned, _ := r.codeRepository.FindByMsisdn(ctx, msisdnReferee); owned.ID > 0 {
+		log.Println("msisdn already register: ", msisdnReferee)
+		return model.ReferralCode{}, "referee already has referral code", util.ErrorInvalidRequest
+	}
+	return referralCode, "", nil
+}
+
+// findReferralCode validate format of code then lookup the active one
+func (r referUseCase) findReferralCode(ctx context.Context, code string) (model.ReferralCode, error) {
+	if len(code) > r.config.CodeConfig().MaxCodeLength() {
+		log.Println("invalid code length")
+		return model.ReferralCode{}, util.ErrorInvalidRequest
+	}
+	//reject mistyped code before lookup to db, code without check digit is legacy or vanity code
+	if !generator.ValidCheckDigit(code) {
+		log.Println("malformed referral code: ", code)
+		return model.ReferralCode{}, util.ErrorReferralCodeMalformed
+	}
+
+	referralCode, err := r.codeRepository.FindByCode(ctx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Println("referral code not found: ", code)
+		return model.ReferralCode{}, util.ErrorInvalidRequest
+	}
+	//code exist but status is inactive
+	if errors.Is(err, util.ErrorDataNotFound) {
+		log.Println("referral code inactive: ", code)
+		return model.ReferralCode{}, util.ErrorReferralCodeInactive
+	}
+	return referralCode, err
+}
+
+// checkReferee make sure msisdn is never referred before
+func (r referUseCase) checkReferee(ctx context.Context, msisdn string) error {
+	history, err := r.historyRepository.FindByMsisdnReferee(ctx, msisdn)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if history.ID > 0 {
+		log.Println("msisdn already register with other referral: ", msisdn)
+		return util.ErrorInvalidRequest
+	}
+	return nil
+}
@@ -3,28 +3,90 @@ package referral
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/fraud"
+	counterMocks "github.com/candraalim/be_tsel_candra/internal/mock/counter"
 	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
 	"github.com/candraalim/be_tsel_candra/internal/storage/model"
 	"github.com/candraalim/be_tsel_candra/internal/util"
 )
 
+type staticRuleEngine struct {
+	verdict fraud.Verdict
+	err     error
+}
+
+func (e staticRuleEngine) Evaluate(context.Context, fraud.Referral) (fraud.Verdict, error) {
+	return e.verdict, e.err
+}
+
 func TestSetupReferUseCase(t *testing.T) {
 	assert.Panics(t, func() {
-		SetupReferUseCase(nil, nil)
+		SetupReferUseCase(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, nil, nil, nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, nil, nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			nil, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&mocks.RewardRepository{}, nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&mocks.RewardRepository{}, &config.ReferralConfig{}, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&mocks.RewardRepository{}, &config.ReferralConfig{}, staticRuleEngine{}, nil, nil, nil)
 	})
 	assert.Panics(t, func() {
-		SetupReferUseCase(&mocks.ReferralCodeRepository{}, nil)
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&mocks.RewardRepository{}, &config.ReferralConfig{}, staticRuleEngine{}, &mocks.Transactor{}, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&mocks.RewardRepository{}, &config.ReferralConfig{}, staticRuleEngine{}, &mocks.Transactor{}, &mocks.OutboxRepository{},
+			nil)
 	})
 	assert.NotPanics(t, func() {
-		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{})
+		SetupReferUseCase(&mocks.ReferralCodeRepository{}, &mocks.ReferralHistoryRepository{}, &mocks.CampaignRepository{},
+			&mocks.RewardRepository{}, &config.ReferralConfig{}, staticRuleEngine{}, &mocks.Transactor{}, &mocks.OutboxRepository{},
+			&counterMocks.Tracker{})
 	})
 }
 
+// transactorMock run fn directly as if it were in a transaction
+func transactorMock() *mocks.Transactor {
+	transactor := &mocks.Transactor{}
+	transactor.On("WithinTransaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	return transactor
+}
+
+// outboxMock accept any event
+func outboxMock() *mocks.OutboxRepository {
+	outboxRepository := &mocks.OutboxRepository{}
+	outboxRepository.On("Insert", mock.Anything, mock.Anything).Return(nil)
+	return outboxRepository
+}
+
 func Test_referUseCase_ProcessReferral(t *testing.T) {
 	t.Run("invalid msisdn", func(t *testing.T) {
 		i := referUseCase{}
@@ -41,6 +103,25 @@ func Test_referUseCase_ProcessReferral(t *testing.T) {
 		})
 		assert.NotNil(t, err)
 	})
+	t.Run("invalid length referral code as configured", func(t *testing.T) {
+		i := referUseCase{config: config.ReferralConfig{Code: &config.CodeConfig{MaxLength: 8}}}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "ABCABC123A",
+			Msisdn: "6280001100001",
+		})
+		assert.Equal(t, util.ErrorInvalidRequest, err)
+	})
+	t.Run("malformed referral code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+
+		i := referUseCase{codeRepository: codeMock}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "4C7AE71568-2",
+			Msisdn: "6280001100001",
+		})
+		assert.Equal(t, util.ErrorReferralCodeMalformed, err)
+		codeMock.AssertNotCalled(t, "FindByCode", mock.Anything, mock.Anything)
+	})
 	t.Run("unknown referral code", func(t *testing.T) {
 		codeMock := &mocks.ReferralCodeRepository{}
 		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, sql.ErrNoRows)
@@ -52,6 +133,30 @@ func Test_referUseCase_ProcessReferral(t *testing.T) {
 		})
 		assert.NotNil(t, err)
 	})
+	t.Run("inactive referral code", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, util.ErrorDataNotFound)
+
+		i := referUseCase{codeRepository: codeMock}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "ABCABC123A",
+			Msisdn: "6280001100001",
+		})
+		assert.Equal(t, util.ErrorReferralCodeInactive, err)
+	})
+	t.Run("expired referral code", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "ABCABC123A",
+			Msisdn: "628000001111", ExpiresAt: &expiresAt}, nil)
+
+		i := referUseCase{codeRepository: codeMock}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "ABCABC123A",
+			Msisdn: "6280001100001",
+		})
+		assert.Equal(t, util.ErrorReferralCodeExpired, err)
+	})
 	t.Run("error db when validate referral code", func(t *testing.T) {
 		codeMock := &mocks.ReferralCodeRepository{}
 		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{}, context.DeadlineExceeded)
@@ -123,13 +228,64 @@ func Test_referUseCase_ProcessReferral(t *testing.T) {
 		historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
 		historyMock.On("Insert", mock.Anything, mock.Anything).Return(sql.ErrConnDone)
 
-		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock}
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{}, nil)
+
+		outboxRepository := &mocks.OutboxRepository{}
+
+		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			ruleEngine: staticRuleEngine{}, transactor: transactorMock(), outboxRepository: outboxRepository}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "ABCABC123A",
+			Msisdn: "6280001100001",
+		})
+		assert.NotNil(t, err)
+		outboxRepository.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	})
+	t.Run("error db when lookup active campaign", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "ABCABC123A",
+			Msisdn: "628000001111"}, nil)
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			ruleEngine: staticRuleEngine{}}
 		_, err := i.ProcessReferral(context.Background(), ReferRequest{
 			Code:   "ABCABC123A",
 			Msisdn: "6280001100001",
 		})
 		assert.NotNil(t, err)
 	})
+	t.Run("success attribute to active campaign", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "ABCABC123A",
+			Msisdn: "628000001111"}, nil)
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
+		historyMock.On("Insert", mock.Anything, mock.MatchedBy(func(h *model.ReferralHistory) bool {
+			return h.CampaignID == 5 && h.Status == model.ReferralStatusPending
+		})).Return(nil)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{{ID: 5}, {ID: 2}}, nil)
+
+		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			ruleEngine: staticRuleEngine{}, transactor: transactorMock(), outboxRepository: outboxMock()}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "ABCABC123A",
+			Msisdn: "6280001100001",
+		})
+		assert.Nil(t, err)
+		historyMock.AssertExpectations(t)
+	})
 	t.Run("success", func(t *testing.T) {
 		codeMock := &mocks.ReferralCodeRepository{}
 		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "ABCABC123A",
@@ -140,7 +296,18 @@ func Test_referUseCase_ProcessReferral(t *testing.T) {
 		historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
 		historyMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
 
-		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock}
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{}, nil)
+
+		outboxRepository := &mocks.OutboxRepository{}
+		outboxRepository.On("Insert", mock.Anything, mock.MatchedBy(func(e *model.OutboxEvent) bool {
+			var data outbox.ReferralEvent
+			return e.Event == outbox.EventReferralCreated && json.Unmarshal([]byte(e.Payload), &data) == nil &&
+				data.Msisdn == "628000001111" && data.MsisdnReferee == "6280001100001" && data.ReferralCode == "ABCABC123A"
+		})).Return(nil)
+
+		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			ruleEngine: staticRuleEngine{}, transactor: transactorMock(), outboxRepository: outboxRepository}
 		resp, err := i.ProcessReferral(context.Background(), ReferRequest{
 			Code:   "ABCABC123A",
 			Msisdn: "6280001100001",
@@ -150,5 +317,69 @@ func Test_referUseCase_ProcessReferral(t *testing.T) {
 			Code:    util.CodeSuccess,
 			Message: util.MessageSuccess,
 		}, resp)
+		outboxRepository.AssertExpectations(t)
+	})
+	t.Run("velocity rule error", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "ABCABC123A",
+			Msisdn: "628000001111"}, nil)
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
+
+		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock,
+			ruleEngine: staticRuleEngine{err: context.DeadlineExceeded}}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "ABCABC123A",
+			Msisdn: "6280001100001",
+		})
+		assert.NotNil(t, err)
+	})
+	t.Run("rejected by velocity rule", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "ABCABC123A",
+			Msisdn: "628000001111"}, nil)
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
+
+		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock,
+			ruleEngine: staticRuleEngine{verdict: fraud.Verdict{Violation: "code_hourly_limit", Reject: true}}}
+		_, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:   "ABCABC123A",
+			Msisdn: "6280001100001",
+		})
+		assert.Equal(t, util.ErrorReferralRejected, err)
+		historyMock.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	})
+	t.Run("flagged by velocity rule", func(t *testing.T) {
+		codeMock := &mocks.ReferralCodeRepository{}
+		codeMock.On("FindByCode", mock.Anything, mock.Anything).Return(model.ReferralCode{Code: "ABCABC123A",
+			Msisdn: "628000001111"}, nil)
+		codeMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(model.ReferralCode{}, nil)
+
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("FindByMsisdnReferee", mock.Anything, mock.Anything).Return(model.ReferralHistory{}, sql.ErrNoRows)
+		historyMock.On("Insert", mock.Anything, mock.MatchedBy(func(h *model.ReferralHistory) bool {
+			return h.Flagged && h.FlagReason == "ip_daily_limit" && h.IPAddress == "10.1.2.3" && h.DeviceID == "device-1"
+		})).Return(nil)
+
+		campaignMock := &mocks.CampaignRepository{}
+		campaignMock.On("FindActiveByDate", mock.Anything, mock.Anything).Return([]model.Campaign{}, nil)
+
+		i := referUseCase{codeRepository: codeMock, historyRepository: historyMock, campaignRepository: campaignMock,
+			ruleEngine: staticRuleEngine{verdict: fraud.Verdict{Violation: "ip_daily_limit"}}, transactor: transactorMock(),
+			outboxRepository: outboxMock()}
+		resp, err := i.ProcessReferral(context.Background(), ReferRequest{
+			Code:      "ABCABC123A",
+			Msisdn:    "6280001100001",
+			IPAddress: "10.1.2.3",
+			DeviceID:  "device-1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, util.CodeSuccess, resp.Code)
+		historyMock.AssertExpectations(t)
 	})
 }
@@ -0,0 +1,48 @@
+package reward
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RewardHandler struct {
+	useCase RewardUseCase
+}
+
+func SetupRewardHandler(useCase RewardUseCase) *RewardHandler {
+	if useCase == nil {
+		panic("reward use case is nil")
+	}
+	return &RewardHandler{
+		useCase: useCase,
+	}
+}
+
+func (h RewardHandler) ClaimReward(e echo.Context) error {
+	msisdn := e.Param("msisdn")
+
+	var level int
+	if lv, err := strconv.Atoi(e.QueryParam("level")); err == nil {
+		level = lv
+	}
+
+	res, err := h.useCase.ClaimReward(e.Request().Context(), msisdn, level)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h RewardHandler) GetListClaim(e echo.Context) error {
+	msisdn := e.Param("msisdn")
+
+	res, err := h.useCase.GetListClaim(e.Request().Context(), msisdn)
+	//handle error response
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,15 @@
+package reward
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetupRewardHandler(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardHandler(nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRewardHandler(&rewardUseCase{})
+	})
+}
@@ -0,0 +1,29 @@
+package reward
+
+type ClaimRewardResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Period        string `json:"period"`
+		Level         int    `json:"level"`
+		TotalReferral int    `json:"totalReferral"`
+		Reward        string `json:"reward"`
+	} `json:"data"`
+}
+
+type ListClaimResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []RewardClaim `json:"list"`
+	} `json:"data"`
+}
+
+type RewardClaim struct {
+	Period        string `json:"period"`
+	TotalReferral int    `json:"totalReferral"`
+	Reward        string `json:"reward"`
+	Level         int    `json:"level"`
+	Status        int    `json:"status"`
+	DateTime      int64  `json:"dateTime"`
+}
@@ -0,0 +1,183 @@
+package reward
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+type RewardUseCase interface {
+	ClaimReward(ctx context.Context, msisdn string, level int) (ClaimRewardResponse, error)
+	GetListClaim(ctx context.Context, msisdn string) (ListClaimResponse, error)
+}
+
+type rewardUseCase struct {
+	historyRepository model.ReferralHistoryRepository
+	rewardRepository  model.RewardRepository
+	claimRepository   model.RewardClaimRepository
+	config            config.ReferralConfig
+	transactor        model.Transactor
+}
+
+func SetupRewardUseCase(referralHistoryRepository model.ReferralHistoryRepository,
+	rewardRepository model.RewardRepository,
+	rewardClaimRepository model.RewardClaimRepository,
+	referralConfig *config.ReferralConfig,
+	transactor model.Transactor) RewardUseCase {
+	if referralHistoryRepository == nil {
+		panic("ReferralHistoryRepository is nil")
+	}
+	if rewardRepository == nil {
+		panic("RewardRepository is nil")
+	}
+	if rewardClaimRepository == nil {
+		panic("RewardClaimRepository is nil")
+	}
+	if referralConfig == nil {
+		panic("ReferralConfig is nil")
+	}
+	if transactor == nil {
+		panic("Transactor is nil")
+	}
+	return &rewardUseCase{
+		historyRepository: referralHistoryRepository,
+		rewardRepository:  rewardRepository,
+		claimRepository:   rewardClaimRepository,
+		config:            *referralConfig,
+		transactor:        transactor,
+	}
+}
+
+// ClaimReward claim reward reached by downline at given level in current month, level 1 is direct referral
+func (r rewardUseCase) ClaimReward(ctx context.Context, msisdn string, level int) (resp ClaimRewardResponse, err error) {
+	//validate msisdn
+	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
+	if err != nil {
+		return ClaimRewardResponse{}, err
+	}
+	if level < 1 {
+		level = 1
+	}
+	if level > r.config.Depth() {
+		log.Println("reward level not available: ", msisdn, level)
+		return ClaimRewardResponse{}, util.ErrorRewardNotAvailable
+	}
+
+	//reward is claimed per month, same as period used by reward inquiry
+	period := time.Now().Format("2006-01")
+	total, err := r.getTotalReferral(ctx, msisdn, period, level)
+	if err != nil {
+		return ClaimRewardResponse{}, err
+	}
+	if total == 0 {
+		log.Println("no referral found in period: ", msisdn, period, level)
+		return ClaimRewardResponse{}, util.ErrorRewardNotAvailable
+	}
+
+	reward, err := r.rewardRepository.FindByLevelAndTotalReferral(ctx, level, total)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Println("reward tier not reached yet: ", msisdn, total)
+		return ClaimRewardResponse{}, util.ErrorRewardNotAvailable
+	}
+	if err != nil {
+		return ClaimRewardResponse{}, err
+	}
+
+	//refuse double claim for the same tier and period
+	claim, err := r.claimRepository.FindByMsisdnRewardAndPeriod(ctx, msisdn, reward.ID, period)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ClaimRewardResponse{}, err
+	}
+	if claim.ID > 0 {
+		log.Println("reward already claimed: ", msisdn, reward.ID, period)
+		return ClaimRewardResponse{}, util.ErrorRewardAlreadyClaimed
+	}
+
+	claim = model.RewardClaim{
+		Msisdn:        msisdn,
+		RewardID:      reward.ID,
+		TotalReferral: reward.TotalReferral,
+		Description:   reward.Description,
+		Period:        period,
+		Level:         level,
+	}
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.claimRepository.Insert(ctx, &claim); err != nil {
+			return err
+		}
+		//referral counted for the claim is rewarded, it still counted for next tier in the period
+		if level == 1 {
+			if _, err := r.historyRepository.MarkRewarded(ctx, msisdn, period); err != nil {
+				log.Println("failed mark referral rewarded: ", msisdn, period, err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ClaimRewardResponse{}, err
+	}
+
+	resp = ClaimRewardResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.Period = period
+	resp.Data.Level = level
+	resp.Data.TotalReferral = total
+	resp.Data.Reward = reward.Description
+	return resp, nil
+}
+
+func (r rewardUseCase) getTotalReferral(ctx context.Context, msisdn, period string, level int) (int, error) {
+	if level == 1 {
+		return r.historyRepository.GetTotalByMsisdnAndMonth(ctx, msisdn, period)
+	}
+	totals, err := r.historyRepository.GetTotalPerLevelByMsisdnAndMonth(ctx, msisdn, period, level)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range totals {
+		if v.Level == level {
+			return v.Total, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r rewardUseCase) GetListClaim(ctx context.Context, msisdn string) (resp ListClaimResponse, err error) {
+	msisdn, err = util.ValidateAndSanitizeMsisdn(msisdn)
+	if err != nil {
+		return ListClaimResponse{}, err
+	}
+
+	entities, err := r.claimRepository.FindByMsisdn(ctx, msisdn)
+	if err != nil {
+		return ListClaimResponse{}, err
+	}
+
+	list := make([]RewardClaim, len(entities))
+	for i, v := range entities {
+		list[i] = RewardClaim{
+			Period:        v.Period,
+			TotalReferral: v.TotalReferral,
+			Reward:        v.Description,
+			Level:         v.Level,
+			Status:        v.Status,
+			DateTime:      v.CreatedDate.UnixNano() / 1000000,
+		}
+	}
+
+	resp = ListClaimResponse{
+		Code:    util.CodeSuccess,
+		Message: util.MessageSuccess,
+	}
+	resp.Data.List = list
+	return resp, nil
+}
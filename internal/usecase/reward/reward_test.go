@@ -0,0 +1,238 @@
+package reward
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+	"github.com/candraalim/be_tsel_candra/internal/util"
+)
+
+func TestSetupRewardUseCase(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupRewardUseCase(nil, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRewardUseCase(&mocks.ReferralHistoryRepository{}, nil, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRewardUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{}, nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRewardUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{}, &mocks.RewardClaimRepository{}, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupRewardUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{}, &mocks.RewardClaimRepository{},
+			&config.ReferralConfig{}, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupRewardUseCase(&mocks.ReferralHistoryRepository{}, &mocks.RewardRepository{}, &mocks.RewardClaimRepository{},
+			&config.ReferralConfig{}, &mocks.Transactor{})
+	})
+}
+
+// transactorMock run fn directly as if it were in a transaction
+func transactorMock() *mocks.Transactor {
+	transactor := &mocks.Transactor{}
+	transactor.On("WithinTransaction", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	return transactor
+}
+
+func Test_rewardUseCase_ClaimReward(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		r := rewardUseCase{}
+		_, err := r.ClaimReward(context.Background(), "080000acbcd", 1)
+		assert.NotNil(t, err)
+	})
+	t.Run("get total referral history return error", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(0, context.DeadlineExceeded)
+
+		r := rewardUseCase{historyRepository: historyMock}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.NotNil(t, err)
+	})
+	t.Run("no referral in current period", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+
+		r := rewardUseCase{historyRepository: historyMock}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.Equal(t, util.ErrorRewardNotAvailable, err)
+	})
+	t.Run("reward tier not reached", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 1, 1).Return(model.Reward{}, sql.ErrNoRows)
+
+		r := rewardUseCase{historyRepository: historyMock, rewardRepository: rewardMock}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.Equal(t, util.ErrorRewardNotAvailable, err)
+	})
+	t.Run("error get claim", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(5, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 1, 5).Return(model.Reward{ID: 2, TotalReferral: 5, Description: "bonus 12 GB"}, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdnRewardAndPeriod", mock.Anything, "62821000000", int64(2), mock.Anything).Return(model.RewardClaim{}, context.DeadlineExceeded)
+
+		r := rewardUseCase{historyRepository: historyMock, rewardRepository: rewardMock, claimRepository: claimMock}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.NotNil(t, err)
+	})
+	t.Run("reward already claimed", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(5, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 1, 5).Return(model.Reward{ID: 2, TotalReferral: 5, Description: "bonus 12 GB"}, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdnRewardAndPeriod", mock.Anything, "62821000000", int64(2), mock.Anything).Return(model.RewardClaim{ID: 7}, nil)
+
+		r := rewardUseCase{historyRepository: historyMock, rewardRepository: rewardMock, claimRepository: claimMock}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.Equal(t, util.ErrorRewardAlreadyClaimed, err)
+	})
+	t.Run("error insert claim", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(5, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 1, 5).Return(model.Reward{ID: 2, TotalReferral: 5, Description: "bonus 12 GB"}, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdnRewardAndPeriod", mock.Anything, "62821000000", int64(2), mock.Anything).Return(model.RewardClaim{}, sql.ErrNoRows)
+		claimMock.On("Insert", mock.Anything, mock.Anything).Return(util.ErrorRewardAlreadyClaimed)
+
+		r := rewardUseCase{historyRepository: historyMock, rewardRepository: rewardMock, claimRepository: claimMock,
+			transactor: transactorMock()}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.Equal(t, util.ErrorRewardAlreadyClaimed, err)
+	})
+	t.Run("success claim reward", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(5, nil)
+		historyMock.On("MarkRewarded", mock.Anything, "62821000000", time.Now().Format("2006-01")).Return(int64(5), nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 1, 5).Return(model.Reward{ID: 2, TotalReferral: 5, Description: "bonus 12 GB"}, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdnRewardAndPeriod", mock.Anything, "62821000000", int64(2), mock.Anything).Return(model.RewardClaim{}, sql.ErrNoRows)
+		claimMock.On("Insert", mock.Anything, mock.MatchedBy(func(c *model.RewardClaim) bool {
+			return c.Msisdn == "62821000000" && c.RewardID == 2 && c.Period == time.Now().Format("2006-01")
+		})).Return(nil)
+
+		r := rewardUseCase{historyRepository: historyMock, rewardRepository: rewardMock, claimRepository: claimMock,
+			transactor: transactorMock()}
+		resp, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.Nil(t, err)
+		assert.Equal(t, util.CodeSuccess, resp.Code)
+		assert.Equal(t, 5, resp.Data.TotalReferral)
+		assert.Equal(t, "bonus 12 GB", resp.Data.Reward)
+		claimMock.AssertExpectations(t)
+		historyMock.AssertExpectations(t)
+	})
+	t.Run("failed mark referral rewarded", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalByMsisdnAndMonth", mock.Anything, mock.Anything, mock.Anything).Return(5, nil)
+		historyMock.On("MarkRewarded", mock.Anything, "62821000000", mock.Anything).Return(int64(0), context.DeadlineExceeded)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 1, 5).Return(model.Reward{ID: 2, TotalReferral: 5, Description: "bonus 12 GB"}, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdnRewardAndPeriod", mock.Anything, "62821000000", int64(2), mock.Anything).Return(model.RewardClaim{}, sql.ErrNoRows)
+		claimMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+		r := rewardUseCase{historyRepository: historyMock, rewardRepository: rewardMock, claimRepository: claimMock,
+			transactor: transactorMock()}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 1)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+	t.Run("level exceed configured depth", func(t *testing.T) {
+		r := rewardUseCase{config: config.ReferralConfig{MaxLevel: 2}}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 3)
+		assert.Equal(t, util.ErrorRewardNotAvailable, err)
+	})
+	t.Run("no referral in level", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalPerLevelByMsisdnAndMonth", mock.Anything, "62821000000", mock.Anything, 2).
+			Return([]model.ReferralLevelTotal{{Level: 1, Total: 4}}, nil)
+
+		r := rewardUseCase{historyRepository: historyMock, config: config.ReferralConfig{MaxLevel: 2}}
+		_, err := r.ClaimReward(context.Background(), "62821000000", 2)
+		assert.Equal(t, util.ErrorRewardNotAvailable, err)
+	})
+	t.Run("success claim second level reward", func(t *testing.T) {
+		historyMock := &mocks.ReferralHistoryRepository{}
+		historyMock.On("GetTotalPerLevelByMsisdnAndMonth", mock.Anything, "62821000000", mock.Anything, 2).
+			Return([]model.ReferralLevelTotal{{Level: 1, Total: 4}, {Level: 2, Total: 6}}, nil)
+
+		rewardMock := &mocks.RewardRepository{}
+		rewardMock.On("FindByLevelAndTotalReferral", mock.Anything, 2, 6).Return(model.Reward{ID: 5, TotalReferral: 5,
+			Description: "bonus 3 GB", Level: 2}, nil)
+
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdnRewardAndPeriod", mock.Anything, "62821000000", int64(5), mock.Anything).Return(model.RewardClaim{}, sql.ErrNoRows)
+		claimMock.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+		r := rewardUseCase{historyRepository: historyMock, rewardRepository: rewardMock, claimRepository: claimMock,
+			config: config.ReferralConfig{MaxLevel: 2}, transactor: transactorMock()}
+		resp, err := r.ClaimReward(context.Background(), "62821000000", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, resp.Data.Level)
+		assert.Equal(t, 6, resp.Data.TotalReferral)
+		assert.Equal(t, "bonus 3 GB", resp.Data.Reward)
+	})
+}
+
+func Test_rewardUseCase_GetListClaim(t *testing.T) {
+	t.Run("invalid msisdn", func(t *testing.T) {
+		r := rewardUseCase{}
+		_, err := r.GetListClaim(context.Background(), "080000acbcd")
+		assert.NotNil(t, err)
+	})
+	t.Run("error get list", func(t *testing.T) {
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		r := rewardUseCase{claimRepository: claimMock}
+		_, err := r.GetListClaim(context.Background(), "62821000000")
+		assert.NotNil(t, err)
+	})
+	t.Run("return success", func(t *testing.T) {
+		claimMock := &mocks.RewardClaimRepository{}
+		claimMock.On("FindByMsisdn", mock.Anything, mock.Anything).Return([]model.RewardClaim{{
+			ID:            3,
+			Msisdn:        "62821000000",
+			RewardID:      2,
+			TotalReferral: 5,
+			Description:   "bonus 12 GB",
+			Period:        "2021-08",
+			CreatedDate:   time.Now(),
+		}}, nil)
+
+		r := rewardUseCase{claimRepository: claimMock}
+		resp, err := r.GetListClaim(context.Background(), "62821000000")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(resp.Data.List))
+		assert.Equal(t, "2021-08", resp.Data.List[0].Period)
+	})
+}
@@ -17,3 +17,11 @@ func ValidateAndSanitizeMsisdn(msisdn string) (string, error) {
 	}
 	return "", ErrorInvalidRequest
 }
+
+// MaskMsisdn hide middle digits of msisdn, only first 4 and last 3 digits are shown e.g. 6281******890
+func MaskMsisdn(msisdn string) string {
+	if len(msisdn) <= 7 {
+		return strings.Repeat("*", len(msisdn))
+	}
+	return msisdn[:4] + strings.Repeat("*", len(msisdn)-7) + msisdn[len(msisdn)-3:]
+}
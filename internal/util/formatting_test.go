@@ -25,3 +25,9 @@ func TestValidateAndSanitizeMsisdn(t *testing.T) {
 		assert.Equal(t, "6280000001", msisdn)
 	})
 }
+
+func TestMaskMsisdn(t *testing.T) {
+	assert.Equal(t, "6281******890", MaskMsisdn("6281234567890"))
+	assert.Equal(t, "6280**001", MaskMsisdn("628000001"))
+	assert.Equal(t, "*****", MaskMsisdn("62800"))
+}
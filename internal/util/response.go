@@ -16,9 +16,19 @@ func (e *ApplicationError) Error() string {
 }
 
 var (
-	ErrorDataNotFound         = &ApplicationError{HttpStatus: 400, ErrorCode: "0001", Message: "data not found"}
-	ErrorInvalidRequest       = &ApplicationError{HttpStatus: 400, ErrorCode: "0077", Message: "invalid request"}
-	ErrorDatabase             = &ApplicationError{HttpStatus: 500, ErrorCode: "0081", Message: "unexpected error"}
-	ErrorGenerateReferralCode = &ApplicationError{HttpStatus: 500, ErrorCode: "0082", Message: "unexpected error"}
-	ErrorGeneral              = &ApplicationError{HttpStatus: 500, ErrorCode: "9999", Message: "system internal error"}
+	ErrorDataNotFound            = &ApplicationError{HttpStatus: 400, ErrorCode: "0001", Message: "data not found"}
+	ErrorRewardNotAvailable      = &ApplicationError{HttpStatus: 400, ErrorCode: "0011", Message: "reward not available"}
+	ErrorRewardAlreadyClaimed    = &ApplicationError{HttpStatus: 400, ErrorCode: "0012", Message: "reward already claimed"}
+	ErrorReferralCodeInactive    = &ApplicationError{HttpStatus: 400, ErrorCode: "0021", Message: "referral code inactive"}
+	ErrorReferralCodeExpired     = &ApplicationError{HttpStatus: 400, ErrorCode: "0022", Message: "referral code expired"}
+	ErrorReferralCodeNotAllowed  = &ApplicationError{HttpStatus: 400, ErrorCode: "0023", Message: "referral code not allowed"}
+	ErrorReferralCodeUnavailable = &ApplicationError{HttpStatus: 400, ErrorCode: "0024", Message: "referral code already used"}
+	ErrorReferralCodeMalformed   = &ApplicationError{HttpStatus: 400, ErrorCode: "0025", Message: "malformed referral code"}
+	ErrorReferralRejected        = &ApplicationError{HttpStatus: 400, ErrorCode: "0031", Message: "referral rejected"}
+	ErrorReferralExpired         = &ApplicationError{HttpStatus: 400, ErrorCode: "0032", Message: "referral expired"}
+	ErrorReferralStatusInvalid   = &ApplicationError{HttpStatus: 400, ErrorCode: "0033", Message: "invalid referral status"}
+	ErrorInvalidRequest          = &ApplicationError{HttpStatus: 400, ErrorCode: "0077", Message: "invalid request"}
+	ErrorDatabase                = &ApplicationError{HttpStatus: 500, ErrorCode: "0081", Message: "unexpected error"}
+	ErrorGenerateReferralCode    = &ApplicationError{HttpStatus: 500, ErrorCode: "0082", Message: "unexpected error"}
+	ErrorGeneral                 = &ApplicationError{HttpStatus: 500, ErrorCode: "9999", Message: "system internal error"}
 )
@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxErrorLength is size of webhook_delivery.last_error column
+const maxErrorLength = 255
+
+// Dispatcher is outbox.EventPublisher delivering event to webhook subscription
+type Dispatcher interface {
+	// Publish queue event to every active subscription of the event, it is sent later by DeliverPending.
+	// Event already queued for a subscription is not queued again
+	Publish(ctx context.Context, event outbox.Event) error
+	// DeliverPending send due delivery, failed delivery is retried with exponential backoff until it is dead
+	DeliverPending(ctx context.Context) error
+}
+
+// Payload is body of callback, ID is the same for every subscription receiving the event and on every retry
+type Payload struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt int64           `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type dispatcher struct {
+	subscriptionRepository model.WebhookSubscriptionRepository
+	deliveryRepository     model.WebhookDeliveryRepository
+	config                 config.WebhookConfig
+	client                 *http.Client
+}
+
+func SetupDispatcher(subscriptionRepository model.WebhookSubscriptionRepository,
+	deliveryRepository model.WebhookDeliveryRepository,
+	webhookConfig *config.WebhookConfig) Dispatcher {
+	if subscriptionRepository == nil {
+		panic("WebhookSubscriptionRepository is nil")
+	}
+	if deliveryRepository == nil {
+		panic("WebhookDeliveryRepository is nil")
+	}
+	if webhookConfig == nil {
+		webhookConfig = &config.WebhookConfig{}
+	}
+	return &dispatcher{
+		subscriptionRepository: subscriptionRepository,
+		deliveryRepository:     deliveryRepository,
+		config:                 *webhookConfig,
+		client:                 &http.Client{Timeout: webhookConfig.Timeout()},
+	}
+}
+
+func (d dispatcher) Publish(ctx context.Context, event outbox.Event) error {
+	subscriptions, err := d.subscriptionRepository.FindActiveByEvent(ctx, event.Name)
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(Payload{
+		ID:        event.ID,
+		Event:     event.Name,
+		CreatedAt: event.CreatedAt.UnixNano() / 1000000,
+		Data:      event.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, v := range subscriptions {
+		err = d.deliveryRepository.Insert(ctx, &model.WebhookDelivery{
+			SubscriptionID: v.ID,
+			EventID:        event.ID,
+			Event:          event.Name,
+			Payload:        string(body),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d dispatcher) DeliverPending(ctx context.Context) error {
+	//claimed delivery is not picked up again until lease passed, long enough for every callback to time out
+	deliveries, err := d.deliveryRepository.ClaimDue(ctx, 2*d.config.Timeout(), d.config.Batch())
+	if err != nil || len(deliveries) == 0 {
+		return err
+	}
+
+	subscriptions := make(map[int64]model.WebhookSubscription)
+	for _, v := range deliveries {
+		if _, ok := subscriptions[v.SubscriptionID]; ok {
+			continue
+		}
+		subscription, err := d.subscriptionRepository.FindByID(ctx, v.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		subscriptions[v.SubscriptionID] = subscription
+	}
+
+	wg := sync.WaitGroup{}
+	for _, v := range deliveries {
+		wg.Add(1)
+		go func(delivery model.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, subscriptions[delivery.SubscriptionID], delivery)
+		}(v)
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver send delivery once and store the result
+func (d dispatcher) deliver(ctx context.Context, subscription model.WebhookSubscription, delivery model.WebhookDelivery) {
+	delivery.Attempt++
+	delivery.ResponseCode = 0
+	delivery.LastError = ""
+
+	var err error
+	//inactive subscription is not retried, its delivery is dead immediately
+	inactive := subscription.Status != model.WebhookSubscriptionStatusActive
+	if inactive {
+		err = fmt.Errorf("subscription is inactive")
+	} else {
+		delivery.ResponseCode, err = d.send(ctx, subscription, delivery)
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = model.WebhookDeliveryStatusDelivered
+	case inactive || delivery.Attempt >= d.config.Attempt():
+		delivery.Status = model.WebhookDeliveryStatusDead
+		log.Println("webhook delivery dead: ", delivery.ID, err)
+	default:
+		delivery.Status = model.WebhookDeliveryStatusPending
+		delivery.NextAttemptDate = time.Now().Add(d.config.Backoff(delivery.Attempt))
+	}
+	if err != nil {
+		delivery.LastError = truncate(err.Error(), maxErrorLength)
+	}
+
+	if err = d.deliveryRepository.UpdateResult(ctx, delivery); err != nil {
+		log.Println("failed to update webhook delivery: ", delivery.ID, err)
+	}
+}
+
+func (d dispatcher) send(ctx context.Context, subscription model.WebhookSubscription, delivery model.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, delivery.Event)
+	request.Header.Set(HeaderEventID, delivery.EventID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, []byte(delivery.Payload)))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	//drain body so connection is reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4096))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign return signature of callback, hex encoded HMAC-SHA256 of "timestamp.body" using subscription secret.
+// Receiver recompute it from X-Webhook-Timestamp header and raw body then compare with X-Webhook-Signature.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret return random secret for subscription registered without one
+func NewSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/candraalim/be_tsel_candra/config"
+	mocks "github.com/candraalim/be_tsel_candra/internal/mock/storage"
+	"github.com/candraalim/be_tsel_candra/internal/outbox"
+	"github.com/candraalim/be_tsel_candra/internal/storage/model"
+)
+
+func TestSetupDispatcher(t *testing.T) {
+	assert.Panics(t, func() {
+		SetupDispatcher(nil, nil, nil)
+	})
+	assert.Panics(t, func() {
+		SetupDispatcher(&mocks.WebhookSubscriptionRepository{}, nil, nil)
+	})
+	assert.NotPanics(t, func() {
+		SetupDispatcher(&mocks.WebhookSubscriptionRepository{}, &mocks.WebhookDeliveryRepository{}, nil)
+	})
+}
+
+func TestSign(t *testing.T) {
+	//echo -n '1628750347.{"id":"1"}' | openssl dgst -sha256 -hmac s3cr3t
+	assert.Equal(t, "sha256=f5c5e2b32aeff30826d48bd8600c23ec774cd719ed57c356066e1ac767dca487",
+		Sign("s3cr3t", 1628750347, []byte(`{"id":"1"}`)))
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	assert.Nil(t, err)
+	assert.Len(t, first, 48)
+	second, _ := NewSecret()
+	assert.NotEqual(t, first, second)
+}
+
+func Test_dispatcher_Publish(t *testing.T) {
+	createdAt := time.Date(2021, 8, 12, 7, 0, 0, 0, time.UTC)
+	event := outbox.Event{ID: "9f2c4a0d6e1b4c3a8d7e5f6a1b2c3d4e", Name: outbox.EventReferralCreated,
+		Data: json.RawMessage(`{"msisdn":"6280000011"}`), CreatedAt: createdAt}
+
+	t.Run("error find subscription", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindActiveByEvent", mock.Anything, outbox.EventReferralCreated).Return(nil, context.DeadlineExceeded)
+
+		d := SetupDispatcher(subscriptionMock, &mocks.WebhookDeliveryRepository{}, nil)
+		err := d.Publish(context.Background(), event)
+		assert.NotNil(t, err)
+	})
+	t.Run("no subscription", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindActiveByEvent", mock.Anything, outbox.EventReferralCreated).Return(nil, nil)
+
+		d := SetupDispatcher(subscriptionMock, &mocks.WebhookDeliveryRepository{}, nil)
+		err := d.Publish(context.Background(), event)
+		assert.Nil(t, err)
+	})
+	t.Run("error queue delivery", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindActiveByEvent", mock.Anything, outbox.EventReferralCreated).Return([]model.WebhookSubscription{
+			{ID: 1},
+		}, nil)
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("Insert", mock.Anything, mock.Anything).Return(context.DeadlineExceeded)
+
+		d := SetupDispatcher(subscriptionMock, deliveryMock, nil)
+		err := d.Publish(context.Background(), event)
+		assert.NotNil(t, err)
+	})
+	t.Run("queued to every subscription with event id", func(t *testing.T) {
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindActiveByEvent", mock.Anything, outbox.EventReferralCreated).Return([]model.WebhookSubscription{
+			{ID: 1}, {ID: 2},
+		}, nil)
+		var deliveries []*model.WebhookDelivery
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			deliveries = append(deliveries, args.Get(1).(*model.WebhookDelivery))
+		}).Return(nil)
+
+		d := SetupDispatcher(subscriptionMock, deliveryMock, nil)
+		err := d.Publish(context.Background(), event)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(deliveries))
+		assert.Equal(t, int64(2), deliveries[1].SubscriptionID)
+		assert.Equal(t, event.ID, deliveries[0].EventID)
+		assert.Equal(t, event.ID, deliveries[1].EventID)
+
+		var payload Payload
+		assert.Nil(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+		assert.Equal(t, event.ID, payload.ID)
+		assert.Equal(t, outbox.EventReferralCreated, payload.Event)
+		assert.Equal(t, createdAt.UnixNano()/1000000, payload.CreatedAt)
+		assert.JSONEq(t, `{"msisdn":"6280000011"}`, string(payload.Data))
+	})
+}
+
+// receiver is local webhook endpoint verifying signature of every callback
+type receiver struct {
+	t      *testing.T
+	secret string
+	status int
+
+	mu       sync.Mutex
+	received []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	assert.Equal(r.t, Sign(r.secret, timestamp, body), req.Header.Get(HeaderSignature))
+	assert.Equal(r.t, "application/json", req.Header.Get("Content-Type"))
+
+	r.mu.Lock()
+	r.received = append(r.received, req.Header.Get(HeaderEvent)+" "+req.Header.Get(HeaderEventID))
+	r.mu.Unlock()
+	w.WriteHeader(r.status)
+}
+
+func Test_dispatcher_DeliverPending(t *testing.T) {
+	webhookConfig := &config.WebhookConfig{MaxAttempt: 3, BackoffSeconds: 60}
+
+	setup := func(status int, deliveries ...model.WebhookDelivery) (*receiver, *mocks.WebhookDeliveryRepository, Dispatcher) {
+		rcv := &receiver{t: t, secret: "s3cr3t", status: status}
+		server := httptest.NewServer(rcv)
+		t.Cleanup(server.Close)
+
+		subscriptionMock := &mocks.WebhookSubscriptionRepository{}
+		subscriptionMock.On("FindByID", mock.Anything, int64(1)).Return(model.WebhookSubscription{ID: 1, URL: server.URL,
+			Secret: "s3cr3t", Status: model.WebhookSubscriptionStatusActive}, nil).Once()
+		subscriptionMock.On("FindByID", mock.Anything, int64(2)).Return(model.WebhookSubscription{ID: 2, URL: server.URL,
+			Secret: "s3cr3t", Status: model.WebhookSubscriptionStatusInactive}, nil).Once()
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("ClaimDue", mock.Anything, 20*time.Second, 50).Return(deliveries, nil)
+		return rcv, deliveryMock, SetupDispatcher(subscriptionMock, deliveryMock, webhookConfig)
+	}
+
+	t.Run("error claim delivery", func(t *testing.T) {
+		deliveryMock := &mocks.WebhookDeliveryRepository{}
+		deliveryMock.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+		d := SetupDispatcher(&mocks.WebhookSubscriptionRepository{}, deliveryMock, webhookConfig)
+		assert.NotNil(t, d.DeliverPending(context.Background()))
+	})
+	t.Run("delivered", func(t *testing.T) {
+		rcv, deliveryMock, d := setup(http.StatusNoContent,
+			model.WebhookDelivery{ID: 1, SubscriptionID: 1, EventID: "a1", Event: outbox.EventReferralCreated, Payload: `{"id":"a1"}`},
+			model.WebhookDelivery{ID: 2, SubscriptionID: 1, EventID: "b2", Event: outbox.EventCodeGenerated, Payload: `{"id":"b2"}`})
+		deliveryMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(v model.WebhookDelivery) bool {
+			return v.Status == model.WebhookDeliveryStatusDelivered && v.Attempt == 1 && v.ResponseCode == 204
+		})).Return(nil).Twice()
+
+		assert.Nil(t, d.DeliverPending(context.Background()))
+		assert.ElementsMatch(t, []string{"referral.created a1", "code.generated b2"}, rcv.received)
+		deliveryMock.AssertExpectations(t)
+	})
+	t.Run("failed delivery retried with backoff", func(t *testing.T) {
+		_, deliveryMock, d := setup(http.StatusInternalServerError,
+			model.WebhookDelivery{ID: 1, SubscriptionID: 1, EventID: "a1", Event: outbox.EventReferralCreated, Attempt: 1})
+		deliveryMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(v model.WebhookDelivery) bool {
+			//second failure wait twice the backoff
+			wait := time.Until(v.NextAttemptDate)
+			return v.Status == model.WebhookDeliveryStatusPending && v.Attempt == 2 && v.ResponseCode == 500 &&
+				v.LastError == "unexpected status 500" && wait > 119*time.Second && wait <= 120*time.Second
+		})).Return(nil).Once()
+
+		assert.Nil(t, d.DeliverPending(context.Background()))
+		deliveryMock.AssertExpectations(t)
+	})
+	t.Run("dead after max attempt", func(t *testing.T) {
+		_, deliveryMock, d := setup(http.StatusBadRequest,
+			model.WebhookDelivery{ID: 1, SubscriptionID: 1, EventID: "a1", Event: outbox.EventReferralCreated, Attempt: 2})
+		deliveryMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(v model.WebhookDelivery) bool {
+			return v.Status == model.WebhookDeliveryStatusDead && v.Attempt == 3 && v.ResponseCode == 400
+		})).Return(nil).Once()
+
+		assert.Nil(t, d.DeliverPending(context.Background()))
+		deliveryMock.AssertExpectations(t)
+	})
+	t.Run("inactive subscription is dead immediately", func(t *testing.T) {
+		rcv, deliveryMock, d := setup(http.StatusOK,
+			model.WebhookDelivery{ID: 1, SubscriptionID: 2, EventID: "a1", Event: outbox.EventReferralCreated})
+		deliveryMock.On("UpdateResult", mock.Anything, mock.MatchedBy(func(v model.WebhookDelivery) bool {
+			return v.Status == model.WebhookDeliveryStatusDead && v.Attempt == 1 && v.LastError == "subscription is inactive"
+		})).Return(nil).Once()
+
+		assert.Nil(t, d.DeliverPending(context.Background()))
+		assert.Empty(t, rcv.received)
+		deliveryMock.AssertExpectations(t)
+	})
+}